@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// levelColors are the ANSI colors of the log levels on a terminal.
+var levelColors = []struct {
+	level []byte
+	color string
+}{
+	{[]byte("DEBUG"), "\x1b[35m"},
+	{[]byte("INFO"), "\x1b[34m"},
+	{[]byte("WARN"), "\x1b[33m"},
+	{[]byte("ERROR"), "\x1b[31m"},
+}
+
+// colorWriter colors the level of each slog text record written through
+// it. slog quotes escape sequences in attribute values, so the level is
+// colored after formatting.
+type colorWriter struct {
+	w io.Writer
+}
+
+func (c colorWriter) Write(p []byte) (int, error) {
+	// The record level precedes the message and all attributes.
+	i := bytes.Index(p, []byte("level="))
+	if i < 0 {
+		return c.w.Write(p)
+	}
+	i += len("level=")
+	for _, lc := range levelColors {
+		if !bytes.HasPrefix(p[i:], lc.level) {
+			continue
+		}
+		end := i + len(lc.level)
+		out := make([]byte, 0, len(p)+len(lc.color)+len("\x1b[0m"))
+		out = append(out, p[:i]...)
+		out = append(out, lc.color...)
+		out = append(out, p[i:end]...)
+		out = append(out, "\x1b[0m"...)
+		out = append(out, p[end:]...)
+		if _, err := c.w.Write(out); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return c.w.Write(p)
+}
+
+// useColor reports whether logs written to f get colored levels: f must be
+// a terminal, and neither --no-color nor NO_COLOR may be set.
+func useColor(f *os.File) bool {
+	return !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestColorWriter(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"time=t level=ERROR msg=failed\n", "time=t level=\x1b[31mERROR\x1b[0m msg=failed\n"},
+		{"time=t level=WARN msg=slow\n", "time=t level=\x1b[33mWARN\x1b[0m msg=slow\n"},
+		{"time=t level=INFO+2 msg=x\n", "time=t level=\x1b[34mINFO\x1b[0m+2 msg=x\n"},
+		// Only the record level is colored, not attribute values.
+		{"time=t level=DEBUG msg=x old=level=ERROR\n", "time=t level=\x1b[35mDEBUG\x1b[0m msg=x old=level=ERROR\n"},
+		{"time=t level=INFO msg=\"x level=DEBUG y\"\n", "time=t level=\x1b[34mINFO\x1b[0m msg=\"x level=DEBUG y\"\n"},
+		{"no level\n", "no level\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		n, err := colorWriter{&buf}.Write([]byte(tt.in))
+		if err != nil || n != len(tt.in) {
+			t.Errorf("Write(%q) = %d, %v", tt.in, n, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Write(%q) wrote %q, want %q", tt.in, buf.String(), tt.want)
+		}
+	}
+}
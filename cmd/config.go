@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Long: `Print the fully-resolved configuration as YAML: built-in defaults,
+merged with every --config file (and its includes), then ZTC_* environment
+overrides. Secrets read from *_file paths are resolved as well.
+
+The Zabbix API password and Vulners API key are masked.
+
+Examples:
+  ztc config show
+  ZTC_ZABBIX_SERVER_PORT=10052 ztc config show -c /etc/ztc.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeConfigYAML(os.Stdout, cfg)
+	},
+}
+
+// writeConfigYAML writes cfg to w as YAML with secrets masked.
+func writeConfigYAML(w io.Writer, c *config.Config) error {
+	out, err := yaml.Parser().Marshal(c.Redacted().ToMap())
+	if err != nil {
+		return fmt.Errorf("failed to render config: %w", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
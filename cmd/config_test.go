@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+)
+
+func TestWriteConfigYAML(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.APIUser = "Admin"
+	cfg.Zabbix.APIPassword = "zabbix-secret"
+	cfg.Vulners.APIKey = "vulners-secret"
+	cfg.Zabbix.ServerPort = 10052
+
+	var out bytes.Buffer
+	if err := writeConfigYAML(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Errorf("output leaks a secret:\n%s", out.String())
+	}
+
+	// The dump must load back strictly into the same (masked) config.
+	path := filepath.Join(t.TempDir(), "dump.yaml")
+	if err := os.WriteFile(path, out.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := config.LoadWithOptions(path, config.LoadOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("reloading dump: %v\n%s", err, out.String())
+	}
+	if got.Zabbix.ServerPort != 10052 || got.Zabbix.APIUser != "Admin" {
+		t.Errorf("reloaded port/user = %d/%q, want 10052/Admin", got.Zabbix.ServerPort, got.Zabbix.APIUser)
+	}
+	if got.Zabbix.APIPassword != "********" {
+		t.Errorf("reloaded password = %q, want mask", got.Zabbix.APIPassword)
+	}
+}
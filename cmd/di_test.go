@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/fixer"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+// TestDIGraphs checks that every dependency of the scanner and fixer can be
+// resolved, without running the constructors (which contact Zabbix).
+func TestDIGraphs(t *testing.T) {
+	cfg := config.DefaultConfig()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var s *scanner.Scanner
+	if err := fx.ValidateApp(fx.Supply(cfg, log), scanner.Module, fx.Populate(&s)); err != nil {
+		t.Errorf("scanner graph: %v", err)
+	}
+
+	var f *fixer.Fixer
+	if err := fx.ValidateApp(fx.Supply(cfg, log), fixer.Module, fx.Populate(&f)); err != nil {
+		t.Errorf("fixer graph: %v", err)
+	}
+}
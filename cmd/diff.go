@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+var diffFormat string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD.json NEW.json",
+	Short: "Compare two exported scans",
+	Long: `Compare two scan exports written by 'ztc scan --export' and report
+newly-appeared and resolved vulnerable packages and bulletins, plus
+per-host score changes. Useful for tracking remediation progress.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		old, err := scanner.ReadJSONFile(args[0])
+		if err != nil {
+			return err
+		}
+		cur, err := scanner.ReadJSONFile(args[1])
+		if err != nil {
+			return err
+		}
+
+		d := scanner.Diff(old.ScanResults, cur.ScanResults)
+
+		switch diffFormat {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(d)
+		case "table":
+			return writeDiffTable(os.Stdout, d)
+		default:
+			return fmt.Errorf("unsupported format %q (want table or json)", diffFormat)
+		}
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFormat, "format", "table", "output format: table or json")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func writeDiffTable(out io.Writer, d *scanner.ScanDiff) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "NEW PACKAGES (%d)\n", len(d.NewPackages))
+	for _, p := range d.NewPackages {
+		fmt.Fprintf(w, "  + %s\t%s\t%s\t%.1f\n", p.Name, p.Version, p.Arch, p.Score)
+	}
+	fmt.Fprintf(w, "RESOLVED PACKAGES (%d)\n", len(d.ResolvedPackages))
+	for _, p := range d.ResolvedPackages {
+		fmt.Fprintf(w, "  - %s\t%s\t%s\t%.1f\n", p.Name, p.Version, p.Arch, p.Score)
+	}
+	fmt.Fprintf(w, "NEW BULLETINS (%d)\n", len(d.NewBulletins))
+	for _, b := range d.NewBulletins {
+		fmt.Fprintf(w, "  + %s\t%.1f\n", b.ID, b.Score)
+	}
+	fmt.Fprintf(w, "RESOLVED BULLETINS (%d)\n", len(d.ResolvedBulletins))
+	for _, b := range d.ResolvedBulletins {
+		fmt.Fprintf(w, "  - %s\t%.1f\n", b.ID, b.Score)
+	}
+	fmt.Fprintf(w, "HOST SCORE CHANGES (%d)\n", len(d.HostScoreChanges))
+	for _, c := range d.HostScoreChanges {
+		fmt.Fprintf(w, "  %s\t%s\t%.1f -> %.1f\t(%+.1f)\n", c.HostID, c.Name, c.OldScore, c.NewScore, c.Delta())
+	}
+
+	return w.Flush()
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"log/slog"
 
@@ -14,11 +19,21 @@ import (
 var (
 	fixBulletinID string
 	fixHostID     string
-	fixHostName   string
+	fixHostNames  []string
 	fixDryRun     bool
 	fixUseSSH     bool
 	fixSSHUser    string
 	fixForce      bool
+
+	fixIncludeDisabled bool
+	fixPackagesOnly    bool
+	fixBulletinsOnly   bool
+	fixAgentWait       bool
+	fixAgentTimeout    time.Duration
+	fixYes             bool
+	fixAllowStale      bool
+	fixLimit           int
+	fixOrder           string
 )
 
 var fixCmd = &cobra.Command{
@@ -31,10 +46,54 @@ to determine which packages to update. If no per-package data is available,
 it falls back to a full system update. Use --dry-run to review the plan
 before executing. Use --force to skip the confirmation prompt.
 
+When a plan spans several hosts (or comes from --bulletin) and stdin is a
+terminal, the host count and commands are shown and the fix only proceeds
+after typing "yes". Pass --yes to skip this for unattended runs.
+
+The plan is refused when the scan data it is built from was pushed more
+than fix.max_data_age hours ago (default 168), since those packages may
+already be fixed or superseded. --dry-run only warns; pass --allow-stale to
+fix from the old data anyway.
+
+Each finished host is logged with a running "fixed N/M" counter; when
+stderr is a terminal a progress bar is drawn as well.
+
+By default a --host fix upgrades the packages the last scan found on the
+host, falling back to a full system update without scan data, and a
+--bulletin fix upgrades the bulletin's packages that the scan also found on
+each affected host. --packages-only and --bulletins-only limit the plan to
+one data source and never fall back to a full update:
+- --packages-only uses only package data; it cannot be combined with
+  --bulletin.
+- --bulletins-only uses only bulletin data: with --host it upgrades the
+  packages of every bulletin affecting the host, with --bulletin all of the
+  bulletin's packages on each affected host, even when no package data was
+  pushed (scan.enabled_lld without "packages").
+When --host and --bulletin are both given, --host wins.
+
+--host-name takes a comma-separated list (or is repeated) to fix several
+hosts by technical name in one plan; each is resolved to its host ID and
+ZTC's virtual hosts are refused. --host wins over --host-name.
+
+For a paced rollout of a --bulletin fix, --order sorts the affected hosts
+(score-desc: highest host CVSS score first, name: by visible name) and
+--limit N plans only the first N of them, e.g. a canary batch.
+
 This command can fix vulnerabilities by:
 - Installing package updates via Zabbix agent (default)
 - Executing commands via SSH (--ssh)
 
+Agent-based fixes first run a harmless system.run probe on each host and
+fail that host with a clear error if the agent does not allow remote
+commands.
+
+By default agent fixes are launched with system.run[...,nowait]: success
+only means the command started. --agent-wait waits for the command and
+checks its output and exit status instead, but the agent kills commands
+that outlive its Timeout setting (at most 30s before Zabbix 7.0), so large
+upgrades may be cut short. Raise the agent Timeout and --agent-timeout
+together, or use --ssh for long-running fixes.
+
 NOTE: Unlike the Python version, which used Vulners-provided fix commands
 (specific version pins), the Go version generates generic OS package manager
 commands (apt-get install --only-upgrade / yum update) with package names
@@ -47,10 +106,21 @@ Always review the remediation plan before executing.`,
 		log := GetLogger()
 		cfg := GetConfig()
 
-		if fixBulletinID == "" && fixHostID == "" && fixHostName == "" {
+		if err := validateHostNames(fixHostNames, cmd.Flags().Changed("host-name")); err != nil {
+			return err
+		}
+		if fixBulletinID == "" && fixHostID == "" && len(fixHostNames) == 0 {
 			return fmt.Errorf("either --bulletin, --host, or --host-name must be specified")
 		}
 
+		if (fixLimit != 0 || fixOrder != "") && (fixBulletinID == "" || fixHostID != "" || len(fixHostNames) > 0) {
+			return fmt.Errorf("--limit and --order only apply to --bulletin fixes")
+		}
+
+		if fixAgentWait && fixUseSSH {
+			return fmt.Errorf("--agent-wait cannot be combined with --ssh")
+		}
+
 		if !fixForce && !fixDryRun {
 			fmt.Fprintln(os.Stderr, "WARNING: The fix command is experimental and executes remote commands.")
 			fmt.Fprintln(os.Stderr, "Use --dry-run to review the plan first, or --force to skip this check.")
@@ -68,10 +138,18 @@ Always review the remediation plan before executing.`,
 		opts := fixer.FixOptions{
 			BulletinID: fixBulletinID,
 			HostID:     fixHostID,
-			HostName:   fixHostName,
+			HostNames:  fixHostNames,
 			DryRun:     fixDryRun,
 			UseSSH:     fixUseSSH,
 			SSHUser:    fixSSHUser,
+
+			IncludeDisabled: fixIncludeDisabled,
+			AllowStale:      fixAllowStale,
+			Scope:           fixScope(),
+			Order:           fixOrder,
+			Limit:           fixLimit,
+			AgentWait:       fixAgentWait,
+			AgentTimeout:    fixAgentTimeout,
 		}
 
 		plan, err := f.Plan(opts)
@@ -94,6 +172,23 @@ Always review the remediation plan before executing.`,
 			return nil
 		}
 
+		// Bulletin-wide plans can touch a whole fleet; make an operator at a
+		// terminal confirm the scope even when --force was given.
+		massFix := fixBulletinID != "" || len(plan.Hosts) > 1
+		if massFix && len(plan.Hosts) > 0 && !fixYes && isTerminal(os.Stdin) {
+			ok, err := confirmFix(os.Stdin, os.Stderr, plan)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("fix cancelled")
+			}
+		}
+
+		if isTerminal(os.Stderr) {
+			opts.Progress = fixProgressBar(stderrProgress)
+		}
+
 		log.Info("Executing fix plan...")
 		results, err := f.Execute(plan, opts)
 		if err != nil {
@@ -112,11 +207,112 @@ Always review the remediation plan before executing.`,
 func init() {
 	fixCmd.Flags().StringVar(&fixBulletinID, "bulletin", "", "bulletin ID to fix")
 	fixCmd.Flags().StringVar(&fixHostID, "host", "", "specific host ID to fix")
-	fixCmd.Flags().StringVar(&fixHostName, "host-name", "", "host technical name to fix (resolved to host ID)")
+	fixCmd.Flags().StringSliceVar(&fixHostNames, "host-name", nil, "host technical names to fix, comma-separated or repeated (resolved to host IDs)")
 	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "show fix plan without executing")
 	fixCmd.Flags().BoolVar(&fixUseSSH, "ssh", false, "use SSH instead of Zabbix agent")
 	fixCmd.Flags().StringVar(&fixSSHUser, "ssh-user", "root", "SSH user for remote execution")
 	fixCmd.Flags().BoolVar(&fixForce, "force", false, "skip experimental confirmation prompt")
+	fixCmd.Flags().BoolVar(&fixIncludeDisabled, "include-disabled", false, "also fix hosts that are disabled in Zabbix")
+	fixCmd.Flags().BoolVar(&fixAllowStale, "allow-stale", false, "fix from scan data older than fix.max_data_age")
+	fixCmd.Flags().BoolVar(&fixPackagesOnly, "packages-only", false, "plan only from package data")
+	fixCmd.Flags().BoolVar(&fixBulletinsOnly, "bulletins-only", false, "plan only from bulletin data")
+	fixCmd.Flags().BoolVar(&fixAgentWait, "agent-wait", false, "wait for agent fix commands and check their output and exit status")
+	fixCmd.Flags().IntVar(&fixLimit, "limit", 0, "with --bulletin, plan at most N hosts, taken in --order (0 = all)")
+	fixCmd.Flags().StringVar(&fixOrder, "order", "", "with --bulletin, order affected hosts by score-desc or name (default: LLD order)")
+	fixCmd.Flags().BoolVar(&fixYes, "yes", false, "do not ask for confirmation before executing the plan")
+	fixCmd.Flags().DurationVar(&fixAgentTimeout, "agent-timeout", 30*time.Second, "with --agent-wait, zabbix_get timeout per command (keep within the agent's Timeout)")
+
+	fixCmd.MarkFlagsMutuallyExclusive("packages-only", "bulletins-only")
 
 	rootCmd.AddCommand(fixCmd)
 }
+
+// validateHostNames rejects empty --host-name entries, including a flag
+// given with an empty value (set reports whether it was given).
+func validateHostNames(names []string, set bool) error {
+	if set && len(names) == 0 {
+		return fmt.Errorf("--host-name must not be empty")
+	}
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("--host-name must not contain empty names, got %q", strings.Join(names, ","))
+		}
+	}
+	return nil
+}
+
+// fixScope returns the fixer.FixOptions.Scope selected by the scope flags.
+func fixScope() string {
+	switch {
+	case fixPackagesOnly:
+		return fixer.ScopePackages
+	case fixBulletinsOnly:
+		return fixer.ScopeBulletins
+	default:
+		return fixer.ScopeAll
+	}
+}
+
+// maxConfirmHosts is how many host names the confirmation summary lists.
+const maxConfirmHosts = 10
+
+// confirmFix prints a summary of plan to out and reports whether the user
+// typed "yes" on in.
+func confirmFix(in io.Reader, out io.Writer, plan *fixer.FixPlan) (bool, error) {
+	fmt.Fprintf(out, "\nAbout to run fix commands on %d host(s):\n", len(plan.Hosts))
+	for i, h := range plan.Hosts {
+		if i == maxConfirmHosts {
+			fmt.Fprintf(out, "  ... and %d more\n", len(plan.Hosts)-maxConfirmHosts)
+			break
+		}
+		fmt.Fprintf(out, "  %s (%s)\n", h.Name, h.IP)
+	}
+
+	// Group hosts by command so a fleet-wide plan stays readable.
+	counts := make(map[string]int)
+	var commands []string
+	for _, h := range plan.Hosts {
+		if counts[h.Command] == 0 {
+			commands = append(commands, h.Command)
+		}
+		counts[h.Command]++
+	}
+	fmt.Fprintln(out, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  [%d host(s)] %s\n", counts[c], c)
+	}
+
+	fmt.Fprint(out, "\nOnly 'yes' will be accepted to continue: ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	return strings.TrimSpace(answer) == "yes", nil
+}
+
+// progressBarWidth is the number of cells in the fix and scan progress bars.
+const progressBarWidth = 30
+
+// fixProgressBar returns a FixOptions.Progress callback that redraws the
+// progress bar p.
+func fixProgressBar(p *progressLine) func(done, total int, result fixer.HostFixResult) {
+	failed := 0
+	return func(done, total int, result fixer.HostFixResult) {
+		if !result.Success {
+			failed++
+		}
+		filled := progressBarWidth * done / total
+		line := fmt.Sprintf("[%s%s] %d/%d hosts fixed",
+			strings.Repeat("#", filled), strings.Repeat(".", progressBarWidth-filled), done, total)
+		if failed > 0 {
+			line += fmt.Sprintf(" (%d failed)", failed)
+		}
+		p.draw(line, done == total)
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/fixer"
+)
+
+func TestConfirmFix(t *testing.T) {
+	plan := &fixer.FixPlan{}
+	for i := 0; i < 12; i++ {
+		cmd := "apt-get update && apt-get install -y --only-upgrade 'openssl'"
+		if i%4 == 0 {
+			cmd = "yum update -y 'openssl'"
+		}
+		plan.Hosts = append(plan.Hosts, fixer.HostFixPlan{
+			Name: fmt.Sprintf("web-%02d", i), IP: fmt.Sprintf("10.0.0.%d", i), Command: cmd,
+		})
+	}
+
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{"yes\n", true},
+		{"  yes  \n", true},
+		{"y\n", false},
+		{"YES\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got, err := confirmFix(strings.NewReader(tt.answer), &out, plan)
+		if err != nil {
+			t.Fatalf("confirmFix(%q): %v", tt.answer, err)
+		}
+		if got != tt.want {
+			t.Errorf("confirmFix(%q) = %v, want %v", tt.answer, got, tt.want)
+		}
+	}
+
+	var out bytes.Buffer
+	_, _ = confirmFix(strings.NewReader("no\n"), &out, plan)
+	summary := out.String()
+	for _, want := range []string{
+		"on 12 host(s)",
+		"web-09 (10.0.0.9)",
+		"... and 2 more",
+		"[9 host(s)] apt-get",
+		"[3 host(s)] yum",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "web-10") {
+		t.Errorf("summary lists more than %d hosts:\n%s", maxConfirmHosts, summary)
+	}
+}
+
+func TestFixProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	progress := fixProgressBar(&progressLine{w: &out})
+
+	progress(1, 3, fixer.HostFixResult{Success: true})
+	if got := out.String(); got != "\r["+strings.Repeat("#", 10)+strings.Repeat(".", 20)+"] 1/3 hosts fixed" {
+		t.Errorf("first update = %q", got)
+	}
+
+	out.Reset()
+	progress(2, 3, fixer.HostFixResult{Success: false})
+	if got := out.String(); !strings.HasSuffix(got, "2/3 hosts fixed (1 failed)") {
+		t.Errorf("second update = %q", got)
+	}
+
+	out.Reset()
+	progress(3, 3, fixer.HostFixResult{Success: true})
+	if got := out.String(); !strings.HasSuffix(got, "3/3 hosts fixed (1 failed)\n") {
+		t.Errorf("final update = %q, want trailing newline", got)
+	}
+}
+
+func TestValidateHostNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		set     bool
+		wantErr bool
+	}{
+		{"not given", nil, false, false},
+		{"one name", []string{"web-01"}, true, false},
+		{"several names", []string{"web-01", "db-01"}, true, false},
+		{"empty flag", nil, true, true},
+		{"empty entry", []string{"web-01", ""}, true, true},
+		{"blank entry", []string{" ", "db-01"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHostNames(tt.names, tt.set)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateHostNames(%q, %v) = %v, wantErr %v", tt.names, tt.set, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+var (
+	hostsFormat     string
+	hostsLinkGroup  string
+	hostsLinkDryRun bool
+)
+
+var hostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "Inspect and onboard the Zabbix hosts ZTC scans",
+}
+
+var hostsMissingTemplateCmd = &cobra.Command{
+	Use:   "missing-template",
+	Short: "List monitored hosts without the OS-Report template",
+	Long: `List the monitored Zabbix hosts that do not have the OS-Report template
+(scan.os_report_template) linked and are therefore never scanned. The ZTC
+virtual hosts are left out.
+
+Use it to track the template rollout and expand scan coverage.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		cfg := GetConfig()
+
+		client, err := initZabbixClient(cfg, log)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Zabbix: %w", err)
+		}
+		defer func() { _ = client.Close() }()
+
+		hosts, err := client.GetHostsMissingTemplateCtx(context.Background(), cfg.Scan.OSReportTemplate)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch hostsFormat {
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(hosts)
+		case "table":
+			return writeHostsTable(out, hosts)
+		default:
+			return fmt.Errorf("unsupported format %q (want table or json)", hostsFormat)
+		}
+	},
+}
+
+var hostsLinkTemplateCmd = &cobra.Command{
+	Use:   "link-template",
+	Short: "Link the OS-Report template to every host in a host group",
+	Long: `Link the OS-Report template (scan.os_report_template) to all hosts of a
+host group that do not have it yet, in a single host.massadd call. The
+template is created first when it does not exist.
+
+Use --dry-run to list the hosts that would be linked without changing
+anything.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		cfg := GetConfig()
+
+		if hostsLinkGroup == "" {
+			return fmt.Errorf("--group must be specified")
+		}
+
+		client, err := initZabbixClient(cfg, log)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Zabbix: %w", err)
+		}
+		defer func() { _ = client.Close() }()
+
+		hosts, err := client.LinkOSReportTemplateCtx(context.Background(), hostsLinkGroup, hostsLinkDryRun)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		verb := "Linked"
+		if hostsLinkDryRun {
+			verb = "Would link"
+		}
+		fmt.Fprintf(out, "%s the OS-Report template to %d host(s) in %s\n", verb, len(hosts), hostsLinkGroup)
+		for _, h := range hosts {
+			fmt.Fprintf(out, "  %s\t%s\n", h.HostID, h.Host)
+		}
+		return nil
+	},
+}
+
+func init() {
+	hostsMissingTemplateCmd.Flags().StringVar(&hostsFormat, "format", "table", "output format: table or json")
+
+	hostsLinkTemplateCmd.Flags().StringVar(&hostsLinkGroup, "group", "", "host group whose hosts get the template")
+	hostsLinkTemplateCmd.Flags().BoolVar(&hostsLinkDryRun, "dry-run", false, "list the hosts that would be linked without linking them")
+
+	hostsCmd.AddCommand(hostsMissingTemplateCmd)
+	hostsCmd.AddCommand(hostsLinkTemplateCmd)
+	rootCmd.AddCommand(hostsCmd)
+}
+
+func writeHostsTable(out io.Writer, hosts []zabbix.Host) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "HOSTS WITHOUT THE OS-REPORT TEMPLATE (%d)\n", len(hosts))
+	for _, h := range hosts {
+		groups := make([]string, len(h.Groups))
+		for i, g := range h.Groups {
+			groups[i] = g.Name
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", h.HostID, h.Host, h.Name, strings.Join(groups, ", "))
+	}
+
+	return w.Flush()
+}
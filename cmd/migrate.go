@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -84,29 +85,85 @@ func renderYAML(cfg *config.Config) ([]byte, error) {
 	writeStr(&buf, "  ", "front_url", cfg.Zabbix.FrontURL, defaults.Zabbix.FrontURL)
 	buf.WriteString(fmt.Sprintf("  api_user: %s\n", yamlQuote(cfg.Zabbix.APIUser)))
 	buf.WriteString(fmt.Sprintf("  api_password: %s\n", yamlQuote(cfg.Zabbix.APIPassword)))
+	writeNonDefault(&buf, "  ", "api_password_file", cfg.Zabbix.APIPasswordFile, "")
+	writeNonDefault(&buf, "  ", "api_token", cfg.Zabbix.APIToken, "")
+	writeNonDefault(&buf, "  ", "api_token_file", cfg.Zabbix.APITokenFile, "")
 	writeStr(&buf, "  ", "server_fqdn", cfg.Zabbix.ServerFQDN, defaults.Zabbix.ServerFQDN)
 	writeInt(&buf, "  ", "server_port", cfg.Zabbix.ServerPort, defaults.Zabbix.ServerPort)
 	writeStr(&buf, "  ", "sender_path", cfg.Zabbix.SenderPath, defaults.Zabbix.SenderPath)
 	writeStr(&buf, "  ", "get_path", cfg.Zabbix.GetPath, defaults.Zabbix.GetPath)
 	writeBool(&buf, "  ", "verify_ssl", cfg.Zabbix.VerifySSL, defaults.Zabbix.VerifySSL)
+	writeInt(&buf, "  ", "api_timeout", cfg.Zabbix.APITimeout, defaults.Zabbix.APITimeout)
+	writeInt(&buf, "  ", "max_concurrent_requests", cfg.Zabbix.MaxConcurrentRequests, defaults.Zabbix.MaxConcurrentRequests)
+	writeStr(&buf, "  ", "content_type", cfg.Zabbix.ContentType, defaults.Zabbix.ContentType)
 
 	buf.WriteString("\nvulners:\n")
 	buf.WriteString(fmt.Sprintf("  api_key: %s\n", yamlQuote(cfg.Vulners.APIKey)))
+	writeNonDefault(&buf, "  ", "api_key_file", cfg.Vulners.APIKeyFile, "")
 	writeStr(&buf, "  ", "host", cfg.Vulners.Host, defaults.Vulners.Host)
 	writeInt(&buf, "  ", "rate_limit", cfg.Vulners.RateLimit, defaults.Vulners.RateLimit)
+	writeIntNonDefault(&buf, "  ", "request_timeout", cfg.Vulners.RequestTimeout, defaults.Vulners.RequestTimeout)
 
 	buf.WriteString("\nscan:\n")
 	writeFloat(&buf, "  ", "min_cvss", cfg.Scan.MinCVSS, defaults.Scan.MinCVSS)
+	if cfg.Scan.PushMinCVSS != defaults.Scan.PushMinCVSS {
+		writeFloat(&buf, "  ", "push_min_cvss", cfg.Scan.PushMinCVSS, defaults.Scan.PushMinCVSS)
+	}
+	writeNonDefault(&buf, "  ", "lld_lifetime", cfg.Scan.LLDLifetime, defaults.Scan.LLDLifetime)
+	if cfg.Scan.CritCVSS != defaults.Scan.CritCVSS {
+		writeFloat(&buf, "  ", "crit_cvss", cfg.Scan.CritCVSS, defaults.Scan.CritCVSS)
+	}
 	writeStr(&buf, "  ", "os_report_template", cfg.Scan.OSReportTemplate, defaults.Scan.OSReportTemplate)
 	writeNonDefault(&buf, "  ", "os_report_visible_name", cfg.Scan.OSReportVisibleName, defaults.Scan.OSReportVisibleName)
 	writeNonDefault(&buf, "  ", "template_group_name", cfg.Scan.TemplateGroupName, defaults.Scan.TemplateGroupName)
 	writeInt(&buf, "  ", "timeout", cfg.Scan.Timeout, defaults.Scan.Timeout)
 	writeInt(&buf, "  ", "workers", cfg.Scan.Workers, defaults.Scan.Workers)
 	writeIntNonDefault(&buf, "  ", "lld_delay", cfg.Scan.LLDDelay, defaults.Scan.LLDDelay)
+	if cfg.Scan.PushRawJSON != defaults.Scan.PushRawJSON {
+		writeBool(&buf, "  ", "push_raw_json", cfg.Scan.PushRawJSON, defaults.Scan.PushRawJSON)
+	}
+	writeIntNonDefault(&buf, "  ", "max_affected_hosts", cfg.Scan.MaxAffectedHosts, defaults.Scan.MaxAffectedHosts)
+	writeIntNonDefault(&buf, "  ", "max_lld_entries", cfg.Scan.MaxLLDEntries, defaults.Scan.MaxLLDEntries)
+	if cfg.Scan.CompressLLD != defaults.Scan.CompressLLD {
+		writeBool(&buf, "  ", "compress_lld", cfg.Scan.CompressLLD, defaults.Scan.CompressLLD)
+	}
+	if cfg.Scan.DiscoveryMode != defaults.Scan.DiscoveryMode {
+		writeStr(&buf, "  ", "discovery_mode", cfg.Scan.DiscoveryMode, defaults.Scan.DiscoveryMode)
+	}
+	writeNonDefault(&buf, "  ", "os_item_tag", cfg.Scan.OSItemTag, defaults.Scan.OSItemTag)
+	writeNonDefault(&buf, "  ", "packages_item_tag", cfg.Scan.PackagesItemTag, defaults.Scan.PackagesItemTag)
+	writeNonDefault(&buf, "  ", "maintenance", cfg.Scan.Maintenance, defaults.Scan.Maintenance)
+	writeNonDefault(&buf, "  ", "score_item_value_type", cfg.Scan.ScoreItemValueType, defaults.Scan.ScoreItemValueType)
+	writeNonDefault(&buf, "  ", "package_item_value", cfg.Scan.PackageItemValue, defaults.Scan.PackageItemValue)
+	if !slices.Equal(cfg.Scan.EnabledLLD, defaults.Scan.EnabledLLD) {
+		fmt.Fprintf(&buf, "  enabled_lld: [%s]\n", strings.Join(cfg.Scan.EnabledLLD, ", "))
+	}
 
 	buf.WriteString("\ntelemetry:\n")
 	writeBool(&buf, "  ", "enabled", cfg.Telemetry.Enabled, defaults.Telemetry.Enabled)
 	writeStr(&buf, "  ", "otlp_endpoint", cfg.Telemetry.OTLPEndpoint, defaults.Telemetry.OTLPEndpoint)
+	writeNonDefault(&buf, "  ", "otlp_protocol", cfg.Telemetry.OTLPProtocol, defaults.Telemetry.OTLPProtocol)
+	if cfg.Telemetry.OTLPInsecure != defaults.Telemetry.OTLPInsecure {
+		writeBool(&buf, "  ", "otlp_insecure", cfg.Telemetry.OTLPInsecure, defaults.Telemetry.OTLPInsecure)
+	}
+	if cfg.Telemetry.SampleRatio != defaults.Telemetry.SampleRatio {
+		writeFloat(&buf, "  ", "sample_ratio", cfg.Telemetry.SampleRatio, defaults.Telemetry.SampleRatio)
+	}
+	writeNonDefault(&buf, "  ", "environment", cfg.Telemetry.Environment, defaults.Telemetry.Environment)
+
+	if cfg.HTTP.UserAgent != "" {
+		buf.WriteString("\nhttp:\n")
+		writeStr(&buf, "  ", "user_agent", cfg.HTTP.UserAgent, "")
+	}
+
+	prefChanged := !slices.Equal(cfg.Fix.AddressPreference, defaults.Fix.AddressPreference)
+	if prefChanged || cfg.Fix.MaxDataAge != defaults.Fix.MaxDataAge {
+		buf.WriteString("\nfix:\n")
+		if prefChanged {
+			fmt.Fprintf(&buf, "  address_preference: [%s]\n", strings.Join(cfg.Fix.AddressPreference, ", "))
+		}
+		writeIntNonDefault(&buf, "  ", "max_data_age", cfg.Fix.MaxDataAge, defaults.Fix.MaxDataAge)
+	}
 
 	// Only render naming section if any value differs from defaults
 	n := cfg.Naming
@@ -124,6 +181,9 @@ func renderYAML(cfg *config.Config) ([]byte, error) {
 		writeNonDefault(&buf, "  ", "group_name", n.GroupName, d.GroupName)
 		writeNonDefault(&buf, "  ", "dashboard_name", n.DashboardName, d.DashboardName)
 		writeNonDefault(&buf, "  ", "action_name", n.ActionName, d.ActionName)
+		if n.HashPackageKeys {
+			writeBool(&buf, "  ", "hash_package_keys", n.HashPackageKeys, d.HashPackageKeys)
+		}
 	}
 
 	return buf.Bytes(), nil
@@ -3,8 +3,14 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
 )
 
 var (
@@ -14,6 +20,11 @@ var (
 	prepareActions      bool
 	prepareAll          bool
 	prepareForce        bool
+	prepareResume       bool
+	prepareSelfTest     bool
+	prepareVerify       bool
+	prepareFormat       string
+	prepareOutput       string
 	prepareUtils        bool // hidden: Python -u compat (no-op in Go)
 )
 
@@ -28,6 +39,22 @@ This command can create:
 - Dashboards for vulnerability visualization (-d)
 - Actions: checked but require manual configuration in the Zabbix UI (-A)
 
+--self-test sends a test value to the vulners.self_test item of every
+virtual host and reads it back, and reports disabled trapper items. Items
+holding scan data are never written.
+
+--verify changes nothing: it compares the template, its discovery rules,
+item and trigger prototypes, the virtual hosts and the dashboard with what
+prepare would create, prints every difference and exits non-zero on drift.
+
+--format template-xml (or template-yaml) writes the OS-Report template as a
+Zabbix import file instead of creating it, for configuration kept in git.
+It does not connect to Zabbix.
+
+Every step can be re-run: existing objects are kept and only missing ones
+are created. --resume goes further and skips the steps whose objects are
+all in place, so a prepare that failed midway continues where it stopped.
+
 When upgrading from the Python version, run with --force to recreate
 templates and discovery rules with the new key schema.
 
@@ -36,6 +63,10 @@ NOTE: This command does not require a Vulners API key.`,
 		log := GetLogger()
 		cfg := GetConfig()
 
+		if prepareFormat != "" {
+			return exportOSReportTemplate(log, cmd.OutOrStdout())
+		}
+
 		log.Info("Preparing Zabbix objects...")
 
 		client, err := initZabbixClient(cfg, log)
@@ -44,10 +75,14 @@ NOTE: This command does not require a Vulners API key.`,
 		}
 		defer func() { _ = client.Close() }()
 
+		if prepareVerify {
+			return runVerify(context.Background(), client, cmd.OutOrStdout())
+		}
+
 		// Default to all when no specific flags are given.
 		// This matches the typical usage (Python: prepare.py -uvtd)
 		// and avoids a silent no-op when migration docs say "run ztc prepare".
-		noFlagsSet := !prepareAll && !prepareTemplates && !prepareVirtualHosts && !prepareDashboard && !prepareActions
+		noFlagsSet := !prepareAll && !prepareTemplates && !prepareVirtualHosts && !prepareDashboard && !prepareActions && !prepareSelfTest
 		if noFlagsSet {
 			log.Warn("No flags specified, defaulting to --all (create all Zabbix objects)")
 		}
@@ -60,6 +95,16 @@ NOTE: This command does not require a Vulners API key.`,
 
 		ctx := context.Background()
 
+		if prepareResume {
+			if err := skipCompletedSteps(ctx, client, log); err != nil {
+				return err
+			}
+		}
+
+		if err := checkPreparePermissions(ctx, client, log); err != nil {
+			return err
+		}
+
 		if prepareForce {
 			log.Warn("Force mode enabled — existing objects will be recreated")
 		}
@@ -95,6 +140,12 @@ NOTE: This command does not require a Vulners API key.`,
 			}
 		}
 
+		if prepareSelfTest {
+			if err := runSelfTest(ctx, client, log); err != nil {
+				return err
+			}
+		}
+
 		log.Info("Zabbix preparation complete")
 		return nil
 	},
@@ -107,6 +158,13 @@ func init() {
 	prepareCmd.Flags().BoolVarP(&prepareDashboard, "dashboard", "d", false, "create dashboard")
 	prepareCmd.Flags().BoolVarP(&prepareActions, "actions", "A", false, "check if actions exist (manual Zabbix UI setup required)")
 	prepareCmd.Flags().BoolVarP(&prepareForce, "force", "f", false, "recreate existing objects (use after upgrade to fix key schema changes)")
+	prepareCmd.Flags().BoolVar(&prepareResume, "resume", false, "skip steps whose Zabbix objects already exist, continuing an interrupted prepare")
+	prepareCmd.MarkFlagsMutuallyExclusive("force", "resume")
+
+	prepareCmd.Flags().BoolVar(&prepareSelfTest, "self-test", false, "send a test value to each virtual host and verify Zabbix accepted it")
+	prepareCmd.Flags().StringVar(&prepareFormat, "format", "", "write the OS-Report template as a Zabbix import file instead: template-xml or template-yaml")
+	prepareCmd.Flags().StringVarP(&prepareOutput, "output", "o", "", "write the --format output to a file instead of stdout")
+	prepareCmd.Flags().BoolVar(&prepareVerify, "verify", false, "report drift between the expected and the existing Zabbix objects without changing them")
 
 	// Hidden Python-compat flags so "prepare -uvtd" doesn't fail.
 	// -u (--utils): Python checked zabbix-sender/get paths; Go does this implicitly.
@@ -115,3 +173,140 @@ func init() {
 
 	rootCmd.AddCommand(prepareCmd)
 }
+
+// skipCompletedSteps clears the selected steps whose objects already exist,
+// so --resume only runs (and checks permissions for) the remaining ones.
+func skipCompletedSteps(ctx context.Context, client *zabbix.Client, log *slog.Logger) error {
+	done, err := client.CompletedStepsCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check completed steps: %w", err)
+	}
+	steps := []struct {
+		name     string
+		selected *bool
+	}{
+		{zabbix.StepTemplates, &prepareTemplates},
+		{zabbix.StepVirtualHosts, &prepareVirtualHosts},
+		{zabbix.StepDashboard, &prepareDashboard},
+	}
+	for _, s := range steps {
+		if *s.selected && done[s.name] {
+			log.Info("Step already complete, skipping", slog.String("step", s.name))
+			*s.selected = false
+		}
+	}
+	return nil
+}
+
+// checkPreparePermissions verifies the API user can create everything the
+// selected prepare steps need, so a missing permission is reported up front
+// instead of as an opaque API error halfway through.
+func checkPreparePermissions(ctx context.Context, client *zabbix.Client, log *slog.Logger) error {
+	var check zabbix.PermissionCheck
+	if prepareTemplates {
+		check.Methods = append(check.Methods, "template.create", "item.create")
+		check.HostGroups = append(check.HostGroups, cfg.Scan.TemplateGroupName)
+	}
+	if prepareVirtualHosts {
+		check.Methods = append(check.Methods, "template.create", "host.create", "host.massupdate",
+			"item.create", "discoveryrule.create", "itemprototype.create", "triggerprototype.create")
+		check.HostGroups = append(check.HostGroups, cfg.Naming.GroupName)
+	}
+	if prepareDashboard {
+		check.Methods = append(check.Methods, "dashboard.create", "graph.create")
+	}
+	if len(check.Methods) == 0 {
+		return nil
+	}
+
+	problems, err := client.CheckPermissionsCtx(ctx, check)
+	if err != nil {
+		// The preflight is advisory; fall through to the real calls.
+		log.Warn("Could not check API user permissions", slog.Any("error", err))
+		return nil
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range problems {
+		if seen[p.Subject] {
+			continue
+		}
+		seen[p.Subject] = true
+		log.Error("Missing Zabbix permission", slog.String("for", p.Subject), slog.String("reason", p.Reason))
+	}
+	return fmt.Errorf("the Zabbix API user %q lacks %d permission(s) required by prepare", cfg.Zabbix.APIUser, len(seen))
+}
+
+// selfTestTimeout bounds how long the self-test waits for Zabbix to make the
+// test values visible through the API.
+const selfTestTimeout = 30 * time.Second
+
+func runSelfTest(ctx context.Context, client *zabbix.Client, log *slog.Logger) error {
+	log.Info("Running trapper self-test...")
+
+	results, err := client.SelfTestCtx(ctx, zabbix.NewSender(cfg, log), selfTestTimeout)
+	if err != nil {
+		return fmt.Errorf("self-test failed: %w", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			log.Error("Trapper item failed the self-test", slog.String("host", r.Host), slog.String("key", r.Key), slog.Any("error", r.Err))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("self-test: %d of %d items failed", failed, len(results))
+	}
+	log.Info("Self-test passed", slog.Int("items", len(results)))
+	return nil
+}
+
+// runVerify prints the drift between the expected and the existing Zabbix
+// objects and fails when there is any.
+func runVerify(ctx context.Context, client *zabbix.Client, out io.Writer) error {
+	drifts, err := client.VerifyCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("verify failed: %w", err)
+	}
+	if len(drifts) == 0 {
+		_, _ = fmt.Fprintln(out, "No drift: all Zabbix objects match the configuration")
+		return nil
+	}
+	for _, d := range drifts {
+		_, _ = fmt.Fprintln(out, d)
+	}
+	return fmt.Errorf("found %d difference(s); run prepare (with --force for changed keys) to fix them", len(drifts))
+}
+
+// templateFormats maps the prepare --format values to export formats.
+var templateFormats = map[string]string{
+	"template-xml":  zabbix.ExportXML,
+	"template-yaml": zabbix.ExportYAML,
+}
+
+// exportOSReportTemplate writes the OS-Report template import file to
+// --output or out.
+func exportOSReportTemplate(log *slog.Logger, out io.Writer) error {
+	format, ok := templateFormats[prepareFormat]
+	if !ok {
+		return fmt.Errorf("unknown --format %q: must be template-xml or template-yaml", prepareFormat)
+	}
+	data, err := zabbix.ExportOSReportTemplate(cfg, format)
+	if err != nil {
+		return err
+	}
+	if prepareOutput == "" {
+		_, err = out.Write(data)
+		return err
+	}
+	if err := os.WriteFile(prepareOutput, data, 0644); err != nil { //nolint:gosec // G306: a template file, not a secret
+		return fmt.Errorf("failed to write template: %w", err)
+	}
+	log.Info("OS-Report template written", slog.String("path", prepareOutput))
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// progressLine is a single-line progress bar redrawn in place on a
+// terminal. Logs sharing the terminal go through logWriter, which clears
+// the bar before each record and redraws it after, so the two never tangle.
+type progressLine struct {
+	mu   sync.Mutex
+	w    io.Writer
+	line string // the bar as shown, "" when none is
+}
+
+// stderrProgress draws the scan and fix progress bars.
+var stderrProgress = &progressLine{w: os.Stderr}
+
+// draw shows line in place of the current bar. done ends the bar with a
+// newline so that later output starts below it.
+func (p *progressLine) draw(line string, done bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = fmt.Fprint(p.w, "\r"+line)
+	p.line = line
+	if done {
+		_, _ = fmt.Fprintln(p.w)
+		p.line = ""
+	}
+}
+
+// logWriter wraps w so that each record written through it moves the bar
+// out of the way.
+func (p *progressLine) logWriter(w io.Writer) io.Writer {
+	return progressLogWriter{p: p, w: w}
+}
+
+type progressLogWriter struct {
+	p *progressLine
+	w io.Writer
+}
+
+func (pw progressLogWriter) Write(b []byte) (int, error) {
+	pw.p.mu.Lock()
+	defer pw.p.mu.Unlock()
+	if pw.p.line == "" {
+		return pw.w.Write(b)
+	}
+	_, _ = fmt.Fprint(pw.p.w, "\r\x1b[K")
+	n, err := pw.w.Write(b)
+	_, _ = fmt.Fprint(pw.p.w, "\r"+pw.p.line)
+	return n, err
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProgressLogWriter(t *testing.T) {
+	var term bytes.Buffer
+	p := &progressLine{w: &term}
+	logs := p.logWriter(&term)
+
+	if _, err := logs.Write([]byte("level=INFO msg=before\n")); err != nil {
+		t.Fatal(err)
+	}
+	p.draw("[##..] 1/2", false)
+	if _, err := logs.Write([]byte("level=WARN msg=during\n")); err != nil {
+		t.Fatal(err)
+	}
+	p.draw("[####] 2/2", true)
+	if _, err := logs.Write([]byte("level=INFO msg=after\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "level=INFO msg=before\n" +
+		"\r[##..] 1/2" +
+		"\r\x1b[K" + "level=WARN msg=during\n" + "\r[##..] 1/2" +
+		"\r[####] 2/2\n" +
+		"level=INFO msg=after\n"
+	if got := term.String(); got != want {
+		t.Errorf("terminal output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/report"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+var (
+	reportHost   string
+	reportFormat string
+	reportOutput string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a vulnerability report for a single host",
+	Long: `Scan a single host and write a Markdown or HTML report with its OS,
+CVSS score, vulnerable packages (versions, CVEs, fix commands) and the
+cumulative fix. Nothing is pushed to Zabbix.
+
+The report is meant to be attached to remediation tickets.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		cfg := GetConfig()
+
+		if reportHost == "" {
+			return fmt.Errorf("--host must be specified")
+		}
+
+		format, err := report.ParseFormat(reportFormat)
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.ValidateVulnersKey(); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		s, err := initScanner(cfg, log)
+		if err != nil {
+			return fmt.Errorf("failed to initialize scanner: %w", err)
+		}
+		defer func() { _ = s.Close() }()
+
+		ids, err := s.ResolveHostIDs(ctx, []string{reportHost})
+		if err != nil {
+			return err
+		}
+
+		results, err := s.Scan(ctx, scanner.ScanOptions{HostIDs: ids, NoPush: true})
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		if len(results.Hosts) == 0 {
+			return fmt.Errorf("host %s was not scanned (missing OS-Report template or data)", reportHost)
+		}
+
+		var w io.Writer = os.Stdout
+		if reportOutput != "" {
+			f, err := os.Create(reportOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create report file: %w", err)
+			}
+			defer func() { _ = f.Close() }()
+			w = f
+		}
+
+		if err := report.WriteHost(w, results.Hosts[0], format, time.Now()); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+
+		if reportOutput != "" {
+			log.Info("Report written", slog.String("path", reportOutput))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportHost, "host", "", "host ID or technical name to report on")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "markdown", "report format: markdown or html")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "write the report to a file instead of stdout")
+
+	rootCmd.AddCommand(reportCmd)
+}
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
@@ -13,8 +16,11 @@ import (
 )
 
 var (
-	cfgFile      string
+	cfgFiles     []string
 	verbose      bool
+	quiet        bool
+	noColor      bool
+	strictConfig bool
 	cfg          *config.Config
 	log          *slog.Logger
 	otelShutdown func(context.Context) error
@@ -31,22 +37,31 @@ in installed packages and reports them back to Zabbix for
 centralized monitoring and alerting.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Skip config loading for commands that handle their own config
-		if cmd.Name() == "version" || cmd.Name() == "migrate-config" {
+		if cmd.Name() == "version" || cmd.Name() == "migrate-config" || cmd.Name() == "diff" {
 			return nil
 		}
 
-		// Initialize logger
-		log = newLogger(verbose)
+		// Initialize logger; keep stdout clean when it carries command output.
+		// Records clear the progress bar first when one is drawn.
+		logFile := os.Stdout
+		if stdoutIsData(cmd) {
+			logFile = os.Stderr
+		}
+		logOut := io.Writer(logFile)
+		if useColor(logFile) {
+			logOut = colorWriter{logFile}
+		}
+		log = newLogger(stderrProgress.logWriter(logOut), logLevel(quiet, verbose))
 
 		// Load configuration
 		var err error
-		cfg, err = config.Load(cfgFile)
+		cfg, err = config.LoadFiles(cfgFiles, config.LoadOptions{Strict: strictConfig})
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
 		// Initialize OpenTelemetry
-		otelShutdown, err = telemetry.Init(context.Background(), &cfg.Telemetry, verbose)
+		otelShutdown, err = telemetry.Init(context.Background(), &cfg.Telemetry, verbose && !quiet)
 		if err != nil {
 			return fmt.Errorf("failed to init telemetry: %w", err)
 		}
@@ -61,15 +76,44 @@ centralized monitoring and alerting.`,
 	},
 }
 
+// Process exit codes.
+const (
+	ExitFailure  = 1 // generic error
+	ExitFindings = 2 // scan findings exceeded a --fail-on-* threshold
+)
+
+// ExitError carries a specific process exit code out of a command.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		os.Exit(ExitFailure)
 	}
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", config.FindConfigPath(), "config file path")
+	rootCmd.PersistentFlags().StringSliceVarP(&cfgFiles, "config", "c", []string{config.FindConfigPath()}, "config file path (repeat to merge several files; later ones override earlier)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only log errors (overrides --verbose)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "do not color log levels on a terminal (env: NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&strictConfig, "strict-config", envBool("ZTC_STRICT_CONFIG"), "fail on unrecognized config keys instead of warning (env: ZTC_STRICT_CONFIG)")
+}
+
+// envBool reports whether the named environment variable holds a true value.
+func envBool(name string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(name))
+	return v
 }
 
 func GetConfig() *config.Config {
@@ -80,10 +124,39 @@ func GetLogger() *slog.Logger {
 	return log
 }
 
-func newLogger(verbose bool) *slog.Logger {
-	level := slog.LevelInfo
-	if verbose {
-		level = slog.LevelDebug
+// logLevel returns the log level selected by --quiet and --verbose; quiet
+// wins when both are set.
+func logLevel(quiet, verbose bool) slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelError
+	case verbose:
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func newLogger(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// stdoutIsData reports whether cmd writes machine-readable output to stdout,
+// in which case logs are sent to stderr instead.
+func stdoutIsData(cmd *cobra.Command) bool {
+	for _, name := range []string{"json-summary", "list-hosts"} {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Value.String() == "true" {
+			return true
+		}
+	}
+	if cmd == reportCmd && reportOutput == "" {
+		return true
+	}
+	if cmd == scanCmd && scanCollectOnly && scanOutput == "" {
+		return true
+	}
+	if cmd == configShowCmd {
+		return true
 	}
-	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+	return false
 }
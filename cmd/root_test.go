@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestLogLevel(t *testing.T) {
+	tests := []struct {
+		quiet, verbose bool
+		want           slog.Level
+	}{
+		{false, false, slog.LevelInfo},
+		{false, true, slog.LevelDebug},
+		{true, false, slog.LevelError},
+		{true, true, slog.LevelError},
+	}
+	for _, tt := range tests {
+		if got := logLevel(tt.quiet, tt.verbose); got != tt.want {
+			t.Errorf("logLevel(quiet=%v, verbose=%v) = %v, want %v", tt.quiet, tt.verbose, got, tt.want)
+		}
+	}
+}
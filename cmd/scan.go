@@ -1,23 +1,51 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"log/slog"
 
 	"github.com/spf13/cobra"
 
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
 	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
 )
 
 var (
-	scanLimit   int
-	scanNoPush  bool
-	scanDryRun  bool
-	scanHostIDs []string
+	scanLimit     int
+	scanNoPush    bool
+	scanDryRun    bool
+	scanHostIDs   []string
+	scanHostsFile string
+	scanExport    string
+	scanFailCVSS  float64
+	scanFailCount int
+	scanListHosts bool
+	scanJSON      bool
+
+	scanCollectOnly bool
+	scanOutput      string
+
+	scanNoLLDDelay   bool
+	scanIncremental  bool
+	scanPushEvery    int
+	scanPushInterval time.Duration
+	scanGroupStats   bool
+	scanVulnersHost  string
+	scanReplayDir    string
 )
 
 var scanCmd = &cobra.Command{
@@ -29,42 +57,111 @@ This command:
 1. Fetches hosts with OS-Report template from Zabbix
 2. Retrieves installed packages for each host
 3. Queries Vulners API for known vulnerabilities
-4. Aggregates results and sends data back to Zabbix`,
+4. Aggregates results and sends data back to Zabbix
+
+When stderr is a terminal, a progress bar shows the scanned and vulnerable
+hosts; it is left out with --quiet and --json-summary.
+
+With --collect-only, only steps 1 and 2 run: the OS name, version and
+package list of each host, as they would be sent to Vulners, are written as
+JSON to --output (stdout by default). Use it to check the inventory before
+spending Vulners API quota.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log := GetLogger()
 		cfg := GetConfig()
 
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		if scanOutput != "" && !scanCollectOnly {
+			return fmt.Errorf("--output requires --collect-only (use --export for scan results)")
+		}
+		if scanListHosts {
+			return listScanHosts(ctx, cfg, log)
+		}
+		if scanCollectOnly {
+			return collectInventory(ctx, cfg, log)
+		}
+
+		if scanReplayDir != "" {
+			cfg.Vulners.ReplayDir = scanReplayDir
+		}
 		if err := cfg.ValidateVulnersKey(); err != nil {
 			return err
 		}
 
-		log.Info("Starting vulnerability scan...")
-
-		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-		defer stop()
+		if scanVulnersHost != "" {
+			if err := validateVulnersHost(scanVulnersHost); err != nil {
+				return err
+			}
+			cfg.Vulners.Host = scanVulnersHost
+			log.Info("Using Vulners endpoint override", slog.String("host", scanVulnersHost))
+		}
 
-		opts := scanner.ScanOptions{
-			Limit:   scanLimit,
-			NoPush:  scanNoPush,
-			DryRun:  scanDryRun,
-			HostIDs: scanHostIDs,
+		if scanNoLLDDelay {
+			cfg.Scan.LLDDelay = 0
 		}
 
+		log.Info("Starting vulnerability scan...")
+
 		s, err := initScanner(cfg, log)
 		if err != nil {
 			return fmt.Errorf("failed to initialize scanner: %w", err)
 		}
 		defer func() { _ = s.Close() }()
 
+		hostIDs := scanHostIDs
+		if scanHostsFile != "" {
+			refs, err := readHostsFile(scanHostsFile)
+			if err != nil {
+				return err
+			}
+			ids, err := s.ResolveHostIDs(ctx, refs)
+			if err != nil {
+				return err
+			}
+			log.Info("Loaded hosts from file", slog.String("path", scanHostsFile), slog.Int("count", len(ids)))
+			hostIDs = append(hostIDs, ids...)
+		}
+
+		opts := scanner.ScanOptions{
+			Limit:           scanLimit,
+			NoPush:          scanNoPush,
+			DryRun:          scanDryRun,
+			HostIDs:         hostIDs,
+			IncrementalPush: scanIncremental,
+			PushEvery:       scanPushEvery,
+			PushInterval:    scanPushInterval,
+			GroupStats:      scanGroupStats,
+		}
+		if isTerminal(os.Stderr) && !quiet && !scanJSON {
+			opts.OnHostEvent = scanProgressBar(stderrProgress)
+		}
+
 		results, err := s.Scan(ctx, opts)
+		if errors.Is(err, scanner.ErrScanSkipped) {
+			log.Info("Scan skipped, nothing pushed", slog.String("reason", err.Error()))
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("scan failed: %w", err)
 		}
 
 		log.Info("Scan completed",
 			slog.Int("hosts_scanned", results.HostsScanned),
+			slog.Int("hosts_excluded", scanner.ExcludedTotal(results.Excluded)),
 			slog.Int("vulnerabilities_found", results.VulnerablePackages),
 		)
+		if len(results.Excluded) > 0 {
+			log.Info("Excluded hosts", slog.String("reasons", scanner.FormatExclusions(results.Excluded)))
+		}
+
+		if scanExport != "" {
+			if err := exportResults(scanExport, results); err != nil {
+				return err
+			}
+			log.Info("Scan results exported", slog.String("path", scanExport))
+		}
 
 		if !scanNoPush && !scanDryRun {
 			log.Info("Pushing results to Zabbix...")
@@ -76,6 +173,17 @@ This command:
 			log.Info("Skipping push to Zabbix (--nopush or --dry-run specified)")
 		}
 
+		if scanJSON {
+			if err := writeJSONSummary(os.Stdout, results, s.GetAggregator().GetStatistics()); err != nil {
+				return fmt.Errorf("failed to write JSON summary: %w", err)
+			}
+		}
+
+		if err := checkFailPolicy(results, scanFailCVSS, scanFailCount); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+
 		return nil
 	},
 }
@@ -86,5 +194,286 @@ func init() {
 	scanCmd.Flags().BoolVar(&scanDryRun, "dry-run", false, "dry run mode (implies --nopush)")
 	scanCmd.Flags().StringSliceVar(&scanHostIDs, "hosts", nil, "specific host IDs to scan (comma-separated)")
 
+	scanCmd.Flags().StringVar(&scanHostsFile, "hosts-file", "", "file with host IDs or technical names to scan, one per line")
+
+	scanCmd.Flags().StringVar(&scanExport, "export", "", "write scan results as JSON to this file (see 'ztc diff')")
+
+	scanCmd.Flags().Float64Var(&scanFailCVSS, "fail-on-cvss", 0, "exit with code 2 if any host, package or bulletin has CVSS >= N (0 = disabled)")
+	scanCmd.Flags().IntVar(&scanFailCount, "fail-on-count", 0, "exit with code 2 if N or more vulnerable packages are found (0 = disabled)")
+
+	scanCmd.Flags().BoolVar(&scanListHosts, "list-hosts", false, "list hosts that would be scanned (and why others are excluded) without querying Vulners")
+
+	scanCmd.Flags().BoolVar(&scanCollectOnly, "collect-only", false, "write each host's OS and package inventory as JSON without querying Vulners or pushing anything")
+	scanCmd.Flags().StringVar(&scanOutput, "output", "", "with --collect-only, write the inventory to this file instead of stdout")
+	scanCmd.MarkFlagsMutuallyExclusive("collect-only", "list-hosts")
+	scanCmd.Flags().BoolVar(&scanJSON, "json-summary", false, "print a one-line JSON summary to stdout (logs go to stderr)")
+
+	scanCmd.Flags().BoolVar(&scanNoLLDDelay, "no-lld-delay", false, "do not wait scan.lld_delay between LLD and value pushes (for re-scans where items already exist)")
+
+	scanCmd.Flags().BoolVar(&scanIncremental, "incremental-push", false, "push partial results to Zabbix during the scan so a crash loses less data")
+	scanCmd.Flags().IntVar(&scanPushEvery, "push-every", 500, "with --incremental-push, flush after every N scanned hosts (0 = disabled)")
+	scanCmd.Flags().DurationVar(&scanPushInterval, "push-interval", 10*time.Minute, "with --incremental-push, flush at least this often (0 = disabled)")
+
+	scanCmd.Flags().BoolVar(&scanGroupStats, "group-stats", false, "also push statistics per Zabbix host group (vulners.group.stats[<groupid>,<metric>] items)")
+
+	scanCmd.Flags().StringVar(&scanVulnersHost, "vulners-host", "", "Vulners API base URL for this run, e.g. an on-prem mirror or a mock (overrides vulners.host)")
+	scanCmd.Flags().StringVar(&scanReplayDir, "vulners-replay", "", "read canned audit results from <os>-<version>.json files in this directory instead of calling Vulners (no API key needed)")
+	scanCmd.MarkFlagsMutuallyExclusive("vulners-host", "vulners-replay")
+
 	rootCmd.AddCommand(scanCmd)
 }
+
+// scanProgressBar returns a ScanOptions.OnHostEvent callback that redraws
+// the progress bar p as hosts finish.
+func scanProgressBar(p *progressLine) func(scanner.HostEvent) {
+	vulnerable, failed := 0, 0
+	return func(e scanner.HostEvent) {
+		switch e.Kind {
+		case scanner.HostCompleted:
+			if e.Entry.Score > 0 {
+				vulnerable++
+			}
+		case scanner.HostFailed:
+			failed++
+		default:
+			return
+		}
+		filled := progressBarWidth * e.Done / e.Total
+		line := fmt.Sprintf("[%s%s] scanned %d/%d hosts, %d vulnerable",
+			strings.Repeat("#", filled), strings.Repeat(".", progressBarWidth-filled), e.Done, e.Total, vulnerable)
+		if failed > 0 {
+			line += fmt.Sprintf(" (%d failed)", failed)
+		}
+		p.draw(line, e.Done == e.Total)
+	}
+}
+
+// validateVulnersHost checks a --vulners-host value is an http(s) URL with
+// a host.
+func validateVulnersHost(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("--vulners-host must be an http(s) URL with a host, got %q", s)
+	}
+	return nil
+}
+
+// scanSummary is the compact machine-readable result printed by --json-summary.
+type scanSummary struct {
+	HostsScanned    int     `json:"hosts_scanned"`
+	HostsVulnerable int     `json:"hosts_vulnerable"`
+	HostsExcluded   int     `json:"hosts_excluded"`
+	Packages        int     `json:"packages"`
+	Bulletins       int     `json:"bulletins"`
+	CVEs            int     `json:"cves"`
+	MaxCVSS         float64 `json:"max_cvss"`
+	AvgCVSS         float64 `json:"avg_cvss"`
+
+	TopCVEs []scanner.CVECount `json:"top_cves,omitempty"`
+
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+}
+
+func writeJSONSummary(w io.Writer, results *scanner.ScanResults, stats scanner.Statistics) error {
+	return json.NewEncoder(w).Encode(scanSummary{
+		HostsScanned:    results.HostsScanned,
+		HostsVulnerable: results.HostsWithVulns,
+		HostsExcluded:   scanner.ExcludedTotal(results.Excluded),
+		Packages:        len(results.Packages),
+		Bulletins:       len(results.Bulletins),
+		CVEs:            stats.TotalCVEs,
+		MaxCVSS:         results.MaxCVSS,
+		AvgCVSS:         math.Round(stats.AvgCVSS*100) / 100,
+		TopCVEs:         stats.TopCVEs,
+		DurationSeconds: math.Round(results.Timings.Total().Seconds()*1000) / 1000,
+	})
+}
+
+// listScanHosts prints every host with the OS-Report template and whether it
+// qualifies for scanning. Vulners is never contacted.
+func listScanHosts(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
+	client, err := initZabbixClient(cfg, log)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Zabbix client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	hm := scanner.NewHostMatrix(cfg, log, client)
+	hostIDs, err := scanHostSelection(ctx, hm)
+	if err != nil {
+		return err
+	}
+
+	candidates, err := hm.Candidates(ctx, scanner.ScanOptions{Limit: scanLimit, HostIDs: hostIDs})
+	if err != nil {
+		return err
+	}
+
+	return writeCandidates(os.Stdout, candidates)
+}
+
+// collectInventory writes the inventory a scan would audit to --output.
+// Vulners is never contacted, so no API key is needed.
+func collectInventory(ctx context.Context, cfg *config.Config, log *slog.Logger) error {
+	client, err := initZabbixClient(cfg, log)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Zabbix client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	hm := scanner.NewHostMatrix(cfg, log, client)
+	hostIDs, err := scanHostSelection(ctx, hm)
+	if err != nil {
+		return err
+	}
+
+	results, err := hm.Inventory(ctx, scanner.ScanOptions{Limit: scanLimit, HostIDs: hostIDs, CollectOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to fetch hosts: %w", err)
+	}
+	log.Info("Collected host inventory", slog.Int("hosts", len(results.Inventory)),
+		slog.Int("hosts_excluded", scanner.ExcludedTotal(results.Excluded)))
+
+	if scanOutput == "" {
+		return scanner.WriteInventoryJSON(os.Stdout, results, time.Now())
+	}
+	f, err := os.Create(scanOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create inventory file: %w", err)
+	}
+	if err := scanner.WriteInventoryJSON(f, results, time.Now()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Info("Inventory written", slog.String("path", scanOutput))
+	return nil
+}
+
+// scanHostSelection returns the host IDs given with --hosts and
+// --hosts-file.
+func scanHostSelection(ctx context.Context, hm *scanner.HostMatrix) ([]string, error) {
+	hostIDs := scanHostIDs
+	if scanHostsFile != "" {
+		refs, err := readHostsFile(scanHostsFile)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := hm.ResolveHostIDs(ctx, refs)
+		if err != nil {
+			return nil, err
+		}
+		hostIDs = append(hostIDs, ids...)
+	}
+	return hostIDs, nil
+}
+
+func writeCandidates(out io.Writer, candidates []scanner.HostCandidate) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOSTID\tHOST\tNAME\tOS\tPACKAGES\tSTATUS")
+	for _, c := range candidates {
+		if c.Data != nil {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s %s\t%d\tok\n",
+				c.Host.HostID, c.Host.Host, c.Host.Name, c.Data.OSName, c.Data.OSVersion, len(c.Data.Packages))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t-\t-\texcluded: %s\n", c.Host.HostID, c.Host.Host, c.Host.Name, c.Reason)
+	}
+	return w.Flush()
+}
+
+// checkFailPolicy returns an ExitError with ExitFindings when results breach
+// the --fail-on-* thresholds. Zero thresholds are disabled.
+func checkFailPolicy(results *scanner.ScanResults, minCVSS float64, maxCount int) error {
+	if minCVSS > 0 {
+		if score, what := maxFinding(results); score >= minCVSS {
+			return &ExitError{
+				Code: ExitFindings,
+				Err:  fmt.Errorf("%s has CVSS %.1f (fail threshold %.1f)", what, score, minCVSS),
+			}
+		}
+	}
+	if maxCount > 0 && len(results.Packages) >= maxCount {
+		return &ExitError{
+			Code: ExitFindings,
+			Err:  fmt.Errorf("found %d vulnerable packages (fail threshold %d)", len(results.Packages), maxCount),
+		}
+	}
+	return nil
+}
+
+// maxFinding returns the highest CVSS score among hosts, packages and
+// bulletins, with a description of where it was found.
+func maxFinding(results *scanner.ScanResults) (float64, string) {
+	var score float64
+	var what string
+	for _, h := range results.Hosts {
+		if h.Score > score {
+			score, what = h.Score, "host "+h.Name
+		}
+	}
+	for _, p := range results.Packages {
+		if p.Score > score {
+			score, what = p.Score, "package "+p.Name+" "+p.Version
+		}
+	}
+	for _, b := range results.Bulletins {
+		if b.Score > score {
+			score, what = b.Score, "bulletin "+b.ID
+		}
+	}
+	return score, what
+}
+
+// exportResults writes results to path in the stable JSON export schema.
+func exportResults(path string, results *scanner.ScanResults) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := scanner.WriteJSON(f, results, time.Now()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return f.Close()
+}
+
+// readHostsFile reads host references from path, see parseHostList. A file
+// without any is an error: an empty selection would scan every host.
+func readHostsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hosts file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	refs, err := parseHostList(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file %s: %w", path, err)
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("hosts file %s lists no hosts", path)
+	}
+	return refs, nil
+}
+
+// parseHostList returns one host reference per line, skipping blank lines
+// and "#" comments (full-line or trailing).
+func parseHostList(r io.Reader) ([]string, error) {
+	var refs []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	return refs, sc.Err()
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+func TestParseHostList(t *testing.T) {
+	input := `# hosts exported from CMDB
+10084
+web-01.example.com   # frontend
+
+  10105
+#10200
+`
+	got, err := parseHostList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseHostList() error: %v", err)
+	}
+	want := []string{"10084", "web-01.example.com", "10105"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHostList() = %v, want %v", got, want)
+	}
+}
+
+func TestReadHostsFile_NoHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	if err := os.WriteFile(path, []byte("# nothing to scan yet\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readHostsFile(path); err == nil || !strings.Contains(err.Error(), "lists no hosts") {
+		t.Errorf("readHostsFile() error = %v, want lists no hosts", err)
+	}
+}
+
+func TestCheckFailPolicy(t *testing.T) {
+	results := &scanner.ScanResults{
+		Hosts:     []scanner.HostEntry{{Name: "web", Score: 6.5}},
+		Packages:  []scanner.PackageEntry{{Name: "openssl", Score: 7.5}, {Name: "curl", Score: 4.0}},
+		Bulletins: []scanner.BulletinEntry{{ID: "USN-1", Score: 5.0}},
+	}
+
+	tests := []struct {
+		name     string
+		cvss     float64
+		count    int
+		wantFail bool
+	}{
+		{"disabled", 0, 0, false},
+		{"cvss below threshold", 8.0, 0, false},
+		{"cvss at threshold", 7.5, 0, true},
+		{"count below threshold", 0, 3, false},
+		{"count at threshold", 0, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFailPolicy(results, tt.cvss, tt.count)
+			if (err != nil) != tt.wantFail {
+				t.Fatalf("checkFailPolicy() = %v, wantFail %v", err, tt.wantFail)
+			}
+			if err == nil {
+				return
+			}
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) || exitErr.Code != ExitFindings {
+				t.Errorf("expected ExitError with code %d, got %v", ExitFindings, err)
+			}
+		})
+	}
+}
+
+func TestWriteCandidates(t *testing.T) {
+	candidates := []scanner.HostCandidate{
+		{
+			Host: zabbix.Host{HostID: "10084", Host: "web-01", Name: "Web 01"},
+			Data: &scanner.HostData{OSName: "ubuntu", OSVersion: "22.04", Packages: make([]string, 42)},
+		},
+		{
+			Host:   zabbix.Host{HostID: "10085", Host: "db-01", Name: "DB 01"},
+			Reason: "too few packages",
+		},
+	}
+
+	var buf strings.Builder
+	if err := writeCandidates(&buf, candidates); err != nil {
+		t.Fatalf("writeCandidates() error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"ubuntu 22.04", "42", "ok", "excluded: too few packages"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSONSummary(t *testing.T) {
+	results := &scanner.ScanResults{
+		HostsScanned:   3,
+		HostsWithVulns: 2,
+		MaxCVSS:        9.8,
+		Packages:       []scanner.PackageEntry{{Name: "openssl"}},
+		Bulletins:      []scanner.BulletinEntry{{ID: "USN-1"}, {ID: "USN-2"}},
+		Excluded:       map[string]int{"too few packages": 1},
+	}
+	stats := scanner.Statistics{TotalCVEs: 5, AvgCVSS: 5.4333}
+
+	var buf strings.Builder
+	if err := writeJSONSummary(&buf, results, stats); err != nil {
+		t.Fatalf("writeJSONSummary() error: %v", err)
+	}
+
+	want := `{"hosts_scanned":3,"hosts_vulnerable":2,"hosts_excluded":1,"packages":1,"bulletins":2,"cves":5,"max_cvss":9.8,"avg_cvss":5.43}` + "\n"
+	if buf.String() != want {
+		t.Errorf("summary = %s, want %s", buf.String(), want)
+	}
+}
+
+func TestValidateVulnersHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr bool
+	}{
+		{"https://vulners.com", false},
+		{"http://127.0.0.1:8080", false},
+		{"https://mirror.example.com/vulners/", false},
+		{"vulners.com", true},
+		{"ftp://vulners.com", true},
+		{"https://", true},
+		{"://bad", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if err := validateVulnersHost(tt.host); (err != nil) != tt.wantErr {
+				t.Errorf("validateVulnersHost(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScanProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	progress := scanProgressBar(&progressLine{w: &out})
+
+	progress(scanner.HostEvent{Kind: scanner.HostStarted, Total: 3})
+	if out.Len() != 0 {
+		t.Errorf("started event drew %q", out.String())
+	}
+
+	progress(scanner.HostEvent{Kind: scanner.HostCompleted, Entry: &scanner.HostEntry{Score: 7.5}, Done: 1, Total: 3})
+	if got := out.String(); got != "\r["+strings.Repeat("#", 10)+strings.Repeat(".", 20)+"] scanned 1/3 hosts, 1 vulnerable" {
+		t.Errorf("first update = %q", got)
+	}
+
+	out.Reset()
+	progress(scanner.HostEvent{Kind: scanner.HostFailed, Err: errors.New("timeout"), Done: 2, Total: 3})
+	if got := out.String(); !strings.HasSuffix(got, "scanned 2/3 hosts, 1 vulnerable (1 failed)") {
+		t.Errorf("second update = %q", got)
+	}
+
+	out.Reset()
+	progress(scanner.HostEvent{Kind: scanner.HostCompleted, Entry: &scanner.HostEntry{}, Done: 3, Total: 3})
+	if got := out.String(); !strings.HasSuffix(got, "scanned 3/3 hosts, 1 vulnerable (1 failed)\n") {
+		t.Errorf("final update = %q, want trailing newline", got)
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+	"github.com/kidoz/zabbix-threat-control-go/internal/server"
+)
+
+var (
+	serveListen  string
+	serveFrom    string
+	serveRefresh time.Duration
+
+	serveScanInterval time.Duration
+	serveScanJitter   time.Duration
+	serveScanNoPush   bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the latest scan results over a read-only REST API",
+	Long: `Serve the latest scan results as JSON for internal portals and scripts.
+
+Endpoints (GET only):
+  /hosts      scanned hosts with their score and cumulative fix
+  /packages   vulnerable packages with the hosts they affect
+  /bulletins  bulletins with their CVEs, packages and hosts
+  /stats      host, package and bulletin counts and CVSS summary
+
+The list endpoints accept ?min_cvss=N to drop entries scoring below N and
+paginate with ?limit=N (default 100, at most 1000) and ?offset=N. They
+return {"total", "offset", "limit", "items"}.
+
+Binaries built with -tags graphql also answer GraphQL queries at /graphql
+(GET or POST) over hosts, packages, bulletins and their CVEs; the schema is
+at /graphql/schema.
+
+By default the results are read back from the LLD the last scan pushed to
+Zabbix. That LLD does not list each host's packages and may cap the
+affected-host name lists (scan.max_affected_hosts); serve a scan export with
+--from FILE ('ztc scan --export') for the full results. Either source is
+reloaded at most once per --refresh interval.
+
+With --scan-interval, serve also runs a scan on start and then once per
+interval, each delayed by a random 0 to --scan-jitter (default 1m) so
+instances started together do not scan at the same moment. Each scan's
+results are served once it finishes and pushed to Zabbix unless --nopush
+is given. While scans run, /scan/events streams
+their progress over a WebSocket as JSON messages, one per host event:
+  {"kind":"started|completed|failed","host_id","name","score","error","done","total"}
+"score" is set for completed hosts, "error" for failed ones. Browser pages
+may only connect from the same host as the API.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log := GetLogger()
+		cfg := GetConfig()
+
+		source := server.FileSource(serveFrom)
+		if serveFrom == "" {
+			client, err := initZabbixClient(cfg, log)
+			if err != nil {
+				return fmt.Errorf("failed to connect to Zabbix: %w", err)
+			}
+			defer func() { _ = client.Close() }()
+			source = server.ZabbixSource(client, cfg.Naming)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		var hub *server.EventHub
+		if serveScanInterval > 0 {
+			if err := cfg.ValidateVulnersKey(); err != nil {
+				return err
+			}
+			s, err := initScanner(cfg, log)
+			if err != nil {
+				return fmt.Errorf("failed to initialize scanner: %w", err)
+			}
+			defer func() { _ = s.Close() }()
+
+			hub = server.NewEventHub()
+			defer hub.Close()
+			var latest atomic.Pointer[scanner.ScanResults]
+			fallback := source
+			source = func(ctx context.Context) (*scanner.ScanResults, error) {
+				if results := latest.Load(); results != nil {
+					return results, nil
+				}
+				return fallback(ctx)
+			}
+			go runServeScans(ctx, s, hub, &latest, log)
+		}
+		api := server.New(source, serveRefresh, log)
+		if hub != nil {
+			api.StreamEvents(hub)
+		}
+
+		srv := &http.Server{
+			Addr:              serveListen,
+			Handler:           api.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		errCh := make(chan error, 1)
+		go func() { errCh <- srv.ListenAndServe() }()
+		log.Info("Serving scan results", slog.String("listen", serveListen))
+
+		select {
+		case err := <-errCh:
+			return fmt.Errorf("server failed: %w", err)
+		case <-ctx.Done():
+		}
+
+		log.Info("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("failed to shut down server: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", "address to listen on; the API has no authentication, so keep it on loopback unless behind a trusted proxy")
+	serveCmd.Flags().StringVar(&serveFrom, "from", "", "serve a scan export file instead of the LLD pushed to Zabbix")
+	serveCmd.Flags().DurationVar(&serveRefresh, "refresh", time.Minute, "how long loaded results are served before reloading")
+	serveCmd.Flags().DurationVar(&serveScanInterval, "scan-interval", 0, "also run a scan every interval, serving its results and streaming its progress at /scan/events (0 = disabled)")
+	serveCmd.Flags().DurationVar(&serveScanJitter, "scan-jitter", time.Minute, "with --scan-interval, delay each scan by a random duration up to this (0 = disabled)")
+	serveCmd.Flags().BoolVar(&serveScanNoPush, "nopush", false, "with --scan-interval, do not push scan results to Zabbix")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+// runServeScans scans once per --scan-interval until ctx is done, publishing
+// host events to hub and storing each scan's results in latest.
+func runServeScans(ctx context.Context, s *scanner.Scanner, hub *server.EventHub, latest *atomic.Pointer[scanner.ScanResults], log *slog.Logger) {
+	ticker := time.NewTicker(serveScanInterval)
+	defer ticker.Stop()
+	for {
+		if err := scanner.WaitJitter(ctx, serveScanJitter); err != nil {
+			return
+		}
+		log.Info("Starting vulnerability scan...")
+		results, err := s.Scan(ctx, scanner.ScanOptions{NoPush: serveScanNoPush, OnHostEvent: hub.Publish})
+		switch {
+		case errors.Is(err, scanner.ErrScanSkipped):
+			log.Info("Scan skipped, keeping the previous results", slog.String("reason", err.Error()))
+		case err != nil:
+			log.Error("Scan failed", slog.Any("error", err))
+		default:
+			log.Info("Scan completed", slog.Int("hosts_scanned", results.HostsScanned))
+			latest.Store(results)
+			if !serveScanNoPush {
+				if err := s.PushResults(ctx, results); err != nil {
+					log.Error("Failed to push results", slog.Any("error", err))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -4,12 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-)
 
-var (
-	Version   = "dev"
-	BuildTime = "unknown"
-	GitCommit = "unknown"
+	"github.com/kidoz/zabbix-threat-control-go/internal/version"
 )
 
 var versionCmd = &cobra.Command{
@@ -17,9 +13,9 @@ var versionCmd = &cobra.Command{
 	Short: "Print version information",
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("Zabbix Threat Control (Go)\n")
-		fmt.Printf("Version:    %s\n", Version)
-		fmt.Printf("Build Time: %s\n", BuildTime)
-		fmt.Printf("Git Commit: %s\n", GitCommit)
+		fmt.Printf("Version:    %s\n", version.Version)
+		fmt.Printf("Build Time: %s\n", version.BuildTime)
+		fmt.Printf("Git Commit: %s\n", version.GitCommit)
 	},
 }
 
@@ -0,0 +1,119 @@
+// Command ztc-grpc serves scans and fixes over gRPC (see
+// internal/grpcapi/ztcpb/ztc.proto).
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/fixer"
+	"github.com/kidoz/zabbix-threat-control-go/internal/grpcapi"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+	"github.com/kidoz/zabbix-threat-control-go/internal/telemetry"
+)
+
+var (
+	cfgFiles []string
+	listen   string
+	allowFix bool
+	verbose  bool
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "ztc-grpc",
+	Short: "Serve ZTC scans and fixes over gRPC",
+	Long: `Serve the ztc.v1.ThreatControl gRPC service:
+
+  Scan        runs a scan, streaming an event as each host starts, completes
+              or fails, then the results; unless no_push is set the results
+              are pushed to Zabbix like 'ztc scan' does
+  GetResults  returns the results of the last scan run by this server
+  Fix         plans a fix like 'ztc fix' and executes it
+
+One scan runs at a time. Fix only accepts dry runs unless the server is
+started with --allow-fix. The server has no authentication or TLS, so it
+listens on localhost by default; put it behind an authenticating proxy
+before exposing it.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          run,
+}
+
+func main() {
+	rootCmd.Flags().StringSliceVarP(&cfgFiles, "config", "c", []string{config.FindConfigPath()}, "config file path (repeat to merge several files; later ones override earlier)")
+	rootCmd.Flags().StringVar(&listen, "listen", "127.0.0.1:50051", "address to listen on")
+	rootCmd.Flags().BoolVar(&allowFix, "allow-fix", false, "execute Fix requests that are not dry runs")
+	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "ztc-grpc: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	cfg, err := config.LoadFiles(cfgFiles, config.LoadOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.ValidateVulnersKey(); err != nil {
+		return err
+	}
+	otelShutdown, err := telemetry.Init(context.Background(), &cfg.Telemetry, verbose)
+	if err != nil {
+		return fmt.Errorf("failed to init telemetry: %w", err)
+	}
+	defer func() { _ = otelShutdown(context.Background()) }()
+
+	// The scanner and fixer each bring their own Zabbix client, so they are
+	// built by separate fx apps.
+	var s *scanner.Scanner
+	if err := fx.New(fx.NopLogger, fx.Supply(cfg, log), scanner.Module, fx.Populate(&s)).Err(); err != nil {
+		return fmt.Errorf("failed to initialize scanner: %w", err)
+	}
+	defer func() { _ = s.Close() }()
+	var f *fixer.Fixer
+	if err := fx.New(fx.NopLogger, fx.Supply(cfg, log), fixer.Module, fx.Populate(&f)).Err(); err != nil {
+		return fmt.Errorf("failed to initialize fixer: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	gs := grpc.NewServer()
+	grpcapi.New(s, f, allowFix, log).Register(gs)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Info("Shutting down")
+		gs.GracefulStop()
+	}()
+
+	log.Info("Serving gRPC", slog.String("listen", lis.Addr().String()), slog.Bool("allow_fix", allowFix))
+	if err := gs.Serve(lis); err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ func main() {
 		"vulners.package.score", "Returns CVSS score for a package.",
 		"vulners.bulletin.score", "Returns CVSS score for a bulletin.",
 		"vulners.stats", "Returns scan statistics.",
+		"vulners.scan.status", "Returns scan status and host fetch error counters as JSON.",
 	)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to register metrics: %s\n", err)
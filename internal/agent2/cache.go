@@ -2,20 +2,63 @@ package agent2
 
 import (
 	"sync"
+	"time"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
 )
 
 // ScanCache holds the most recent scan results in a thread-safe manner.
+// It also keeps each host's last audit result keyed by its inventory
+// fingerprint, so unchanged hosts are not re-audited on the next cycle
+// (see scanner.HostCache), for at most the host max age.
 type ScanCache struct {
 	mu      sync.RWMutex
 	results *scanner.ScanResults
 	stats   scanner.Statistics
+	status  ScanStatus
+
+	hostMu     sync.Mutex
+	hosts      map[string]cachedHost
+	hostMaxAge time.Duration
+	now        func() time.Time
+}
+
+type cachedHost struct {
+	fingerprint string
+	entry       scanner.HostEntry
+	stored      time.Time
+}
+
+// ScanStatus reports the outcome of the background scans, exported as
+// vulners.scan.status.
+type ScanStatus struct {
+	LastScan  int64  `json:"last_scan"`  // unix time the last scan finished
+	LastError string `json:"last_error"` // why the last scan failed; empty on success
+
+	// FetchErrors counts the hosts whose data could not be fetched from
+	// Zabbix in the last completed scan, FetchErrorsTotal all of them since
+	// the plugin started and FailingScans the consecutive scans with at
+	// least one, so a persistent partial failure stands out from a blip.
+	FetchErrors      int   `json:"fetch_errors"`
+	FetchErrorsTotal int64 `json:"fetch_errors_total"`
+	FailingScans     int   `json:"failing_scans"`
 }
 
-// NewScanCache creates a new empty cache.
+// NewScanCache creates a new empty cache keeping host entries for
+// DefaultHostCacheMaxAge.
 func NewScanCache() *ScanCache {
-	return &ScanCache{}
+	return &ScanCache{
+		hosts:      make(map[string]cachedHost),
+		hostMaxAge: DefaultHostCacheMaxAge * time.Second,
+		now:        time.Now,
+	}
+}
+
+// SetHostMaxAge sets how long host entries are reused; 0 disables reuse.
+func (c *ScanCache) SetHostMaxAge(maxAge time.Duration) {
+	c.hostMu.Lock()
+	defer c.hostMu.Unlock()
+	c.hostMaxAge = maxAge
 }
 
 // Update replaces the cached data atomically.
@@ -26,6 +69,33 @@ func (c *ScanCache) Update(results *scanner.ScanResults, stats scanner.Statistic
 	c.stats = stats
 }
 
+// RecordScan updates the scan status after a scan; results is nil when the
+// scan failed with err.
+func (c *ScanCache) RecordScan(results *scanner.ScanResults, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.LastScan = time.Now().Unix()
+	if err != nil {
+		c.status.LastError = err.Error()
+		return
+	}
+	c.status.LastError = ""
+	c.status.FetchErrors = results.Excluded[scanner.ReasonFetchFailed]
+	c.status.FetchErrorsTotal += int64(c.status.FetchErrors)
+	if c.status.FetchErrors > 0 {
+		c.status.FailingScans++
+	} else {
+		c.status.FailingScans = 0
+	}
+}
+
+// Status returns the current scan status.
+func (c *ScanCache) Status() ScanStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
 // Results returns the cached scan results (may be nil if no scan has run).
 func (c *ScanCache) Results() *scanner.ScanResults {
 	c.mu.RLock()
@@ -39,3 +109,44 @@ func (c *ScanCache) Stats() scanner.Statistics {
 	defer c.mu.RUnlock()
 	return c.stats
 }
+
+// GetHost returns the cached audit result for hostID when the host's
+// fingerprint is unchanged and the entry is younger than the host max age.
+func (c *ScanCache) GetHost(hostID, fingerprint string) (scanner.HostEntry, bool) {
+	c.hostMu.Lock()
+	defer c.hostMu.Unlock()
+	cached, ok := c.hosts[hostID]
+	if !ok || cached.fingerprint != fingerprint || c.now().Sub(cached.stored) >= c.hostMaxAge {
+		return scanner.HostEntry{}, false
+	}
+	return cached.entry, true
+}
+
+// PutHost stores the audit result for hostID.
+func (c *ScanCache) PutHost(hostID, fingerprint string, entry scanner.HostEntry) {
+	c.hostMu.Lock()
+	defer c.hostMu.Unlock()
+	c.hosts[hostID] = cachedHost{fingerprint: fingerprint, entry: entry, stored: c.now()}
+}
+
+// ClearHosts evicts every cached host entry.
+func (c *ScanCache) ClearHosts() {
+	c.hostMu.Lock()
+	defer c.hostMu.Unlock()
+	clear(c.hosts)
+}
+
+// RetainHosts evicts hosts that were not part of the latest scan.
+func (c *ScanCache) RetainHosts(hostIDs []string) {
+	keep := make(map[string]bool, len(hostIDs))
+	for _, id := range hostIDs {
+		keep[id] = true
+	}
+	c.hostMu.Lock()
+	defer c.hostMu.Unlock()
+	for id := range c.hosts {
+		if !keep[id] {
+			delete(c.hosts, id)
+		}
+	}
+}
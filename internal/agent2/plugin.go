@@ -3,9 +3,12 @@ package agent2
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"io"
@@ -21,24 +24,42 @@ import (
 // DefaultScanInterval is the default seconds between background scans.
 const DefaultScanInterval = 3600
 
+// DefaultScanJitter is the default maximum random delay, in seconds, before
+// each background scan.
+const DefaultScanJitter = 60
+
+// DefaultHostCacheMaxAge is the default seconds a host's cached audit
+// result is reused before the host is audited again.
+const DefaultHostCacheMaxAge = 86400
+
 // ZTCPlugin implements Configurator, Runner and Exporter for Zabbix Agent 2.
+//
+// Configure may be called again while the plugin runs; see reload for which
+// options take effect without a restart.
 type ZTCPlugin struct {
 	plugin.Base
 
-	cfg          *config.Config
-	scanInterval int
+	cfg          atomic.Pointer[config.Config]
+	scanInterval atomic.Int64
+	scanJitter   atomic.Int64
 	cache        *ScanCache
 
+	// intervalChanged wakes scanLoop to reset its ticker after a reload.
+	intervalChanged chan struct{}
+
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
 // NewPlugin creates a new ZTCPlugin instance.
 func NewPlugin() *ZTCPlugin {
-	return &ZTCPlugin{
-		cache:        NewScanCache(),
-		scanInterval: DefaultScanInterval,
+	p := &ZTCPlugin{
+		cache:           NewScanCache(),
+		intervalChanged: make(chan struct{}, 1),
 	}
+	p.scanInterval.Store(DefaultScanInterval)
+	p.scanJitter.Store(DefaultScanJitter)
+	return p
 }
 
 // --- Configurator ---
@@ -53,7 +74,63 @@ func (p *ZTCPlugin) Configure(globalOptions *plugin.GlobalOptions, privateOption
 		return
 	}
 
+	cfg, interval, jitter, cacheMaxAge := parseOptions(opts)
+	if interval <= 0 {
+		interval = p.scanInterval.Load()
+	}
+	p.scanJitter.Store(jitter)
+	p.cache.SetHostMaxAge(time.Duration(cacheMaxAge) * time.Second)
+
+	if cur := p.cfg.Load(); cur != nil {
+		p.reload(cur, cfg, interval)
+		return
+	}
+
+	p.cfg.Store(cfg)
+	p.scanInterval.Store(interval)
+}
+
+// reload applies a re-Configure to a running plugin. ScanInterval,
+// ScanJitter, HostCacheMaxAge (stored by Configure), MinCVSS and Workers are
+// swapped in
+// atomically; a scan already in progress keeps the config it started with.
+// Any other change needs an agent restart.
+func (p *ZTCPlugin) reload(cur, next *config.Config, interval int64) {
+	merged := *cur
+	merged.Scan.MinCVSS = next.Scan.MinCVSS
+	merged.Scan.Workers = next.Scan.Workers
+
+	ignored := *next
+	ignored.Scan.MinCVSS = cur.Scan.MinCVSS
+	ignored.Scan.Workers = cur.Scan.Workers
+	if !reflect.DeepEqual(&ignored, cur) {
+		p.Warningf("configuration reloaded: only ScanInterval, ScanJitter, HostCacheMaxAge, MinCVSS and Workers apply without a restart; other changes are ignored until the agent restarts")
+	}
+
+	p.cfg.Store(&merged)
+	if merged.Scan.MinCVSS != cur.Scan.MinCVSS {
+		// Cached entries were filtered with the old MinCVSS.
+		p.cache.ClearHosts()
+	}
+	if p.scanInterval.Swap(interval) != interval {
+		select {
+		case p.intervalChanged <- struct{}{}:
+		default:
+		}
+	}
+	p.Infof("configuration reloaded (scan interval: %ds, jitter: up to %ds, min CVSS: %g, workers: %d)",
+		interval, p.scanJitter.Load(), merged.Scan.MinCVSS, merged.Scan.Workers)
+}
+
+// parseOptions builds a config from Plugins.VulnersThreatControl.* options.
+// The returned scan interval is 0 when ScanInterval is not set; the jitter
+// and host cache max age default to DefaultScanJitter and
+// DefaultHostCacheMaxAge.
+func parseOptions(opts map[string]string) (*config.Config, int64, int64, int64) {
 	cfg := config.DefaultConfig()
+	var interval int64
+	jitter := int64(DefaultScanJitter)
+	cacheMaxAge := int64(DefaultHostCacheMaxAge)
 
 	if v, ok := opts["VulnersApiKey"]; ok {
 		cfg.Vulners.APIKey = v
@@ -104,13 +181,26 @@ func (p *ZTCPlugin) Configure(globalOptions *plugin.GlobalOptions, privateOption
 			cfg.Scan.Timeout = t
 		}
 	}
+	if v, ok := opts["Maintenance"]; ok {
+		cfg.Scan.Maintenance = v
+	}
 	if v, ok := opts["ScanInterval"]; ok {
-		if si, err := strconv.Atoi(v); err == nil {
-			p.scanInterval = si
+		if si, err := strconv.ParseInt(v, 10, 64); err == nil {
+			interval = si
+		}
+	}
+	if v, ok := opts["ScanJitter"]; ok {
+		if sj, err := strconv.ParseInt(v, 10, 64); err == nil && sj >= 0 {
+			jitter = sj
+		}
+	}
+	if v, ok := opts["HostCacheMaxAge"]; ok {
+		if ma, err := strconv.ParseInt(v, 10, 64); err == nil && ma >= 0 {
+			cacheMaxAge = ma
 		}
 	}
 
-	p.cfg = cfg
+	return cfg, interval, jitter, cacheMaxAge
 }
 
 // Validate checks mandatory configuration.
@@ -128,6 +218,12 @@ func (p *ZTCPlugin) Validate(privateOptions any) error {
 	if opts["ZabbixApiPassword"] == "" {
 		return fmt.Errorf("Plugins.VulnersThreatControl.ZabbixApiPassword is required")
 	}
+	switch v := opts["Maintenance"]; v {
+	case "", config.MaintenanceIgnore, config.MaintenanceSkipHosts, config.MaintenanceSkipScan:
+	default:
+		return fmt.Errorf("Plugins.VulnersThreatControl.Maintenance must be %q, %q or %q, got %q",
+			config.MaintenanceIgnore, config.MaintenanceSkipHosts, config.MaintenanceSkipScan, v)
+	}
 	return nil
 }
 
@@ -135,7 +231,7 @@ func (p *ZTCPlugin) Validate(privateOptions any) error {
 
 // Start is called when Agent 2 starts the plugin.
 func (p *ZTCPlugin) Start() {
-	p.Infof("starting VulnersThreatControl plugin (scan interval: %ds)", p.scanInterval)
+	p.Infof("starting VulnersThreatControl plugin (scan interval: %ds, jitter: up to %ds)", p.scanInterval.Load(), p.scanJitter.Load())
 
 	ctx, cancel := context.WithCancel(context.Background())
 	p.cancel = cancel
@@ -153,42 +249,68 @@ func (p *ZTCPlugin) Stop() {
 func (p *ZTCPlugin) scanLoop(ctx context.Context) {
 	defer p.wg.Done()
 
-	// Run immediately on start, then periodically.
-	p.runScan(ctx)
+	// Run right after a random jitter on start, then periodically, each
+	// scan again delayed by a fresh jitter.
+	p.runScanAfterJitter(ctx)
 
-	interval := p.scanInterval
-	if interval <= 0 {
-		interval = DefaultScanInterval
-	}
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	ticker := time.NewTicker(p.interval())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			p.runScan(ctx)
+			p.runScanAfterJitter(ctx)
+		case <-p.intervalChanged:
+			ticker.Reset(p.interval())
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// interval returns the current time between background scans.
+func (p *ZTCPlugin) interval() time.Duration {
+	interval := p.scanInterval.Load()
+	if interval <= 0 {
+		interval = DefaultScanInterval
+	}
+	return time.Duration(interval) * time.Second
+}
+
+// runScanAfterJitter waits up to ScanJitter seconds, then scans unless the
+// plugin is stopping.
+func (p *ZTCPlugin) runScanAfterJitter(ctx context.Context) {
+	jitter := time.Duration(p.scanJitter.Load()) * time.Second
+	if err := scanner.WaitJitter(ctx, jitter); err != nil {
+		return
+	}
+	p.runScan(ctx)
+}
+
 func (p *ZTCPlugin) runScan(ctx context.Context) {
-	if p.cfg == nil {
+	// Scan with a snapshot so a concurrent reload cannot change settings
+	// mid-scan.
+	cfg := p.cfg.Load()
+	if cfg == nil {
 		p.Errf("plugin not configured, skipping scan")
 		return
 	}
 
 	// Create a nop logger for the scanner internals.
 	// Plugin logging goes through p.Base (SDK logger).
-	s, err := scanner.New(p.cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s, err := scanner.New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
 	if err != nil {
 		p.Errf("failed to create scanner: %s", err)
 		return
 	}
 	defer func() { _ = s.Close() }()
 
-	results, err := s.Scan(ctx, scanner.ScanOptions{})
+	results, err := s.Scan(ctx, scanner.ScanOptions{HostCache: p.cache})
+	if errors.Is(err, scanner.ErrScanSkipped) {
+		p.Infof("scan skipped: hosts are in maintenance")
+		return
+	}
+	p.cache.RecordScan(results, err)
 	if err != nil {
 		p.Errf("scan failed: %s", err)
 		return
@@ -197,6 +319,9 @@ func (p *ZTCPlugin) runScan(ctx context.Context) {
 	stats := s.GetAggregator().GetStatistics()
 	p.cache.Update(results, stats)
 
+	if n := results.Excluded[scanner.ReasonFetchFailed]; n > 0 {
+		p.Warningf("failed to fetch data of %d hosts from Zabbix", n)
+	}
 	p.Infof("scan completed: %d hosts, %d vulns", results.HostsScanned, results.VulnerablePackages)
 }
 
@@ -204,12 +329,21 @@ func (p *ZTCPlugin) runScan(ctx context.Context) {
 
 // Export handles item key requests from Agent 2.
 func (p *ZTCPlugin) Export(key string, params []string, ctx plugin.ContextProvider) (any, error) {
+	// The status is available before the first scan completes.
+	if key == "vulners.scan.status" {
+		b, err := json.Marshal(p.cache.Status())
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal scan status: %w", err)
+		}
+		return string(b), nil
+	}
+
 	results := p.cache.Results()
 	if results == nil {
 		return nil, fmt.Errorf("no scan data available yet")
 	}
 
-	lldGen := scanner.NewLLDGenerator(p.cfg.Naming)
+	lldGen := scanner.ProvideLLDGenerator(p.cfg.Load())
 
 	switch key {
 	case "vulners.hosts_lld":
@@ -286,6 +420,8 @@ func (p *ZTCPlugin) getStatMetric(metric string) (any, error) {
 		return stats.MaxCVSS, nil
 	case "avg_score":
 		return stats.AvgCVSS, nil
+	case "fleet_risk":
+		return stats.FleetRisk, nil
 	default:
 		return nil, fmt.Errorf("unknown stats metric: %s", metric)
 	}
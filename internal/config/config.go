@@ -3,17 +3,27 @@ package config
 import (
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/toml"
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/confmap"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
 	"gopkg.in/ini.v1"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/version"
 )
 
 // DefaultConfigPath is the default config path, matching the original Python project.
@@ -44,6 +54,36 @@ type Config struct {
 	Scan      ScanConfig      `koanf:"scan"`
 	Telemetry TelemetryConfig `koanf:"telemetry"`
 	Naming    NamingConfig    `koanf:"naming"`
+	HTTP      HTTPConfig      `koanf:"http"`
+	Fix       FixConfig       `koanf:"fix"`
+}
+
+// FixConfig holds settings for the fix command.
+type FixConfig struct {
+	// AddressPreference is the order in which host interfaces are tried
+	// when picking the address to run a fix on. See AddressPreferences.
+	AddressPreference []string `koanf:"address_preference"`
+	// MaxDataAge is the age in hours beyond which the pushed scan data is
+	// too stale to plan a fix from; fix refuses to run on it unless told
+	// to (0 = no check).
+	MaxDataAge int `koanf:"max_data_age"`
+}
+
+// AddressPreferences lists the valid FixConfig.AddressPreference entries.
+// The scope selects interfaces: "agent" is the main agent interface,
+// "main" any main interface, "any" every interface. A bare scope uses the
+// interface's "Connect to" setting (IP or DNS, falling back to the other);
+// "-ip" and "-dns" force one of them.
+var AddressPreferences = []string{
+	"agent", "agent-ip", "agent-dns",
+	"main", "main-ip", "main-dns",
+	"any", "any-ip", "any-dns",
+}
+
+// HTTPConfig holds settings shared by the Zabbix and Vulners HTTP clients.
+type HTTPConfig struct {
+	// UserAgent overrides the default "zabbix-threat-control-go/<version>".
+	UserAgent string `koanf:"user_agent"`
 }
 
 // NamingConfig holds customizable names for virtual hosts, groups, dashboards, and actions.
@@ -60,6 +100,10 @@ type NamingConfig struct {
 	GroupName             string `koanf:"group_name"`
 	DashboardName         string `koanf:"dashboard_name"`
 	ActionName            string `koanf:"action_name"`
+	// HashPackageKeys replaces name/version/arch in package item keys with a
+	// short hash, for package names that are too long or contain characters
+	// Zabbix rejects in keys. Names stay readable in the LLD macros.
+	HashPackageKeys bool `koanf:"hash_package_keys"`
 }
 
 // ZabbixConfig holds Zabbix connection settings
@@ -67,47 +111,196 @@ type ZabbixConfig struct {
 	FrontURL    string `koanf:"front_url"`
 	APIUser     string `koanf:"api_user"`
 	APIPassword string `koanf:"api_password"`
-	ServerFQDN  string `koanf:"server_fqdn"`
-	ServerPort  int    `koanf:"server_port"`
-	SenderPath  string `koanf:"sender_path"`
-	GetPath     string `koanf:"get_path"`
-	VerifySSL   bool   `koanf:"verify_ssl"`
+	// APIPasswordFile, when set, is read at load time and replaces APIPassword.
+	APIPasswordFile string `koanf:"api_password_file"`
+	// APIToken is a Zabbix API token (Zabbix 5.4+) used instead of
+	// api_user/api_password. Token sessions are not logged out on close.
+	APIToken string `koanf:"api_token"`
+	// APITokenFile, when set, is read at load time and replaces APIToken.
+	APITokenFile string `koanf:"api_token_file"`
+	ServerFQDN   string `koanf:"server_fqdn"`
+	ServerPort   int    `koanf:"server_port"`
+	SenderPath   string `koanf:"sender_path"`
+	GetPath      string `koanf:"get_path"`
+	VerifySSL    bool   `koanf:"verify_ssl"`
+	// APITimeout is the Zabbix API request timeout in seconds, independent
+	// of scan.timeout which bounds Vulners requests.
+	APITimeout int `koanf:"api_timeout"`
+	// MaxConcurrentRequests caps in-flight Zabbix API calls across all
+	// goroutines, independent of scan.workers which gates Vulners audits.
+	MaxConcurrentRequests int `koanf:"max_concurrent_requests"`
+	// ContentType is sent with every API request. Some proxies in front of
+	// the frontend only accept "application/json".
+	ContentType string `koanf:"content_type"`
 }
 
 // VulnersConfig holds Vulners API settings
 type VulnersConfig struct {
-	APIKey    string `koanf:"api_key"`
-	Host      string `koanf:"host"`
-	RateLimit int    `koanf:"rate_limit"`
+	APIKey string `koanf:"api_key"`
+	// APIKeyFile, when set, is read at load time and replaces APIKey.
+	APIKeyFile string `koanf:"api_key_file"`
+	Host       string `koanf:"host"`
+	RateLimit  int    `koanf:"rate_limit"`
+	// RequestTimeout bounds a single host audit in seconds, retries
+	// included. 0 uses scan.timeout; raise it for hosts with thousands of
+	// packages, whose audits can take longer than other requests.
+	RequestTimeout int `koanf:"request_timeout"`
+	// ReplayDir, set by "ztc scan --vulners-replay", serves canned audit
+	// results from a directory instead of calling the Vulners API. It is not
+	// read from config files.
+	ReplayDir string `koanf:"-"`
 }
 
 // ScanConfig holds scanning parameters
 type ScanConfig struct {
 	MinCVSS             float64 `koanf:"min_cvss"`
+	CritCVSS            float64 `koanf:"crit_cvss"` // value of the {$SCORE.CRIT} macro
 	OSReportTemplate    string  `koanf:"os_report_template"`
 	OSReportVisibleName string  `koanf:"os_report_visible_name"`
 	TemplateGroupName   string  `koanf:"template_group_name"`
 	Timeout             int     `koanf:"timeout"`
 	Workers             int     `koanf:"workers"`
 	LLDDelay            int     `koanf:"lld_delay"`
+	// PushRawJSON also sends the full scan result as JSON to the
+	// vulners.scan.raw item on the statistics host.
+	PushRawJSON bool `koanf:"push_raw_json"`
+	// MaxAffectedHosts caps the host name lists in package and bulletin LLD
+	// macros, with an "... and N more" entry for the rest (0 = no cap). The
+	// host ID lists ztc fix plans from are never capped.
+	MaxAffectedHosts int `koanf:"max_affected_hosts"`
+	// MaxLLDEntries caps the hosts, packages and bulletins LLD at this many
+	// entries each, keeping the highest-scoring ones (0 = no cap). It guards
+	// Zabbix against pathological scan results.
+	MaxLLDEntries int `koanf:"max_lld_entries"`
+	// CompressLLD sends the LLD values gzipped and base64-encoded. prepare
+	// then gives the Vulners discovery rules a JavaScript preprocessing
+	// step decoding them, as Zabbix has no built-in one.
+	CompressLLD bool `koanf:"compress_lld"`
+	// DiscoveryMode selects how hosts to scan are found: DiscoveryTemplate
+	// reads the OS-Report template items, DiscoveryInventory reads the
+	// os/os_full and software_full host inventory fields.
+	DiscoveryMode string `koanf:"discovery_mode"`
+	// OSItemTag and PackagesItemTag select the OS-Report items by a
+	// "name:value" item tag instead of the system.sw.os and
+	// system.sw.packages keys, for templates whose item keys were
+	// customized. Item tags need Zabbix 5.4 or later.
+	OSItemTag       string `koanf:"os_item_tag"`
+	PackagesItemTag string `koanf:"packages_item_tag"`
+	// ScoreItemValueType is the Zabbix value type of the discovered package
+	// and bulletin items: ValueTypeUnsigned or ValueTypeFloat. Despite the
+	// "score" key prefix these items hold affected host counts; float is
+	// only useful when they are repurposed to hold CVSS scores.
+	ScoreItemValueType string `koanf:"score_item_value_type"`
+	// PackageItemValue selects what the package and bulletin items hold:
+	// PackageValueCount (affected host count, as the Python version does) or
+	// PackageValueCVSS (the CVSS score, stored as float regardless of
+	// ScoreItemValueType).
+	PackageItemValue string `koanf:"package_item_value"`
+	// OSNameMap adds OS name normalization rules: a host whose OS name
+	// contains the (case-insensitive) key is audited as the Vulners OS in the
+	// value. These rules take precedence over the built-in ones.
+	OSNameMap map[string]string `koanf:"os_name_map"`
+	// EnabledLLD lists the LLD types (see LLDTypes) that scans push and
+	// prepare creates discovery rules for. Leaving out "packages" and
+	// "bulletins" avoids thousands of discovered items on large fleets, but
+	// ztc fix then refuses the plans that need the missing LLD.
+	EnabledLLD []string `koanf:"enabled_lld"`
+	// PushMinCVSS drops packages and bulletins scoring below it from the
+	// data pushed to Zabbix, while scan results and exports keep everything
+	// that passed MinCVSS. Hosts are always pushed (0 = push everything).
+	PushMinCVSS float64 `koanf:"push_min_cvss"`
+	// LLDLifetime is the lifetime of the Vulners discovery rules: how long
+	// Zabbix keeps discovered items after they drop out of the LLD data, as
+	// a Zabbix time period such as "7d". Existing rules pick it up with
+	// prepare --force.
+	LLDLifetime string `koanf:"lld_lifetime"`
+	// Maintenance selects how hosts in an active Zabbix maintenance are
+	// treated: MaintenanceIgnore scans them, MaintenanceSkipHosts excludes
+	// them and MaintenanceSkipScan skips the whole scan while any of them
+	// is in maintenance.
+	Maintenance string `koanf:"maintenance"`
 }
 
+// LLD types for ScanConfig.EnabledLLD.
+const (
+	LLDHosts     = "hosts"
+	LLDPackages  = "packages"
+	LLDBulletins = "bulletins"
+)
+
+// LLDTypes lists the valid ScanConfig.EnabledLLD entries.
+var LLDTypes = []string{LLDHosts, LLDPackages, LLDBulletins}
+
+// LLDEnabled reports whether the LLD type kind is in EnabledLLD.
+func (s ScanConfig) LLDEnabled(kind string) bool {
+	return slices.Contains(s.EnabledLLD, kind)
+}
+
+// Item value types for ScanConfig.ScoreItemValueType.
+const (
+	ValueTypeFloat    = "float"
+	ValueTypeUnsigned = "unsigned"
+)
+
+// Package and bulletin item contents for ScanConfig.PackageItemValue.
+const (
+	PackageValueCount = "count"
+	PackageValueCVSS  = "cvss"
+)
+
+// Host discovery modes for ScanConfig.DiscoveryMode.
+const (
+	DiscoveryTemplate  = "template"
+	DiscoveryInventory = "inventory"
+)
+
+// Maintenance handling modes for ScanConfig.Maintenance.
+const (
+	MaintenanceIgnore    = "ignore"
+	MaintenanceSkipHosts = "skip_hosts"
+	MaintenanceSkipScan  = "skip_scan"
+)
+
 // TelemetryConfig holds OpenTelemetry settings
 type TelemetryConfig struct {
-	Enabled      bool   `koanf:"enabled"`
+	Enabled bool `koanf:"enabled"`
+	// OTLPEndpoint is a collector URL, whose scheme selects TLS
+	// ("https://collector:4318"), or a bare host:port. When empty the
+	// standard OTEL_EXPORTER_OTLP_* variables configure the exporter.
 	OTLPEndpoint string `koanf:"otlp_endpoint"`
+	// OTLPProtocol is OTLPProtocolHTTP or OTLPProtocolGRPC. When empty,
+	// OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL
+	// decides, defaulting to HTTP.
+	OTLPProtocol string `koanf:"otlp_protocol"`
+	// OTLPInsecure sends to a bare host:port OTLPEndpoint without TLS.
+	OTLPInsecure bool `koanf:"otlp_insecure"`
+	// SampleRatio is the fraction of traces to sample, from 0 to 1. Child
+	// spans follow their parent's decision.
+	SampleRatio float64 `koanf:"sample_ratio"`
+	// Environment is reported as the deployment.environment resource
+	// attribute (e.g. "production"), left out when empty.
+	Environment string `koanf:"environment"`
 }
 
+// OTLP exporter protocols for TelemetryConfig.OTLPProtocol.
+const (
+	OTLPProtocolHTTP = "http"
+	OTLPProtocolGRPC = "grpc"
+)
+
 // DefaultConfig returns a Config with default values
 func DefaultConfig() *Config {
 	return &Config{
 		Zabbix: ZabbixConfig{
-			FrontURL:   "http://localhost",
-			ServerFQDN: "localhost",
-			ServerPort: 10051,
-			SenderPath: "zabbix_sender",
-			GetPath:    "zabbix_get",
-			VerifySSL:  true,
+			FrontURL:              "http://localhost",
+			ServerFQDN:            "localhost",
+			ServerPort:            10051,
+			SenderPath:            "zabbix_sender",
+			GetPath:               "zabbix_get",
+			VerifySSL:             true,
+			APITimeout:            30,
+			MaxConcurrentRequests: 4,
+			ContentType:           "application/json-rpc",
 		},
 		Vulners: VulnersConfig{
 			Host:      "https://vulners.com",
@@ -115,15 +308,29 @@ func DefaultConfig() *Config {
 		},
 		Scan: ScanConfig{
 			MinCVSS:             1.0,
+			CritCVSS:            9.0,
 			OSReportTemplate:    "tmpl.vulners.os-report",
 			OSReportVisibleName: "Template Vulners OS-Report",
 			TemplateGroupName:   "Templates",
 			Timeout:             30,
 			Workers:             4,
 			LLDDelay:            300,
+			DiscoveryMode:       DiscoveryTemplate,
+			ScoreItemValueType:  ValueTypeUnsigned,
+			MaxLLDEntries:       100000,
+			PackageItemValue:    PackageValueCount,
+			EnabledLLD:          slices.Clone(LLDTypes),
+			LLDLifetime:         "0",
+			Maintenance:         MaintenanceIgnore,
 		},
 		Telemetry: TelemetryConfig{
-			Enabled: false,
+			Enabled:      false,
+			OTLPInsecure: true,
+			SampleRatio:  1.0,
+		},
+		Fix: FixConfig{
+			AddressPreference: []string{"agent", "main", "any"},
+			MaxDataAge:        168,
 		},
 		Naming: NamingConfig{
 			HostsHost:             "vulners.hosts",
@@ -141,73 +348,142 @@ func DefaultConfig() *Config {
 	}
 }
 
+// includeKey is the top-level YAML key listing additional files to merge.
+const includeKey = "include"
+
+// LoadOptions controls optional loader behaviour.
+type LoadOptions struct {
+	// Strict turns unrecognized-key warnings into load errors.
+	Strict bool
+}
+
 // Load reads configuration from a file, auto-detecting format by extension.
-// .yaml/.yml → YAML (Koanf), .conf/.ini or anything else → legacy INI.
+// .yaml/.yml → YAML, .json → JSON, .toml → TOML (Koanf), .conf/.ini or anything else → legacy INI.
 // Environment variables (ZTC_ prefix) always override file values.
 func Load(path string) (*Config, error) {
+	return LoadWithOptions(path, LoadOptions{})
+}
+
+// LoadWithOptions is like Load but accepts loader options (e.g. strict mode).
+func LoadWithOptions(path string, opts LoadOptions) (*Config, error) {
+	return LoadFiles([]string{path}, opts)
+}
+
+// LoadFiles merges several config files in order: later files override
+// earlier ones, and environment variables override all of them. This lets
+// secrets live in a separate, tightly-permissioned file.
+func LoadFiles(paths []string, opts LoadOptions) (*Config, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no config file given")
+	}
+
+	k := koanf.New(".")
+
+	if err := loadDefaults(k); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if err := mergeFile(k, path, opts, seen); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnvOverrides(k); err != nil {
+		return nil, err
+	}
+
+	return unmarshalAndValidate(k)
+}
+
+// mergeFile merges a single config file (and, for YAML, its includes) into k.
+func mergeFile(k *koanf.Koanf, path string, opts LoadOptions, seen map[string]bool) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil, fmt.Errorf("config file not found: %s", path)
+		return fmt.Errorf("config file not found: %s", path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path %s: %w", path, err)
 	}
+	if seen[abs] {
+		return fmt.Errorf("config file included more than once: %s", path)
+	}
+	seen[abs] = true
 
 	ext := strings.ToLower(filepath.Ext(path))
 
 	switch ext {
 	case ".yaml", ".yml":
-		return loadYAML(path)
+		return mergeDocument(k, path, yaml.Parser(), "YAML", opts, seen)
+	case ".json":
+		return mergeDocument(k, path, json.Parser(), "JSON", opts, seen)
+	case ".toml":
+		return mergeDocument(k, path, toml.Parser(), "TOML", opts, seen)
 	default:
 		// .conf, .ini, or no extension → try INI (backwards compat)
-		return loadINI(path)
+		return mergeINI(k, path, opts)
 	}
 }
 
-// loadYAML loads config from a YAML file with Koanf.
-func loadYAML(path string) (*Config, error) {
-	k := koanf.New(".")
+// mergeDocument merges a YAML, JSON or TOML config file, decoded by p, into k.
+// Files listed under the top-level "include" key are merged after the
+// file itself, in order, with relative paths resolved against the
+// including file's directory.
+func mergeDocument(k *koanf.Koanf, path string, p koanf.Parser, format string, opts LoadOptions, seen map[string]bool) error {
+	// Parse the file into its own instance first so unknown keys can be
+	// detected before they are merged over the defaults.
+	fk := koanf.New(".")
+	if err := fk.Load(file.Provider(path), p); err != nil {
+		return fmt.Errorf("failed to parse %s config file %s: %w", format, path, err)
+	}
 
-	if err := loadDefaults(k); err != nil {
-		return nil, err
+	includes := fk.Strings(includeKey)
+	if one, ok := fk.Get(includeKey).(string); ok && one != "" {
+		includes = []string{one}
 	}
+	fk.Delete(includeKey)
 
-	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML config file: %w", err)
+	if err := reportUnknownKeys(unknownYAMLKeys(fk.Keys()), opts); err != nil {
+		return err
 	}
 
-	if err := loadEnvOverrides(k); err != nil {
-		return nil, err
+	if err := k.Merge(fk); err != nil {
+		return fmt.Errorf("failed to merge %s config %s: %w", format, path, err)
 	}
 
-	return unmarshalAndValidate(k)
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(path), inc)
+		}
+		if err := mergeFile(k, inc, opts, seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// loadINI loads config from a legacy INI file (backwards compatible with
-// the original Python zabbix-threat-control project).
-func loadINI(path string) (*Config, error) {
+// mergeINI merges a legacy INI file (backwards compatible with the original
+// Python zabbix-threat-control project) into k.
+func mergeINI(k *koanf.Koanf, path string, opts LoadOptions) error {
 	iniFile, err := ini.Load(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse INI config file: %w", err)
+		return fmt.Errorf("failed to parse INI config file %s: %w", path, err)
 	}
 
 	// Map INI sections/keys → flat koanf key map
 	m, warnings := iniToMap(iniFile)
-	for _, w := range warnings {
-		fmt.Fprintf(os.Stderr, "WARNING: %s\n", w)
-	}
-
-	k := koanf.New(".")
-
-	if err := loadDefaults(k); err != nil {
-		return nil, err
+	if err := reportUnknownKeys(warnings, opts); err != nil {
+		return err
 	}
 
 	if err := k.Load(confmap.Provider(m, "."), nil); err != nil {
-		return nil, fmt.Errorf("failed to load INI values: %w", err)
-	}
-
-	if err := loadEnvOverrides(k); err != nil {
-		return nil, err
+		return fmt.Errorf("failed to load INI values: %w", err)
 	}
 
-	return unmarshalAndValidate(k)
+	return nil
 }
 
 // LoadINI is an exported variant for the migrate-config command.
@@ -252,9 +528,13 @@ func LoadINIWithWarnings(path string) (*Config, []string, error) {
 // iniKeyMap maps INI key names (lowercased, no separators) to koanf key paths.
 var iniKeyMap = map[string]string{
 	// MANDATORY section
-	"vulnersapikey":     "vulners.api_key",
-	"zabbixapiuser":     "zabbix.api_user",
-	"zabbixapipassword": "zabbix.api_password",
+	"vulnersapikey":         "vulners.api_key",
+	"zabbixapiuser":         "zabbix.api_user",
+	"zabbixapipassword":     "zabbix.api_password",
+	"zabbixapipasswordfile": "zabbix.api_password_file",
+	"zabbixapitoken":        "zabbix.api_token",
+	"zabbixapitokenfile":    "zabbix.api_token_file",
+	"vulnersapikeyfile":     "vulners.api_key_file",
 	// OPTIONAL section
 	"zabbixfronturl":      "zabbix.front_url",
 	"zabbixserverfqdn":    "zabbix.server_fqdn",
@@ -264,6 +544,7 @@ var iniKeyMap = map[string]string{
 	"zabbixgetpath":       "zabbix.get_path",    // Go alias
 	"zabbixget":           "zabbix.get_path",    // Python key: ZabbixGet
 	"mincvss":             "scan.min_cvss",
+	"critcvss":            "scan.crit_cvss",
 	"osreporttemplate":    "scan.os_report_template",     // Go alias
 	"templatehost":        "scan.os_report_template",     // Python key: TemplateHost
 	"templatevisiblename": "scan.os_report_visible_name", // Python key: TemplateVisibleName
@@ -280,14 +561,33 @@ var iniKeyMap = map[string]string{
 	"hostgroupname":         "naming.group_name",
 	"dashboardname":         "naming.dashboard_name",
 	"actionname":            "naming.action_name",
+	"hashpackagekeys":       "naming.hash_package_keys",
 	// ADVANCED section
-	"zabbixverifyssl":  "zabbix.verify_ssl", // Go alias
-	"verifyssl":        "zabbix.verify_ssl", // Python key: VerifySSL
-	"vulnershost":      "vulners.host",
-	"vulnersratelimit": "vulners.rate_limit",
-	"timeout":          "scan.timeout",
-	"workers":          "scan.workers",
-	"llddelay":         "scan.lld_delay",
+	"zabbixverifyssl":             "zabbix.verify_ssl", // Go alias
+	"verifyssl":                   "zabbix.verify_ssl", // Python key: VerifySSL
+	"zabbixapitimeout":            "zabbix.api_timeout",
+	"zabbixmaxconcurrentrequests": "zabbix.max_concurrent_requests",
+	"zabbixcontenttype":           "zabbix.content_type",
+	"vulnershost":                 "vulners.host",
+	"vulnersratelimit":            "vulners.rate_limit",
+	"vulnersrequesttimeout":       "vulners.request_timeout",
+	"timeout":                     "scan.timeout",
+	"workers":                     "scan.workers",
+	"llddelay":                    "scan.lld_delay",
+	"useragent":                   "http.user_agent",
+	"pushrawjson":                 "scan.push_raw_json",
+	"maxaffectedhosts":            "scan.max_affected_hosts",
+	"maxlldentries":               "scan.max_lld_entries",
+	"compresslld":                 "scan.compress_lld",
+	"pushmincvss":                 "scan.push_min_cvss",
+	"lldlifetime":                 "scan.lld_lifetime",
+	"maxdataage":                  "fix.max_data_age",
+	"discoverymode":               "scan.discovery_mode",
+	"ositemtag":                   "scan.os_item_tag",
+	"packagesitemtag":             "scan.packages_item_tag",
+	"maintenance":                 "scan.maintenance",
+	"scoreitemvaluetype":          "scan.score_item_value_type",
+	"packageitemvalue":            "scan.package_item_value",
 }
 
 // legacyINIKeys lists Python-era INI keys that are recognized but have no
@@ -331,6 +631,81 @@ func iniToMap(f *ini.File) (map[string]interface{}, []string) {
 	return m, warnings
 }
 
+// reportUnknownKeys prints key warnings to stderr, or returns them as a
+// single error when strict mode is enabled.
+func reportUnknownKeys(warnings []string, opts LoadOptions) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	if opts.Strict {
+		errs := make([]error, len(warnings))
+		for i, w := range warnings {
+			errs[i] = errors.New(w)
+		}
+		return fmt.Errorf("strict config: %d unrecognized key(s): %w", len(warnings), errors.Join(errs...))
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", w)
+	}
+	return nil
+}
+
+// unknownYAMLKeys returns a warning for every flattened key that does not map
+// to a Config field.
+func unknownYAMLKeys(keys []string) []string {
+	known, prefixes := knownKeys()
+	var warnings []string
+	for _, key := range keys {
+		if known[key] || hasKnownPrefix(key, prefixes) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("unrecognized config key %q (ignored)", key))
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+func hasKnownPrefix(key string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(key, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// knownKeys walks the koanf tags of Config and returns the set of valid leaf
+// keys plus the prefixes of map-typed fields (whose sub-keys are free-form).
+func knownKeys() (map[string]bool, []string) {
+	known := make(map[string]bool)
+	var prefixes []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("koanf")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			key := tag
+			if prefix != "" {
+				key = prefix + "." + tag
+			}
+			switch f.Type.Kind() {
+			case reflect.Struct:
+				walk(f.Type, key)
+			case reflect.Map:
+				known[key] = true
+				prefixes = append(prefixes, key)
+			default:
+				known[key] = true
+			}
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return known, prefixes
+}
+
 // --- helpers ---
 
 func loadDefaults(k *koanf.Koanf) error {
@@ -342,16 +717,34 @@ func loadDefaults(k *koanf.Koanf) error {
 		"zabbix.sender_path":             defaults.Zabbix.SenderPath,
 		"zabbix.get_path":                defaults.Zabbix.GetPath,
 		"zabbix.verify_ssl":              defaults.Zabbix.VerifySSL,
+		"zabbix.api_timeout":             defaults.Zabbix.APITimeout,
+		"zabbix.max_concurrent_requests": defaults.Zabbix.MaxConcurrentRequests,
+		"zabbix.content_type":            defaults.Zabbix.ContentType,
 		"vulners.host":                   defaults.Vulners.Host,
 		"vulners.rate_limit":             defaults.Vulners.RateLimit,
+		"vulners.request_timeout":        defaults.Vulners.RequestTimeout,
 		"scan.min_cvss":                  defaults.Scan.MinCVSS,
+		"scan.push_min_cvss":             defaults.Scan.PushMinCVSS,
+		"scan.lld_lifetime":              defaults.Scan.LLDLifetime,
+		"scan.crit_cvss":                 defaults.Scan.CritCVSS,
 		"scan.os_report_template":        defaults.Scan.OSReportTemplate,
 		"scan.os_report_visible_name":    defaults.Scan.OSReportVisibleName,
 		"scan.template_group_name":       defaults.Scan.TemplateGroupName,
 		"scan.timeout":                   defaults.Scan.Timeout,
 		"scan.workers":                   defaults.Scan.Workers,
 		"scan.lld_delay":                 defaults.Scan.LLDDelay,
+		"scan.push_raw_json":             defaults.Scan.PushRawJSON,
+		"scan.max_affected_hosts":        defaults.Scan.MaxAffectedHosts,
+		"scan.max_lld_entries":           defaults.Scan.MaxLLDEntries,
+		"scan.compress_lld":              defaults.Scan.CompressLLD,
+		"scan.discovery_mode":            defaults.Scan.DiscoveryMode,
+		"scan.maintenance":               defaults.Scan.Maintenance,
+		"scan.score_item_value_type":     defaults.Scan.ScoreItemValueType,
+		"scan.package_item_value":        defaults.Scan.PackageItemValue,
+		"scan.enabled_lld":               defaults.Scan.EnabledLLD,
 		"telemetry.enabled":              defaults.Telemetry.Enabled,
+		"telemetry.otlp_insecure":        defaults.Telemetry.OTLPInsecure,
+		"telemetry.sample_ratio":         defaults.Telemetry.SampleRatio,
 		"naming.hosts_host":              defaults.Naming.HostsHost,
 		"naming.hosts_visible_name":      defaults.Naming.HostsVisibleName,
 		"naming.packages_host":           defaults.Naming.PackagesHost,
@@ -363,6 +756,9 @@ func loadDefaults(k *koanf.Koanf) error {
 		"naming.group_name":              defaults.Naming.GroupName,
 		"naming.dashboard_name":          defaults.Naming.DashboardName,
 		"naming.action_name":             defaults.Naming.ActionName,
+		"naming.hash_package_keys":       defaults.Naming.HashPackageKeys,
+		"fix.address_preference":         defaults.Fix.AddressPreference,
+		"fix.max_data_age":               defaults.Fix.MaxDataAge,
 	}, "."), nil)
 }
 
@@ -383,24 +779,59 @@ func unmarshalAndValidate(k *koanf.Koanf) (*Config, error) {
 	if err := k.Unmarshal("", &cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := cfg.resolveSecretFiles(); err != nil {
+		return nil, err
+	}
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
 }
 
+// resolveSecretFiles replaces secrets with the contents of their *_file
+// counterparts (Docker/Kubernetes secret mounts). A set file path takes
+// precedence over an inline value, but not over the secret's ZTC_* env var.
+func (c *Config) resolveSecretFiles() error {
+	secrets := []struct {
+		key  string
+		env  string
+		path string
+		dst  *string
+	}{
+		{"zabbix.api_password_file", "ZTC_ZABBIX_API_PASSWORD", c.Zabbix.APIPasswordFile, &c.Zabbix.APIPassword},
+		{"zabbix.api_token_file", "ZTC_ZABBIX_API_TOKEN", c.Zabbix.APITokenFile, &c.Zabbix.APIToken},
+		{"vulners.api_key_file", "ZTC_VULNERS_API_KEY", c.Vulners.APIKeyFile, &c.Vulners.APIKey},
+	}
+
+	var errs []error
+	for _, s := range secrets {
+		if s.path == "" || os.Getenv(s.env) != "" {
+			continue
+		}
+		data, err := os.ReadFile(s.path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.key, err))
+			continue
+		}
+		*s.dst = strings.TrimRight(string(data), "\r\n")
+	}
+	return errors.Join(errs...)
+}
+
 // Validate checks that Zabbix connection fields are set and values are in range.
 // It does NOT require vulners.api_key — that is only needed for scan/fix commands
 // and is validated by ValidateVulnersKey().
 func (c *Config) Validate() error {
 	var errs []error
 
-	// Zabbix connection (always required)
-	if c.Zabbix.APIUser == "" {
-		errs = append(errs, fmt.Errorf("zabbix.api_user is required"))
-	}
-	if c.Zabbix.APIPassword == "" {
-		errs = append(errs, fmt.Errorf("zabbix.api_password is required"))
+	// Zabbix connection (always required): an API token or user/password
+	if c.Zabbix.APIToken == "" {
+		if c.Zabbix.APIUser == "" {
+			errs = append(errs, fmt.Errorf("zabbix.api_user is required"))
+		}
+		if c.Zabbix.APIPassword == "" {
+			errs = append(errs, fmt.Errorf("zabbix.api_password is required"))
+		}
 	}
 
 	// Range checks
@@ -416,28 +847,168 @@ func (c *Config) Validate() error {
 	if c.Scan.MinCVSS < 0 || c.Scan.MinCVSS > 10 {
 		errs = append(errs, fmt.Errorf("scan.min_cvss must be between 0.0 and 10.0, got %g", c.Scan.MinCVSS))
 	}
+	if c.Scan.PushMinCVSS < 0 || c.Scan.PushMinCVSS > 10 {
+		errs = append(errs, fmt.Errorf("scan.push_min_cvss must be between 0.0 and 10.0, got %g", c.Scan.PushMinCVSS))
+	}
+	if c.Scan.CritCVSS < 0 || c.Scan.CritCVSS > 10 {
+		errs = append(errs, fmt.Errorf("scan.crit_cvss must be between 0.0 and 10.0, got %g", c.Scan.CritCVSS))
+	}
 	if c.Scan.Workers <= 0 {
 		errs = append(errs, fmt.Errorf("scan.workers must be greater than 0, got %d", c.Scan.Workers))
 	}
 	if c.Scan.Timeout <= 0 {
 		errs = append(errs, fmt.Errorf("scan.timeout must be greater than 0, got %d", c.Scan.Timeout))
 	}
+	if c.Scan.DiscoveryMode != DiscoveryTemplate && c.Scan.DiscoveryMode != DiscoveryInventory {
+		errs = append(errs, fmt.Errorf("scan.discovery_mode must be %q or %q, got %q", DiscoveryTemplate, DiscoveryInventory, c.Scan.DiscoveryMode))
+	}
+	if !slices.Contains([]string{MaintenanceIgnore, MaintenanceSkipHosts, MaintenanceSkipScan}, c.Scan.Maintenance) {
+		errs = append(errs, fmt.Errorf("scan.maintenance must be %q, %q or %q, got %q", MaintenanceIgnore, MaintenanceSkipHosts, MaintenanceSkipScan, c.Scan.Maintenance))
+	}
+	if c.Scan.ScoreItemValueType != ValueTypeFloat && c.Scan.ScoreItemValueType != ValueTypeUnsigned {
+		errs = append(errs, fmt.Errorf("scan.score_item_value_type must be %q or %q, got %q", ValueTypeFloat, ValueTypeUnsigned, c.Scan.ScoreItemValueType))
+	}
+	if c.Scan.PackageItemValue != PackageValueCount && c.Scan.PackageItemValue != PackageValueCVSS {
+		errs = append(errs, fmt.Errorf("scan.package_item_value must be %q or %q, got %q", PackageValueCount, PackageValueCVSS, c.Scan.PackageItemValue))
+	}
+	for match, osName := range c.Scan.OSNameMap {
+		if strings.TrimSpace(match) == "" || strings.TrimSpace(osName) == "" {
+			errs = append(errs, fmt.Errorf("scan.os_name_map entries must have a non-empty name and Vulners OS, got %q: %q", match, osName))
+		}
+	}
+	for _, kind := range c.Scan.EnabledLLD {
+		if !slices.Contains(LLDTypes, kind) {
+			errs = append(errs, fmt.Errorf("scan.enabled_lld: unknown entry %q (valid: %s)", kind, strings.Join(LLDTypes, ", ")))
+		}
+	}
+	if !isTimePeriod(c.Scan.LLDLifetime) {
+		errs = append(errs, fmt.Errorf("scan.lld_lifetime must be a Zabbix time period such as 0, 3600, 12h or 7d, got %q", c.Scan.LLDLifetime))
+	}
+	if c.Scan.MaxAffectedHosts < 0 {
+		errs = append(errs, fmt.Errorf("scan.max_affected_hosts must be >= 0, got %d", c.Scan.MaxAffectedHosts))
+	}
+	if c.Scan.MaxLLDEntries < 0 {
+		errs = append(errs, fmt.Errorf("scan.max_lld_entries must be >= 0, got %d", c.Scan.MaxLLDEntries))
+	}
+	for _, it := range []struct{ key, tag string }{
+		{"scan.os_item_tag", c.Scan.OSItemTag},
+		{"scan.packages_item_tag", c.Scan.PackagesItemTag},
+	} {
+		if name, _, ok := strings.Cut(it.tag, ":"); it.tag != "" && (!ok || name == "") {
+			errs = append(errs, fmt.Errorf("%s must be an item tag as name:value, got %q", it.key, it.tag))
+		}
+	}
+	switch c.Telemetry.OTLPProtocol {
+	case "", OTLPProtocolHTTP, OTLPProtocolGRPC:
+	default:
+		errs = append(errs, fmt.Errorf("telemetry.otlp_protocol must be %q or %q, got %q", OTLPProtocolHTTP, OTLPProtocolGRPC, c.Telemetry.OTLPProtocol))
+	}
+	if c.Telemetry.SampleRatio < 0 || c.Telemetry.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("telemetry.sample_ratio must be between 0.0 and 1.0, got %g", c.Telemetry.SampleRatio))
+	}
+	if c.Zabbix.APITimeout <= 0 {
+		errs = append(errs, fmt.Errorf("zabbix.api_timeout must be greater than 0, got %d", c.Zabbix.APITimeout))
+	}
+	if c.Zabbix.ContentType == "" {
+		errs = append(errs, fmt.Errorf("zabbix.content_type must not be empty"))
+	}
+	if c.Zabbix.MaxConcurrentRequests <= 0 {
+		errs = append(errs, fmt.Errorf("zabbix.max_concurrent_requests must be greater than 0, got %d", c.Zabbix.MaxConcurrentRequests))
+	}
 	if c.Vulners.RateLimit < 0 {
 		errs = append(errs, fmt.Errorf("vulners.rate_limit must be >= 0, got %d", c.Vulners.RateLimit))
 	}
+	if c.Vulners.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("vulners.request_timeout must be >= 0, got %d", c.Vulners.RequestTimeout))
+	}
+	if c.Fix.MaxDataAge < 0 {
+		errs = append(errs, fmt.Errorf("fix.max_data_age must be >= 0, got %d", c.Fix.MaxDataAge))
+	}
+	if len(c.Fix.AddressPreference) == 0 {
+		errs = append(errs, fmt.Errorf("fix.address_preference must not be empty"))
+	}
+	for _, p := range c.Fix.AddressPreference {
+		if !slices.Contains(AddressPreferences, p) {
+			errs = append(errs, fmt.Errorf("fix.address_preference: unknown entry %q (valid: %s)", p, strings.Join(AddressPreferences, ", ")))
+		}
+	}
 
 	return errors.Join(errs...)
 }
 
+// isTimePeriod reports whether s is a Zabbix time period: a number of
+// seconds with an optional s, m, h, d or w suffix.
+func isTimePeriod(s string) bool {
+	digits := strings.TrimRight(s, "smhdw")
+	if len(s)-len(digits) > 1 || digits == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(digits, 10, 32)
+	return err == nil
+}
+
 // ValidateVulnersKey checks that the Vulners API key is set.
 // Call this in commands that need the Vulners API (scan, fix).
 func (c *Config) ValidateVulnersKey() error {
-	if c.Vulners.APIKey == "" {
+	if c.Vulners.APIKey == "" && c.Vulners.ReplayDir == "" {
 		return fmt.Errorf("vulners.api_key is required (set in config file or ZTC_VULNERS_API_KEY env var)")
 	}
 	return nil
 }
 
+// UserAgent returns the User-Agent to send on outgoing HTTP requests.
+func (c *Config) UserAgent() string {
+	if c.HTTP.UserAgent != "" {
+		return c.HTTP.UserAgent
+	}
+	return version.UserAgent()
+}
+
+// secretMask replaces secret values in Redacted output.
+const secretMask = "********"
+
+// Redacted returns a copy of c with secrets masked, safe to print or log.
+// Empty secrets stay empty so a missing value is still visible.
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.Fix.AddressPreference = slices.Clone(c.Fix.AddressPreference)
+	r.Scan.OSNameMap = maps.Clone(c.Scan.OSNameMap)
+	r.Scan.EnabledLLD = slices.Clone(c.Scan.EnabledLLD)
+	for _, s := range []*string{&r.Zabbix.APIPassword, &r.Zabbix.APIToken, &r.Vulners.APIKey} {
+		if *s != "" {
+			*s = secretMask
+		}
+	}
+	return &r
+}
+
+// LogValue implements slog.LogValuer, logging the redacted config.
+func (c *Config) LogValue() slog.Value {
+	return slog.AnyValue(c.Redacted().ToMap())
+}
+
+// ToMap returns c as nested maps keyed by koanf tags, mirroring the YAML
+// file layout.
+func (c *Config) ToMap() map[string]interface{} {
+	var walk func(v reflect.Value) map[string]interface{}
+	walk = func(v reflect.Value) map[string]interface{} {
+		m := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			tag := v.Type().Field(i).Tag.Get("koanf")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			if f := v.Field(i); f.Kind() == reflect.Struct {
+				m[tag] = walk(f)
+			} else {
+				m[tag] = f.Interface()
+			}
+		}
+		return m
+	}
+	return walk(reflect.ValueOf(*c))
+}
+
 // ZabbixAPIURL returns the full Zabbix API URL
 func (c *Config) ZabbixAPIURL() string {
 	return strings.TrimRight(c.Zabbix.FrontURL, "/") + "/api_jsonrpc.php"
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -76,6 +78,43 @@ func TestValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("negative max_lld_entries", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.MaxLLDEntries = -1
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "scan.max_lld_entries") {
+			t.Errorf("expected scan.max_lld_entries error, got: %v", err)
+		}
+	})
+
+	t.Run("item tag without value", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.OSItemTag = "vulners:os"
+		cfg.Scan.PackagesItemTag = "vulners"
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "scan.packages_item_tag") || strings.Contains(err.Error(), "scan.os_item_tag") {
+			t.Errorf("expected only a scan.packages_item_tag error, got: %v", err)
+		}
+	})
+
+	t.Run("negative request_timeout", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Vulners.RequestTimeout = -1
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "vulners.request_timeout") {
+			t.Errorf("expected vulners.request_timeout error, got: %v", err)
+		}
+	})
+
+	t.Run("api_key not needed when replaying", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Vulners.APIKey = ""
+		cfg.Vulners.ReplayDir = "testdata/replay"
+		if err := cfg.ValidateVulnersKey(); err != nil {
+			t.Errorf("ValidateVulnersKey() with ReplayDir: %v", err)
+		}
+	})
+
 	t.Run("missing api_user", func(t *testing.T) {
 		cfg := validConfig()
 		cfg.Zabbix.APIUser = ""
@@ -94,6 +133,16 @@ func TestValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("api_token replaces user and password", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Zabbix.APIUser = ""
+		cfg.Zabbix.APIPassword = ""
+		cfg.Zabbix.APIToken = "token"
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with api_token: %v", err)
+		}
+	})
+
 	t.Run("invalid server_port", func(t *testing.T) {
 		cfg := validConfig()
 		cfg.Zabbix.ServerPort = 0
@@ -139,6 +188,157 @@ func TestValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid crit_cvss", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.CritCVSS = 10.5
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "crit_cvss") {
+			t.Errorf("expected crit_cvss error, got: %v", err)
+		}
+	})
+
+	t.Run("empty content_type", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Zabbix.ContentType = ""
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "content_type") {
+			t.Errorf("expected content_type error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid address_preference", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Fix.AddressPreference = []string{"agent", "agent-fqdn"}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "agent-fqdn") {
+			t.Errorf("expected address_preference error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid score_item_value_type", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.ScoreItemValueType = "int"
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "score_item_value_type") {
+			t.Errorf("expected score_item_value_type error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid package_item_value", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.PackageItemValue = "score"
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "package_item_value") {
+			t.Errorf("expected package_item_value error, got: %v", err)
+		}
+	})
+
+	t.Run("unknown enabled_lld entry", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.EnabledLLD = []string{"hosts", "groups"}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "enabled_lld") {
+			t.Errorf("expected enabled_lld error, got: %v", err)
+		}
+	})
+
+	t.Run("push_min_cvss out of range", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.PushMinCVSS = 11
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "push_min_cvss") {
+			t.Errorf("expected push_min_cvss error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid lld_lifetime", func(t *testing.T) {
+		for _, lifetime := range []string{"", "7days", "-1d", "1dd"} {
+			cfg := validConfig()
+			cfg.Scan.LLDLifetime = lifetime
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), "lld_lifetime") {
+				t.Errorf("lld_lifetime %q: expected lld_lifetime error, got: %v", lifetime, err)
+			}
+		}
+		cfg := validConfig()
+		cfg.Scan.LLDLifetime = "7d"
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("lld_lifetime 7d: %v", err)
+		}
+	})
+
+	t.Run("negative max_data_age", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Fix.MaxDataAge = -1
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "max_data_age") {
+			t.Errorf("expected max_data_age error, got: %v", err)
+		}
+	})
+
+	t.Run("unknown otlp_protocol", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Telemetry.OTLPProtocol = "thrift"
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "otlp_protocol") {
+			t.Errorf("expected otlp_protocol error, got: %v", err)
+		}
+	})
+
+	t.Run("sample_ratio out of range", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Telemetry.SampleRatio = 1.5
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "sample_ratio") {
+			t.Errorf("expected sample_ratio error, got: %v", err)
+		}
+	})
+
+	t.Run("empty os_name_map value", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.OSNameMap = map[string]string{"rocky": ""}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "os_name_map") {
+			t.Errorf("expected os_name_map error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid maintenance", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.Maintenance = "skip"
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "scan.maintenance") {
+			t.Errorf("expected scan.maintenance error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid discovery_mode", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Scan.DiscoveryMode = "tags"
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "discovery_mode") {
+			t.Errorf("expected discovery_mode error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid max_concurrent_requests", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Zabbix.MaxConcurrentRequests = 0
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "max_concurrent_requests") {
+			t.Errorf("expected max_concurrent_requests error, got: %v", err)
+		}
+	})
+
+	t.Run("invalid api_timeout", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Zabbix.APITimeout = 0
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "api_timeout") {
+			t.Errorf("expected api_timeout error, got: %v", err)
+		}
+	})
+
 	t.Run("invalid workers", func(t *testing.T) {
 		cfg := validConfig()
 		cfg.Scan.Workers = 0
@@ -290,6 +490,8 @@ scan:
   min_cvss: 5.0
   workers: 8
   timeout: 60
+fix:
+  address_preference: [agent-dns, any]
 `
 	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
 		t.Fatal(err)
@@ -318,6 +520,164 @@ scan:
 	if cfg.Scan.Timeout != 60 {
 		t.Errorf("Timeout = %d, want 60", cfg.Scan.Timeout)
 	}
+	if got := strings.Join(cfg.Fix.AddressPreference, ","); got != "agent-dns,any" {
+		t.Errorf("AddressPreference = %q, want agent-dns,any", got)
+	}
+}
+
+func TestLoadOSNameMap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.yaml")
+
+	content := `
+zabbix:
+  api_user: admin
+  api_password: secret
+vulners:
+  api_key: "test-api-key"
+scan:
+  os_name_map:
+    rocky: centos
+    astra linux: astralinux
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadWithOptions(path, LoadOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	want := map[string]string{"rocky": "centos", "astra linux": "astralinux"}
+	if !reflect.DeepEqual(cfg.Scan.OSNameMap, want) {
+		t.Errorf("OSNameMap = %v, want %v", cfg.Scan.OSNameMap, want)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.json")
+
+	content := `{
+  "zabbix": {
+    "front_url": "http://zabbix.example.com",
+    "api_user": "admin",
+    "api_password": "secret"
+  },
+  "vulners": {"api_key": "test-api-key"},
+  "scan": {"min_cvss": 5.5, "workers": 8},
+  "fix": {"address_preference": ["main-ip", "any"]}
+}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZTC_SCAN_TIMEOUT", "90")
+
+	cfg, err := LoadWithOptions(path, LoadOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Zabbix.FrontURL != "http://zabbix.example.com" {
+		t.Errorf("FrontURL = %q, want http://zabbix.example.com", cfg.Zabbix.FrontURL)
+	}
+	if cfg.Vulners.APIKey != "test-api-key" {
+		t.Errorf("APIKey = %q, want test-api-key", cfg.Vulners.APIKey)
+	}
+	if cfg.Scan.MinCVSS != 5.5 || cfg.Scan.Workers != 8 {
+		t.Errorf("MinCVSS/Workers = %g/%d, want 5.5/8", cfg.Scan.MinCVSS, cfg.Scan.Workers)
+	}
+	if cfg.Scan.Timeout != 90 {
+		t.Errorf("Timeout = %d, want 90 from env override", cfg.Scan.Timeout)
+	}
+	if cfg.Zabbix.ServerPort != 10051 {
+		t.Errorf("ServerPort = %d, want default 10051", cfg.Zabbix.ServerPort)
+	}
+	if got := strings.Join(cfg.Fix.AddressPreference, ","); got != "main-ip,any" {
+		t.Errorf("AddressPreference = %q, want main-ip,any", got)
+	}
+
+	t.Run("unknown key is rejected in strict mode", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.json")
+		if err := os.WriteFile(bad, []byte(`{"zabbix": {"api_user": "a", "api_password": "b", "port": 1}}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadWithOptions(bad, LoadOptions{Strict: true}); err == nil {
+			t.Error("expected error for unknown key zabbix.port")
+		}
+	})
+
+	t.Run("invalid values fail validation", func(t *testing.T) {
+		bad := filepath.Join(dir, "invalid.json")
+		if err := os.WriteFile(bad, []byte(`{"zabbix": {"api_user": "a", "api_password": "b"}, "scan": {"workers": 0}}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(bad); err == nil || !strings.Contains(err.Error(), "scan.workers") {
+			t.Errorf("err = %v, want scan.workers validation error", err)
+		}
+	})
+}
+
+func TestLoadTOML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.toml")
+
+	content := `
+[zabbix]
+front_url = "http://zabbix.example.com"
+api_user = "admin"
+api_password = "secret"
+server_port = 10052
+
+[vulners]
+api_key = "test-api-key"
+
+[scan]
+min_cvss = 5.5
+workers = 8
+push_raw_json = true
+
+[fix]
+address_preference = ["agent-ip", "any"]
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZTC_SCAN_TIMEOUT", "90")
+
+	cfg, err := LoadWithOptions(path, LoadOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if cfg.Zabbix.FrontURL != "http://zabbix.example.com" || cfg.Zabbix.ServerPort != 10052 {
+		t.Errorf("FrontURL/ServerPort = %q/%d", cfg.Zabbix.FrontURL, cfg.Zabbix.ServerPort)
+	}
+	if cfg.Vulners.APIKey != "test-api-key" {
+		t.Errorf("APIKey = %q, want test-api-key", cfg.Vulners.APIKey)
+	}
+	if cfg.Scan.MinCVSS != 5.5 || cfg.Scan.Workers != 8 || !cfg.Scan.PushRawJSON {
+		t.Errorf("MinCVSS/Workers/PushRawJSON = %g/%d/%v", cfg.Scan.MinCVSS, cfg.Scan.Workers, cfg.Scan.PushRawJSON)
+	}
+	if cfg.Scan.Timeout != 90 {
+		t.Errorf("Timeout = %d, want 90 from env override", cfg.Scan.Timeout)
+	}
+	if cfg.Scan.LLDDelay != 300 {
+		t.Errorf("LLDDelay = %d, want default 300", cfg.Scan.LLDDelay)
+	}
+	if got := strings.Join(cfg.Fix.AddressPreference, ","); got != "agent-ip,any" {
+		t.Errorf("AddressPreference = %q, want agent-ip,any", got)
+	}
+
+	t.Run("syntax error", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.toml")
+		if err := os.WriteFile(bad, []byte("[zabbix\napi_user = admin\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(bad); err == nil || !strings.Contains(err.Error(), "TOML") {
+			t.Errorf("err = %v, want TOML parse error", err)
+		}
+	})
 }
 
 func TestLoadINI(t *testing.T) {
@@ -550,3 +910,339 @@ HostGroupName = MyGroup
 		t.Errorf("GroupName = %q", cfg.Naming.GroupName)
 	}
 }
+
+func TestLoadYAML_UnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.yaml")
+
+	content := `
+zabbix:
+  front_url: "http://zabbix.example.com"
+  api_user: admin
+  api_password: secret
+  api_pasword: typo
+scan:
+  wokers: 8
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("lenient by default", func(t *testing.T) {
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if cfg.Zabbix.APIPassword != "secret" {
+			t.Errorf("APIPassword = %q, want secret", cfg.Zabbix.APIPassword)
+		}
+	})
+
+	t.Run("strict rejects unknown keys", func(t *testing.T) {
+		_, err := LoadWithOptions(path, LoadOptions{Strict: true})
+		if err == nil {
+			t.Fatal("expected error in strict mode")
+		}
+		for _, key := range []string{"zabbix.api_pasword", "scan.wokers"} {
+			if !strings.Contains(err.Error(), key) {
+				t.Errorf("expected %q in error, got: %v", key, err)
+			}
+		}
+	})
+}
+
+func TestLoadINI_StrictUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.conf")
+
+	content := `[MANDATORY]
+VulnersApiKey = test-key
+ZabbixApiUser = admin
+ZabbixApiPassword = secret
+
+[OPTIONAL]
+UnknownKey = some_value
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	_, err := LoadWithOptions(path, LoadOptions{Strict: true})
+	if err == nil || !strings.Contains(err.Error(), "UnknownKey") {
+		t.Errorf("expected UnknownKey error in strict mode, got: %v", err)
+	}
+}
+
+func TestUnknownYAMLKeys_AllKnown(t *testing.T) {
+	keys := []string{"zabbix.front_url", "vulners.api_key", "scan.lld_delay", "naming.group_name", "telemetry.enabled"}
+	if w := unknownYAMLKeys(keys); len(w) != 0 {
+		t.Errorf("expected no warnings, got %v", w)
+	}
+}
+
+func TestLoadFiles_Merge(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "ztc.yaml")
+	secrets := filepath.Join(dir, "secrets.yaml")
+
+	if err := os.WriteFile(base, []byte(`
+zabbix:
+  front_url: "http://zabbix.example.com"
+  api_user: admin
+  api_password: placeholder
+scan:
+  workers: 8
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secrets, []byte(`
+zabbix:
+  api_password: from-secrets
+vulners:
+  api_key: secret-key
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFiles([]string{base, secrets}, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadFiles() error: %v", err)
+	}
+	if cfg.Zabbix.APIPassword != "from-secrets" {
+		t.Errorf("APIPassword = %q, want from-secrets (later file wins)", cfg.Zabbix.APIPassword)
+	}
+	if cfg.Zabbix.APIUser != "admin" {
+		t.Errorf("APIUser = %q, want admin (kept from earlier file)", cfg.Zabbix.APIUser)
+	}
+	if cfg.Vulners.APIKey != "secret-key" {
+		t.Errorf("APIKey = %q, want secret-key", cfg.Vulners.APIKey)
+	}
+	if cfg.Scan.Workers != 8 {
+		t.Errorf("Workers = %d, want 8", cfg.Scan.Workers)
+	}
+
+	t.Setenv("ZTC_ZABBIX_API_PASSWORD", "from-env")
+	cfg, err = LoadFiles([]string{base, secrets}, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadFiles() error: %v", err)
+	}
+	if cfg.Zabbix.APIPassword != "from-env" {
+		t.Errorf("APIPassword = %q, want from-env (env overrides files)", cfg.Zabbix.APIPassword)
+	}
+}
+
+func TestLoadYAML_Include(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "private"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(dir, "ztc.yaml")
+	secrets := filepath.Join(dir, "private", "secrets.yaml")
+
+	if err := os.WriteFile(base, []byte(`
+include:
+  - private/secrets.yaml
+zabbix:
+  front_url: "http://zabbix.example.com"
+  api_user: admin
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secrets, []byte(`
+zabbix:
+  api_password: secret
+vulners:
+  api_key: secret-key
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadWithOptions(base, LoadOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Zabbix.APIPassword != "secret" {
+		t.Errorf("APIPassword = %q, want secret", cfg.Zabbix.APIPassword)
+	}
+	if cfg.Vulners.APIKey != "secret-key" {
+		t.Errorf("APIKey = %q, want secret-key", cfg.Vulners.APIKey)
+	}
+}
+
+func TestLoadYAML_IncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	if err := os.WriteFile(a, []byte("include: b.yaml\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("include: a.yaml\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(a)
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected include cycle error, got: %v", err)
+	}
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	pwFile := filepath.Join(dir, "zabbix_password")
+	keyFile := filepath.Join(dir, "vulners_key")
+	if err := os.WriteFile(pwFile, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte("file-key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		path := filepath.Join(dir, "test.yaml")
+		content := `
+zabbix:
+  api_user: admin
+  api_password_file: ` + pwFile + `
+vulners:
+  api_key: inline-key
+  api_key_file: ` + keyFile + `
+`
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if cfg.Zabbix.APIPassword != "file-secret" {
+			t.Errorf("APIPassword = %q, want file-secret", cfg.Zabbix.APIPassword)
+		}
+		if cfg.Vulners.APIKey != "file-key" {
+			t.Errorf("APIKey = %q, want file-key (file overrides inline)", cfg.Vulners.APIKey)
+		}
+	})
+
+	t.Run("ini", func(t *testing.T) {
+		path := filepath.Join(dir, "test.conf")
+		content := `[MANDATORY]
+VulnersApiKeyFile = ` + keyFile + `
+ZabbixApiUser = admin
+ZabbixApiPasswordFile = ` + pwFile + `
+`
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadWithOptions(path, LoadOptions{Strict: true})
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if cfg.Zabbix.APIPassword != "file-secret" {
+			t.Errorf("APIPassword = %q, want file-secret", cfg.Zabbix.APIPassword)
+		}
+		if cfg.Vulners.APIKey != "file-key" {
+			t.Errorf("APIKey = %q, want file-key", cfg.Vulners.APIKey)
+		}
+	})
+
+	t.Run("env var wins", func(t *testing.T) {
+		t.Setenv("ZTC_VULNERS_API_KEY", "env-key")
+		path := filepath.Join(dir, "env.yaml")
+		content := `
+zabbix:
+  api_user: admin
+  api_password_file: ` + pwFile + `
+vulners:
+  api_key_file: ` + keyFile + `
+`
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if cfg.Vulners.APIKey != "env-key" {
+			t.Errorf("APIKey = %q, want env-key (env overrides the file)", cfg.Vulners.APIKey)
+		}
+		if cfg.Zabbix.APIPassword != "file-secret" {
+			t.Errorf("APIPassword = %q, want file-secret", cfg.Zabbix.APIPassword)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.yaml")
+		content := `
+zabbix:
+  api_user: admin
+  api_password_file: ` + filepath.Join(dir, "does-not-exist") + `
+`
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := Load(path)
+		if err == nil || !strings.Contains(err.Error(), "zabbix.api_password_file") {
+			t.Errorf("expected api_password_file error, got: %v", err)
+		}
+	})
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Zabbix.APIPassword = "zabbix-password"
+	cfg.Vulners.APIKey = "vulners-key"
+
+	r := cfg.Redacted()
+	if r.Zabbix.APIPassword != secretMask || r.Vulners.APIKey != secretMask {
+		t.Errorf("secrets not masked: password=%q key=%q", r.Zabbix.APIPassword, r.Vulners.APIKey)
+	}
+	if cfg.Zabbix.APIPassword == secretMask || cfg.Vulners.APIKey != "vulners-key" {
+		t.Error("Redacted modified the original config")
+	}
+	r.Fix.AddressPreference[0] = "any"
+	if cfg.Fix.AddressPreference[0] != "agent" {
+		t.Error("Redacted shares AddressPreference with the original config")
+	}
+
+	cfg.Vulners.APIKey = ""
+	if got := cfg.Redacted().Vulners.APIKey; got != "" {
+		t.Errorf("empty APIKey redacted to %q, want empty", got)
+	}
+
+	logged := fmt.Sprint(cfg.LogValue().Any())
+	if strings.Contains(logged, cfg.Zabbix.APIPassword) {
+		t.Errorf("LogValue leaks the password: %s", logged)
+	}
+}
+
+func TestToMap(t *testing.T) {
+	m := DefaultConfig().ToMap()
+	zbx, ok := m["zabbix"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("zabbix section = %T, want map", m["zabbix"])
+	}
+	if zbx["server_port"] != 10051 {
+		t.Errorf("zabbix.server_port = %v, want 10051", zbx["server_port"])
+	}
+
+	// Every leaf must be a key the loader accepts.
+	known, _ := knownKeys()
+	var check func(prefix string, m map[string]interface{})
+	check = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			if sub, ok := v.(map[string]interface{}); ok {
+				check(prefix+k+".", sub)
+			} else if !known[prefix+k] {
+				t.Errorf("ToMap key %q is not a known config key", prefix+k)
+			}
+		}
+	}
+	check("", m)
+}
@@ -3,27 +3,35 @@ package fixer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
 	"log/slog"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/remediation"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
 )
 
 // Executor executes fix commands on remote hosts
 type Executor struct {
 	cfg *config.Config
 	log *slog.Logger
+
+	// getErr is set when zabbix_get is missing; SSH fixes do not need it.
+	getErr error
 }
 
 // NewExecutor creates a new executor
 func NewExecutor(cfg *config.Config, log *slog.Logger) *Executor {
 	return &Executor{
-		cfg: cfg,
-		log: log,
+		cfg:    cfg,
+		log:    log,
+		getErr: zabbix.CheckBinary("zabbix_get", cfg.Zabbix.GetPath, "zabbix.get_path"),
 	}
 }
 
@@ -47,15 +55,118 @@ func (e *Executor) ExecuteViaAgent(ctx context.Context, hostIP, port, command st
 	// Use zabbix_get to execute the command
 	// Note: This requires the host to have system.run enabled in agent config.
 	// Use nowait mode so long-running updates don't block/timeout the agent.
-	key := fmt.Sprintf("system.run[%s,nowait]", command)
+	return e.zabbixGet(ctx, hostIP, port, fmt.Sprintf("system.run[%s,nowait]", command), 0)
+}
+
+// exitMarker prefixes the exit status line appended to waited agent commands.
+const exitMarker = "ztc-exit="
+
+// noRetryError marks a failure that ExecuteWithRetry must not retry: the
+// command ran, or may still be running, on the host.
+type noRetryError struct{ err error }
+
+func (e *noRetryError) Error() string { return e.err.Error() }
+func (e *noRetryError) Unwrap() error { return e.err }
+
+// ExecuteViaAgentWait executes a command via Zabbix agent and waits for it,
+// returning its combined output. A non-zero exit status is an error.
+// Only failures to reach the agent are retried by ExecuteWithRetry; an exit
+// status or a timeout means the command ran, and rerunning an upgrade that
+// failed or is still running would make things worse.
+//
+// The agent kills commands that outlive its Timeout setting (at most 30s
+// before Zabbix 7.0), so long package upgrades may not finish in wait mode;
+// timeout is passed to zabbix_get and should not exceed the agent's.
+func (e *Executor) ExecuteViaAgentWait(ctx context.Context, hostIP, port, command string, timeout time.Duration) (string, error) {
+	if err := ValidateHostTarget(hostIP); err != nil {
+		return "", fmt.Errorf("invalid host: %w", err)
+	}
+
+	if port == "" {
+		port = "10050"
+	}
 
-	cmd := exec.CommandContext(ctx, //nolint:gosec // G204: hostIP and command are validated by sanitize.go before reaching here
-		e.cfg.Zabbix.GetPath,
-		"-s", hostIP,
-		"-p", port,
-		"-k", key,
+	e.log.Debug("Executing command via Zabbix agent (wait)",
+		slog.String("host", hostIP),
+		slog.String("port", port),
+		slog.String("command", command),
+		slog.Duration("timeout", timeout),
 	)
 
+	key := fmt.Sprintf("system.run[(%s) 2>&1; echo %s$?,wait]", command, exitMarker)
+	out, err := e.zabbixGet(ctx, hostIP, port, key, timeout)
+	if err != nil {
+		// zabbix_get timed out after connecting: the command may still run.
+		if strings.Contains(err.Error(), "Timeout while") && !strings.Contains(err.Error(), "Timeout while connecting") {
+			return "", &noRetryError{err}
+		}
+		return "", err
+	}
+	out, err = parseExitMarker(out)
+	if err != nil {
+		return out, &noRetryError{err}
+	}
+	return out, nil
+}
+
+// parseExitMarker strips the exit status line from a waited command's
+// output and turns a non-zero or missing status into an error.
+func parseExitMarker(out string) (string, error) {
+	out = strings.TrimRight(out, "\n")
+	body, status := "", out
+	if i := strings.LastIndex(out, "\n"); i >= 0 {
+		body, status = out[:i], out[i+1:]
+	}
+	code, ok := strings.CutPrefix(status, exitMarker)
+	if !ok {
+		if strings.HasPrefix(out, "ZBX_NOTSUPPORTED") {
+			return out, fmt.Errorf("agent rejected the command: %s", out)
+		}
+		return out, fmt.Errorf("no exit status in agent output (command timed out or was killed)")
+	}
+	if code != "0" {
+		return body, fmt.Errorf("command exited with status %s", code)
+	}
+	return body, nil
+}
+
+// remoteCommandProbe is echoed by the agent to prove system.run works.
+const remoteCommandProbe = "ztc-probe"
+
+// ProbeRemoteCommands checks that the agent at hostIP:port runs system.run
+// commands. In nowait mode a disabled system.run is indistinguishable from
+// success, so the probe waits for the echoed output.
+func (e *Executor) ProbeRemoteCommands(ctx context.Context, hostIP, port string) error {
+	if err := ValidateHostTarget(hostIP); err != nil {
+		return fmt.Errorf("invalid host: %w", err)
+	}
+	if port == "" {
+		port = "10050"
+	}
+
+	out, err := e.zabbixGet(ctx, hostIP, port, "system.run[echo "+remoteCommandProbe+"]", 0)
+	if err != nil {
+		return err
+	}
+	if got := strings.TrimSpace(out); got != remoteCommandProbe {
+		return fmt.Errorf("remote commands are disabled on the agent at %s:%s (probe returned %q); "+
+			"set AllowKey=system.run[*] (EnableRemoteCommands=1 before Zabbix 5.0) or use --ssh", hostIP, port, got)
+	}
+	return nil
+}
+
+// zabbixGet queries one item key from the agent at hostIP:port. A positive
+// timeout is passed to zabbix_get with -t.
+func (e *Executor) zabbixGet(ctx context.Context, hostIP, port, key string, timeout time.Duration) (string, error) {
+	if e.getErr != nil {
+		return "", e.getErr
+	}
+	args := []string{"-s", hostIP, "-p", port, "-k", key}
+	if timeout > 0 {
+		args = append(args, "-t", strconv.Itoa(int(timeout.Seconds())))
+	}
+	cmd := exec.CommandContext(ctx, e.cfg.Zabbix.GetPath, args...) //nolint:gosec // G204: hostIP and command are validated by sanitize.go before reaching here
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -103,77 +214,38 @@ func (e *Executor) ExecuteViaSSH(ctx context.Context, hostIP, user, command stri
 
 // GenerateFixCommand generates the package fix command for a host
 func (e *Executor) GenerateFixCommand(osName string, packages []string) string {
-	if err := SanitizePackages(packages); err != nil {
+	command, err := remediation.FixCommand(osName, packages)
+	if err != nil {
 		e.log.Warn("Invalid package name detected, falling back to full system update", slog.Any("error", err))
-		packages = nil
-	}
-
-	osName = strings.ToLower(osName)
-
-	switch {
-	case strings.Contains(osName, "ubuntu") || strings.Contains(osName, "debian"):
-		return generateDebianFixCommand(packages)
-	case strings.Contains(osName, "centos") || strings.Contains(osName, "red hat") || strings.Contains(osName, "redhat") || strings.Contains(osName, "rhel"):
-		return generateRHELFixCommand(packages)
-	case strings.Contains(osName, "amazon"):
-		return generateAmazonFixCommand(packages)
-	default:
-		// Default to apt for unknown distros
-		return generateDebianFixCommand(packages)
 	}
+	return command
 }
 
-func generateDebianFixCommand(packages []string) string {
-	if len(packages) == 0 {
-		return "apt-get update && apt-get upgrade -y"
-	}
-	pkgList := quotePackages(packages)
-	return fmt.Sprintf("apt-get update && apt-get install -y --only-upgrade %s", pkgList)
-}
-
-func generateRHELFixCommand(packages []string) string {
-	if len(packages) == 0 {
-		return "yum update -y"
-	}
-	pkgList := quotePackages(packages)
-	return fmt.Sprintf("yum update -y %s", pkgList)
-}
-
-func generateAmazonFixCommand(packages []string) string {
-	if len(packages) == 0 {
-		return "yum update -y"
-	}
-	pkgList := quotePackages(packages)
-	return fmt.Sprintf("yum update -y %s", pkgList)
-}
-
-// quotePackages wraps each package name in single quotes for defense-in-depth.
-func quotePackages(packages []string) string {
-	quoted := make([]string, len(packages))
-	for i, pkg := range packages {
-		quoted[i] = "'" + pkg + "'"
-	}
-	return strings.Join(quoted, " ")
-}
-
-// ExecuteWithRetry executes a command with retry logic
+// ExecuteWithRetry executes a command with retry logic. On failure it
+// returns the output of the last attempt with its error. Failures marked
+// by noRetryError are returned at once.
 func (e *Executor) ExecuteWithRetry(ctx context.Context, fn func() (string, error), maxRetries int) (string, error) {
+	var lastOutput string
 	var lastErr error
 	for i := 0; i <= maxRetries; i++ {
 		output, err := fn()
 		if err == nil {
 			return output, nil
 		}
-		lastErr = err
+		lastOutput, lastErr = output, err
+		var noRetry *noRetryError
+		if errors.As(err, &noRetry) {
+			break
+		}
 
 		if i < maxRetries {
 			e.log.Warn("Command failed, retrying...", slog.Any("error", err), slog.Int("attempt", i+1))
 			select {
 			case <-ctx.Done():
-				return "", ctx.Err()
+				return lastOutput, ctx.Err()
 			case <-time.After(time.Duration(i+1) * time.Second):
 			}
 		}
 	}
-	return "", lastErr
+	return lastOutput, lastErr
 }
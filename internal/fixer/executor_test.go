@@ -1,8 +1,13 @@
 package fixer
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"io"
 	"log/slog"
@@ -14,86 +19,156 @@ func newTestExecutor() *Executor {
 	return NewExecutor(config.DefaultConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
 }
 
-func TestGenerateFixCommand(t *testing.T) {
-	e := newTestExecutor()
-
+func TestProbeRemoteCommands(t *testing.T) {
 	tests := []struct {
-		name     string
-		osName   string
-		packages []string
-		contains string // substring expected in the command
+		name    string
+		reply   string // what the fake zabbix_get prints
+		wantErr string
 	}{
-		{"ubuntu routes to apt", "Ubuntu 20.04", []string{"nginx"}, "apt-get"},
-		{"debian routes to apt", "Debian GNU/Linux", []string{"openssl"}, "apt-get"},
-		{"centos routes to yum", "CentOS Linux 7", []string{"httpd"}, "yum"},
-		{"rhel routes to yum", "RHEL 8", []string{"httpd"}, "yum"},
-		{"amazon routes to yum", "Amazon Linux 2", []string{"httpd"}, "yum"},
-		{"unknown defaults to apt", "Arch Linux", []string{"nginx"}, "apt-get"},
+		{"enabled", "ztc-probe", ""},
+		{"disabled", "ZBX_NOTSUPPORTED: Unknown metric system.run", "remote commands are disabled"},
+		{"empty reply", "", "remote commands are disabled"},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cmd := e.GenerateFixCommand(tt.osName, tt.packages)
-			if !strings.Contains(cmd, tt.contains) {
-				t.Errorf("GenerateFixCommand(%q, %v) = %q, want to contain %q",
-					tt.osName, tt.packages, cmd, tt.contains)
+			script := filepath.Join(t.TempDir(), "zabbix_get")
+			body := "#!/bin/sh\necho '" + tt.reply + "'\n"
+			if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.DefaultConfig()
+			cfg.Zabbix.GetPath = script
+			e := NewExecutor(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			err := e.ProbeRemoteCommands(context.Background(), "10.0.0.1", "")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ProbeRemoteCommands: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func TestGenerateDebianFixCommand(t *testing.T) {
-	t.Run("nil packages = full upgrade", func(t *testing.T) {
-		cmd := generateDebianFixCommand(nil)
-		if cmd != "apt-get update && apt-get upgrade -y" {
-			t.Errorf("got %q, want full upgrade command", cmd)
-		}
-	})
+func TestParseExitMarker(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    string
+		wantErr string
+	}{
+		{"success", "Reading package lists...\nDone\nztc-exit=0\n", "Reading package lists...\nDone", ""},
+		{"success without output", "ztc-exit=0", "", ""},
+		{"failure", "E: Unable to locate package foo\nztc-exit=100\n", "E: Unable to locate package foo", "exited with status 100"},
+		{"killed by agent timeout", "Reading package lists...", "Reading package lists...", "no exit status"},
+		{"unsupported", "ZBX_NOTSUPPORTED: Timeout while executing a shell script.", "ZBX_NOTSUPPORTED: Timeout while executing a shell script.", "agent rejected"},
+	}
 
-	t.Run("with packages = only-upgrade with quoted names", func(t *testing.T) {
-		cmd := generateDebianFixCommand([]string{"nginx", "openssl"})
-		if !strings.Contains(cmd, "--only-upgrade") {
-			t.Errorf("got %q, want --only-upgrade", cmd)
-		}
-		if !strings.Contains(cmd, "'nginx'") {
-			t.Errorf("got %q, want quoted package name 'nginx'", cmd)
-		}
-		if !strings.Contains(cmd, "'openssl'") {
-			t.Errorf("got %q, want quoted package name 'openssl'", cmd)
-		}
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExitMarker(tt.out)
+			if got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
 }
 
-func TestGenerateRHELFixCommand(t *testing.T) {
-	t.Run("nil packages = full update", func(t *testing.T) {
-		cmd := generateRHELFixCommand(nil)
-		if cmd != "yum update -y" {
-			t.Errorf("got %q, want yum update -y", cmd)
-		}
-	})
+func TestExecuteViaAgentWait(t *testing.T) {
+	// The fake zabbix_get records its arguments and runs the system.run
+	// command it was given, like an agent in wait mode would.
+	dir := t.TempDir()
+	script := filepath.Join(dir, "zabbix_get")
+	body := `#!/bin/sh
+echo "$@" > "` + filepath.Join(dir, "args") + `"
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-k" ]; then key="$2"; fi
+  shift
+done
+cmd="${key#system.run[}"
+sh -c "${cmd%,wait]}"
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.GetPath = script
+	e := NewExecutor(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
 
-	t.Run("with packages", func(t *testing.T) {
-		cmd := generateRHELFixCommand([]string{"httpd"})
-		if !strings.Contains(cmd, "yum update -y") {
-			t.Errorf("got %q, want yum update -y", cmd)
-		}
-		if !strings.Contains(cmd, "'httpd'") {
-			t.Errorf("got %q, want quoted 'httpd'", cmd)
-		}
-	})
+	out, err := e.ExecuteViaAgentWait(context.Background(), "10.0.0.1", "", "echo upgraded", 20*time.Second)
+	if err != nil {
+		t.Fatalf("ExecuteViaAgentWait: %v", err)
+	}
+	if out != "upgraded" {
+		t.Errorf("output = %q, want upgraded", out)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if !strings.Contains(string(args), "-t 20") {
+		t.Errorf("zabbix_get args = %q, want -t 20", args)
+	}
+
+	out, err = e.ExecuteViaAgentWait(context.Background(), "10.0.0.1", "", "echo boom; false", 0)
+	if err == nil || !strings.Contains(err.Error(), "status 1") {
+		t.Errorf("err = %v, want exit status 1", err)
+	}
+	if out != "boom" {
+		t.Errorf("output = %q, want boom", out)
+	}
+	var noRetry *noRetryError
+	if !errors.As(err, &noRetry) {
+		t.Errorf("err = %T, want a noRetryError so the upgrade is not rerun", err)
+	}
+}
+
+func TestExecuteWithRetry_NoRetry(t *testing.T) {
+	e := newTestExecutor()
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"transport error", errors.New("zabbix_get failed: connection refused"), 3},
+		{"exit status", &noRetryError{errors.New("command exited with status 100")}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_, err := e.ExecuteWithRetry(context.Background(), func() (string, error) {
+				calls++
+				return "", tt.err
+			}, 2)
+			if err == nil || err.Error() != tt.err.Error() {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
 }
 
-func TestGenerateAmazonFixCommand(t *testing.T) {
-	t.Run("nil packages = full update", func(t *testing.T) {
-		cmd := generateAmazonFixCommand(nil)
-		if cmd != "yum update -y" {
-			t.Errorf("got %q, want yum update -y", cmd)
-		}
-	})
+func TestExecuteViaAgent_MissingZabbixGet(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.GetPath = filepath.Join(t.TempDir(), "no", "zabbix_get")
+	e := NewExecutor(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
 
-	t.Run("with packages", func(t *testing.T) {
-		cmd := generateAmazonFixCommand([]string{"curl"})
-		if !strings.Contains(cmd, "'curl'") {
-			t.Errorf("got %q, want quoted 'curl'", cmd)
-		}
-	})
+	_, err := e.ExecuteViaAgent(context.Background(), "10.0.0.1", "", "true")
+	if err == nil || !strings.Contains(err.Error(), "zabbix_get not found at "+cfg.Zabbix.GetPath) ||
+		!strings.Contains(err.Error(), "zabbix.get_path") {
+		t.Errorf("err = %v, want a zabbix_get not found error naming zabbix.get_path", err)
+	}
 }
@@ -1,11 +1,14 @@
 package fixer
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"log/slog"
 
@@ -13,11 +16,23 @@ import (
 	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
 )
 
+// ZabbixAPI is the part of the Zabbix API client used by the fixer.
+// *zabbix.Client implements it; tests substitute a fake.
+type ZabbixAPI interface {
+	GetHostByIDCtx(ctx context.Context, hostID string) (*zabbix.Host, error)
+	GetHostsByIDsCtx(ctx context.Context, hostIDs []string) ([]zabbix.Host, error)
+	GetHostByNameCtx(ctx context.Context, name string) (*zabbix.Host, error)
+	GetHostItemsCtx(ctx context.Context, hostID string, keyPattern string, tags ...zabbix.ItemTag) ([]zabbix.Item, error)
+	GetItemValueCtx(ctx context.Context, hostTechName, itemKey string) (string, error)
+	GetItemCtx(ctx context.Context, hostTechName, itemKey string) (*zabbix.Item, error)
+	Close() error
+}
+
 // Fixer orchestrates vulnerability remediation
 type Fixer struct {
 	cfg          *config.Config
 	log          *slog.Logger
-	zabbixClient *zabbix.Client
+	zabbixClient ZabbixAPI
 	executor     *Executor
 }
 
@@ -45,50 +60,158 @@ func (f *Fixer) Plan(opts FixOptions) (*FixPlan, error) {
 	// are never valid fix targets. This prevents accidental remediation
 	// against localhost when {HOST.HOST} from a Zabbix action macro is
 	// passed through (it resolves to virtual hosts like "vulners.packages").
-	if opts.HostName != "" && f.isVirtualHost(opts.HostName) {
-		return nil, fmt.Errorf("host %q is a ZTC virtual host, not a real monitored host — refusing to fix", opts.HostName)
+	for _, name := range opts.HostNames {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("host names must not be empty")
+		}
+		if f.isVirtualHost(name) {
+			return nil, fmt.Errorf("host %q is a ZTC virtual host, not a real monitored host — refusing to fix", name)
+		}
 	}
 
-	// Resolve host name to host ID if provided
-	if opts.HostName != "" && opts.HostID == "" {
-		host, err := f.zabbixClient.GetHostByNameCtx(ctx, opts.HostName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve host name %q: %w", opts.HostName, err)
-		}
-		opts.HostID = host.HostID
-		f.log.Info("Resolved host name to ID", slog.String("host_name", opts.HostName), slog.String("host_id", opts.HostID))
+	switch opts.Order {
+	case OrderLLD, OrderScoreDesc, OrderName:
+	default:
+		return nil, fmt.Errorf("order must be %q or %q, got %q", OrderScoreDesc, OrderName, opts.Order)
+	}
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("limit must be >= 0, got %d", opts.Limit)
 	}
 
-	// If a specific host is requested
+	if err := f.checkEnabledLLD(opts); err != nil {
+		return nil, err
+	}
+	if err := f.checkDataAge(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	// Resolve host names to host IDs if provided
+	hostIDs := make([]string, 0, len(opts.HostNames))
 	if opts.HostID != "" {
-		hostPlan, err := f.planForHost(ctx, opts.HostID)
-		if err != nil {
-			return nil, err
+		hostIDs = append(hostIDs, opts.HostID)
+	} else {
+		for _, name := range opts.HostNames {
+			host, err := f.zabbixClient.GetHostByNameCtx(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve host name %q: %w", name, err)
+			}
+			f.log.Info("Resolved host name to ID", slog.String("host_name", name), slog.String("host_id", host.HostID))
+			if !slices.Contains(hostIDs, host.HostID) {
+				hostIDs = append(hostIDs, host.HostID)
+			}
 		}
-		if hostPlan != nil {
-			plan.Hosts = append(plan.Hosts, *hostPlan)
+	}
+
+	// If specific hosts are requested
+	if len(hostIDs) > 0 {
+		for _, hostID := range hostIDs {
+			hostPlan, err := f.planForHost(ctx, hostID, opts.IncludeDisabled, opts.Scope)
+			if err != nil {
+				return nil, err
+			}
+			if hostPlan != nil {
+				plan.Hosts = append(plan.Hosts, *hostPlan)
+			}
 		}
 		return plan, nil
 	}
 
 	// If a bulletin is specified, find all affected hosts
 	if opts.BulletinID != "" {
-		return f.planForBulletin(ctx, opts.BulletinID)
+		if opts.Scope == ScopePackages {
+			return nil, fmt.Errorf("a bulletin fix is driven by bulletin data and cannot be limited to packages")
+		}
+		return f.planForBulletin(ctx, opts)
 	}
 
 	return nil, fmt.Errorf("either --host, --host-name, or --bulletin must be specified")
 }
 
-// planForHost creates a fix plan for a specific host
-func (f *Fixer) planForHost(ctx context.Context, hostID string) (*HostFixPlan, error) {
+// checkEnabledLLD refuses to plan from an LLD that scan.enabled_lld keeps
+// scans from pushing. Without the packages LLD every host would look free
+// of vulnerable packages and get a full system update instead.
+func (f *Fixer) checkEnabledLLD(opts FixOptions) error {
+	if opts.Scope != ScopeBulletins && !f.cfg.Scan.LLDEnabled(config.LLDPackages) {
+		return fmt.Errorf("ztc fix needs the packages LLD, but scan.enabled_lld leaves it out; add %q to it and run 'ztc scan', or pass --bulletins-only", config.LLDPackages)
+	}
+	bulletinPlan := opts.BulletinID != "" && opts.HostID == "" && len(opts.HostNames) == 0
+	if (opts.Scope == ScopeBulletins || bulletinPlan) && !f.cfg.Scan.LLDEnabled(config.LLDBulletins) {
+		return fmt.Errorf("ztc fix needs the bulletins LLD for this plan, but scan.enabled_lld leaves it out; add %q to it and run 'ztc scan'", config.LLDBulletins)
+	}
+	return nil
+}
+
+// checkDataAge refuses to plan from an LLD pushed longer than
+// fix.max_data_age hours ago, since the packages it lists may have been
+// fixed or superseded since. Dry runs and AllowStale only warn.
+func (f *Fixer) checkDataAge(ctx context.Context, opts FixOptions) error {
+	maxAge := time.Duration(f.cfg.Fix.MaxDataAge) * time.Hour
+	if maxAge == 0 {
+		return nil
+	}
+
+	// The plan is driven by the packages LLD unless only bulletin data is
+	// used; a bulletin without a host always reads the bulletins LLD.
+	hostName, key := f.cfg.Naming.PackagesHost, "vulners.packages_lld"
+	if opts.Scope == ScopeBulletins || (opts.BulletinID != "" && opts.HostID == "" && len(opts.HostNames) == 0) {
+		hostName, key = f.cfg.Naming.BulletinsHost, "vulners.bulletins_lld"
+	}
+
+	item, err := f.zabbixClient.GetItemCtx(ctx, hostName, key)
+	if err != nil {
+		return fmt.Errorf("failed to check scan data age: %w", err)
+	}
+	clock := int64(0)
+	if item != nil {
+		clock, _ = strconv.ParseInt(item.LastClock, 10, 64)
+	}
+	if clock <= 0 {
+		f.log.Debug("No scan data timestamp, skipping age check", slog.String("item", key))
+		return nil
+	}
+
+	scanned := time.Unix(clock, 0)
+	age := time.Since(scanned)
+	if age <= maxAge {
+		return nil
+	}
+	if opts.DryRun || opts.AllowStale {
+		f.log.Warn("Scan data is stale, the plan may be outdated",
+			slog.String("item", key),
+			slog.Time("pushed_at", scanned),
+			slog.Duration("age", age.Truncate(time.Minute)),
+		)
+		return nil
+	}
+	return fmt.Errorf("scan data in %s was pushed %s ago, more than fix.max_data_age (%dh); run 'ztc scan' first or pass --allow-stale",
+		key, age.Truncate(time.Minute), f.cfg.Fix.MaxDataAge)
+}
+
+// planForHost creates a fix plan for a specific host. It returns a nil plan
+// for a disabled host unless includeDisabled is set, and for a host without
+// data in a limited scope.
+func (f *Fixer) planForHost(ctx context.Context, hostID string, includeDisabled bool, scope string) (*HostFixPlan, error) {
 	host, err := f.zabbixClient.GetHostByIDCtx(ctx, hostID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get host: %w", err)
 	}
+	if f.skipDisabled(host, includeDisabled) {
+		return nil, nil
+	}
 
 	// Get host's vulnerable packages from previously-pushed scan data.
-	packages := f.getVulnerablePackages(ctx, hostID)
+	var packages []string
+	if scope == ScopeBulletins {
+		packages = f.getBulletinPackages(ctx, hostID)
+	} else {
+		packages = f.getVulnerablePackages(ctx, hostID)
+	}
 
+	if len(packages) == 0 && scope != ScopeAll {
+		f.log.Warn("No vulnerability data found for host in this fix scope, nothing to fix",
+			slog.String("host", host.Name), slog.String("scope", scope))
+		return nil, nil
+	}
 	if len(packages) == 0 {
 		f.log.Warn("No per-package vulnerability data found; fix will perform a full system update",
 			slog.String("host", host.Name))
@@ -118,13 +241,14 @@ func (f *Fixer) planForHost(ctx context.Context, hostID string) (*HostFixPlan, e
 
 // planForBulletin creates a fix plan for a bulletin across affected hosts only.
 // It queries the bulletins LLD data to identify which hosts and packages are
-// affected by the specific bulletin, rather than upgrading everything.
-func (f *Fixer) planForBulletin(ctx context.Context, bulletinID string) (*FixPlan, error) {
-	f.log.Info("Creating fix plan for bulletin", slog.String("bulletin", bulletinID))
+// affected by the specific bulletin, rather than upgrading everything. The
+// hosts are planned in opts.Order, stopping after opts.Limit of them.
+func (f *Fixer) planForBulletin(ctx context.Context, opts FixOptions) (*FixPlan, error) {
+	f.log.Info("Creating fix plan for bulletin", slog.String("bulletin", opts.BulletinID))
 
 	plan := &FixPlan{}
 
-	affectedHostIDs, affectedPkgs, err := f.getBulletinInfo(ctx, bulletinID)
+	affectedHostIDs, affectedPkgs, err := f.getBulletinInfo(ctx, opts.BulletinID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bulletin info: %w", err)
 	}
@@ -135,19 +259,41 @@ func (f *Fixer) planForBulletin(ctx context.Context, bulletinID string) (*FixPla
 		pkgSet[pkg] = true
 	}
 
-	for _, hostID := range affectedHostIDs {
-		host, err := f.zabbixClient.GetHostByIDCtx(ctx, hostID)
-		if err != nil {
-			f.log.Warn("Failed to get host, skipping", slog.Any("error", err), slog.String("host", hostID))
+	hosts, err := f.zabbixClient.GetHostsByIDsCtx(ctx, affectedHostIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected hosts: %w", err)
+	}
+	hostsByID := make(map[string]*zabbix.Host, len(hosts))
+	for i := range hosts {
+		hostsByID[hosts[i].HostID] = &hosts[i]
+	}
+
+	f.orderHosts(ctx, affectedHostIDs, hostsByID, opts.Order)
+
+	for i, hostID := range affectedHostIDs {
+		if opts.Limit > 0 && len(plan.Hosts) == opts.Limit {
+			f.log.Info("Bulletin plan capped by the host limit",
+				slog.Int("limit", opts.Limit), slog.Int("hosts_left_out", len(affectedHostIDs)-i))
+			break
+		}
+		host, ok := hostsByID[hostID]
+		if !ok {
+			f.log.Warn("Host not found in Zabbix, skipping", slog.String("host", hostID))
+			continue
+		}
+		if f.skipDisabled(host, opts.IncludeDisabled) {
 			continue
 		}
 
-		// Get only the bulletin's packages that exist on this host
-		allPackages := f.getVulnerablePackages(ctx, hostID)
-		var packages []string
-		for _, pkg := range allPackages {
-			if pkgSet[pkg] {
-				packages = appendUniqueStr(packages, pkg)
+		// Get only the bulletin's packages that exist on this host, or all of
+		// them when the packages LLD is not consulted.
+		packages := affectedPkgs
+		if opts.Scope != ScopeBulletins {
+			packages = nil
+			for _, pkg := range f.getVulnerablePackages(ctx, hostID) {
+				if pkgSet[pkg] {
+					packages = appendUniqueStr(packages, pkg)
+				}
 			}
 		}
 		if len(packages) == 0 {
@@ -179,6 +325,66 @@ func (f *Fixer) planForBulletin(ctx context.Context, bulletinID string) (*FixPla
 	return plan, nil
 }
 
+// orderHosts sorts hostIDs in place by order. Ties, and hosts without a
+// score in the hosts LLD, fall back to name order.
+func (f *Fixer) orderHosts(ctx context.Context, hostIDs []string, hostsByID map[string]*zabbix.Host, order string) {
+	if order == OrderLLD {
+		return
+	}
+	var scores map[string]float64
+	if order == OrderScoreDesc {
+		scores = f.getHostScores(ctx)
+	}
+	name := func(id string) string {
+		if host, ok := hostsByID[id]; ok {
+			return host.Name
+		}
+		return id
+	}
+	slices.SortStableFunc(hostIDs, func(a, b string) int {
+		if c := cmp.Compare(scores[b], scores[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(name(a), name(b))
+	})
+}
+
+// getHostScores reads each host's CVSS score from the hosts LLD on the
+// virtual hosts host. It returns nil when there is no usable LLD.
+func (f *Fixer) getHostScores(ctx context.Context) map[string]float64 {
+	lldJSON, err := f.zabbixClient.GetItemValueCtx(ctx, f.cfg.Naming.HostsHost, "vulners.hosts_lld")
+	if err != nil || lldJSON == "" {
+		f.log.Warn("No hosts LLD data to order by score, ordering by name", slog.Any("error", err))
+		return nil
+	}
+	lldData, err := zabbix.DecodeLLD(lldJSON)
+	if err != nil {
+		f.log.Warn("Failed to parse hosts LLD data, ordering by name", slog.Any("error", err))
+		return nil
+	}
+
+	scores := make(map[string]float64, len(lldData.Data))
+	for _, entry := range lldData.Data {
+		id, _ := entry["{#H.ID}"].(string)
+		score, _ := entry["{#H.SCORE}"].(string)
+		if v, err := strconv.ParseFloat(score, 64); err == nil && id != "" {
+			scores[id] = v
+		}
+	}
+	return scores
+}
+
+// skipDisabled reports whether host is disabled in Zabbix and should be left
+// out of the plan; such hosts are often decommissioned.
+func (f *Fixer) skipDisabled(host *zabbix.Host, includeDisabled bool) bool {
+	if host.Status != zabbix.HostStatusDisabled || includeDisabled {
+		return false
+	}
+	f.log.Warn("Host is disabled in Zabbix, skipping (use --include-disabled to fix it anyway)",
+		slog.String("host", host.Name))
+	return true
+}
+
 // getBulletinInfo queries the bulletins LLD data from the virtual host to find
 // affected host IDs and package names for a specific bulletin.
 func (f *Fixer) getBulletinInfo(ctx context.Context, bulletinID string) (hostIDs []string, pkgs []string, err error) {
@@ -190,8 +396,8 @@ func (f *Fixer) getBulletinInfo(ctx context.Context, bulletinID string) (hostIDs
 		return nil, nil, fmt.Errorf("no bulletins LLD data found; run 'ztc scan' first")
 	}
 
-	var lldData zabbix.LLDData
-	if err := json.Unmarshal([]byte(lldJSON), &lldData); err != nil {
+	lldData, err := zabbix.DecodeLLD(lldJSON)
+	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse bulletins LLD: %w", err)
 	}
 
@@ -203,23 +409,69 @@ func (f *Fixer) getBulletinInfo(ctx context.Context, bulletinID string) (hostIDs
 
 		// Parse comma-separated host IDs
 		if hostsStr, ok := entry["{#B.HOSTS}"].(string); ok && hostsStr != "" {
-			hostIDs = strings.Split(hostsStr, ",")
-		}
-		// Parse comma-separated package strings and extract just the name.
-		// {#B.PKGS} contains raw package strings like "nginx 1.18.0 amd64"
-		// but getVulnerablePackages() returns just the name portion.
-		if pkgsStr, ok := entry["{#B.PKGS}"].(string); ok && pkgsStr != "" {
-			for _, raw := range strings.Split(pkgsStr, ",") {
-				name := strings.Fields(raw)[0]
-				pkgs = appendUniqueStr(pkgs, name)
+			for _, id := range strings.Split(hostsStr, ",") {
+				id = strings.TrimSpace(id)
+				if id != "" {
+					hostIDs = append(hostIDs, id)
+				}
 			}
 		}
-		return hostIDs, pkgs, nil
+		pkgsStr, _ := entry["{#B.PKGS}"].(string)
+		return hostIDs, bulletinPackageNames(nil, pkgsStr), nil
 	}
 
 	return nil, nil, fmt.Errorf("bulletin %q not found in LLD data", bulletinID)
 }
 
+// bulletinPackageNames appends the names from a {#B.PKGS} value to pkgs.
+// {#B.PKGS} contains comma-separated raw package strings like
+// "nginx 1.18.0 amd64" but getVulnerablePackages() returns just the name
+// portion. Empty or whitespace-only segments are skipped.
+func bulletinPackageNames(pkgs []string, pkgsStr string) []string {
+	for _, raw := range strings.Split(pkgsStr, ",") {
+		fields := strings.Fields(raw)
+		if len(fields) == 0 {
+			continue
+		}
+		pkgs = appendUniqueStr(pkgs, fields[0])
+	}
+	return pkgs
+}
+
+// getBulletinPackages queries the bulletins LLD data on the virtual
+// bulletins host and returns the package names of every bulletin affecting
+// the given host.
+func (f *Fixer) getBulletinPackages(ctx context.Context, hostID string) []string {
+	lldJSON, err := f.zabbixClient.GetItemValueCtx(ctx, f.cfg.Naming.BulletinsHost, "vulners.bulletins_lld")
+	if err != nil {
+		f.log.Debug("Failed to get bulletins LLD data", slog.Any("error", err), slog.String("host", hostID))
+		return nil
+	}
+	if lldJSON == "" {
+		f.log.Debug("No bulletins LLD data found; run 'ztc scan' first", slog.String("host", hostID))
+		return nil
+	}
+
+	lldData, err := zabbix.DecodeLLD(lldJSON)
+	if err != nil {
+		f.log.Debug("Failed to parse bulletins LLD data", slog.Any("error", err))
+		return nil
+	}
+
+	var packages []string
+	for _, entry := range lldData.Data {
+		hostsStr, _ := entry["{#B.HOSTS}"].(string)
+		for _, hid := range strings.Split(hostsStr, ",") {
+			if strings.TrimSpace(hid) == hostID {
+				pkgsStr, _ := entry["{#B.PKGS}"].(string)
+				packages = bulletinPackageNames(packages, pkgsStr)
+				break
+			}
+		}
+	}
+	return packages
+}
+
 // Execute executes a fix plan
 func (f *Fixer) Execute(plan *FixPlan, opts FixOptions) (*FixResults, error) {
 	ctx := context.Background()
@@ -233,9 +485,8 @@ func (f *Fixer) Execute(plan *FixPlan, opts FixOptions) (*FixResults, error) {
 	}
 	semaphore := make(chan struct{}, workers)
 
-	sshUser := opts.SSHUser
-	if sshUser == "" {
-		sshUser = "root"
+	if opts.SSHUser == "" {
+		opts.SSHUser = "root"
 	}
 
 	for _, hostPlan := range plan.Hosts {
@@ -245,16 +496,27 @@ func (f *Fixer) Execute(plan *FixPlan, opts FixOptions) (*FixResults, error) {
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			result := f.executeOnHost(ctx, &hp, opts.UseSSH, sshUser)
+			result := f.executeOnHost(ctx, &hp, opts)
 
 			mu.Lock()
+			defer mu.Unlock()
 			results.Hosts = append(results.Hosts, result)
 			if result.Success {
 				results.Successful++
 			} else {
 				results.Failed++
 			}
-			mu.Unlock()
+			done := len(results.Hosts)
+			f.log.Info("Fix progress",
+				slog.String("fixed", fmt.Sprintf("%d/%d", done, len(plan.Hosts))),
+				slog.String("host", hp.Name),
+				slog.Bool("success", result.Success),
+				slog.Int("successful", results.Successful),
+				slog.Int("failed", results.Failed),
+			)
+			if opts.Progress != nil {
+				opts.Progress(done, len(plan.Hosts), result)
+			}
 		}(hostPlan)
 	}
 
@@ -263,7 +525,7 @@ func (f *Fixer) Execute(plan *FixPlan, opts FixOptions) (*FixResults, error) {
 }
 
 // executeOnHost executes the fix on a single host
-func (f *Fixer) executeOnHost(ctx context.Context, plan *HostFixPlan, useSSH bool, sshUser string) HostFixResult {
+func (f *Fixer) executeOnHost(ctx context.Context, plan *HostFixPlan, opts FixOptions) HostFixResult {
 	result := HostFixResult{
 		HostID: plan.HostID,
 		Name:   plan.Name,
@@ -273,28 +535,37 @@ func (f *Fixer) executeOnHost(ctx context.Context, plan *HostFixPlan, useSSH boo
 		slog.String("host", plan.Name),
 		slog.String("ip", plan.IP),
 		slog.String("command", plan.Command),
-		slog.Bool("ssh", useSSH),
+		slog.Bool("ssh", opts.UseSSH),
+		slog.Bool("agent_wait", opts.AgentWait),
 	)
 
 	var output string
 	var err error
-	if useSSH {
+	switch {
+	case opts.UseSSH:
 		output, err = f.executor.ExecuteWithRetry(ctx, func() (string, error) {
-			return f.executor.ExecuteViaSSH(ctx, plan.IP, sshUser, plan.Command)
+			return f.executor.ExecuteViaSSH(ctx, plan.IP, opts.SSHUser, plan.Command)
 		}, 2)
-	} else {
+	default:
+		err = f.executor.ProbeRemoteCommands(ctx, plan.IP, plan.AgentPort)
+		if err != nil {
+			break
+		}
 		output, err = f.executor.ExecuteWithRetry(ctx, func() (string, error) {
+			if opts.AgentWait {
+				return f.executor.ExecuteViaAgentWait(ctx, plan.IP, plan.AgentPort, plan.Command, opts.AgentTimeout)
+			}
 			return f.executor.ExecuteViaAgent(ctx, plan.IP, plan.AgentPort, plan.Command)
 		}, 2)
 	}
 
+	result.Output = output
 	if err != nil {
 		result.Success = false
 		result.Error = err.Error()
 		f.log.Error("Fix execution failed", slog.Any("error", err), slog.String("host", plan.Name))
 	} else {
 		result.Success = true
-		result.Output = output
 		f.log.Info("Fix executed successfully", slog.String("host", plan.Name))
 	}
 
@@ -317,8 +588,8 @@ func (f *Fixer) getVulnerablePackages(ctx context.Context, hostID string) []stri
 		return nil
 	}
 
-	var lldData zabbix.LLDData
-	if err := json.Unmarshal([]byte(lldJSON), &lldData); err != nil {
+	lldData, err := zabbix.DecodeLLD(lldJSON)
+	if err != nil {
 		f.log.Debug("Failed to parse packages LLD data", slog.Any("error", err))
 		return nil
 	}
@@ -360,51 +631,64 @@ func appendUniqueStr(slice []string, s string) []string {
 	return append(slice, s)
 }
 
-// getHostAddress extracts the IP/DNS address and agent port from a host.
-// Prefers the main agent interface (type=1, main=1), matching Python's
+// getHostAddress extracts the IP/DNS address and agent port from a host,
+// trying the interface scopes in fix.address_preference order. The default
+// prefers the main agent interface (type=1, main=1), matching Python's
 // hostinterface.get(filter={"main":"1","type":"1"}).
 func (f *Fixer) getHostAddress(host *zabbix.Host) (address, port string) {
-	for _, iface := range host.Interfaces {
-		if iface.Main == "1" && iface.Type == "1" {
-			port = iface.Port
-			if iface.UseIP == "1" && iface.IP != "" {
-				return iface.IP, port
-			}
-			if iface.DNS != "" {
-				return iface.DNS, port
-			}
+	for _, pref := range f.cfg.Fix.AddressPreference {
+		if address, port = interfaceAddress(host.Interfaces, pref); address != "" {
+			return address, port
 		}
 	}
+	return "", ""
+}
 
-	// Fallback: any main interface
-	for _, iface := range host.Interfaces {
-		if iface.Main == "1" {
-			port = iface.Port
-			if iface.UseIP == "1" && iface.IP != "" {
-				return iface.IP, port
+// interfaceAddress returns the address and port of the first interface
+// matching one config.AddressPreferences entry.
+func interfaceAddress(ifaces []zabbix.HostInterface, pref string) (address, port string) {
+	scope, field, _ := strings.Cut(pref, "-")
+	for _, iface := range ifaces {
+		switch scope {
+		case "agent":
+			if iface.Main != "1" || iface.Type != "1" {
+				continue
 			}
-			if iface.DNS != "" {
-				return iface.DNS, port
+		case "main":
+			if iface.Main != "1" {
+				continue
 			}
 		}
-	}
 
-	// Last resort: any interface
-	for _, iface := range host.Interfaces {
-		if iface.IP != "" {
-			return iface.IP, iface.Port
+		switch field {
+		case "ip":
+			address = iface.IP
+		case "dns":
+			address = iface.DNS
+		default:
+			// Follow the interface's "Connect to" setting.
+			address = iface.DNS
+			if (iface.UseIP == "1" && iface.IP != "") || address == "" {
+				address = iface.IP
+			}
 		}
-		if iface.DNS != "" {
-			return iface.DNS, iface.Port
+		if address != "" {
+			return address, iface.Port
 		}
 	}
-
 	return "", ""
 }
 
-// getHostOS gets the OS name for a host
+// getHostOS gets the OS name for a host, from the item tagged
+// scan.os_item_tag when one is configured.
 func (f *Fixer) getHostOS(ctx context.Context, hostID string) string {
-	items, err := f.zabbixClient.GetHostItemsCtx(ctx, hostID, "system.sw.os")
+	var items []zabbix.Item
+	var err error
+	if tag := f.cfg.Scan.OSItemTag; tag != "" {
+		items, err = f.zabbixClient.GetHostItemsCtx(ctx, hostID, "", zabbix.ParseItemTag(tag))
+	} else {
+		items, err = f.zabbixClient.GetHostItemsCtx(ctx, hostID, "system.sw.os")
+	}
 	if err != nil {
 		return ""
 	}
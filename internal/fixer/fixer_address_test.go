@@ -113,3 +113,49 @@ func TestGetHostAddress_DNSFallbackLastResort(t *testing.T) {
 		t.Errorf("addr = %q, want fallback.local", addr)
 	}
 }
+
+func TestGetHostAddress_DNSPreferred(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Fix.AddressPreference = []string{"agent-dns", "agent", "any-dns", "any"}
+	f := &Fixer{log: slog.New(slog.NewTextHandler(io.Discard, nil)), cfg: cfg}
+
+	tests := []struct {
+		name     string
+		ifaces   []zabbix.HostInterface
+		wantAddr string
+		wantPort string
+	}{
+		{
+			name: "agent DNS over agent IP",
+			ifaces: []zabbix.HostInterface{
+				{Type: "1", Main: "1", UseIP: "1", IP: "10.0.0.1", DNS: "web01.example.com", Port: "10050"},
+			},
+			wantAddr: "web01.example.com", wantPort: "10050",
+		},
+		{
+			name: "agent IP when it has no DNS name",
+			ifaces: []zabbix.HostInterface{
+				{Type: "2", Main: "1", UseIP: "1", IP: "10.0.0.2", DNS: "snmp.example.com", Port: "161"},
+				{Type: "1", Main: "1", UseIP: "1", IP: "10.0.0.1", Port: "10050"},
+			},
+			wantAddr: "10.0.0.1", wantPort: "10050",
+		},
+		{
+			name: "any DNS when there is no agent interface",
+			ifaces: []zabbix.HostInterface{
+				{Type: "2", Main: "0", UseIP: "1", IP: "10.0.0.3", Port: "161"},
+				{Type: "2", Main: "1", UseIP: "1", IP: "10.0.0.2", DNS: "snmp.example.com", Port: "162"},
+			},
+			wantAddr: "snmp.example.com", wantPort: "162",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, port := f.getHostAddress(&zabbix.Host{Interfaces: tt.ifaces})
+			if addr != tt.wantAddr || port != tt.wantPort {
+				t.Errorf("got %s:%s, want %s:%s", addr, port, tt.wantAddr, tt.wantPort)
+			}
+		})
+	}
+}
@@ -0,0 +1,603 @@
+package fixer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+// fakeZabbix is an in-memory ZabbixAPI. values maps host technical name →
+// item key → last value and serves both GetItemValueCtx and GetHostItemsCtx
+// (keyed by host ID for the latter).
+type fakeZabbix struct {
+	hosts  []zabbix.Host
+	values map[string]map[string]string
+
+	hostGetCalls int // GetHostByIDCtx and GetHostsByIDsCtx calls
+
+	lastClock string // lastclock of every item GetItemCtx returns
+}
+
+func (f *fakeZabbix) GetHostByIDCtx(_ context.Context, hostID string) (*zabbix.Host, error) {
+	f.hostGetCalls++
+	for i := range f.hosts {
+		if f.hosts[i].HostID == hostID {
+			return &f.hosts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("host not found: %s", hostID)
+}
+
+func (f *fakeZabbix) GetHostsByIDsCtx(_ context.Context, hostIDs []string) ([]zabbix.Host, error) {
+	f.hostGetCalls++
+	var hosts []zabbix.Host
+	for _, h := range f.hosts {
+		for _, id := range hostIDs {
+			if h.HostID == id {
+				hosts = append(hosts, h)
+			}
+		}
+	}
+	return hosts, nil
+}
+
+func (f *fakeZabbix) GetHostByNameCtx(_ context.Context, name string) (*zabbix.Host, error) {
+	for i := range f.hosts {
+		if f.hosts[i].Host == name {
+			return &f.hosts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("host not found: %s", name)
+}
+
+func (f *fakeZabbix) GetHostItemsCtx(_ context.Context, hostID, key string, _ ...zabbix.ItemTag) ([]zabbix.Item, error) {
+	if v, ok := f.values[hostID][key]; ok {
+		return []zabbix.Item{{HostID: hostID, Key: key, Value: v}}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeZabbix) GetItemValueCtx(_ context.Context, host, key string) (string, error) {
+	return f.values[host][key], nil
+}
+
+func (f *fakeZabbix) GetItemCtx(_ context.Context, host, key string) (*zabbix.Item, error) {
+	v, ok := f.values[host][key]
+	if !ok {
+		return nil, nil
+	}
+	return &zabbix.Item{Key: key, Value: v, LastClock: f.lastClock}, nil
+}
+
+func (f *fakeZabbix) Close() error { return nil }
+
+// lldJSON encodes LLD rows the way the scanner pushes them.
+func lldJSON(t *testing.T, rows ...map[string]interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(zabbix.LLDData{Data: rows})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func agentHost(id, name, ip string) zabbix.Host {
+	return zabbix.Host{
+		HostID: id, Host: name, Name: name,
+		Interfaces: []zabbix.HostInterface{{Type: "1", Main: "1", UseIP: "1", IP: ip, Port: "10050"}},
+	}
+}
+
+func newTestFixer(client ZabbixAPI) *Fixer {
+	cfg := config.DefaultConfig()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return &Fixer{cfg: cfg, log: log, zabbixClient: client, executor: NewExecutor(cfg, log)}
+}
+
+// fixtureClient has three hosts affected by USN-1 (openssl, libssl) with
+// host 30 missing from Zabbix, plus an unrelated nginx finding on host 10.
+func fixtureClient(t *testing.T) *fakeZabbix {
+	naming := config.DefaultConfig().Naming
+	return &fakeZabbix{
+		hosts: []zabbix.Host{
+			agentHost("10", "web-01", "10.0.0.10"),
+			agentHost("20", "web-02", "10.0.0.20"),
+			agentHost("110", "db-01", "10.0.0.110"),
+		},
+		values: map[string]map[string]string{
+			naming.BulletinsHost: {"vulners.bulletins_lld": lldJSON(t,
+				map[string]interface{}{
+					"{#B.ID}":    "USN-1",
+					"{#B.HOSTS}": "10,20,30",
+					"{#B.PKGS}":  "openssl 1.1.1f amd64,libssl1.1 1.1.1f amd64",
+				},
+				map[string]interface{}{
+					"{#B.ID}":    "USN-2",
+					"{#B.HOSTS}": "110",
+					"{#B.PKGS}":  "nginx 1.18 amd64",
+				},
+			)},
+			naming.PackagesHost: {"vulners.packages_lld": lldJSON(t,
+				map[string]interface{}{"{#P.NAME}": "openssl", "{#P.HOSTS}": "10,20"},
+				map[string]interface{}{"{#P.NAME}": "libssl1.1", "{#P.HOSTS}": "10"},
+				map[string]interface{}{"{#P.NAME}": "nginx", "{#P.HOSTS}": "110,10"},
+			)},
+			"10":  {"system.sw.os": "Ubuntu 20.04"},
+			"20":  {"system.sw.os": "CentOS Linux 7"},
+			"110": {"system.sw.os": "Ubuntu 22.04"},
+		},
+	}
+}
+
+func TestPlan_Bulletin(t *testing.T) {
+	client := fixtureClient(t)
+	f := newTestFixer(client)
+
+	plan, err := f.Plan(FixOptions{BulletinID: "USN-1"})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	// Host 30 is not in Zabbix and is skipped; nginx is not part of USN-1.
+	if len(plan.Hosts) != 2 {
+		t.Fatalf("got %d host plans, want 2: %+v", len(plan.Hosts), plan.Hosts)
+	}
+	web1, web2 := plan.Hosts[0], plan.Hosts[1]
+	if web1.HostID != "10" || !reflect.DeepEqual(web1.Packages, []string{"openssl", "libssl1.1"}) {
+		t.Errorf("host 10 plan = %+v", web1)
+	}
+	if web1.IP != "10.0.0.10" || web1.AgentPort != "10050" {
+		t.Errorf("host 10 address = %s:%s", web1.IP, web1.AgentPort)
+	}
+	if !strings.HasPrefix(web1.Command, "apt-get") {
+		t.Errorf("host 10 command = %q, want apt-get", web1.Command)
+	}
+	if web2.HostID != "20" || !reflect.DeepEqual(web2.Packages, []string{"openssl"}) {
+		t.Errorf("host 20 plan = %+v", web2)
+	}
+	if !strings.HasPrefix(web2.Command, "yum") {
+		t.Errorf("host 20 command = %q, want yum", web2.Command)
+	}
+	if client.hostGetCalls != 1 {
+		t.Errorf("host lookups = %d, want a single batched call", client.hostGetCalls)
+	}
+}
+
+func TestGetBulletinInfo_EmptyFields(t *testing.T) {
+	naming := config.DefaultConfig().Naming
+	f := newTestFixer(&fakeZabbix{values: map[string]map[string]string{
+		naming.BulletinsHost: {"vulners.bulletins_lld": lldJSON(t, map[string]interface{}{
+			"{#B.ID}":    "USN-1",
+			"{#B.HOSTS}": "10,, 20,,",
+			"{#B.PKGS}":  "nginx 1.18 amd64,,openssl 1.1 amd64, ,",
+		})},
+	}})
+
+	hostIDs, pkgs, err := f.getBulletinInfo(context.Background(), "USN-1")
+	if err != nil {
+		t.Fatalf("getBulletinInfo: %v", err)
+	}
+	if !reflect.DeepEqual(hostIDs, []string{"10", "20"}) {
+		t.Errorf("hostIDs = %q, want [10 20]", hostIDs)
+	}
+	if !reflect.DeepEqual(pkgs, []string{"nginx", "openssl"}) {
+		t.Errorf("pkgs = %q, want [nginx openssl]", pkgs)
+	}
+}
+
+func TestPlan_BulletinErrors(t *testing.T) {
+	t.Run("unknown bulletin", func(t *testing.T) {
+		f := newTestFixer(fixtureClient(t))
+		if _, err := f.Plan(FixOptions{BulletinID: "USN-404"}); err == nil {
+			t.Error("expected error for unknown bulletin")
+		}
+	})
+
+	t.Run("no scan data", func(t *testing.T) {
+		f := newTestFixer(&fakeZabbix{})
+		_, err := f.Plan(FixOptions{BulletinID: "USN-1"})
+		if err == nil || !strings.Contains(err.Error(), "run 'ztc scan' first") {
+			t.Errorf("err = %v, want hint to run a scan", err)
+		}
+	})
+}
+
+func TestPlan_Host(t *testing.T) {
+	f := newTestFixer(fixtureClient(t))
+
+	plan, err := f.Plan(FixOptions{HostNames: []string{"web-01"}})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Hosts) != 1 {
+		t.Fatalf("got %d host plans, want 1", len(plan.Hosts))
+	}
+	// Host ID "10" must not match "110" in {#P.HOSTS}, but nginx lists 10 explicitly.
+	want := []string{"openssl", "libssl1.1", "nginx"}
+	if got := plan.Hosts[0].Packages; !reflect.DeepEqual(got, want) {
+		t.Errorf("packages = %v, want %v", got, want)
+	}
+
+	plan, err = f.Plan(FixOptions{HostID: "110"})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if got := plan.Hosts[0].Packages; !reflect.DeepEqual(got, []string{"nginx"}) {
+		t.Errorf("host 110 packages = %v, want [nginx]", got)
+	}
+}
+
+func TestPlan_CompressedLLD(t *testing.T) {
+	client := fixtureClient(t)
+	for _, values := range client.values {
+		for key, value := range values {
+			if strings.HasSuffix(key, "_lld") {
+				values[key] = gzipBase64(t, value)
+			}
+		}
+	}
+	f := newTestFixer(client)
+
+	plan, err := f.Plan(FixOptions{BulletinID: "USN-1"})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Hosts) != 2 {
+		t.Errorf("got %d host plans from compressed LLD, want 2", len(plan.Hosts))
+	}
+}
+
+// gzipBase64 encodes s like scan.compress_lld does.
+func gzipBase64(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestPlan_HostNames(t *testing.T) {
+	f := newTestFixer(fixtureClient(t))
+
+	plan, err := f.Plan(FixOptions{HostNames: []string{"web-01", "db-01", "web-01"}})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	var ids []string
+	for _, hp := range plan.Hosts {
+		ids = append(ids, hp.HostID)
+	}
+	if want := []string{"10", "110"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("planned hosts = %v, want %v", ids, want)
+	}
+
+	for _, names := range [][]string{
+		{"web-01", ""},
+		{"web-01", " "},
+		{"web-01", f.cfg.Naming.BulletinsHost},
+	} {
+		if _, err := f.Plan(FixOptions{HostNames: names}); err == nil {
+			t.Errorf("Plan(%q): expected an error", names)
+		}
+	}
+}
+
+func TestPlan_BulletinOrderAndLimit(t *testing.T) {
+	naming := config.DefaultConfig().Naming
+	client := func() *fakeZabbix {
+		return &fakeZabbix{
+			hosts: []zabbix.Host{
+				agentHost("1", "delta", "10.0.0.1"),
+				agentHost("2", "alpha", "10.0.0.2"),
+				agentHost("3", "charlie", "10.0.0.3"),
+				agentHost("4", "bravo", "10.0.0.4"),
+			},
+			values: map[string]map[string]string{
+				naming.BulletinsHost: {"vulners.bulletins_lld": lldJSON(t, map[string]interface{}{
+					"{#B.ID}":    "USN-1",
+					"{#B.HOSTS}": "1,2,3,4",
+					"{#B.PKGS}":  "openssl 1.1.1f amd64",
+				})},
+				// Host 4 has no score and sorts last by score.
+				naming.HostsHost: {"vulners.hosts_lld": lldJSON(t,
+					map[string]interface{}{"{#H.ID}": "1", "{#H.SCORE}": "9.8"},
+					map[string]interface{}{"{#H.ID}": "2", "{#H.SCORE}": "5.0"},
+					map[string]interface{}{"{#H.ID}": "3", "{#H.SCORE}": "7.5"},
+				)},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		order    string
+		limit    int
+		disabled string // host ID disabled in Zabbix
+		want     []string
+	}{
+		{"lld order", OrderLLD, 0, "", []string{"1", "2", "3", "4"}},
+		{"by name", OrderName, 0, "", []string{"2", "4", "3", "1"}},
+		{"by score", OrderScoreDesc, 0, "", []string{"1", "3", "2", "4"}},
+		{"top 2 by score", OrderScoreDesc, 2, "", []string{"1", "3"}},
+		{"limit counts planned hosts", OrderName, 3, "2", []string{"4", "3", "1"}},
+		{"limit above host count", OrderLLD, 10, "", []string{"1", "2", "3", "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := client()
+			for i := range c.hosts {
+				if c.hosts[i].HostID == tt.disabled {
+					c.hosts[i].Status = zabbix.HostStatusDisabled
+				}
+			}
+			plan, err := newTestFixer(c).Plan(FixOptions{BulletinID: "USN-1", Scope: ScopeBulletins, Order: tt.order, Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+			var got []string
+			for _, hp := range plan.Hosts {
+				got = append(got, hp.HostID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planned hosts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("invalid options", func(t *testing.T) {
+		f := newTestFixer(client())
+		if _, err := f.Plan(FixOptions{BulletinID: "USN-1", Order: "random"}); err == nil {
+			t.Error("expected an error for an unknown order")
+		}
+		if _, err := f.Plan(FixOptions{BulletinID: "USN-1", Limit: -1}); err == nil {
+			t.Error("expected an error for a negative limit")
+		}
+	})
+}
+
+func TestPlan_HostWithoutScanData(t *testing.T) {
+	f := newTestFixer(&fakeZabbix{
+		hosts:  []zabbix.Host{agentHost("10", "web-01", "10.0.0.10")},
+		values: map[string]map[string]string{"10": {"system.sw.os": "Ubuntu 20.04"}},
+	})
+
+	plan, err := f.Plan(FixOptions{HostID: "10"})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	hp := plan.Hosts[0]
+	if len(hp.Packages) != 0 || hp.Command != "apt-get update && apt-get upgrade -y" {
+		t.Errorf("plan = %+v, want full system upgrade", hp)
+	}
+}
+
+func TestPlan_RejectsVirtualHost(t *testing.T) {
+	f := newTestFixer(fixtureClient(t))
+	if _, err := f.Plan(FixOptions{HostNames: []string{f.cfg.Naming.PackagesHost}}); err == nil {
+		t.Error("expected virtual host to be rejected")
+	}
+}
+
+func TestPlan_SkipsDisabledHosts(t *testing.T) {
+	client := fixtureClient(t)
+	client.hosts[1].Status = zabbix.HostStatusDisabled // web-02
+
+	f := newTestFixer(client)
+
+	plan, err := f.Plan(FixOptions{BulletinID: "USN-1"})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Hosts) != 1 || plan.Hosts[0].HostID != "10" {
+		t.Errorf("bulletin plan = %+v, want only host 10", plan.Hosts)
+	}
+
+	plan, err = f.Plan(FixOptions{HostNames: []string{"web-02"}})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Hosts) != 0 {
+		t.Errorf("host plan = %+v, want none for a disabled host", plan.Hosts)
+	}
+
+	plan, err = f.Plan(FixOptions{BulletinID: "USN-1", IncludeDisabled: true})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan.Hosts) != 2 {
+		t.Errorf("got %d host plans with IncludeDisabled, want 2", len(plan.Hosts))
+	}
+}
+
+func TestExecute_Progress(t *testing.T) {
+	// The fake zabbix_get answers the probe and every command for all hosts
+	// except 10.0.0.20, which has remote commands disabled.
+	script := filepath.Join(t.TempDir(), "zabbix_get")
+	body := `#!/bin/sh
+case "$*" in
+  *10.0.0.20*) echo 'ZBX_NOTSUPPORTED' ;;
+  *) echo 'ztc-probe' ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f := newTestFixer(fixtureClient(t))
+	f.cfg.Zabbix.GetPath = script
+	f.executor = NewExecutor(f.cfg, f.log)
+
+	plan, err := f.Plan(FixOptions{BulletinID: "USN-1"})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	var calls []int
+	results, err := f.Execute(plan, FixOptions{
+		Progress: func(done, total int, _ HostFixResult) {
+			if total != len(plan.Hosts) {
+				t.Errorf("total = %d, want %d", total, len(plan.Hosts))
+			}
+			calls = append(calls, done)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !reflect.DeepEqual(calls, []int{1, 2}) {
+		t.Errorf("progress calls = %v, want [1 2]", calls)
+	}
+	if results.Successful != 1 || results.Failed != 1 {
+		t.Errorf("successful/failed = %d/%d, want 1/1", results.Successful, results.Failed)
+	}
+}
+
+func TestPlan_Scope(t *testing.T) {
+	tests := []struct {
+		name string
+		opts FixOptions
+		want map[string][]string // host ID → packages
+	}{
+		{"host, packages only", FixOptions{HostID: "10", Scope: ScopePackages},
+			map[string][]string{"10": {"openssl", "libssl1.1", "nginx"}}},
+		// nginx is in the packages LLD for host 10 but no bulletin names it.
+		{"host, bulletins only", FixOptions{HostID: "10", Scope: ScopeBulletins},
+			map[string][]string{"10": {"openssl", "libssl1.1"}}},
+		{"host ID prefix does not match", FixOptions{HostID: "110", Scope: ScopeBulletins},
+			map[string][]string{"110": {"nginx"}}},
+		// The packages LLD is not consulted, so host 20 gets the whole bulletin.
+		{"bulletin, bulletins only", FixOptions{BulletinID: "USN-1", Scope: ScopeBulletins},
+			map[string][]string{"10": {"openssl", "libssl1.1"}, "20": {"openssl", "libssl1.1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := newTestFixer(fixtureClient(t)).Plan(tt.opts)
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+			got := make(map[string][]string)
+			for _, hp := range plan.Hosts {
+				got[hp.HostID] = hp.Packages
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("packages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("bulletin, packages only", func(t *testing.T) {
+		f := newTestFixer(fixtureClient(t))
+		if _, err := f.Plan(FixOptions{BulletinID: "USN-1", Scope: ScopePackages}); err == nil {
+			t.Error("expected error for a packages-only bulletin fix")
+		}
+	})
+
+	t.Run("no full update without data", func(t *testing.T) {
+		f := newTestFixer(&fakeZabbix{
+			hosts:  []zabbix.Host{agentHost("10", "web-01", "10.0.0.10")},
+			values: map[string]map[string]string{"10": {"system.sw.os": "Ubuntu 20.04"}},
+		})
+		for _, scope := range []string{ScopePackages, ScopeBulletins} {
+			plan, err := f.Plan(FixOptions{HostID: "10", Scope: scope})
+			if err != nil {
+				t.Fatalf("Plan: %v", err)
+			}
+			if len(plan.Hosts) != 0 {
+				t.Errorf("scope %s plan = %+v, want none", scope, plan.Hosts)
+			}
+		}
+	})
+}
+
+func TestPlan_DisabledLLD(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled []string
+		opts    FixOptions
+		wantErr string // empty: the plan succeeds
+	}{
+		{"host without packages LLD", []string{config.LLDHosts, config.LLDBulletins},
+			FixOptions{HostID: "10"}, "packages LLD"},
+		{"bulletin without packages LLD", []string{config.LLDHosts, config.LLDBulletins},
+			FixOptions{BulletinID: "USN-1"}, "packages LLD"},
+		{"bulletins only without packages LLD", []string{config.LLDHosts, config.LLDBulletins},
+			FixOptions{HostID: "10", Scope: ScopeBulletins}, ""},
+		{"bulletin without bulletins LLD", []string{config.LLDHosts, config.LLDPackages},
+			FixOptions{BulletinID: "USN-1"}, "bulletins LLD"},
+		{"host without bulletins LLD", []string{config.LLDHosts, config.LLDPackages},
+			FixOptions{HostID: "10"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTestFixer(fixtureClient(t))
+			f.cfg.Scan.EnabledLLD = tt.enabled
+			plan, err := f.Plan(tt.opts)
+			if tt.wantErr == "" {
+				if err != nil || len(plan.Hosts) == 0 {
+					t.Fatalf("Plan = %+v, %v; want a plan", plan, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Plan = %+v, %v; want a %s error", plan, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlan_StaleData(t *testing.T) {
+	stale := strconv.FormatInt(time.Now().Add(-8*24*time.Hour).Unix(), 10)
+	fresh := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		lastClock string
+		maxAge    int
+		opts      FixOptions
+		wantErr   bool
+	}{
+		{"fresh data", fresh, 168, FixOptions{HostID: "10"}, false},
+		{"stale host data", stale, 168, FixOptions{HostID: "10"}, true},
+		{"stale bulletin data", stale, 168, FixOptions{BulletinID: "USN-1"}, true},
+		{"stale data in a dry run", stale, 168, FixOptions{HostID: "10", DryRun: true}, false},
+		{"stale data allowed", stale, 168, FixOptions{HostID: "10", AllowStale: true}, false},
+		{"check disabled", stale, 0, FixOptions{HostID: "10"}, false},
+		{"no timestamp", "", 168, FixOptions{HostID: "10"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fixtureClient(t)
+			client.lastClock = tt.lastClock
+			f := newTestFixer(client)
+			f.cfg.Fix.MaxDataAge = tt.maxAge
+
+			_, err := f.Plan(tt.opts)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "--allow-stale") {
+					t.Errorf("err = %v, want stale data error", err)
+				}
+			} else if err != nil {
+				t.Errorf("Plan: %v", err)
+			}
+		})
+	}
+}
@@ -11,15 +11,21 @@ import (
 
 // Module provides all fixer dependencies for fx injection.
 var Module = fx.Module("fixer",
-	fx.Provide(ProvideFixer, NewExecutor),
+	fx.Provide(ProvideFixer, NewExecutor, ProvideZabbixAPI),
 	zabbix.Module,
 )
 
+// ProvideZabbixAPI exposes the Zabbix client through the fixer's ZabbixAPI
+// interface.
+func ProvideZabbixAPI(c *zabbix.Client) ZabbixAPI {
+	return c
+}
+
 // ProvideFixer assembles a Fixer from its injected dependencies.
 func ProvideFixer(
 	cfg *config.Config,
 	log *slog.Logger,
-	zabbixClient *zabbix.Client,
+	zabbixClient ZabbixAPI,
 	executor *Executor,
 ) *Fixer {
 	return &Fixer{
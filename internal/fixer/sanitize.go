@@ -8,8 +8,7 @@ import (
 )
 
 var (
-	packageNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._+:~-]*$`)
-	sshUserRe     = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9._-]*$`)
+	sshUserRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9._-]*$`)
 	// fqdnRe validates a hostname: starts and ends with alphanumeric, allows
 	// dots and hyphens in between. Each label must be <=63 chars and the total
 	// length must be <=253 chars. Validated further in isValidFQDN.
@@ -52,20 +51,6 @@ func isValidFQDN(s string) bool {
 	return true
 }
 
-// ValidatePackageName validates that a package name contains only safe characters.
-func ValidatePackageName(name string) error {
-	if name == "" {
-		return fmt.Errorf("package name is empty")
-	}
-	if len(name) > 256 {
-		return fmt.Errorf("package name too long: %d chars", len(name))
-	}
-	if !packageNameRe.MatchString(name) {
-		return fmt.Errorf("invalid package name: %q", name)
-	}
-	return nil
-}
-
 // ValidateSSHUser validates that an SSH username contains only safe characters.
 func ValidateSSHUser(user string) error {
 	if user == "" {
@@ -79,13 +64,3 @@ func ValidateSSHUser(user string) error {
 	}
 	return nil
 }
-
-// SanitizePackages validates all package names in the slice.
-func SanitizePackages(packages []string) error {
-	for _, pkg := range packages {
-		if err := ValidatePackageName(pkg); err != nil {
-			return err
-		}
-	}
-	return nil
-}
@@ -33,33 +33,6 @@ func TestValidateHostTarget(t *testing.T) {
 	}
 }
 
-func TestValidatePackageName(t *testing.T) {
-	tests := []struct {
-		name    string
-		pkg     string
-		wantErr bool
-	}{
-		{"nginx", "nginx", false},
-		{"with arch", "libc6:amd64", false},
-		{"with devel suffix", "kernel-devel", false},
-		{"python version", "python3.11", false},
-		{"with tilde", "pkg~beta1", false},
-		{"with plus", "g++", false},
-		{"injection attempt", "$(whoami)", true},
-		{"spaces", "nginx openssl", true},
-		{"empty", "", true},
-		{"semicolon", "nginx;rm", true},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := ValidatePackageName(tt.pkg)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("ValidatePackageName(%q) error = %v, wantErr %v", tt.pkg, err, tt.wantErr)
-			}
-		})
-	}
-}
-
 func TestValidateSSHUser(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -83,26 +56,3 @@ func TestValidateSSHUser(t *testing.T) {
 		})
 	}
 }
-
-func TestSanitizePackages(t *testing.T) {
-	t.Run("valid packages", func(t *testing.T) {
-		err := SanitizePackages([]string{"nginx", "openssl", "libc6:amd64"})
-		if err != nil {
-			t.Errorf("unexpected error: %v", err)
-		}
-	})
-
-	t.Run("nil packages", func(t *testing.T) {
-		err := SanitizePackages(nil)
-		if err != nil {
-			t.Errorf("unexpected error: %v", err)
-		}
-	})
-
-	t.Run("invalid package", func(t *testing.T) {
-		err := SanitizePackages([]string{"nginx", "$(bad)", "openssl"})
-		if err == nil {
-			t.Error("expected error for invalid package name")
-		}
-	})
-}
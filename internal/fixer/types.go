@@ -1,15 +1,62 @@
 package fixer
 
+import "time"
+
 // FixOptions configures a fix operation
 type FixOptions struct {
-	BulletinID string // Bulletin ID to fix (optional)
-	HostID     string // Specific host ID to fix (optional)
-	HostName   string // Host technical name to fix (optional, resolved to HostID)
-	DryRun     bool   // Don't execute, just show plan
-	UseSSH     bool   // Use SSH instead of Zabbix agent
-	SSHUser    string // SSH user for remote execution (default: root)
+	BulletinID string   // Bulletin ID to fix (optional)
+	HostID     string   // Specific host ID to fix (optional)
+	HostNames  []string // Host technical names to fix (optional, ignored with HostID)
+	DryRun     bool     // Don't execute, just show plan
+	UseSSH     bool     // Use SSH instead of Zabbix agent
+	SSHUser    string   // SSH user for remote execution (default: root)
+
+	IncludeDisabled bool // Also plan fixes for hosts disabled in Zabbix
+	AllowStale      bool // Plan from scan data older than fix.max_data_age
+
+	// Scope limits which pushed data drives the plan: ScopeAll, ScopePackages
+	// or ScopeBulletins.
+	Scope string
+
+	// Order sorts the hosts of a bulletin plan (OrderLLD, OrderScoreDesc or
+	// OrderName) and Limit keeps only the first Limit planned hosts
+	// (0 = all), for canary-style rollouts.
+	Order string
+	Limit int
+
+	// AgentWait runs agent fixes with a waiting system.run so output and
+	// exit status are captured; AgentTimeout bounds each command.
+	AgentWait    bool
+	AgentTimeout time.Duration
+
+	// Progress, if set, is called after each host finishes with the number
+	// of hosts done so far. Calls are serialized.
+	Progress func(done, total int, result HostFixResult)
 }
 
+// Fix scopes for FixOptions.Scope.
+const (
+	// ScopeAll plans host fixes from the packages LLD and bulletin fixes
+	// from the bulletin's packages that the packages LLD lists for each
+	// host. A host without package data gets a full system update.
+	ScopeAll = ""
+	// ScopePackages uses only the packages LLD; it cannot drive a bulletin
+	// fix, and hosts without package data are left out.
+	ScopePackages = "packages"
+	// ScopeBulletins uses only the bulletins LLD: a host fix upgrades the
+	// packages of every bulletin affecting the host, a bulletin fix upgrades
+	// all the bulletin's packages on each affected host. Hosts without
+	// bulletin data are left out.
+	ScopeBulletins = "bulletins"
+)
+
+// Host orders for FixOptions.Order.
+const (
+	OrderLLD       = ""           // as the bulletins LLD lists them
+	OrderScoreDesc = "score-desc" // highest host CVSS score (from the hosts LLD) first
+	OrderName      = "name"       // by visible host name
+)
+
 // FixPlan describes the fix actions to take
 type FixPlan struct {
 	Hosts    []HostFixPlan
@@ -0,0 +1,140 @@
+package grpcapi
+
+import (
+	"github.com/kidoz/zabbix-threat-control-go/internal/fixer"
+	"github.com/kidoz/zabbix-threat-control-go/internal/grpcapi/ztcpb"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+var hostEventKinds = map[string]ztcpb.HostEvent_Kind{
+	scanner.HostStarted:   ztcpb.HostEvent_STARTED,
+	scanner.HostCompleted: ztcpb.HostEvent_COMPLETED,
+	scanner.HostFailed:    ztcpb.HostEvent_FAILED,
+}
+
+func hostEventToPB(e scanner.HostEvent) *ztcpb.HostEvent {
+	pb := &ztcpb.HostEvent{
+		Kind:   hostEventKinds[e.Kind],
+		HostId: e.HostID,
+		Name:   e.Name,
+		Done:   int32(e.Done),
+		Total:  int32(e.Total),
+	}
+	if e.Entry != nil {
+		pb.Score = e.Entry.Score
+	}
+	if e.Err != nil {
+		pb.Error = e.Err.Error()
+	}
+	return pb
+}
+
+func resultsToPB(r *scanner.ScanResults) *ztcpb.ScanResults {
+	pb := &ztcpb.ScanResults{
+		HostsScanned:       int32(r.HostsScanned),
+		HostsWithVulns:     int32(r.HostsWithVulns),
+		VulnerablePackages: int32(r.VulnerablePackages),
+		MaxCvss:            r.MaxCVSS,
+	}
+	for _, h := range r.Hosts {
+		pb.Hosts = append(pb.Hosts, hostToPB(h))
+	}
+	for _, p := range r.Packages {
+		pb.Packages = append(pb.Packages, &ztcpb.PackageEntry{
+			Name:              p.Name,
+			Version:           p.Version,
+			Arch:              p.Arch,
+			Score:             p.Score,
+			Fix:               p.Fix,
+			AffectedHosts:     p.AffectedHosts,
+			AffectedHostNames: p.AffectedHostNames,
+			Bulletins:         p.Bulletins,
+		})
+	}
+	for _, b := range r.Bulletins {
+		pb.Bulletins = append(pb.Bulletins, &ztcpb.BulletinEntry{
+			Id:                b.ID,
+			Type:              b.Type,
+			Score:             b.Score,
+			Cves:              b.CVEs,
+			Fix:               b.Fix,
+			AffectedPkgs:      b.AffectedPkgs,
+			AffectedHosts:     b.AffectedHosts,
+			AffectedHostNames: b.AffectedHostNames,
+		})
+	}
+	if len(r.Excluded) > 0 {
+		pb.Excluded = make(map[string]int32, len(r.Excluded))
+		for reason, n := range r.Excluded {
+			pb.Excluded[reason] = int32(n)
+		}
+	}
+	return pb
+}
+
+func hostToPB(h scanner.HostEntry) *ztcpb.HostEntry {
+	pb := &ztcpb.HostEntry{
+		HostId:        h.HostID,
+		Host:          h.Host,
+		Name:          h.Name,
+		OsName:        h.OSName,
+		OsVersion:     h.OSVersion,
+		Score:         h.Score,
+		CumulativeFix: h.CumulativeFix,
+	}
+	for _, p := range h.Packages {
+		pb.Packages = append(pb.Packages, &ztcpb.PackageVuln{
+			Name:      p.Name,
+			Version:   p.Version,
+			Arch:      p.Arch,
+			Score:     p.Score,
+			Fix:       p.Fix,
+			Bulletins: p.Bulletins,
+			Cves:      p.CVEs,
+		})
+	}
+	for _, b := range h.Bulletins {
+		pb.Bulletins = append(pb.Bulletins, &ztcpb.BulletinSummary{
+			Id:            b.ID,
+			Type:          b.Type,
+			Score:         b.Score,
+			Cves:          b.CVEs,
+			Fix:           b.Fix,
+			AffectedPkg:   b.AffectedPkg,
+			AffectedHosts: b.AffectedHosts,
+		})
+	}
+	for _, g := range h.Groups {
+		pb.Groups = append(pb.Groups, &ztcpb.HostGroup{GroupId: g.GroupID, Name: g.Name})
+	}
+	return pb
+}
+
+func planToPB(p *fixer.FixPlan) *ztcpb.FixPlan {
+	pb := &ztcpb.FixPlan{Packages: p.Packages}
+	for _, h := range p.Hosts {
+		pb.Hosts = append(pb.Hosts, &ztcpb.HostFixPlan{
+			HostId:    h.HostID,
+			Name:      h.Name,
+			Ip:        h.IP,
+			AgentPort: h.AgentPort,
+			Packages:  h.Packages,
+			Command:   h.Command,
+		})
+	}
+	return pb
+}
+
+func fixResultsToPB(r *fixer.FixResults) *ztcpb.FixResults {
+	pb := &ztcpb.FixResults{Successful: int32(r.Successful), Failed: int32(r.Failed)}
+	for _, h := range r.Hosts {
+		pb.Hosts = append(pb.Hosts, &ztcpb.HostFixResult{
+			HostId:  h.HostID,
+			Name:    h.Name,
+			Success: h.Success,
+			Output:  h.Output,
+			Error:   h.Error,
+		})
+	}
+	return pb
+}
@@ -0,0 +1,188 @@
+// Package grpcapi serves scans and fixes over the ztc.v1.ThreatControl gRPC
+// service defined in ztcpb/ztc.proto.
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/fixer"
+	"github.com/kidoz/zabbix-threat-control-go/internal/grpcapi/ztcpb"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+// Scanner is the part of scanner.Scanner the server uses.
+type Scanner interface {
+	Scan(ctx context.Context, opts scanner.ScanOptions) (*scanner.ScanResults, error)
+	PushResults(ctx context.Context, results *scanner.ScanResults) error
+	ResolveHostIDs(ctx context.Context, refs []string) ([]string, error)
+}
+
+// Fixer is the part of fixer.Fixer the server uses.
+type Fixer interface {
+	Plan(opts fixer.FixOptions) (*fixer.FixPlan, error)
+	Execute(plan *fixer.FixPlan, opts fixer.FixOptions) (*fixer.FixResults, error)
+}
+
+// Server implements ztcpb.ThreatControlServer. It runs one scan at a time
+// and keeps the results of the last one for GetResults.
+type Server struct {
+	ztcpb.UnimplementedThreatControlServer
+
+	scanner  Scanner
+	fixer    Fixer
+	allowFix bool
+	log      *slog.Logger
+
+	scanMu sync.Mutex // held while a scan runs
+
+	mu      sync.Mutex
+	results *scanner.ScanResults
+}
+
+// New creates a server. Unless allowFix is set, Fix only accepts dry runs.
+func New(s Scanner, f Fixer, allowFix bool, log *slog.Logger) *Server {
+	return &Server{scanner: s, fixer: f, allowFix: allowFix, log: log}
+}
+
+// Register registers the server on a gRPC server.
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	ztcpb.RegisterThreatControlServer(r, s)
+}
+
+// Scan runs a scan, streaming per-host progress and then the results.
+func (s *Server) Scan(req *ztcpb.ScanRequest, stream grpc.ServerStreamingServer[ztcpb.ScanEvent]) error {
+	if req.GetLimit() < 0 {
+		return status.Error(codes.InvalidArgument, "limit must be >= 0")
+	}
+	if !s.scanMu.TryLock() {
+		return status.Error(codes.Unavailable, "a scan is already running")
+	}
+	defer s.scanMu.Unlock()
+
+	ctx := stream.Context()
+	opts := scanner.ScanOptions{Limit: int(req.GetLimit()), NoPush: req.GetNoPush()}
+	if len(req.GetHosts()) > 0 {
+		ids, err := s.scanner.ResolveHostIDs(ctx, req.GetHosts())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		opts.HostIDs = ids
+	}
+
+	// Events arrive serialized; a failed send means the client went away,
+	// which also cancels ctx and with it the scan.
+	var sendErr error
+	opts.OnHostEvent = func(e scanner.HostEvent) {
+		if sendErr != nil {
+			return
+		}
+		sendErr = stream.Send(&ztcpb.ScanEvent{Event: &ztcpb.ScanEvent_Host{Host: hostEventToPB(e)}})
+	}
+
+	s.log.Info("Starting scan requested over gRPC", slog.Int("hosts", len(opts.HostIDs)), slog.Int("limit", opts.Limit))
+	results, err := s.scanner.Scan(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	if !opts.NoPush {
+		if err := s.scanner.PushResults(ctx, results); err != nil {
+			return fmt.Errorf("failed to push results: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.results = results
+	s.mu.Unlock()
+
+	return stream.Send(&ztcpb.ScanEvent{Event: &ztcpb.ScanEvent_Results{Results: resultsToPB(results)}})
+}
+
+// GetResults returns the results of the last scan.
+func (s *Server) GetResults(context.Context, *ztcpb.GetResultsRequest) (*ztcpb.ScanResults, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.results == nil {
+		return nil, status.Error(codes.NotFound, "no scan has completed on this server yet")
+	}
+	return resultsToPB(s.results), nil
+}
+
+// Fix plans a fix and executes it unless the request is a dry run.
+func (s *Server) Fix(_ context.Context, req *ztcpb.FixRequest) (*ztcpb.FixResponse, error) {
+	opts, err := fixOptions(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !opts.DryRun && !s.allowFix {
+		return nil, status.Error(codes.PermissionDenied, "fixes are disabled on this server; use dry_run or start it with --allow-fix")
+	}
+
+	plan, err := s.fixer.Plan(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fix plan: %w", err)
+	}
+	resp := &ztcpb.FixResponse{Plan: planToPB(plan)}
+	if opts.DryRun {
+		return resp, nil
+	}
+
+	s.log.Info("Executing fix requested over gRPC", slog.Int("hosts", len(plan.Hosts)))
+	results, err := s.fixer.Execute(plan, opts)
+	if err != nil {
+		return nil, fmt.Errorf("fix execution failed: %w", err)
+	}
+	resp.Results = fixResultsToPB(results)
+	return resp, nil
+}
+
+// fixOptions validates req the way 'ztc fix' validates its flags.
+func fixOptions(req *ztcpb.FixRequest) (fixer.FixOptions, error) {
+	opts := fixer.FixOptions{
+		BulletinID:      req.GetBulletinId(),
+		HostID:          req.GetHostId(),
+		DryRun:          req.GetDryRun(),
+		UseSSH:          req.GetUseSsh(),
+		SSHUser:         req.GetSshUser(),
+		IncludeDisabled: req.GetIncludeDisabled(),
+		AllowStale:      req.GetAllowStale(),
+		Scope:           req.GetScope(),
+		AgentWait:       req.GetAgentWait(),
+		AgentTimeout:    time.Duration(req.GetAgentTimeoutSeconds()) * time.Second,
+	}
+	if name := req.GetHostName(); name != "" {
+		opts.HostNames = []string{name}
+	}
+	if opts.BulletinID == "" && opts.HostID == "" && len(opts.HostNames) == 0 {
+		return opts, fmt.Errorf("one of bulletin_id, host_id or host_name is required")
+	}
+	switch opts.Scope {
+	case fixer.ScopeAll, fixer.ScopePackages, fixer.ScopeBulletins:
+	default:
+		return opts, fmt.Errorf("scope must be %q, %q or %q, got %q", fixer.ScopeAll, fixer.ScopePackages, fixer.ScopeBulletins, opts.Scope)
+	}
+	if opts.AgentWait && opts.UseSSH {
+		return opts, fmt.Errorf("agent_wait cannot be combined with use_ssh")
+	}
+	if req.GetAgentTimeoutSeconds() < 0 {
+		return opts, fmt.Errorf("agent_timeout_seconds must be >= 0")
+	}
+	if opts.SSHUser == "" {
+		opts.SSHUser = "root"
+	}
+	if opts.AgentTimeout == 0 {
+		opts.AgentTimeout = 30 * time.Second
+	}
+	return opts, nil
+}
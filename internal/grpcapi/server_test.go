@@ -0,0 +1,186 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/fixer"
+	"github.com/kidoz/zabbix-threat-control-go/internal/grpcapi/ztcpb"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+type fakeScanner struct {
+	opts   scanner.ScanOptions
+	pushed bool
+}
+
+func (f *fakeScanner) Scan(_ context.Context, opts scanner.ScanOptions) (*scanner.ScanResults, error) {
+	f.opts = opts
+	web := scanner.HostEntry{HostID: "10", Name: "web-01", Score: 9.8}
+	opts.OnHostEvent(scanner.HostEvent{Kind: scanner.HostStarted, HostID: "10", Name: "web-01", Total: 2})
+	opts.OnHostEvent(scanner.HostEvent{Kind: scanner.HostCompleted, HostID: "10", Name: "web-01", Entry: &web, Done: 1, Total: 2})
+	opts.OnHostEvent(scanner.HostEvent{Kind: scanner.HostFailed, HostID: "20", Name: "db-01", Err: errors.New("audit failed"), Done: 2, Total: 2})
+	return &scanner.ScanResults{
+		HostsScanned: 1,
+		MaxCVSS:      9.8,
+		Hosts:        []scanner.HostEntry{web},
+		Excluded:     map[string]int{scanner.ReasonFetchFailed: 1},
+	}, nil
+}
+
+func (f *fakeScanner) PushResults(context.Context, *scanner.ScanResults) error {
+	f.pushed = true
+	return nil
+}
+
+func (f *fakeScanner) ResolveHostIDs(_ context.Context, refs []string) ([]string, error) {
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = "id-" + ref
+	}
+	return ids, nil
+}
+
+type fakeFixer struct {
+	executed bool
+}
+
+func (f *fakeFixer) Plan(opts fixer.FixOptions) (*fixer.FixPlan, error) {
+	return &fixer.FixPlan{Hosts: []fixer.HostFixPlan{{HostID: opts.HostID, Name: "web-01", Command: "yum update openssl"}}}, nil
+}
+
+func (f *fakeFixer) Execute(plan *fixer.FixPlan, _ fixer.FixOptions) (*fixer.FixResults, error) {
+	f.executed = true
+	return &fixer.FixResults{Successful: 1, Hosts: []fixer.HostFixResult{{HostID: plan.Hosts[0].HostID, Success: true}}}, nil
+}
+
+func newTestClient(t *testing.T, srv *Server) ztcpb.ThreatControlClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	srv.Register(gs)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return ztcpb.NewThreatControlClient(conn)
+}
+
+func TestScan_StreamsHostEvents(t *testing.T) {
+	sc := &fakeScanner{}
+	client := newTestClient(t, New(sc, &fakeFixer{}, false, slog.New(slog.DiscardHandler)))
+	ctx := context.Background()
+
+	if _, err := client.GetResults(ctx, &ztcpb.GetResultsRequest{}); status.Code(err) != codes.NotFound {
+		t.Fatalf("GetResults before a scan: %v, want NotFound", err)
+	}
+
+	stream, err := client.Scan(ctx, &ztcpb.ScanRequest{Hosts: []string{"web-01"}, Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []*ztcpb.HostEvent
+	var results *ztcpb.ScanResults
+	for {
+		ev, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h := ev.GetHost(); h != nil {
+			events = append(events, h)
+		}
+		if r := ev.GetResults(); r != nil {
+			results = r
+		}
+	}
+
+	wantKinds := []ztcpb.HostEvent_Kind{ztcpb.HostEvent_STARTED, ztcpb.HostEvent_COMPLETED, ztcpb.HostEvent_FAILED}
+	if len(events) != len(wantKinds) {
+		t.Fatalf("got %d host events, want %d", len(events), len(wantKinds))
+	}
+	for i, kind := range wantKinds {
+		if events[i].GetKind() != kind {
+			t.Errorf("event %d kind = %v, want %v", i, events[i].GetKind(), kind)
+		}
+	}
+	if events[1].GetScore() != 9.8 || events[2].GetError() != "audit failed" || events[2].GetDone() != 2 {
+		t.Errorf("unexpected events: %v", events)
+	}
+	if results == nil || results.GetMaxCvss() != 9.8 || results.GetExcluded()[scanner.ReasonFetchFailed] != 1 {
+		t.Fatalf("unexpected results: %v", results)
+	}
+
+	if got := sc.opts.HostIDs; len(got) != 1 || got[0] != "id-web-01" || sc.opts.Limit != 5 {
+		t.Errorf("scan options = %+v", sc.opts)
+	}
+	if !sc.pushed {
+		t.Error("results were not pushed")
+	}
+
+	got, err := client.GetResults(ctx, &ztcpb.GetResultsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.GetHosts()) != 1 || got.GetHosts()[0].GetName() != "web-01" {
+		t.Errorf("GetResults = %v", got)
+	}
+}
+
+func TestFix(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowFix     bool
+		req          *ztcpb.FixRequest
+		wantCode     codes.Code
+		wantExecuted bool
+	}{
+		{"dry run", false, &ztcpb.FixRequest{HostId: "10", DryRun: true}, codes.OK, false},
+		{"execution disabled", false, &ztcpb.FixRequest{HostId: "10"}, codes.PermissionDenied, false},
+		{"execution allowed", true, &ztcpb.FixRequest{HostId: "10"}, codes.OK, true},
+		{"no target", true, &ztcpb.FixRequest{DryRun: true}, codes.InvalidArgument, false},
+		{"bad scope", true, &ztcpb.FixRequest{HostId: "10", Scope: "all"}, codes.InvalidArgument, false},
+		{"agent wait over ssh", true, &ztcpb.FixRequest{HostId: "10", AgentWait: true, UseSsh: true}, codes.InvalidArgument, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := &fakeFixer{}
+			client := newTestClient(t, New(&fakeScanner{}, fx, tt.allowFix, slog.New(slog.DiscardHandler)))
+
+			resp, err := client.Fix(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if fx.executed != tt.wantExecuted {
+				t.Errorf("executed = %v, want %v", fx.executed, tt.wantExecuted)
+			}
+			if err != nil {
+				return
+			}
+			if resp.GetPlan().GetHosts()[0].GetCommand() != "yum update openssl" {
+				t.Errorf("plan = %v", resp.GetPlan())
+			}
+			if (resp.GetResults() != nil) != tt.wantExecuted {
+				t.Errorf("results = %v", resp.GetResults())
+			}
+		})
+	}
+}
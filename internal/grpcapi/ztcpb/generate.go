@@ -0,0 +1,5 @@
+// Package ztcpb holds the protobuf messages and gRPC stubs of the
+// ThreatControl service, generated from ztc.proto.
+package ztcpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ztc.proto
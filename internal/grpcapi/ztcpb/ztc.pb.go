@@ -0,0 +1,1687 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: ztc.proto
+
+package ztcpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type HostEvent_Kind int32
+
+const (
+	HostEvent_KIND_UNSPECIFIED HostEvent_Kind = 0
+	HostEvent_STARTED          HostEvent_Kind = 1
+	HostEvent_COMPLETED        HostEvent_Kind = 2
+	HostEvent_FAILED           HostEvent_Kind = 3
+)
+
+// Enum value maps for HostEvent_Kind.
+var (
+	HostEvent_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "STARTED",
+		2: "COMPLETED",
+		3: "FAILED",
+	}
+	HostEvent_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"STARTED":          1,
+		"COMPLETED":        2,
+		"FAILED":           3,
+	}
+)
+
+func (x HostEvent_Kind) Enum() *HostEvent_Kind {
+	p := new(HostEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x HostEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HostEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_ztc_proto_enumTypes[0].Descriptor()
+}
+
+func (HostEvent_Kind) Type() protoreflect.EnumType {
+	return &file_ztc_proto_enumTypes[0]
+}
+
+func (x HostEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HostEvent_Kind.Descriptor instead.
+func (HostEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{2, 0}
+}
+
+type ScanRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Host IDs or technical names to scan; empty scans all hosts.
+	Hosts []string `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	// Maximum number of hosts to scan; 0 is unlimited.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Do not push the results to Zabbix.
+	NoPush        bool `protobuf:"varint,3,opt,name=no_push,json=noPush,proto3" json:"no_push,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanRequest) Reset() {
+	*x = ScanRequest{}
+	mi := &file_ztc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRequest) ProtoMessage() {}
+
+func (x *ScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRequest.ProtoReflect.Descriptor instead.
+func (*ScanRequest) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ScanRequest) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *ScanRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ScanRequest) GetNoPush() bool {
+	if x != nil {
+		return x.NoPush
+	}
+	return false
+}
+
+type ScanEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ScanEvent_Host
+	//	*ScanEvent_Results
+	Event         isScanEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanEvent) Reset() {
+	*x = ScanEvent{}
+	mi := &file_ztc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanEvent) ProtoMessage() {}
+
+func (x *ScanEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanEvent.ProtoReflect.Descriptor instead.
+func (*ScanEvent) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScanEvent) GetEvent() isScanEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ScanEvent) GetHost() *HostEvent {
+	if x != nil {
+		if x, ok := x.Event.(*ScanEvent_Host); ok {
+			return x.Host
+		}
+	}
+	return nil
+}
+
+func (x *ScanEvent) GetResults() *ScanResults {
+	if x != nil {
+		if x, ok := x.Event.(*ScanEvent_Results); ok {
+			return x.Results
+		}
+	}
+	return nil
+}
+
+type isScanEvent_Event interface {
+	isScanEvent_Event()
+}
+
+type ScanEvent_Host struct {
+	Host *HostEvent `protobuf:"bytes,1,opt,name=host,proto3,oneof"`
+}
+
+type ScanEvent_Results struct {
+	Results *ScanResults `protobuf:"bytes,2,opt,name=results,proto3,oneof"`
+}
+
+func (*ScanEvent_Host) isScanEvent_Event() {}
+
+func (*ScanEvent_Results) isScanEvent_Event() {}
+
+type HostEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Kind   HostEvent_Kind         `protobuf:"varint,1,opt,name=kind,proto3,enum=ztc.v1.HostEvent_Kind" json:"kind,omitempty"`
+	HostId string                 `protobuf:"bytes,2,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Name   string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// CVSS score of a completed host.
+	Score float64 `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	// Why the audit of a failed host failed.
+	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// Hosts completed or failed so far, out of total.
+	Done          int32 `protobuf:"varint,6,opt,name=done,proto3" json:"done,omitempty"`
+	Total         int32 `protobuf:"varint,7,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostEvent) Reset() {
+	*x = HostEvent{}
+	mi := &file_ztc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostEvent) ProtoMessage() {}
+
+func (x *HostEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostEvent.ProtoReflect.Descriptor instead.
+func (*HostEvent) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HostEvent) GetKind() HostEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return HostEvent_KIND_UNSPECIFIED
+}
+
+func (x *HostEvent) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *HostEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HostEvent) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *HostEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *HostEvent) GetDone() int32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *HostEvent) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResultsRequest) Reset() {
+	*x = GetResultsRequest{}
+	mi := &file_ztc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResultsRequest) ProtoMessage() {}
+
+func (x *GetResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResultsRequest.ProtoReflect.Descriptor instead.
+func (*GetResultsRequest) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{3}
+}
+
+type ScanResults struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	HostsScanned       int32                  `protobuf:"varint,1,opt,name=hosts_scanned,json=hostsScanned,proto3" json:"hosts_scanned,omitempty"`
+	HostsWithVulns     int32                  `protobuf:"varint,2,opt,name=hosts_with_vulns,json=hostsWithVulns,proto3" json:"hosts_with_vulns,omitempty"`
+	VulnerablePackages int32                  `protobuf:"varint,3,opt,name=vulnerable_packages,json=vulnerablePackages,proto3" json:"vulnerable_packages,omitempty"`
+	MaxCvss            float64                `protobuf:"fixed64,4,opt,name=max_cvss,json=maxCvss,proto3" json:"max_cvss,omitempty"`
+	Hosts              []*HostEntry           `protobuf:"bytes,5,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Packages           []*PackageEntry        `protobuf:"bytes,6,rep,name=packages,proto3" json:"packages,omitempty"`
+	Bulletins          []*BulletinEntry       `protobuf:"bytes,7,rep,name=bulletins,proto3" json:"bulletins,omitempty"`
+	// Excluded host count per reason.
+	Excluded      map[string]int32 `protobuf:"bytes,8,rep,name=excluded,proto3" json:"excluded,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanResults) Reset() {
+	*x = ScanResults{}
+	mi := &file_ztc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanResults) ProtoMessage() {}
+
+func (x *ScanResults) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanResults.ProtoReflect.Descriptor instead.
+func (*ScanResults) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScanResults) GetHostsScanned() int32 {
+	if x != nil {
+		return x.HostsScanned
+	}
+	return 0
+}
+
+func (x *ScanResults) GetHostsWithVulns() int32 {
+	if x != nil {
+		return x.HostsWithVulns
+	}
+	return 0
+}
+
+func (x *ScanResults) GetVulnerablePackages() int32 {
+	if x != nil {
+		return x.VulnerablePackages
+	}
+	return 0
+}
+
+func (x *ScanResults) GetMaxCvss() float64 {
+	if x != nil {
+		return x.MaxCvss
+	}
+	return 0
+}
+
+func (x *ScanResults) GetHosts() []*HostEntry {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *ScanResults) GetPackages() []*PackageEntry {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+func (x *ScanResults) GetBulletins() []*BulletinEntry {
+	if x != nil {
+		return x.Bulletins
+	}
+	return nil
+}
+
+func (x *ScanResults) GetExcluded() map[string]int32 {
+	if x != nil {
+		return x.Excluded
+	}
+	return nil
+}
+
+type HostEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HostId        string                 `protobuf:"bytes,1,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Host          string                 `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	OsName        string                 `protobuf:"bytes,4,opt,name=os_name,json=osName,proto3" json:"os_name,omitempty"`
+	OsVersion     string                 `protobuf:"bytes,5,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"`
+	Score         float64                `protobuf:"fixed64,6,opt,name=score,proto3" json:"score,omitempty"`
+	CumulativeFix string                 `protobuf:"bytes,7,opt,name=cumulative_fix,json=cumulativeFix,proto3" json:"cumulative_fix,omitempty"`
+	Packages      []*PackageVuln         `protobuf:"bytes,8,rep,name=packages,proto3" json:"packages,omitempty"`
+	Bulletins     []*BulletinSummary     `protobuf:"bytes,9,rep,name=bulletins,proto3" json:"bulletins,omitempty"`
+	Groups        []*HostGroup           `protobuf:"bytes,10,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostEntry) Reset() {
+	*x = HostEntry{}
+	mi := &file_ztc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostEntry) ProtoMessage() {}
+
+func (x *HostEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostEntry.ProtoReflect.Descriptor instead.
+func (*HostEntry) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HostEntry) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *HostEntry) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *HostEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HostEntry) GetOsName() string {
+	if x != nil {
+		return x.OsName
+	}
+	return ""
+}
+
+func (x *HostEntry) GetOsVersion() string {
+	if x != nil {
+		return x.OsVersion
+	}
+	return ""
+}
+
+func (x *HostEntry) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *HostEntry) GetCumulativeFix() string {
+	if x != nil {
+		return x.CumulativeFix
+	}
+	return ""
+}
+
+func (x *HostEntry) GetPackages() []*PackageVuln {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+func (x *HostEntry) GetBulletins() []*BulletinSummary {
+	if x != nil {
+		return x.Bulletins
+	}
+	return nil
+}
+
+func (x *HostEntry) GetGroups() []*HostGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type HostGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostGroup) Reset() {
+	*x = HostGroup{}
+	mi := &file_ztc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostGroup) ProtoMessage() {}
+
+func (x *HostGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostGroup.ProtoReflect.Descriptor instead.
+func (*HostGroup) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *HostGroup) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *HostGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type PackageVuln struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Arch          string                 `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
+	Score         float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	Fix           string                 `protobuf:"bytes,5,opt,name=fix,proto3" json:"fix,omitempty"`
+	Bulletins     []string               `protobuf:"bytes,6,rep,name=bulletins,proto3" json:"bulletins,omitempty"`
+	Cves          []string               `protobuf:"bytes,7,rep,name=cves,proto3" json:"cves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackageVuln) Reset() {
+	*x = PackageVuln{}
+	mi := &file_ztc_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackageVuln) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackageVuln) ProtoMessage() {}
+
+func (x *PackageVuln) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackageVuln.ProtoReflect.Descriptor instead.
+func (*PackageVuln) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PackageVuln) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PackageVuln) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *PackageVuln) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *PackageVuln) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *PackageVuln) GetFix() string {
+	if x != nil {
+		return x.Fix
+	}
+	return ""
+}
+
+func (x *PackageVuln) GetBulletins() []string {
+	if x != nil {
+		return x.Bulletins
+	}
+	return nil
+}
+
+func (x *PackageVuln) GetCves() []string {
+	if x != nil {
+		return x.Cves
+	}
+	return nil
+}
+
+type BulletinSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Score         float64                `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	Cves          []string               `protobuf:"bytes,4,rep,name=cves,proto3" json:"cves,omitempty"`
+	Fix           string                 `protobuf:"bytes,5,opt,name=fix,proto3" json:"fix,omitempty"`
+	AffectedPkg   []string               `protobuf:"bytes,6,rep,name=affected_pkg,json=affectedPkg,proto3" json:"affected_pkg,omitempty"`
+	AffectedHosts []string               `protobuf:"bytes,7,rep,name=affected_hosts,json=affectedHosts,proto3" json:"affected_hosts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulletinSummary) Reset() {
+	*x = BulletinSummary{}
+	mi := &file_ztc_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulletinSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulletinSummary) ProtoMessage() {}
+
+func (x *BulletinSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulletinSummary.ProtoReflect.Descriptor instead.
+func (*BulletinSummary) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BulletinSummary) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BulletinSummary) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *BulletinSummary) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *BulletinSummary) GetCves() []string {
+	if x != nil {
+		return x.Cves
+	}
+	return nil
+}
+
+func (x *BulletinSummary) GetFix() string {
+	if x != nil {
+		return x.Fix
+	}
+	return ""
+}
+
+func (x *BulletinSummary) GetAffectedPkg() []string {
+	if x != nil {
+		return x.AffectedPkg
+	}
+	return nil
+}
+
+func (x *BulletinSummary) GetAffectedHosts() []string {
+	if x != nil {
+		return x.AffectedHosts
+	}
+	return nil
+}
+
+type PackageEntry struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version           string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Arch              string                 `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
+	Score             float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	Fix               string                 `protobuf:"bytes,5,opt,name=fix,proto3" json:"fix,omitempty"`
+	AffectedHosts     []string               `protobuf:"bytes,6,rep,name=affected_hosts,json=affectedHosts,proto3" json:"affected_hosts,omitempty"`
+	AffectedHostNames []string               `protobuf:"bytes,7,rep,name=affected_host_names,json=affectedHostNames,proto3" json:"affected_host_names,omitempty"`
+	Bulletins         []string               `protobuf:"bytes,8,rep,name=bulletins,proto3" json:"bulletins,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PackageEntry) Reset() {
+	*x = PackageEntry{}
+	mi := &file_ztc_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackageEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackageEntry) ProtoMessage() {}
+
+func (x *PackageEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackageEntry.ProtoReflect.Descriptor instead.
+func (*PackageEntry) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PackageEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PackageEntry) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *PackageEntry) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *PackageEntry) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *PackageEntry) GetFix() string {
+	if x != nil {
+		return x.Fix
+	}
+	return ""
+}
+
+func (x *PackageEntry) GetAffectedHosts() []string {
+	if x != nil {
+		return x.AffectedHosts
+	}
+	return nil
+}
+
+func (x *PackageEntry) GetAffectedHostNames() []string {
+	if x != nil {
+		return x.AffectedHostNames
+	}
+	return nil
+}
+
+func (x *PackageEntry) GetBulletins() []string {
+	if x != nil {
+		return x.Bulletins
+	}
+	return nil
+}
+
+type BulletinEntry struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type              string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Score             float64                `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	Cves              []string               `protobuf:"bytes,4,rep,name=cves,proto3" json:"cves,omitempty"`
+	Fix               string                 `protobuf:"bytes,5,opt,name=fix,proto3" json:"fix,omitempty"`
+	AffectedPkgs      []string               `protobuf:"bytes,6,rep,name=affected_pkgs,json=affectedPkgs,proto3" json:"affected_pkgs,omitempty"`
+	AffectedHosts     []string               `protobuf:"bytes,7,rep,name=affected_hosts,json=affectedHosts,proto3" json:"affected_hosts,omitempty"`
+	AffectedHostNames []string               `protobuf:"bytes,8,rep,name=affected_host_names,json=affectedHostNames,proto3" json:"affected_host_names,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BulletinEntry) Reset() {
+	*x = BulletinEntry{}
+	mi := &file_ztc_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulletinEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulletinEntry) ProtoMessage() {}
+
+func (x *BulletinEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulletinEntry.ProtoReflect.Descriptor instead.
+func (*BulletinEntry) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BulletinEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BulletinEntry) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *BulletinEntry) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *BulletinEntry) GetCves() []string {
+	if x != nil {
+		return x.Cves
+	}
+	return nil
+}
+
+func (x *BulletinEntry) GetFix() string {
+	if x != nil {
+		return x.Fix
+	}
+	return ""
+}
+
+func (x *BulletinEntry) GetAffectedPkgs() []string {
+	if x != nil {
+		return x.AffectedPkgs
+	}
+	return nil
+}
+
+func (x *BulletinEntry) GetAffectedHosts() []string {
+	if x != nil {
+		return x.AffectedHosts
+	}
+	return nil
+}
+
+func (x *BulletinEntry) GetAffectedHostNames() []string {
+	if x != nil {
+		return x.AffectedHostNames
+	}
+	return nil
+}
+
+type FixRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	BulletinId string                 `protobuf:"bytes,1,opt,name=bulletin_id,json=bulletinId,proto3" json:"bulletin_id,omitempty"`
+	HostId     string                 `protobuf:"bytes,2,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	HostName   string                 `protobuf:"bytes,3,opt,name=host_name,json=hostName,proto3" json:"host_name,omitempty"`
+	// Only plan the fix.
+	DryRun          bool   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	UseSsh          bool   `protobuf:"varint,5,opt,name=use_ssh,json=useSsh,proto3" json:"use_ssh,omitempty"`
+	SshUser         string `protobuf:"bytes,6,opt,name=ssh_user,json=sshUser,proto3" json:"ssh_user,omitempty"`
+	IncludeDisabled bool   `protobuf:"varint,7,opt,name=include_disabled,json=includeDisabled,proto3" json:"include_disabled,omitempty"`
+	// "", "packages" or "bulletins", as for ztc fix.
+	Scope      string `protobuf:"bytes,8,opt,name=scope,proto3" json:"scope,omitempty"`
+	AllowStale bool   `protobuf:"varint,9,opt,name=allow_stale,json=allowStale,proto3" json:"allow_stale,omitempty"`
+	AgentWait  bool   `protobuf:"varint,10,opt,name=agent_wait,json=agentWait,proto3" json:"agent_wait,omitempty"`
+	// Timeout of each waited agent command in seconds.
+	AgentTimeoutSeconds int32 `protobuf:"varint,11,opt,name=agent_timeout_seconds,json=agentTimeoutSeconds,proto3" json:"agent_timeout_seconds,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *FixRequest) Reset() {
+	*x = FixRequest{}
+	mi := &file_ztc_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FixRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FixRequest) ProtoMessage() {}
+
+func (x *FixRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FixRequest.ProtoReflect.Descriptor instead.
+func (*FixRequest) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FixRequest) GetBulletinId() string {
+	if x != nil {
+		return x.BulletinId
+	}
+	return ""
+}
+
+func (x *FixRequest) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *FixRequest) GetHostName() string {
+	if x != nil {
+		return x.HostName
+	}
+	return ""
+}
+
+func (x *FixRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *FixRequest) GetUseSsh() bool {
+	if x != nil {
+		return x.UseSsh
+	}
+	return false
+}
+
+func (x *FixRequest) GetSshUser() string {
+	if x != nil {
+		return x.SshUser
+	}
+	return ""
+}
+
+func (x *FixRequest) GetIncludeDisabled() bool {
+	if x != nil {
+		return x.IncludeDisabled
+	}
+	return false
+}
+
+func (x *FixRequest) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *FixRequest) GetAllowStale() bool {
+	if x != nil {
+		return x.AllowStale
+	}
+	return false
+}
+
+func (x *FixRequest) GetAgentWait() bool {
+	if x != nil {
+		return x.AgentWait
+	}
+	return false
+}
+
+func (x *FixRequest) GetAgentTimeoutSeconds() int32 {
+	if x != nil {
+		return x.AgentTimeoutSeconds
+	}
+	return 0
+}
+
+type FixResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Plan  *FixPlan               `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	// Unset for a dry run.
+	Results       *FixResults `protobuf:"bytes,2,opt,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FixResponse) Reset() {
+	*x = FixResponse{}
+	mi := &file_ztc_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FixResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FixResponse) ProtoMessage() {}
+
+func (x *FixResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FixResponse.ProtoReflect.Descriptor instead.
+func (*FixResponse) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FixResponse) GetPlan() *FixPlan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *FixResponse) GetResults() *FixResults {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type FixPlan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hosts         []*HostFixPlan         `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Packages      []string               `protobuf:"bytes,2,rep,name=packages,proto3" json:"packages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FixPlan) Reset() {
+	*x = FixPlan{}
+	mi := &file_ztc_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FixPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FixPlan) ProtoMessage() {}
+
+func (x *FixPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FixPlan.ProtoReflect.Descriptor instead.
+func (*FixPlan) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FixPlan) GetHosts() []*HostFixPlan {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *FixPlan) GetPackages() []string {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+type HostFixPlan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HostId        string                 `protobuf:"bytes,1,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Ip            string                 `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
+	AgentPort     string                 `protobuf:"bytes,4,opt,name=agent_port,json=agentPort,proto3" json:"agent_port,omitempty"`
+	Packages      []string               `protobuf:"bytes,5,rep,name=packages,proto3" json:"packages,omitempty"`
+	Command       string                 `protobuf:"bytes,6,opt,name=command,proto3" json:"command,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostFixPlan) Reset() {
+	*x = HostFixPlan{}
+	mi := &file_ztc_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostFixPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostFixPlan) ProtoMessage() {}
+
+func (x *HostFixPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostFixPlan.ProtoReflect.Descriptor instead.
+func (*HostFixPlan) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *HostFixPlan) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *HostFixPlan) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HostFixPlan) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *HostFixPlan) GetAgentPort() string {
+	if x != nil {
+		return x.AgentPort
+	}
+	return ""
+}
+
+func (x *HostFixPlan) GetPackages() []string {
+	if x != nil {
+		return x.Packages
+	}
+	return nil
+}
+
+func (x *HostFixPlan) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+type FixResults struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Successful    int32                  `protobuf:"varint,1,opt,name=successful,proto3" json:"successful,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Hosts         []*HostFixResult       `protobuf:"bytes,3,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FixResults) Reset() {
+	*x = FixResults{}
+	mi := &file_ztc_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FixResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FixResults) ProtoMessage() {}
+
+func (x *FixResults) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FixResults.ProtoReflect.Descriptor instead.
+func (*FixResults) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FixResults) GetSuccessful() int32 {
+	if x != nil {
+		return x.Successful
+	}
+	return 0
+}
+
+func (x *FixResults) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *FixResults) GetHosts() []*HostFixResult {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+type HostFixResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HostId        string                 `protobuf:"bytes,1,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	Output        string                 `protobuf:"bytes,4,opt,name=output,proto3" json:"output,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostFixResult) Reset() {
+	*x = HostFixResult{}
+	mi := &file_ztc_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostFixResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostFixResult) ProtoMessage() {}
+
+func (x *HostFixResult) ProtoReflect() protoreflect.Message {
+	mi := &file_ztc_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostFixResult.ProtoReflect.Descriptor instead.
+func (*HostFixResult) Descriptor() ([]byte, []int) {
+	return file_ztc_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *HostFixResult) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *HostFixResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HostFixResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *HostFixResult) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *HostFixResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_ztc_proto protoreflect.FileDescriptor
+
+const file_ztc_proto_rawDesc = "" +
+	"\n" +
+	"\tztc.proto\x12\x06ztc.v1\"R\n" +
+	"\vScanRequest\x12\x14\n" +
+	"\x05hosts\x18\x01 \x03(\tR\x05hosts\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x17\n" +
+	"\ano_push\x18\x03 \x01(\bR\x06noPush\"n\n" +
+	"\tScanEvent\x12'\n" +
+	"\x04host\x18\x01 \x01(\v2\x11.ztc.v1.HostEventH\x00R\x04host\x12/\n" +
+	"\aresults\x18\x02 \x01(\v2\x13.ztc.v1.ScanResultsH\x00R\aresultsB\a\n" +
+	"\x05event\"\x80\x02\n" +
+	"\tHostEvent\x12*\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x16.ztc.v1.HostEvent.KindR\x04kind\x12\x17\n" +
+	"\ahost_id\x18\x02 \x01(\tR\x06hostId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x12\n" +
+	"\x04done\x18\x06 \x01(\x05R\x04done\x12\x14\n" +
+	"\x05total\x18\a \x01(\x05R\x05total\"D\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\v\n" +
+	"\aSTARTED\x10\x01\x12\r\n" +
+	"\tCOMPLETED\x10\x02\x12\n" +
+	"\n" +
+	"\x06FAILED\x10\x03\"\x13\n" +
+	"\x11GetResultsRequest\"\xb4\x03\n" +
+	"\vScanResults\x12#\n" +
+	"\rhosts_scanned\x18\x01 \x01(\x05R\fhostsScanned\x12(\n" +
+	"\x10hosts_with_vulns\x18\x02 \x01(\x05R\x0ehostsWithVulns\x12/\n" +
+	"\x13vulnerable_packages\x18\x03 \x01(\x05R\x12vulnerablePackages\x12\x19\n" +
+	"\bmax_cvss\x18\x04 \x01(\x01R\amaxCvss\x12'\n" +
+	"\x05hosts\x18\x05 \x03(\v2\x11.ztc.v1.HostEntryR\x05hosts\x120\n" +
+	"\bpackages\x18\x06 \x03(\v2\x14.ztc.v1.PackageEntryR\bpackages\x123\n" +
+	"\tbulletins\x18\a \x03(\v2\x15.ztc.v1.BulletinEntryR\tbulletins\x12=\n" +
+	"\bexcluded\x18\b \x03(\v2!.ztc.v1.ScanResults.ExcludedEntryR\bexcluded\x1a;\n" +
+	"\rExcludedEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xd4\x02\n" +
+	"\tHostEntry\x12\x17\n" +
+	"\ahost_id\x18\x01 \x01(\tR\x06hostId\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x17\n" +
+	"\aos_name\x18\x04 \x01(\tR\x06osName\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\x05 \x01(\tR\tosVersion\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x01R\x05score\x12%\n" +
+	"\x0ecumulative_fix\x18\a \x01(\tR\rcumulativeFix\x12/\n" +
+	"\bpackages\x18\b \x03(\v2\x13.ztc.v1.PackageVulnR\bpackages\x125\n" +
+	"\tbulletins\x18\t \x03(\v2\x17.ztc.v1.BulletinSummaryR\tbulletins\x12)\n" +
+	"\x06groups\x18\n" +
+	" \x03(\v2\x11.ztc.v1.HostGroupR\x06groups\":\n" +
+	"\tHostGroup\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xa9\x01\n" +
+	"\vPackageVuln\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
+	"\x04arch\x18\x03 \x01(\tR\x04arch\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x10\n" +
+	"\x03fix\x18\x05 \x01(\tR\x03fix\x12\x1c\n" +
+	"\tbulletins\x18\x06 \x03(\tR\tbulletins\x12\x12\n" +
+	"\x04cves\x18\a \x03(\tR\x04cves\"\xbb\x01\n" +
+	"\x0fBulletinSummary\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\x12\x12\n" +
+	"\x04cves\x18\x04 \x03(\tR\x04cves\x12\x10\n" +
+	"\x03fix\x18\x05 \x01(\tR\x03fix\x12!\n" +
+	"\faffected_pkg\x18\x06 \x03(\tR\vaffectedPkg\x12%\n" +
+	"\x0eaffected_hosts\x18\a \x03(\tR\raffectedHosts\"\xed\x01\n" +
+	"\fPackageEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
+	"\x04arch\x18\x03 \x01(\tR\x04arch\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\x12\x10\n" +
+	"\x03fix\x18\x05 \x01(\tR\x03fix\x12%\n" +
+	"\x0eaffected_hosts\x18\x06 \x03(\tR\raffectedHosts\x12.\n" +
+	"\x13affected_host_names\x18\a \x03(\tR\x11affectedHostNames\x12\x1c\n" +
+	"\tbulletins\x18\b \x03(\tR\tbulletins\"\xeb\x01\n" +
+	"\rBulletinEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\x12\x12\n" +
+	"\x04cves\x18\x04 \x03(\tR\x04cves\x12\x10\n" +
+	"\x03fix\x18\x05 \x01(\tR\x03fix\x12#\n" +
+	"\raffected_pkgs\x18\x06 \x03(\tR\faffectedPkgs\x12%\n" +
+	"\x0eaffected_hosts\x18\a \x03(\tR\raffectedHosts\x12.\n" +
+	"\x13affected_host_names\x18\b \x03(\tR\x11affectedHostNames\"\xe5\x02\n" +
+	"\n" +
+	"FixRequest\x12\x1f\n" +
+	"\vbulletin_id\x18\x01 \x01(\tR\n" +
+	"bulletinId\x12\x17\n" +
+	"\ahost_id\x18\x02 \x01(\tR\x06hostId\x12\x1b\n" +
+	"\thost_name\x18\x03 \x01(\tR\bhostName\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x12\x17\n" +
+	"\ause_ssh\x18\x05 \x01(\bR\x06useSsh\x12\x19\n" +
+	"\bssh_user\x18\x06 \x01(\tR\asshUser\x12)\n" +
+	"\x10include_disabled\x18\a \x01(\bR\x0fincludeDisabled\x12\x14\n" +
+	"\x05scope\x18\b \x01(\tR\x05scope\x12\x1f\n" +
+	"\vallow_stale\x18\t \x01(\bR\n" +
+	"allowStale\x12\x1d\n" +
+	"\n" +
+	"agent_wait\x18\n" +
+	" \x01(\bR\tagentWait\x122\n" +
+	"\x15agent_timeout_seconds\x18\v \x01(\x05R\x13agentTimeoutSeconds\"`\n" +
+	"\vFixResponse\x12#\n" +
+	"\x04plan\x18\x01 \x01(\v2\x0f.ztc.v1.FixPlanR\x04plan\x12,\n" +
+	"\aresults\x18\x02 \x01(\v2\x12.ztc.v1.FixResultsR\aresults\"P\n" +
+	"\aFixPlan\x12)\n" +
+	"\x05hosts\x18\x01 \x03(\v2\x13.ztc.v1.HostFixPlanR\x05hosts\x12\x1a\n" +
+	"\bpackages\x18\x02 \x03(\tR\bpackages\"\x9f\x01\n" +
+	"\vHostFixPlan\x12\x17\n" +
+	"\ahost_id\x18\x01 \x01(\tR\x06hostId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02ip\x18\x03 \x01(\tR\x02ip\x12\x1d\n" +
+	"\n" +
+	"agent_port\x18\x04 \x01(\tR\tagentPort\x12\x1a\n" +
+	"\bpackages\x18\x05 \x03(\tR\bpackages\x12\x18\n" +
+	"\acommand\x18\x06 \x01(\tR\acommand\"q\n" +
+	"\n" +
+	"FixResults\x12\x1e\n" +
+	"\n" +
+	"successful\x18\x01 \x01(\x05R\n" +
+	"successful\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12+\n" +
+	"\x05hosts\x18\x03 \x03(\v2\x15.ztc.v1.HostFixResultR\x05hosts\"\x84\x01\n" +
+	"\rHostFixResult\x12\x17\n" +
+	"\ahost_id\x18\x01 \x01(\tR\x06hostId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x16\n" +
+	"\x06output\x18\x04 \x01(\tR\x06output\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error2\xaf\x01\n" +
+	"\rThreatControl\x120\n" +
+	"\x04Scan\x12\x13.ztc.v1.ScanRequest\x1a\x11.ztc.v1.ScanEvent0\x01\x12<\n" +
+	"\n" +
+	"GetResults\x12\x19.ztc.v1.GetResultsRequest\x1a\x13.ztc.v1.ScanResults\x12.\n" +
+	"\x03Fix\x12\x12.ztc.v1.FixRequest\x1a\x13.ztc.v1.FixResponseBBZ@github.com/kidoz/zabbix-threat-control-go/internal/grpcapi/ztcpbb\x06proto3"
+
+var (
+	file_ztc_proto_rawDescOnce sync.Once
+	file_ztc_proto_rawDescData []byte
+)
+
+func file_ztc_proto_rawDescGZIP() []byte {
+	file_ztc_proto_rawDescOnce.Do(func() {
+		file_ztc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ztc_proto_rawDesc), len(file_ztc_proto_rawDesc)))
+	})
+	return file_ztc_proto_rawDescData
+}
+
+var file_ztc_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_ztc_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_ztc_proto_goTypes = []any{
+	(HostEvent_Kind)(0),       // 0: ztc.v1.HostEvent.Kind
+	(*ScanRequest)(nil),       // 1: ztc.v1.ScanRequest
+	(*ScanEvent)(nil),         // 2: ztc.v1.ScanEvent
+	(*HostEvent)(nil),         // 3: ztc.v1.HostEvent
+	(*GetResultsRequest)(nil), // 4: ztc.v1.GetResultsRequest
+	(*ScanResults)(nil),       // 5: ztc.v1.ScanResults
+	(*HostEntry)(nil),         // 6: ztc.v1.HostEntry
+	(*HostGroup)(nil),         // 7: ztc.v1.HostGroup
+	(*PackageVuln)(nil),       // 8: ztc.v1.PackageVuln
+	(*BulletinSummary)(nil),   // 9: ztc.v1.BulletinSummary
+	(*PackageEntry)(nil),      // 10: ztc.v1.PackageEntry
+	(*BulletinEntry)(nil),     // 11: ztc.v1.BulletinEntry
+	(*FixRequest)(nil),        // 12: ztc.v1.FixRequest
+	(*FixResponse)(nil),       // 13: ztc.v1.FixResponse
+	(*FixPlan)(nil),           // 14: ztc.v1.FixPlan
+	(*HostFixPlan)(nil),       // 15: ztc.v1.HostFixPlan
+	(*FixResults)(nil),        // 16: ztc.v1.FixResults
+	(*HostFixResult)(nil),     // 17: ztc.v1.HostFixResult
+	nil,                       // 18: ztc.v1.ScanResults.ExcludedEntry
+}
+var file_ztc_proto_depIdxs = []int32{
+	3,  // 0: ztc.v1.ScanEvent.host:type_name -> ztc.v1.HostEvent
+	5,  // 1: ztc.v1.ScanEvent.results:type_name -> ztc.v1.ScanResults
+	0,  // 2: ztc.v1.HostEvent.kind:type_name -> ztc.v1.HostEvent.Kind
+	6,  // 3: ztc.v1.ScanResults.hosts:type_name -> ztc.v1.HostEntry
+	10, // 4: ztc.v1.ScanResults.packages:type_name -> ztc.v1.PackageEntry
+	11, // 5: ztc.v1.ScanResults.bulletins:type_name -> ztc.v1.BulletinEntry
+	18, // 6: ztc.v1.ScanResults.excluded:type_name -> ztc.v1.ScanResults.ExcludedEntry
+	8,  // 7: ztc.v1.HostEntry.packages:type_name -> ztc.v1.PackageVuln
+	9,  // 8: ztc.v1.HostEntry.bulletins:type_name -> ztc.v1.BulletinSummary
+	7,  // 9: ztc.v1.HostEntry.groups:type_name -> ztc.v1.HostGroup
+	14, // 10: ztc.v1.FixResponse.plan:type_name -> ztc.v1.FixPlan
+	16, // 11: ztc.v1.FixResponse.results:type_name -> ztc.v1.FixResults
+	15, // 12: ztc.v1.FixPlan.hosts:type_name -> ztc.v1.HostFixPlan
+	17, // 13: ztc.v1.FixResults.hosts:type_name -> ztc.v1.HostFixResult
+	1,  // 14: ztc.v1.ThreatControl.Scan:input_type -> ztc.v1.ScanRequest
+	4,  // 15: ztc.v1.ThreatControl.GetResults:input_type -> ztc.v1.GetResultsRequest
+	12, // 16: ztc.v1.ThreatControl.Fix:input_type -> ztc.v1.FixRequest
+	2,  // 17: ztc.v1.ThreatControl.Scan:output_type -> ztc.v1.ScanEvent
+	5,  // 18: ztc.v1.ThreatControl.GetResults:output_type -> ztc.v1.ScanResults
+	13, // 19: ztc.v1.ThreatControl.Fix:output_type -> ztc.v1.FixResponse
+	17, // [17:20] is the sub-list for method output_type
+	14, // [14:17] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_ztc_proto_init() }
+func file_ztc_proto_init() {
+	if File_ztc_proto != nil {
+		return
+	}
+	file_ztc_proto_msgTypes[1].OneofWrappers = []any{
+		(*ScanEvent_Host)(nil),
+		(*ScanEvent_Results)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ztc_proto_rawDesc), len(file_ztc_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ztc_proto_goTypes,
+		DependencyIndexes: file_ztc_proto_depIdxs,
+		EnumInfos:         file_ztc_proto_enumTypes,
+		MessageInfos:      file_ztc_proto_msgTypes,
+	}.Build()
+	File_ztc_proto = out.File
+	file_ztc_proto_goTypes = nil
+	file_ztc_proto_depIdxs = nil
+}
@@ -0,0 +1,70 @@
+// Package remediation builds the package upgrade commands that ztc fix runs
+// and the scanner shows in the {#H.FIXCMD} LLD macro, so both agree.
+package remediation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FixCommand returns the command that upgrades packages on a host with the
+// given OS, or a full system update when packages is empty. An invalid
+// package name is reported along with the full system update command.
+func FixCommand(osName string, packages []string) (string, error) {
+	err := SanitizePackages(packages)
+	if err != nil {
+		packages = nil
+	}
+	return osFixCommand(osName, packages), err
+}
+
+// osFixCommand picks the package manager command for osName.
+func osFixCommand(osName string, packages []string) string {
+	osName = strings.ToLower(osName)
+
+	switch {
+	case strings.Contains(osName, "ubuntu") || strings.Contains(osName, "debian"):
+		return generateDebianFixCommand(packages)
+	case strings.Contains(osName, "centos") || strings.Contains(osName, "red hat") || strings.Contains(osName, "redhat") || strings.Contains(osName, "rhel"),
+		strings.Contains(osName, "rocky") || strings.Contains(osName, "alma") || strings.Contains(osName, "cloudlinux"):
+		return generateRHELFixCommand(packages)
+	case strings.Contains(osName, "amazon"):
+		return generateAmazonFixCommand(packages)
+	default:
+		// Default to apt for unknown distros
+		return generateDebianFixCommand(packages)
+	}
+}
+
+func generateDebianFixCommand(packages []string) string {
+	if len(packages) == 0 {
+		return "apt-get update && apt-get upgrade -y"
+	}
+	pkgList := quotePackages(packages)
+	return fmt.Sprintf("apt-get update && apt-get install -y --only-upgrade %s", pkgList)
+}
+
+func generateRHELFixCommand(packages []string) string {
+	if len(packages) == 0 {
+		return "yum update -y"
+	}
+	pkgList := quotePackages(packages)
+	return fmt.Sprintf("yum update -y %s", pkgList)
+}
+
+func generateAmazonFixCommand(packages []string) string {
+	if len(packages) == 0 {
+		return "yum update -y"
+	}
+	pkgList := quotePackages(packages)
+	return fmt.Sprintf("yum update -y %s", pkgList)
+}
+
+// quotePackages wraps each package name in single quotes for defense-in-depth.
+func quotePackages(packages []string) string {
+	quoted := make([]string, len(packages))
+	for i, pkg := range packages {
+		quoted[i] = "'" + pkg + "'"
+	}
+	return strings.Join(quoted, " ")
+}
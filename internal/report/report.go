@@ -0,0 +1,129 @@
+// Package report renders per-host vulnerability reports suitable for
+// attaching to remediation tickets.
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+// Format selects the report output format.
+type Format string
+
+// Supported report formats.
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat validates a user-supplied format name.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unsupported report format %q (want markdown or html)", s)
+	}
+}
+
+// hostReport is the data passed to the report templates.
+type hostReport struct {
+	Host        scanner.HostEntry
+	GeneratedAt string
+}
+
+var funcs = map[string]any{
+	"join":  strings.Join,
+	"score": func(f float64) string { return fmt.Sprintf("%.1f", f) },
+}
+
+const markdownTmpl = `# Vulnerability report: {{.Host.Name}}
+
+| | |
+|---|---|
+| Host | {{.Host.Host}} (ID {{.Host.HostID}}) |
+| OS | {{.Host.OSName}} {{.Host.OSVersion}} |
+| CVSS score | {{score .Host.Score}} |
+| Vulnerable packages | {{len .Host.Packages}} |
+| Generated | {{.GeneratedAt}} |
+
+## Vulnerable packages
+{{if .Host.Packages}}
+| Package | Version | Arch | CVSS | CVEs | Fix |
+|---|---|---|---|---|---|
+{{- range .Host.Packages}}
+| {{.Name}} | {{.Version}} | {{.Arch}} | {{score .Score}} | {{join .CVEs ", "}} | ` + "`{{.Fix}}`" + ` |
+{{- end}}
+{{else}}
+No vulnerable packages found.
+{{end}}
+## Cumulative fix
+{{if .Host.CumulativeFix}}
+` + "```sh\n{{.Host.CumulativeFix}}\n```" + `
+{{else}}
+Nothing to fix.
+{{end -}}
+`
+
+const htmlTmpl = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Vulnerability report: {{.Host.Name}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Vulnerability report: {{.Host.Name}}</h1>
+<table>
+<tr><th>Host</th><td>{{.Host.Host}} (ID {{.Host.HostID}})</td></tr>
+<tr><th>OS</th><td>{{.Host.OSName}} {{.Host.OSVersion}}</td></tr>
+<tr><th>CVSS score</th><td>{{score .Host.Score}}</td></tr>
+<tr><th>Vulnerable packages</th><td>{{len .Host.Packages}}</td></tr>
+<tr><th>Generated</th><td>{{.GeneratedAt}}</td></tr>
+</table>
+<h2>Vulnerable packages</h2>
+{{if .Host.Packages}}<table>
+<tr><th>Package</th><th>Version</th><th>Arch</th><th>CVSS</th><th>CVEs</th><th>Fix</th></tr>
+{{range .Host.Packages}}<tr><td>{{.Name}}</td><td>{{.Version}}</td><td>{{.Arch}}</td><td>{{score .Score}}</td><td>{{join .CVEs ", "}}</td><td><code>{{.Fix}}</code></td></tr>
+{{end}}</table>
+{{else}}<p>No vulnerable packages found.</p>
+{{end}}<h2>Cumulative fix</h2>
+{{if .Host.CumulativeFix}}<pre><code>{{.Host.CumulativeFix}}</code></pre>
+{{else}}<p>Nothing to fix.</p>
+{{end}}</body>
+</html>
+`
+
+var (
+	mdTemplate   = template.Must(template.New("markdown").Funcs(funcs).Parse(markdownTmpl))
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(htmlTmpl))
+)
+
+// WriteHost renders the report for a single scanned host.
+func WriteHost(w io.Writer, host scanner.HostEntry, format Format, now time.Time) error {
+	data := hostReport{
+		Host:        host,
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+	}
+
+	switch format {
+	case FormatMarkdown:
+		return mdTemplate.Execute(w, data)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, data)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+func testHost() scanner.HostEntry {
+	return scanner.HostEntry{
+		HostID:        "10084",
+		Host:          "web-01",
+		Name:          "Web <01>",
+		OSName:        "ubuntu",
+		OSVersion:     "22.04",
+		Score:         7.5,
+		CumulativeFix: "apt-get --assume-yes install --only-upgrade openssl",
+		Packages: []scanner.PackageVuln{
+			{Name: "openssl", Version: "3.0.2", Arch: "amd64", Score: 7.5, Fix: "apt-get install openssl", CVEs: []string{"CVE-2023-0001", "CVE-2023-0002"}},
+		},
+	}
+}
+
+func TestWriteHost_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHost(&buf, testHost(), FormatMarkdown, time.Unix(0, 0)); err != nil {
+		t.Fatalf("WriteHost() error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Vulnerability report: Web <01>",
+		"| OS | ubuntu 22.04 |",
+		"| CVSS score | 7.5 |",
+		"| openssl | 3.0.2 | amd64 | 7.5 | CVE-2023-0001, CVE-2023-0002 | `apt-get install openssl` |",
+		"apt-get --assume-yes install --only-upgrade openssl",
+		"1970-01-01T00:00:00Z",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteHost_HTMLEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHost(&buf, testHost(), FormatHTML, time.Unix(0, 0)); err != nil {
+		t.Fatalf("WriteHost() error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Web &lt;01&gt;") {
+		t.Errorf("expected escaped host name in HTML:\n%s", out)
+	}
+	if !strings.Contains(out, "<td>openssl</td>") {
+		t.Errorf("expected package row in HTML:\n%s", out)
+	}
+}
+
+func TestWriteHost_NoPackages(t *testing.T) {
+	host := testHost()
+	host.Packages = nil
+	host.CumulativeFix = ""
+
+	var buf bytes.Buffer
+	if err := WriteHost(&buf, host, FormatMarkdown, time.Now()); err != nil {
+		t.Fatalf("WriteHost() error: %v", err)
+	}
+	for _, want := range []string{"No vulnerable packages found.", "Nothing to fix."} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{"markdown", FormatMarkdown, false},
+		{"md", FormatMarkdown, false},
+		{"HTML", FormatHTML, false},
+		{"pdf", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
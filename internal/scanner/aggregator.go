@@ -1,11 +1,15 @@
 package scanner
 
 import (
+	"slices"
 	"sort"
+	"sync"
 )
 
-// Aggregator aggregates vulnerability data across hosts
+// Aggregator aggregates vulnerability data across hosts. It is safe for
+// concurrent use, so results can be read while hosts are still being added.
 type Aggregator struct {
+	mu        sync.Mutex
 	hosts     []HostEntry
 	packages  map[string]*PackageEntry
 	bulletins map[string]*BulletinEntry
@@ -21,6 +25,8 @@ func NewAggregator() *Aggregator {
 
 // Reset clears accumulated data for a fresh scan.
 func (a *Aggregator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.hosts = nil
 	a.packages = make(map[string]*PackageEntry)
 	a.bulletins = make(map[string]*BulletinEntry)
@@ -28,58 +34,74 @@ func (a *Aggregator) Reset() {
 
 // AddHost adds a host's vulnerability data to the aggregator
 func (a *Aggregator) AddHost(entry HostEntry) {
-	a.hosts = append(a.hosts, entry)
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	// Aggregate packages (keyed by name|version|arch to avoid merging
-	// different-arch packages with the same name+version).
+	a.hosts = append(a.hosts, entry)
 	for _, pkg := range entry.Packages {
-		key := pkg.Name + "|" + pkg.Version + "|" + pkg.Arch
-		if _, exists := a.packages[key]; !exists {
-			a.packages[key] = &PackageEntry{
-				Name:    pkg.Name,
-				Version: pkg.Version,
-				Arch:    pkg.Arch,
-				Score:   pkg.Score,
-				Fix:     pkg.Fix,
-			}
-		}
-		a.packages[key].AffectedHosts = appendUnique(a.packages[key].AffectedHosts, entry.HostID)
-		a.packages[key].AffectedHostNames = appendUnique(a.packages[key].AffectedHostNames, entry.Name)
-		a.packages[key].Bulletins = appendUniqueSlice(a.packages[key].Bulletins, pkg.Bulletins)
+		// Keyed by name|version|arch to avoid merging different-arch
+		// packages with the same name+version.
+		a.addPackage(pkg.Name+"|"+pkg.Version+"|"+pkg.Arch, pkg, entry)
+	}
+	for _, bulletin := range entry.Bulletins {
+		a.addBulletin(bulletin, entry)
+	}
+}
 
-		// Update score if higher
-		if pkg.Score > a.packages[key].Score {
-			a.packages[key].Score = pkg.Score
+// addPackage merges one of host's packages. The caller holds a.mu.
+func (a *Aggregator) addPackage(key string, pkg PackageVuln, host HostEntry) {
+	p, exists := a.packages[key]
+	if !exists {
+		p = &PackageEntry{
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Arch:    pkg.Arch,
+			Score:   pkg.Score,
+			Fix:     pkg.Fix,
 		}
+		a.packages[key] = p
 	}
+	p.AffectedHosts = appendUnique(p.AffectedHosts, host.HostID)
+	p.AffectedHostNames = appendUnique(p.AffectedHostNames, host.Name)
+	p.Bulletins = appendUniqueSlice(p.Bulletins, pkg.Bulletins)
 
-	// Aggregate bulletins
-	for _, bulletin := range entry.Bulletins {
-		if _, exists := a.bulletins[bulletin.ID]; !exists {
-			a.bulletins[bulletin.ID] = &BulletinEntry{
-				ID:    bulletin.ID,
-				Type:  bulletin.Type,
-				Score: bulletin.Score,
-				CVEs:  bulletin.CVEs,
-				Fix:   bulletin.Fix,
-			}
-		}
-		a.bulletins[bulletin.ID].AffectedHosts = appendUnique(a.bulletins[bulletin.ID].AffectedHosts, entry.HostID)
-		a.bulletins[bulletin.ID].AffectedHostNames = appendUnique(a.bulletins[bulletin.ID].AffectedHostNames, entry.Name)
-		a.bulletins[bulletin.ID].AffectedPkgs = appendUniqueSlice(a.bulletins[bulletin.ID].AffectedPkgs, bulletin.AffectedPkg)
+	// Update score if higher
+	if pkg.Score > p.Score {
+		p.Score = pkg.Score
+	}
+}
 
-		// Update score if higher
-		if bulletin.Score > a.bulletins[bulletin.ID].Score {
-			a.bulletins[bulletin.ID].Score = bulletin.Score
+// addBulletin merges one of host's bulletins. The caller holds a.mu.
+func (a *Aggregator) addBulletin(bulletin BulletinSummary, host HostEntry) {
+	b, exists := a.bulletins[bulletin.ID]
+	if !exists {
+		b = &BulletinEntry{
+			ID:    bulletin.ID,
+			Type:  bulletin.Type,
+			Score: bulletin.Score,
+			CVEs:  bulletin.CVEs,
+			Fix:   bulletin.Fix,
 		}
+		a.bulletins[bulletin.ID] = b
+	}
+	b.AffectedHosts = appendUnique(b.AffectedHosts, host.HostID)
+	b.AffectedHostNames = appendUnique(b.AffectedHostNames, host.Name)
+	b.AffectedPkgs = appendUniqueSlice(b.AffectedPkgs, bulletin.AffectedPkg)
+
+	// Update score if higher
+	if bulletin.Score > b.Score {
+		b.Score = bulletin.Score
 	}
 }
 
-// GetResults returns the aggregated results
+// GetResults returns a snapshot of the aggregated results
 func (a *Aggregator) GetResults() *ScanResults {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	results := &ScanResults{
 		HostsScanned: len(a.hosts),
-		Hosts:        a.hosts,
+		Hosts:        slices.Clone(a.hosts),
 	}
 
 	// Count vulnerable hosts and find max CVSS
@@ -94,7 +116,11 @@ func (a *Aggregator) GetResults() *ScanResults {
 
 	// Convert packages map to slice
 	for _, pkg := range a.packages {
-		results.Packages = append(results.Packages, *pkg)
+		p := *pkg
+		p.AffectedHosts = slices.Clone(pkg.AffectedHosts)
+		p.AffectedHostNames = slices.Clone(pkg.AffectedHostNames)
+		p.Bulletins = slices.Clone(pkg.Bulletins)
+		results.Packages = append(results.Packages, p)
 		results.VulnerablePackages++
 	}
 
@@ -105,7 +131,11 @@ func (a *Aggregator) GetResults() *ScanResults {
 
 	// Convert bulletins map to slice
 	for _, bulletin := range a.bulletins {
-		results.Bulletins = append(results.Bulletins, *bulletin)
+		b := *bulletin
+		b.AffectedHosts = slices.Clone(bulletin.AffectedHosts)
+		b.AffectedHostNames = slices.Clone(bulletin.AffectedHostNames)
+		b.AffectedPkgs = slices.Clone(bulletin.AffectedPkgs)
+		results.Bulletins = append(results.Bulletins, b)
 	}
 
 	// Sort bulletins by score (descending)
@@ -118,6 +148,9 @@ func (a *Aggregator) GetResults() *ScanResults {
 
 // GetStatistics returns aggregated statistics
 func (a *Aggregator) GetStatistics() Statistics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	stats := Statistics{
 		TotalHosts:     len(a.hosts),
 		TotalPackages:  len(a.packages),
@@ -151,16 +184,24 @@ func (a *Aggregator) GetStatistics() Statistics {
 		stats.Histogram[bucket]++
 	}
 
-	// Count unique CVEs
+	// Count unique CVEs and how many bulletins reference each
+	bulletinsPerCVE := make(map[string]int)
 	for _, bulletin := range a.bulletins {
+		seen := make(map[string]bool, len(bulletin.CVEs))
 		for _, cve := range bulletin.CVEs {
 			cveSet[cve] = true
+			if !seen[cve] {
+				seen[cve] = true
+				bulletinsPerCVE[cve]++
+			}
 		}
 	}
 	stats.TotalCVEs = len(cveSet)
+	stats.TopCVEs = topCVEs(bulletinsPerCVE, TopCVELimit)
 
 	// Calculate average, min, median over ALL hosts (matching Python).
 	// Python uses score_list = [0] as fallback when empty → all zeros.
+	stats.FleetRisk = totalScore
 	if len(scores) > 0 {
 		stats.AvgCVSS = totalScore / float64(len(scores))
 
@@ -178,6 +219,59 @@ func (a *Aggregator) GetStatistics() Statistics {
 	return stats
 }
 
+// GetGroupStatistics returns Statistics for each host group, computed over
+// the group's hosts only, ordered by group name. A host in several groups
+// counts toward each of them.
+func (a *Aggregator) GetGroupStatistics() []GroupStatistics {
+	a.mu.Lock()
+	groups := make(map[string]*Aggregator)
+	names := make(map[string]string)
+	for _, host := range a.hosts {
+		for _, g := range host.Groups {
+			if groups[g.GroupID] == nil {
+				groups[g.GroupID] = NewAggregator()
+				names[g.GroupID] = g.Name
+			}
+			groups[g.GroupID].AddHost(host)
+		}
+	}
+	a.mu.Unlock()
+
+	stats := make([]GroupStatistics, 0, len(groups))
+	for id, agg := range groups {
+		stats = append(stats, GroupStatistics{GroupID: id, Name: names[id], Statistics: agg.GetStatistics()})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Name != stats[j].Name {
+			return stats[i].Name < stats[j].Name
+		}
+		return stats[i].GroupID < stats[j].GroupID
+	})
+	return stats
+}
+
+// TopCVELimit is the number of entries kept in Statistics.TopCVEs.
+const TopCVELimit = 10
+
+// topCVEs returns the n CVEs with the highest bulletin counts, ties broken
+// by CVE ID so the order is stable between runs.
+func topCVEs(counts map[string]int, n int) []CVECount {
+	top := make([]CVECount, 0, len(counts))
+	for cve, count := range counts {
+		top = append(top, CVECount{CVE: cve, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].CVE < top[j].CVE
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
 // appendUnique appends a value to a slice if it doesn't already exist
 func appendUnique(slice []string, value string) []string {
 	for _, v := range slice {
@@ -1,8 +1,12 @@
 package scanner
 
 import (
+	"fmt"
 	"math"
+	"sync"
 	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
 )
 
 func TestAppendUnique(t *testing.T) {
@@ -215,6 +219,9 @@ func TestAggregator_GetStatistics(t *testing.T) {
 	if math.Abs(stats.AvgCVSS-expectedAvg) > 0.001 {
 		t.Errorf("AvgCVSS = %f, want %f", stats.AvgCVSS, expectedAvg)
 	}
+	if math.Abs(stats.FleetRisk-12.5) > 0.001 {
+		t.Errorf("FleetRisk = %f, want 12.5", stats.FleetRisk)
+	}
 	// CVEs: CVE-2021-1234, CVE-2021-5678, CVE-2021-9999 = 3 unique
 	if stats.TotalCVEs != 3 {
 		t.Errorf("TotalCVEs = %d, want 3", stats.TotalCVEs)
@@ -468,3 +475,185 @@ func TestAggregator_Reset(t *testing.T) {
 		t.Errorf("after Reset, TotalPackages = %d, want 0", stats.TotalPackages)
 	}
 }
+
+func TestAggregator_GetStatistics_TopCVEs(t *testing.T) {
+	agg := NewAggregator()
+	agg.AddHost(HostEntry{
+		HostID: "1",
+		Bulletins: []BulletinSummary{
+			{ID: "USN-1", CVEs: []string{"CVE-A", "CVE-B"}},
+			{ID: "USN-2", CVEs: []string{"CVE-A", "CVE-A"}}, // duplicate within one bulletin counts once
+			{ID: "USN-3", CVEs: []string{"CVE-A", "CVE-C"}},
+		},
+	})
+	agg.AddHost(HostEntry{
+		HostID: "2",
+		Bulletins: []BulletinSummary{
+			{ID: "USN-1", CVEs: []string{"CVE-A", "CVE-B"}}, // same bulletin on another host
+			{ID: "USN-4", CVEs: []string{"CVE-B"}},
+		},
+	})
+
+	stats := agg.GetStatistics()
+	want := []CVECount{{"CVE-A", 3}, {"CVE-B", 2}, {"CVE-C", 1}}
+	if len(stats.TopCVEs) != len(want) {
+		t.Fatalf("TopCVEs = %v, want %v", stats.TopCVEs, want)
+	}
+	for i := range want {
+		if stats.TopCVEs[i] != want[i] {
+			t.Errorf("TopCVEs[%d] = %v, want %v", i, stats.TopCVEs[i], want[i])
+		}
+	}
+}
+
+func TestTopCVEs_Limit(t *testing.T) {
+	counts := make(map[string]int)
+	for i := 0; i < TopCVELimit+5; i++ {
+		counts[fmt.Sprintf("CVE-%02d", i)] = 1
+	}
+	top := topCVEs(counts, TopCVELimit)
+	if len(top) != TopCVELimit {
+		t.Fatalf("len = %d, want %d", len(top), TopCVELimit)
+	}
+	if top[0].CVE != "CVE-00" {
+		t.Errorf("ties not ordered by ID: first = %s", top[0].CVE)
+	}
+}
+
+func TestAggregator_GroupStatistics(t *testing.T) {
+	prod := zabbix.HostGroup{GroupID: "10", Name: "Prod"}
+	staging := zabbix.HostGroup{GroupID: "20", Name: "Staging"}
+	linux := zabbix.HostGroup{GroupID: "30", Name: "Linux servers"}
+
+	agg := NewAggregator()
+	agg.AddHost(HostEntry{
+		HostID: "1", Score: 9.0, Groups: []zabbix.HostGroup{prod, linux},
+		Packages:  []PackageVuln{{Name: "openssl", Version: "1.1", Score: 9.0}},
+		Bulletins: []BulletinSummary{{ID: "B1", Score: 9.0, CVEs: []string{"CVE-1", "CVE-2"}}},
+	})
+	agg.AddHost(HostEntry{HostID: "2", Score: 0, Groups: []zabbix.HostGroup{prod, linux}})
+	agg.AddHost(HostEntry{
+		HostID: "3", Score: 4.0, Groups: []zabbix.HostGroup{staging, linux},
+		Packages:  []PackageVuln{{Name: "curl", Version: "7.68", Score: 4.0}},
+		Bulletins: []BulletinSummary{{ID: "B2", Score: 4.0, CVEs: []string{"CVE-3"}}},
+	})
+	agg.AddHost(HostEntry{HostID: "4", Score: 5.0}) // no groups
+
+	groups := agg.GetGroupStatistics()
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3", len(groups))
+	}
+
+	want := []struct {
+		name                      string
+		hosts, vulnHosts, pkgs    int
+		cves                      int
+		maxCVSS, avgCVSS, fleetRk float64
+	}{
+		{"Linux servers", 3, 2, 2, 3, 9.0, 13.0 / 3, 13.0},
+		{"Prod", 2, 1, 1, 2, 9.0, 4.5, 9.0},
+		{"Staging", 1, 1, 1, 1, 4.0, 4.0, 4.0},
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Name != w.name {
+			t.Errorf("groups[%d].Name = %q, want %q", i, g.Name, w.name)
+			continue
+		}
+		if g.TotalHosts != w.hosts || g.VulnerableHosts != w.vulnHosts || g.TotalPackages != w.pkgs || g.TotalCVEs != w.cves {
+			t.Errorf("%s: hosts/vuln/pkgs/cves = %d/%d/%d/%d, want %d/%d/%d/%d", w.name,
+				g.TotalHosts, g.VulnerableHosts, g.TotalPackages, g.TotalCVEs, w.hosts, w.vulnHosts, w.pkgs, w.cves)
+		}
+		if g.MaxCVSS != w.maxCVSS || math.Abs(g.AvgCVSS-w.avgCVSS) > 0.001 || math.Abs(g.FleetRisk-w.fleetRk) > 0.001 {
+			t.Errorf("%s: max/avg/risk = %g/%g/%g, want %g/%g/%g", w.name,
+				g.MaxCVSS, g.AvgCVSS, g.FleetRisk, w.maxCVSS, w.avgCVSS, w.fleetRk)
+		}
+	}
+
+	// Fleet-wide statistics are unaffected by grouping.
+	if stats := agg.GetStatistics(); stats.TotalHosts != 4 {
+		t.Errorf("fleet TotalHosts = %d, want 4", stats.TotalHosts)
+	}
+}
+
+func TestAggregator_ConcurrentAddHost(t *testing.T) {
+	hosts := syntheticHosts(64, 50)
+
+	serial := NewAggregator()
+	for _, h := range hosts {
+		serial.AddHost(h)
+	}
+
+	concurrent := NewAggregator()
+	var wg sync.WaitGroup
+	for _, h := range hosts {
+		wg.Add(1)
+		go func(h HostEntry) {
+			defer wg.Done()
+			concurrent.AddHost(h)
+		}(h)
+	}
+	wg.Wait()
+
+	want, got := serial.GetStatistics(), concurrent.GetStatistics()
+	if got.TotalHosts != want.TotalHosts || got.TotalPackages != want.TotalPackages ||
+		got.TotalBulletins != want.TotalBulletins || got.TotalCVEs != want.TotalCVEs {
+		t.Errorf("concurrent statistics = %+v, want %+v", got, want)
+	}
+	for _, pkg := range concurrent.GetResults().Packages {
+		if pkg.Name == "common-0" && len(pkg.AffectedHosts) != len(hosts) {
+			t.Errorf("common-0 affected hosts = %d, want %d", len(pkg.AffectedHosts), len(hosts))
+		}
+	}
+}
+
+func TestScanResults_Top(t *testing.T) {
+	results := &ScanResults{
+		Hosts:     []HostEntry{{HostID: "1", Score: 5}, {HostID: "2", Score: 9.8}, {HostID: "3", Score: 5}, {HostID: "4", Score: 7}},
+		Packages:  []PackageEntry{{Name: "openssl", Score: 7.5}, {Name: "bash", Score: 9.1}},
+		Bulletins: []BulletinEntry{{ID: "USN-1", Score: 6}},
+	}
+	hostIDs := func(hosts []HostEntry) []string {
+		ids := make([]string, len(hosts))
+		for i, h := range hosts {
+			ids[i] = h.HostID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"zero", 0, nil},
+		{"negative", -1, nil},
+		{"top two", 2, []string{"2", "4"}},
+		{"ties keep order", 4, []string{"2", "4", "1", "3"}},
+		{"more than there are", 10, []string{"2", "4", "1", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hostIDs(results.TopHosts(tt.n))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("TopHosts(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+
+	if got := results.TopPackages(10); len(got) != 2 || got[0].Name != "bash" {
+		t.Errorf("TopPackages(10) = %+v, want bash first of 2", got)
+	}
+	if got := results.TopPackages(0); len(got) != 0 {
+		t.Errorf("TopPackages(0) = %+v, want none", got)
+	}
+	if got := results.TopBulletins(5); len(got) != 1 || got[0].ID != "USN-1" {
+		t.Errorf("TopBulletins(5) = %+v, want USN-1", got)
+	}
+	if got := (&ScanResults{}).TopBulletins(3); len(got) != 0 {
+		t.Errorf("TopBulletins on empty results = %+v, want none", got)
+	}
+	if results.Hosts[0].HostID != "1" {
+		t.Error("TopHosts reordered the results")
+	}
+}
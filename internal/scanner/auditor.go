@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	vulners "github.com/kidoz/go-vulners"
+)
+
+// Auditor audits the packages installed on a Linux host. The scanner only
+// sees this interface, so results can come from the Vulners API or from
+// canned files (see ReplayAuditor).
+type Auditor interface {
+	LinuxAudit(ctx context.Context, osName, osVersion string, packages []string) (*vulners.AuditResult, error)
+}
+
+// vulnersAuditor audits hosts with the Vulners API.
+type vulnersAuditor struct {
+	client *vulners.Client
+}
+
+func (a vulnersAuditor) LinuxAudit(ctx context.Context, osName, osVersion string, packages []string) (*vulners.AuditResult, error) {
+	return a.client.Audit().LinuxAudit(ctx, osName, osVersion, packages)
+}
+
+// ReplayAuditor serves vulners.AuditResult JSON files from a directory
+// instead of calling Vulners, for demos and CI without an API key. A host is
+// answered from "<os>-<version>.json" (e.g. "ubuntu-22.04.json", names as
+// sent to Vulners), falling back to "<os>.json"; every host with that OS
+// gets the same result.
+type ReplayAuditor struct {
+	dir string
+
+	mu    sync.Mutex
+	cache map[string]*vulners.AuditResult
+}
+
+// NewReplayAuditor returns a ReplayAuditor reading from dir.
+func NewReplayAuditor(dir string) (*ReplayAuditor, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("vulners replay directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("vulners replay directory: %s is not a directory", dir)
+	}
+	return &ReplayAuditor{dir: dir, cache: make(map[string]*vulners.AuditResult)}, nil
+}
+
+// LinuxAudit returns the canned result for osName and osVersion. The
+// packages are ignored.
+func (a *ReplayAuditor) LinuxAudit(_ context.Context, osName, osVersion string, _ []string) (*vulners.AuditResult, error) {
+	for _, name := range []string{osName + "-" + osVersion + ".json", osName + ".json"} {
+		result, err := a.load(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return result, err
+	}
+	return nil, fmt.Errorf("no replay data for %s %s in %s", osName, osVersion, a.dir)
+}
+
+// load reads and caches one replay file. Results are shared between hosts,
+// which is safe because the scanner only reads them.
+func (a *ReplayAuditor) load(name string) (*vulners.AuditResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if result, ok := a.cache[name]; ok {
+		return result, nil
+	}
+	data, err := os.ReadFile(filepath.Join(a.dir, filepath.Base(name)))
+	if err != nil {
+		return nil, err
+	}
+	var result vulners.AuditResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid replay file %s: %w", name, err)
+	}
+	a.cache[name] = &result
+	return &result, nil
+}
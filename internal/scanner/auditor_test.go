@@ -0,0 +1,230 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	vulners "github.com/kidoz/go-vulners"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+const replayUbuntu = `{
+  "cvss": 9.8,
+  "cumulativeFix": "apt-get --assume-yes install --only-upgrade openssl",
+  "vulnerabilities": [
+    {"package": "openssl 1.1.1f amd64", "bulletinID": "USN-1", "cvelist": ["CVE-2024-0001"],
+     "cvss": {"score": 9.8}, "fix": "apt-get --assume-yes install --only-upgrade openssl"}
+  ]
+}`
+
+func TestReplayAuditor(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ubuntu-22.04.json"), []byte(replayUbuntu), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "debian.json"), []byte(`{"cvss": 5}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewReplayAuditor(dir)
+	if err != nil {
+		t.Fatalf("NewReplayAuditor: %v", err)
+	}
+	ctx := context.Background()
+
+	if r, err := a.LinuxAudit(ctx, "ubuntu", "22.04", nil); err != nil || r.CVSSScore != 9.8 {
+		t.Errorf("ubuntu 22.04 = %+v, %v; want the ubuntu-22.04.json result", r, err)
+	}
+	if r, err := a.LinuxAudit(ctx, "debian", "12", nil); err != nil || r.CVSSScore != 5 {
+		t.Errorf("debian 12 = %+v, %v; want the debian.json fallback", r, err)
+	}
+	if _, err := a.LinuxAudit(ctx, "ubuntu", "20.04", nil); err == nil || !strings.Contains(err.Error(), "no replay data") {
+		t.Errorf("ubuntu 20.04 err = %v, want missing replay data", err)
+	}
+
+	if _, err := NewReplayAuditor(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
+
+func TestScan_Replay(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ubuntu-22.04.json"), []byte(replayUbuntu), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Vulners.ReplayDir = dir
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	auditor, err := ProvideAuditor(cfg, log)
+	if err != nil {
+		t.Fatalf("ProvideAuditor: %v", err)
+	}
+	if _, ok := auditor.(*ReplayAuditor); !ok {
+		t.Fatalf("auditor = %T, want *ReplayAuditor", auditor)
+	}
+
+	packages := "openssl 1.1.1f amd64\n" + strings.Repeat("bash 5.1 amd64\n", 5)
+	client := &fakeZabbix{
+		hosts: []zabbix.Host{{HostID: "1", Host: "web-01", Name: "Web 01"}},
+		items: map[string]map[string]string{
+			"1": {"system.sw.os": "Ubuntu 22.04.3 LTS", "system.sw.packages": packages},
+		},
+	}
+	s := &Scanner{
+		cfg:          cfg,
+		log:          log,
+		zabbixClient: client,
+		auditor:      auditor,
+		hostMatrix:   NewHostMatrix(cfg, log, client),
+		aggregator:   NewAggregator(),
+		lldGenerator: ProvideLLDGenerator(cfg),
+	}
+
+	results, err := s.Scan(context.Background(), ScanOptions{NoPush: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(results.Hosts) != 1 || results.Hosts[0].Score != 9.8 {
+		t.Fatalf("hosts = %+v, want web-01 with score 9.8", results.Hosts)
+	}
+	if len(results.Bulletins) != 1 || results.Bulletins[0].ID != "USN-1" {
+		t.Errorf("bulletins = %+v, want USN-1", results.Bulletins)
+	}
+}
+
+// deadlineAuditor records the deadline of the context it is called with.
+type deadlineAuditor struct {
+	remaining time.Duration
+}
+
+func (a *deadlineAuditor) LinuxAudit(ctx context.Context, _, _ string, _ []string) (*vulners.AuditResult, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		a.remaining = time.Until(deadline)
+	}
+	return &vulners.AuditResult{}, nil
+}
+
+func TestScanHost_AuditTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestTimeout int
+		want           time.Duration
+	}{
+		{"scan.timeout by default", 0, 30 * time.Second},
+		{"vulners.request_timeout", 300, 300 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Vulners.RequestTimeout = tt.requestTimeout
+			auditor := &deadlineAuditor{}
+			s := &Scanner{cfg: cfg, log: slog.New(slog.NewTextHandler(io.Discard, nil)), auditor: auditor}
+
+			host := &HostData{Host: &zabbix.Host{HostID: "1"}, OSName: "ubuntu", OSVersion: "22.04"}
+			if _, err := s.scanHost(context.Background(), host); err != nil {
+				t.Fatalf("scanHost: %v", err)
+			}
+			if auditor.remaining <= tt.want-time.Second || auditor.remaining > tt.want {
+				t.Errorf("audit deadline in %v, want about %v", auditor.remaining, tt.want)
+			}
+		})
+	}
+}
+
+// staticAuditor returns a fixed result and error.
+type staticAuditor struct {
+	result *vulners.AuditResult
+	err    error
+}
+
+func (a staticAuditor) LinuxAudit(context.Context, string, string, []string) (*vulners.AuditResult, error) {
+	return a.result, a.err
+}
+
+func TestScanHost_KeepsCommasInFix(t *testing.T) {
+	const fix = "apt-get install --only-upgrade libssl3=3.0.2-0ubuntu1.15 openssl,curl"
+	s := &Scanner{cfg: config.DefaultConfig(), log: slog.New(slog.NewTextHandler(io.Discard, nil)), auditor: staticAuditor{result: &vulners.AuditResult{
+		CVSSScore:     7.5,
+		CumulativeFix: fix,
+	}}}
+	host := &HostData{Host: &zabbix.Host{HostID: "1"}, OSName: "ubuntu", OSVersion: "22.04", Packages: []string{"a 1 amd64"}}
+
+	entry, err := s.scanHost(context.Background(), host)
+	if err != nil {
+		t.Fatalf("scanHost: %v", err)
+	}
+	if entry.CumulativeFix != fix {
+		t.Errorf("CumulativeFix = %q, want %q", entry.CumulativeFix, fix)
+	}
+	// LLD data is JSON, so the macro carries the command unchanged.
+	lld := ProvideLLDGenerator(config.DefaultConfig()).GenerateHostsLLD([]HostEntry{*entry})
+	if got := lld.Data[0]["{#H.FIX}"]; got != fix {
+		t.Errorf("{#H.FIX} = %q, want %q", got, fix)
+	}
+}
+
+func TestScanHost_AuditSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	host := &HostData{Host: &zabbix.Host{HostID: "1"}, OSName: "ubuntu", OSVersion: "22.04", Packages: []string{"a 1 amd64"}}
+	scan := func(a Auditor) sdktrace.ReadOnlySpan {
+		t.Helper()
+		s := &Scanner{cfg: config.DefaultConfig(), log: slog.New(slog.NewTextHandler(io.Discard, nil)), auditor: a}
+		_, _ = s.scanHost(context.Background(), host)
+		for _, span := range slices.Backward(recorder.Ended()) {
+			if span.Name() == "Vulners.LinuxAudit" {
+				return span
+			}
+		}
+		t.Fatal("no Vulners.LinuxAudit span recorded")
+		return nil
+	}
+	attrs := func(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		m := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes() {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+
+	span := scan(staticAuditor{result: &vulners.AuditResult{
+		CVSSScore:       7.5,
+		Vulnerabilities: []vulners.Vulnerability{{Package: "a 1 amd64"}, {Package: "a 1 amd64"}},
+	}})
+	a := attrs(span)
+	if a["vulners.outcome"].AsString() != "ok" || a["vulners.vulnerability_count"].AsInt64() != 2 || a["vulners.cvss_score"].AsFloat64() != 7.5 {
+		t.Errorf("success attributes = %v", a)
+	}
+	if span.Parent().SpanID() == (trace.SpanID{}) {
+		t.Error("audit span has no parent, want it under Scanner.scanHost")
+	}
+
+	span = scan(staticAuditor{err: &vulners.APIError{StatusCode: 503, Message: "unavailable"}})
+	a = attrs(span)
+	if a["vulners.outcome"].AsString() != "error" || a["http.response.status_code"].AsInt64() != 503 {
+		t.Errorf("error attributes = %v", a)
+	}
+	if span.Status().Code != codes.Error || len(span.Events()) == 0 {
+		t.Errorf("status = %v, events = %d; want the error recorded", span.Status(), len(span.Events()))
+	}
+}
@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+// Realistic fleet size for the benchmarks: 1000 hosts with 200 vulnerable
+// packages each.
+const (
+	benchHosts    = 1000
+	benchPackages = 200
+)
+
+// syntheticHosts returns n scanned hosts with pkgs vulnerable packages
+// each, one bulletin per package. Most packages come from a shared pool, as
+// on a fleet running the same distribution; the rest are host-specific. The
+// data is deterministic. Package "common-0" is present on every host.
+func syntheticHosts(n, pkgs int) []HostEntry {
+	const pool = 500
+	rng := rand.New(rand.NewPCG(1, 2))
+	groups := []zabbix.HostGroup{{GroupID: "1", Name: "Linux servers"}, {GroupID: "2", Name: "Web"}, {GroupID: "3", Name: "DB"}}
+
+	hosts := make([]HostEntry, n)
+	for i := range hosts {
+		h := HostEntry{
+			HostID: fmt.Sprint(10000 + i),
+			Host:   fmt.Sprintf("host-%04d", i),
+			Name:   fmt.Sprintf("Host %04d", i),
+			OSName: "ubuntu", OSVersion: "22.04",
+			Groups: groups[:1+i%len(groups)],
+		}
+		for j := 0; j < pkgs; j++ {
+			var name string
+			switch {
+			case j == 0:
+				name = "common-0"
+			case j%5 == 0:
+				name = fmt.Sprintf("local-%d-%d", i, j)
+			default:
+				name = fmt.Sprintf("common-%d", 1+rng.IntN(pool))
+			}
+			score := float64(rng.IntN(100)) / 10
+			bulletin := "USN-" + name
+			h.Packages = append(h.Packages, PackageVuln{
+				Name: name, Version: "1.0-1", Arch: "amd64", Score: score,
+				Fix: "apt-get install --only-upgrade " + name, Bulletins: []string{bulletin},
+				CVEs: []string{"CVE-2024-" + name},
+			})
+			h.Bulletins = append(h.Bulletins, BulletinSummary{
+				ID: bulletin, Type: "ubuntu", Score: score,
+				CVEs: []string{"CVE-2024-" + name}, AffectedPkg: []string{name},
+			})
+			h.Score = max(h.Score, score)
+		}
+		hosts[i] = h
+	}
+	return hosts
+}
+
+// aggregated returns an aggregator holding hosts.
+func aggregated(hosts []HostEntry) *Aggregator {
+	agg := NewAggregator()
+	for _, h := range hosts {
+		agg.AddHost(h)
+	}
+	return agg
+}
+
+// BenchmarkAggregatorAddHost adds hosts one at a time from a single
+// goroutine, as Scan does while draining the scan stream.
+func BenchmarkAggregatorAddHost(b *testing.B) {
+	hosts := syntheticHosts(benchHosts, benchPackages)
+	for b.Loop() {
+		aggregated(hosts)
+	}
+}
+
+func BenchmarkGetStatistics(b *testing.B) {
+	agg := aggregated(syntheticHosts(benchHosts, benchPackages))
+	for b.Loop() {
+		agg.GetStatistics()
+	}
+}
+
+func BenchmarkGetResults(b *testing.B) {
+	agg := aggregated(syntheticHosts(benchHosts, benchPackages))
+	for b.Loop() {
+		agg.GetResults()
+	}
+}
+
+func BenchmarkGeneratePackagesLLD(b *testing.B) {
+	results := aggregated(syntheticHosts(benchHosts, benchPackages)).GetResults()
+	gen := NewLLDGenerator(testNaming())
+	for b.Loop() {
+		gen.GeneratePackagesLLD(results.Packages)
+	}
+}
+
+func BenchmarkGenerateBulletinsLLD(b *testing.B) {
+	results := aggregated(syntheticHosts(benchHosts, benchPackages)).GetResults()
+	gen := NewLLDGenerator(testNaming())
+	for b.Loop() {
+		gen.GenerateBulletinsLLD(results.Bulletins)
+	}
+}
+
+func BenchmarkGenerateHostsLLD(b *testing.B) {
+	hosts := syntheticHosts(benchHosts, benchPackages)
+	gen := NewLLDGenerator(testNaming())
+	for b.Loop() {
+		gen.GenerateHostsLLD(hosts)
+	}
+}
@@ -0,0 +1,95 @@
+package scanner
+
+import "sort"
+
+// ScanDiff describes how vulnerability state changed between two scans.
+type ScanDiff struct {
+	NewPackages       []PackageEntry    `json:"new_packages"`
+	ResolvedPackages  []PackageEntry    `json:"resolved_packages"`
+	NewBulletins      []BulletinEntry   `json:"new_bulletins"`
+	ResolvedBulletins []BulletinEntry   `json:"resolved_bulletins"`
+	HostScoreChanges  []HostScoreChange `json:"host_score_changes"`
+}
+
+// HostScoreChange records a host whose CVSS score differs between scans.
+// A host missing from one side is reported with a score of 0 there.
+type HostScoreChange struct {
+	HostID   string  `json:"host_id"`
+	Name     string  `json:"name"`
+	OldScore float64 `json:"old_score"`
+	NewScore float64 `json:"new_score"`
+}
+
+// Delta returns NewScore - OldScore.
+func (c HostScoreChange) Delta() float64 {
+	return c.NewScore - c.OldScore
+}
+
+type packageKey struct {
+	name, version, arch string
+}
+
+// Diff compares two scan results. Packages are keyed by (name, version,
+// arch) and bulletins by ID.
+func Diff(old, cur *ScanResults) *ScanDiff {
+	d := &ScanDiff{}
+
+	oldPkgs := make(map[packageKey]bool, len(old.Packages))
+	for _, p := range old.Packages {
+		oldPkgs[packageKey{p.Name, p.Version, p.Arch}] = true
+	}
+	curPkgs := make(map[packageKey]bool, len(cur.Packages))
+	for _, p := range cur.Packages {
+		k := packageKey{p.Name, p.Version, p.Arch}
+		curPkgs[k] = true
+		if !oldPkgs[k] {
+			d.NewPackages = append(d.NewPackages, p)
+		}
+	}
+	for _, p := range old.Packages {
+		if !curPkgs[packageKey{p.Name, p.Version, p.Arch}] {
+			d.ResolvedPackages = append(d.ResolvedPackages, p)
+		}
+	}
+
+	oldBulletins := make(map[string]bool, len(old.Bulletins))
+	for _, b := range old.Bulletins {
+		oldBulletins[b.ID] = true
+	}
+	curBulletins := make(map[string]bool, len(cur.Bulletins))
+	for _, b := range cur.Bulletins {
+		curBulletins[b.ID] = true
+		if !oldBulletins[b.ID] {
+			d.NewBulletins = append(d.NewBulletins, b)
+		}
+	}
+	for _, b := range old.Bulletins {
+		if !curBulletins[b.ID] {
+			d.ResolvedBulletins = append(d.ResolvedBulletins, b)
+		}
+	}
+
+	changes := make(map[string]*HostScoreChange)
+	for _, h := range old.Hosts {
+		changes[h.HostID] = &HostScoreChange{HostID: h.HostID, Name: h.Name, OldScore: h.Score}
+	}
+	for _, h := range cur.Hosts {
+		c, ok := changes[h.HostID]
+		if !ok {
+			c = &HostScoreChange{HostID: h.HostID}
+			changes[h.HostID] = c
+		}
+		c.Name = h.Name
+		c.NewScore = h.Score
+	}
+	for _, c := range changes {
+		if c.OldScore != c.NewScore {
+			d.HostScoreChanges = append(d.HostScoreChanges, *c)
+		}
+	}
+	sort.Slice(d.HostScoreChanges, func(i, j int) bool {
+		return d.HostScoreChanges[i].HostID < d.HostScoreChanges[j].HostID
+	})
+
+	return d
+}
@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := &ScanResults{
+		Hosts: []HostEntry{
+			{HostID: "1", Name: "web", Score: 7.5},
+			{HostID: "2", Name: "db", Score: 5.0},
+			{HostID: "3", Name: "gone", Score: 9.8},
+		},
+		Packages: []PackageEntry{
+			{Name: "openssl", Version: "3.0.2", Arch: "amd64"},
+			{Name: "curl", Version: "7.81", Arch: "amd64"},
+		},
+		Bulletins: []BulletinEntry{{ID: "USN-1"}, {ID: "USN-2"}},
+	}
+	cur := &ScanResults{
+		Hosts: []HostEntry{
+			{HostID: "1", Name: "web", Score: 4.3},
+			{HostID: "2", Name: "db", Score: 5.0},
+			{HostID: "4", Name: "new", Score: 6.1},
+		},
+		Packages: []PackageEntry{
+			{Name: "openssl", Version: "3.0.2", Arch: "amd64"},
+			{Name: "curl", Version: "7.81", Arch: "i386"},
+		},
+		Bulletins: []BulletinEntry{{ID: "USN-2"}, {ID: "USN-3"}},
+	}
+
+	d := Diff(old, cur)
+
+	if len(d.NewPackages) != 1 || d.NewPackages[0].Arch != "i386" {
+		t.Errorf("NewPackages = %+v, want curl/i386", d.NewPackages)
+	}
+	if len(d.ResolvedPackages) != 1 || d.ResolvedPackages[0].Arch != "amd64" || d.ResolvedPackages[0].Name != "curl" {
+		t.Errorf("ResolvedPackages = %+v, want curl/amd64", d.ResolvedPackages)
+	}
+	if len(d.NewBulletins) != 1 || d.NewBulletins[0].ID != "USN-3" {
+		t.Errorf("NewBulletins = %+v, want USN-3", d.NewBulletins)
+	}
+	if len(d.ResolvedBulletins) != 1 || d.ResolvedBulletins[0].ID != "USN-1" {
+		t.Errorf("ResolvedBulletins = %+v, want USN-1", d.ResolvedBulletins)
+	}
+
+	want := []HostScoreChange{
+		{HostID: "1", Name: "web", OldScore: 7.5, NewScore: 4.3},
+		{HostID: "3", Name: "gone", OldScore: 9.8, NewScore: 0},
+		{HostID: "4", Name: "new", OldScore: 0, NewScore: 6.1},
+	}
+	if len(d.HostScoreChanges) != len(want) {
+		t.Fatalf("HostScoreChanges = %+v, want %+v", d.HostScoreChanges, want)
+	}
+	for i := range want {
+		if d.HostScoreChanges[i] != want[i] {
+			t.Errorf("HostScoreChanges[%d] = %+v, want %+v", i, d.HostScoreChanges[i], want[i])
+		}
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	in := &ScanResults{
+		HostsScanned: 1,
+		Hosts:        []HostEntry{{HostID: "1", Name: "web", Score: 7.5}},
+		Packages:     []PackageEntry{{Name: "openssl", Version: "3.0.2", Arch: "amd64"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, in, time.Unix(0, 0)); err != nil {
+		t.Fatalf("WriteJSON() error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"schema_version": 1`)) {
+		t.Errorf("export missing schema_version:\n%s", buf.String())
+	}
+
+	out, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON() error: %v", err)
+	}
+	if out.HostsScanned != 1 || len(out.Hosts) != 1 || out.Hosts[0].Score != 7.5 || out.Packages[0].Name != "openssl" {
+		t.Errorf("round trip mismatch: %+v", out.ScanResults)
+	}
+}
+
+func TestReadJSON_SchemaMismatch(t *testing.T) {
+	if _, err := ReadJSON(bytes.NewBufferString(`{"schema_version": 99}`)); err == nil {
+		t.Error("expected error for unknown schema version")
+	}
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ExportSchemaVersion is bumped whenever the exported JSON layout changes
+// incompatibly.
+const ExportSchemaVersion = 1
+
+// ExportedResults is the on-disk JSON form of a scan.
+type ExportedResults struct {
+	SchemaVersion int       `json:"schema_version"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	*ScanResults
+}
+
+// WriteJSON writes results in the stable export schema.
+func WriteJSON(w io.Writer, results *ScanResults, now time.Time) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ExportedResults{
+		SchemaVersion: ExportSchemaVersion,
+		GeneratedAt:   now.UTC(),
+		ScanResults:   results,
+	})
+}
+
+// ReadJSON reads results previously written by WriteJSON.
+func ReadJSON(r io.Reader) (*ExportedResults, error) {
+	exp := &ExportedResults{ScanResults: &ScanResults{}}
+	if err := json.NewDecoder(r).Decode(exp); err != nil {
+		return nil, fmt.Errorf("failed to decode scan export: %w", err)
+	}
+	if exp.SchemaVersion != ExportSchemaVersion {
+		return nil, fmt.Errorf("unsupported scan export schema version %d (want %d)", exp.SchemaVersion, ExportSchemaVersion)
+	}
+	return exp, nil
+}
+
+// ReadJSONFile is ReadJSON for a file path.
+func ReadJSONFile(path string) (*ExportedResults, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan export: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	exp, err := ReadJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return exp, nil
+}
+
+// InventoryExport is the JSON written by "ztc scan --collect-only".
+type InventoryExport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Hosts       []HostInventory `json:"hosts"`
+	Excluded    map[string]int  `json:"excluded,omitempty"`
+}
+
+// WriteInventoryJSON writes the inventory of a collect-only scan.
+func WriteInventoryJSON(w io.Writer, results *ScanResults, now time.Time) error {
+	hosts := results.Inventory
+	if hosts == nil {
+		hosts = []HostInventory{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(InventoryExport{
+		GeneratedAt: now.UTC(),
+		Hosts:       hosts,
+		Excluded:    results.Excluded,
+	})
+}
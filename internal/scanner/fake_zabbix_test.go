@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+// fakeZabbix is an in-memory ZabbixAPI. items maps hostID → item key → value.
+type fakeZabbix struct {
+	hosts []zabbix.Host
+	items map[string]map[string]string // host ID → item key, or "tag:value" when selected by tag → last value
+
+	hostsErr error // returned by the host listing calls
+
+	maintenances    []zabbix.Maintenance
+	maintenancesErr error
+
+	values map[string]map[string]string // host name → item key → last value
+}
+
+func (f *fakeZabbix) GetHostsWithTemplateCtx(context.Context, string) ([]zabbix.Host, error) {
+	return f.hosts, f.hostsErr
+}
+
+func (f *fakeZabbix) GetHostsWithInventoryCtx(context.Context) ([]zabbix.Host, error) {
+	return f.hosts, f.hostsErr
+}
+
+func (f *fakeZabbix) GetHostByNameCtx(_ context.Context, name string) (*zabbix.Host, error) {
+	for i := range f.hosts {
+		if f.hosts[i].Host == name {
+			return &f.hosts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("host not found: %s", name)
+}
+
+func (f *fakeZabbix) GetHostItemsCtx(_ context.Context, hostID string, key string, tags ...zabbix.ItemTag) ([]zabbix.Item, error) {
+	if key == "" && len(tags) == 1 {
+		key = tags[0].Tag + ":" + tags[0].Value
+	}
+	if v, ok := f.items[hostID][key]; ok {
+		return []zabbix.Item{{HostID: hostID, Key: key, Value: v}}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeZabbix) GetMaintenancesCtx(context.Context, []string) ([]zabbix.Maintenance, error) {
+	return f.maintenances, f.maintenancesErr
+}
+
+func (f *fakeZabbix) GetItemValueCtx(_ context.Context, host, key string) (string, error) {
+	return f.values[host][key], nil
+}
+
+func (f *fakeZabbix) Close() error { return nil }
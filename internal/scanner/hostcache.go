@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+)
+
+// HostCache stores per-host scan results between scans so that hosts whose
+// inventory has not changed are not audited again. Implementations must be
+// safe for concurrent use, and should expire entries after a maximum age
+// so hosts are re-audited against an up-to-date vulnerability database.
+type HostCache interface {
+	// GetHost returns the entry stored for hostID if it was stored with the
+	// same fingerprint and has not expired.
+	GetHost(hostID, fingerprint string) (HostEntry, bool)
+	// PutHost stores the entry scanned for hostID with the given fingerprint.
+	PutHost(hostID, fingerprint string, entry HostEntry)
+	// RetainHosts evicts every host not in hostIDs.
+	RetainHosts(hostIDs []string)
+}
+
+// HostFingerprint hashes what determines a host's cached entry: OS name,
+// OS version and the package set, plus the Vulners host audited against and
+// scan.min_cvss, which filters the entry. Package order does not matter.
+func HostFingerprint(hd *HostData, cfg *config.Config) string {
+	packages := slices.Clone(hd.Packages)
+	slices.Sort(packages)
+
+	h := sha256.New()
+	h.Write([]byte(cfg.Vulners.Host))
+	h.Write([]byte{0})
+	h.Write(strconv.AppendFloat(nil, cfg.Scan.MinCVSS, 'g', -1, 64))
+	h.Write([]byte{0})
+	h.Write([]byte(hd.OSName))
+	h.Write([]byte{0})
+	h.Write([]byte(hd.OSVersion))
+	for _, pkg := range packages {
+		h.Write([]byte{0})
+		h.Write([]byte(pkg))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedHost returns the cached entry for hd, refreshed with the host's
+// current names, or false when it must be scanned.
+func cachedHost(cache HostCache, hd *HostData, fingerprint string) (*HostEntry, bool) {
+	if cache == nil {
+		return nil, false
+	}
+	entry, ok := cache.GetHost(hd.Host.HostID, fingerprint)
+	if !ok {
+		return nil, false
+	}
+	entry.Host = hd.Host.Host
+	entry.Name = hd.Host.Name
+	entry.Groups = hd.Host.Groups
+	return &entry, true
+}
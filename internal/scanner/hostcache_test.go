@@ -0,0 +1,174 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+func TestHostFingerprint(t *testing.T) {
+	cfg := config.DefaultConfig()
+	base := &HostData{OSName: "ubuntu", OSVersion: "22.04", Packages: []string{"a 1 amd64", "b 2 amd64"}}
+	fp := HostFingerprint(base, cfg)
+
+	reordered := &HostData{OSName: "ubuntu", OSVersion: "22.04", Packages: []string{"b 2 amd64", "a 1 amd64"}}
+	if HostFingerprint(reordered, cfg) != fp {
+		t.Error("package order changed the fingerprint")
+	}
+	if base.Packages[0] != "a 1 amd64" {
+		t.Error("HostFingerprint reordered the caller's packages")
+	}
+
+	changed := []*HostData{
+		{OSName: "ubuntu", OSVersion: "22.04", Packages: []string{"a 1 amd64", "b 3 amd64"}},
+		{OSName: "ubuntu", OSVersion: "24.04", Packages: []string{"a 1 amd64", "b 2 amd64"}},
+		{OSName: "debian", OSVersion: "22.04", Packages: []string{"a 1 amd64", "b 2 amd64"}},
+		{OSName: "ubuntu", OSVersion: "22.04", Packages: []string{"a 1 amd64"}},
+	}
+	for _, hd := range changed {
+		if HostFingerprint(hd, cfg) == fp {
+			t.Errorf("fingerprint unchanged for %+v", hd)
+		}
+	}
+
+	minCVSS := config.DefaultConfig()
+	minCVSS.Scan.MinCVSS = 7
+	mirror := config.DefaultConfig()
+	mirror.Vulners.Host = "https://vulners-mirror.example.com"
+	for _, c := range []*config.Config{minCVSS, mirror} {
+		if HostFingerprint(base, c) == fp {
+			t.Errorf("fingerprint unchanged for min_cvss %g, Vulners host %s", c.Scan.MinCVSS, c.Vulners.Host)
+		}
+	}
+}
+
+// memHostCache is a minimal HostCache for tests.
+type memHostCache struct {
+	mu    sync.Mutex
+	hosts map[string]string // hostID → fingerprint
+	entry map[string]HostEntry
+}
+
+func (c *memHostCache) GetHost(hostID, fingerprint string) (HostEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hosts[hostID] != fingerprint {
+		return HostEntry{}, false
+	}
+	return c.entry[hostID], true
+}
+
+func (c *memHostCache) PutHost(hostID, fingerprint string, entry HostEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hosts[hostID] = fingerprint
+	c.entry[hostID] = entry
+}
+
+func (c *memHostCache) RetainHosts(hostIDs []string) {
+	keep := make(map[string]bool)
+	for _, id := range hostIDs {
+		keep[id] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range c.hosts {
+		if !keep[id] {
+			delete(c.hosts, id)
+			delete(c.entry, id)
+		}
+	}
+}
+
+func TestScan_HostCache(t *testing.T) {
+	var audits atomic.Int32
+	vulnersAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		audits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"result":"OK","data":{}}`)
+	}))
+	defer vulnersAPI.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Vulners.APIKey = "test"
+	cfg.Vulners.Host = vulnersAPI.URL
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	vc, err := ProvideVulnersClient(cfg, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// validateHostData drops hosts with five packages or fewer.
+	packages := func(first string) string {
+		return first + "\n" + strings.Repeat("bash 5.1 amd64\n", 5)
+	}
+	client := &fakeZabbix{
+		hosts: []zabbix.Host{
+			{HostID: "1", Host: "web-01", Name: "Web 01"},
+			{HostID: "2", Host: "db-01", Name: "DB 01"},
+		},
+		items: map[string]map[string]string{
+			"1": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": packages("openssl 1.1.1f amd64")},
+			"2": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": packages("curl 7.81 amd64")},
+		},
+	}
+	s := &Scanner{
+		cfg:          cfg,
+		log:          log,
+		zabbixClient: client,
+		auditor:      vulnersAuditor{client: vc},
+		hostMatrix:   NewHostMatrix(cfg, log, client),
+		aggregator:   NewAggregator(),
+		lldGenerator: ProvideLLDGenerator(cfg),
+	}
+	cache := &memHostCache{hosts: map[string]string{}, entry: map[string]HostEntry{}}
+	opts := ScanOptions{HostCache: cache}
+
+	scan := func() *ScanResults {
+		t.Helper()
+		results, err := s.Scan(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		return results
+	}
+
+	if scan(); audits.Load() != 2 {
+		t.Fatalf("first scan audited %d hosts, want 2", audits.Load())
+	}
+
+	// Unchanged inventory: nothing is audited, but renames are picked up.
+	client.hosts[0].Name = "Web 01 (renamed)"
+	results := scan()
+	if audits.Load() != 2 {
+		t.Errorf("second scan audited %d hosts, want 0", audits.Load()-2)
+	}
+	if len(results.Hosts) != 2 {
+		t.Fatalf("got %d hosts, want 2", len(results.Hosts))
+	}
+	for _, h := range results.Hosts {
+		if h.HostID == "1" && h.Name != "Web 01 (renamed)" {
+			t.Errorf("cached host name = %q, want the current name", h.Name)
+		}
+	}
+
+	// A changed package set is re-audited; a removed host is evicted.
+	client.items["1"]["system.sw.packages"] = packages("openssl 3.0.2 amd64")
+	client.hosts = client.hosts[:1]
+	scan()
+	if audits.Load() != 3 {
+		t.Errorf("third scan audited %d hosts, want 1", audits.Load()-2)
+	}
+	if _, ok := cache.hosts["2"]; ok {
+		t.Error("removed host was not evicted from the cache")
+	}
+}
@@ -3,7 +3,11 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"log/slog"
 
@@ -20,15 +24,27 @@ type HostData struct {
 	Packages  []string
 }
 
+// ZabbixAPI is the part of the Zabbix API client used by the scanner.
+// *zabbix.Client implements it; tests substitute a fake.
+type ZabbixAPI interface {
+	GetHostsWithTemplateCtx(ctx context.Context, templateName string) ([]zabbix.Host, error)
+	GetHostsWithInventoryCtx(ctx context.Context) ([]zabbix.Host, error)
+	GetHostByNameCtx(ctx context.Context, name string) (*zabbix.Host, error)
+	GetHostItemsCtx(ctx context.Context, hostID string, keyPattern string, tags ...zabbix.ItemTag) ([]zabbix.Item, error)
+	GetMaintenancesCtx(ctx context.Context, maintenanceIDs []string) ([]zabbix.Maintenance, error)
+	GetItemValueCtx(ctx context.Context, hostTechName, itemKey string) (string, error)
+	Close() error
+}
+
 // HostMatrix fetches and organizes host data from Zabbix
 type HostMatrix struct {
 	cfg    *config.Config
 	log    *slog.Logger
-	client *zabbix.Client
+	client ZabbixAPI
 }
 
 // NewHostMatrix creates a new host matrix
-func NewHostMatrix(cfg *config.Config, log *slog.Logger, client *zabbix.Client) *HostMatrix {
+func NewHostMatrix(cfg *config.Config, log *slog.Logger, client ZabbixAPI) *HostMatrix {
 	return &HostMatrix{
 		cfg:    cfg,
 		log:    log,
@@ -36,18 +52,149 @@ func NewHostMatrix(cfg *config.Config, log *slog.Logger, client *zabbix.Client)
 	}
 }
 
+// HostCandidate is a host carrying the OS-Report template together with the
+// outcome of fetching and validating its data.
+type HostCandidate struct {
+	Host   zabbix.Host
+	Data   *HostData // nil when the host was excluded
+	Reason string    // why the host was excluded; empty when Data is set
+}
+
+// ReasonFetchFailed is the exclusion reason of hosts whose data could not be
+// fetched from Zabbix.
+const ReasonFetchFailed = "fetch failed"
+
+// Exclusion reasons set by scan.maintenance: ReasonMaintenance for hosts in
+// an active maintenance, ReasonMaintenanceWindow for the other hosts of a
+// scan skipped under skip_scan.
+const (
+	ReasonMaintenance       = "in maintenance"
+	ReasonMaintenanceWindow = "scan skipped for maintenance"
+)
+
+// FetchResult holds the hosts qualifying for a scan and a count of the
+// excluded ones per exclusion reason.
+type FetchResult struct {
+	Hosts    []HostData
+	Excluded map[string]int
+
+	// Maintenance lists the IDs of the hosts excluded as in maintenance.
+	Maintenance []string
+	// ScanSkipped is set when scan.maintenance is skip_scan and a host is
+	// in maintenance: the whole scan must be skipped.
+	ScanSkipped bool
+}
+
+// ExcludedTotal sums per-reason exclusion counts.
+func ExcludedTotal(excluded map[string]int) int {
+	n := 0
+	for _, c := range excluded {
+		n += c
+	}
+	return n
+}
+
 // FetchHosts fetches hosts with the OS-Report template and their data
-func (hm *HostMatrix) FetchHosts(ctx context.Context, opts ScanOptions) ([]HostData, error) {
+func (hm *HostMatrix) FetchHosts(ctx context.Context, opts ScanOptions) (*FetchResult, error) {
+	candidates, err := hm.Candidates(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FetchResult{Excluded: make(map[string]int)}
+	for _, c := range candidates {
+		switch {
+		case c.Data != nil:
+			result.Hosts = append(result.Hosts, *c.Data)
+		case c.Reason == ReasonMaintenance:
+			result.Maintenance = append(result.Maintenance, c.Host.HostID)
+			fallthrough
+		default:
+			result.Excluded[c.Reason]++
+		}
+	}
+	result.ScanSkipped = hm.cfg.Scan.Maintenance == config.MaintenanceSkipScan && len(result.Maintenance) > 0
+
+	if n := ExcludedTotal(result.Excluded); n > 0 {
+		hm.log.Info("Excluded hosts", slog.Int("count", n), slog.String("reasons", FormatExclusions(result.Excluded)))
+	}
+
+	return result, nil
+}
+
+// Inventory fetches the hosts like FetchHosts and returns the inventory a
+// scan would audit, without contacting Vulners.
+func (hm *HostMatrix) Inventory(ctx context.Context, opts ScanOptions) (*ScanResults, error) {
+	start := time.Now()
+	fetched, err := hm.FetchHosts(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &ScanResults{
+		Excluded:  fetched.Excluded,
+		Inventory: make([]HostInventory, len(fetched.Hosts)),
+	}
+	results.Timings.FetchHosts = time.Since(start)
+	for i, hd := range fetched.Hosts {
+		results.Inventory[i] = HostInventory{
+			HostID:    hd.Host.HostID,
+			Host:      hd.Host.Host,
+			Name:      hd.Host.Name,
+			OSName:    hd.OSName,
+			OSVersion: hd.OSVersion,
+			Packages:  hd.Packages,
+		}
+	}
+	return results, nil
+}
+
+// FormatExclusions renders exclusion counts as "8 too few packages, 1 ...",
+// most frequent reason first.
+func FormatExclusions(excluded map[string]int) string {
+	reasons := make([]string, 0, len(excluded))
+	for r := range excluded {
+		reasons = append(reasons, r)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if excluded[reasons[i]] != excluded[reasons[j]] {
+			return excluded[reasons[i]] > excluded[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	parts := make([]string, len(reasons))
+	for i, r := range reasons {
+		parts[i] = fmt.Sprintf("%d %s", excluded[r], r)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Candidates fetches the hosts selected by scan.discovery_mode and validates
+// their data, returning every host with either its data or its exclusion
+// reason.
+func (hm *HostMatrix) Candidates(ctx context.Context, opts ScanOptions) ([]HostCandidate, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "HostMatrix.FetchHosts")
 	defer span.End()
 
-	// Get hosts with OS-Report template
-	hosts, err := hm.client.GetHostsWithTemplateCtx(ctx, hm.cfg.Scan.OSReportTemplate)
+	inventory := hm.cfg.Scan.DiscoveryMode == config.DiscoveryInventory
+
+	var hosts []zabbix.Host
+	var err error
+	if inventory {
+		hosts, err = hm.client.GetHostsWithInventoryCtx(ctx)
+	} else {
+		hosts, err = hm.client.GetHostsWithTemplateCtx(ctx, hm.cfg.Scan.OSReportTemplate)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get hosts: %w", err)
 	}
 
-	hm.log.Info("Found hosts with OS-Report template", slog.Int("count", len(hosts)))
+	if inventory {
+		hm.log.Info("Found hosts with inventory", slog.Int("count", len(hosts)))
+	} else {
+		hm.log.Info("Found hosts with OS-Report template", slog.Int("count", len(hosts)))
+	}
 
 	// Filter by specific host IDs if provided
 	if len(opts.HostIDs) > 0 {
@@ -72,31 +219,119 @@ func (hm *HostMatrix) FetchHosts(ctx context.Context, opts ScanOptions) ([]HostD
 		hm.log.Info("Applied host limit", slog.Int("limit", opts.Limit))
 	}
 
-	// Fetch data for each host
-	var hostData []HostData
-	for _, host := range hosts {
-		data, err := hm.fetchHostData(ctx, &host)
-		if err != nil {
-			hm.log.Warn("Failed to fetch host data", slog.Any("error", err), slog.String("host", host.Name))
+	skip := hm.maintenanceSkips(ctx, hosts)
+
+	candidates := make([]HostCandidate, len(hosts))
+	if inventory {
+		for i, host := range hosts {
+			candidates[i].Host = host
+			if reason := skip(&host); reason != "" {
+				hm.resolveCandidate(&candidates[i], nil, reason, nil)
+				continue
+			}
+			data, reason := hm.hostDataFromInventory(&candidates[i].Host)
+			hm.resolveCandidate(&candidates[i], data, reason, nil)
+		}
+		return candidates, nil
+	}
+
+	// Fetch data for each host. The Zabbix client caps in-flight API calls
+	// at zabbix.max_concurrent_requests, so more fetchers would only queue.
+	fetchers := max(hm.cfg.Zabbix.MaxConcurrentRequests, 1)
+	semaphore := make(chan struct{}, fetchers)
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		candidates[i].Host = host
+		if reason := skip(&host); reason != "" {
+			hm.resolveCandidate(&candidates[i], nil, reason, nil)
 			continue
 		}
+		wg.Add(1)
+		go func(c *HostCandidate) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			data, reason, err := hm.fetchHostData(ctx, &c.Host)
+			hm.resolveCandidate(c, data, reason, err)
+		}(&candidates[i])
+	}
+	wg.Wait()
 
-		if data != nil {
-			hostData = append(hostData, *data)
+	return candidates, nil
+}
+
+// maintenanceSkips applies scan.maintenance to hosts, logging the active
+// maintenances, and returns the exclusion reason of each host: empty for
+// hosts to scan.
+func (hm *HostMatrix) maintenanceSkips(ctx context.Context, hosts []zabbix.Host) func(*zabbix.Host) string {
+	mode := hm.cfg.Scan.Maintenance
+	if mode == config.MaintenanceIgnore {
+		return func(*zabbix.Host) string { return "" }
+	}
+
+	count := 0
+	var ids []string
+	for _, h := range hosts {
+		if h.MaintenanceStatus == zabbix.HostInMaintenance {
+			count++
+			if h.MaintenanceID != "" && !slices.Contains(ids, h.MaintenanceID) {
+				ids = append(ids, h.MaintenanceID)
+			}
 		}
 	}
+	if count == 0 {
+		return func(*zabbix.Host) string { return "" }
+	}
 
-	return hostData, nil
+	// The maintenance names only make the log readable; failing to fetch
+	// them does not change which hosts are skipped.
+	names := ids
+	if maintenances, err := hm.client.GetMaintenancesCtx(ctx, ids); err != nil {
+		hm.log.Warn("Failed to get maintenance names", slog.Any("error", err))
+	} else if len(maintenances) > 0 {
+		names = make([]string, len(maintenances))
+		for i, m := range maintenances {
+			names[i] = m.Name
+		}
+	}
+	hm.log.Info("Hosts in maintenance", slog.Int("count", count), slog.String("maintenances", strings.Join(names, ", ")), slog.String("mode", mode))
+
+	skipScan := mode == config.MaintenanceSkipScan
+	return func(h *zabbix.Host) string {
+		switch {
+		case h.MaintenanceStatus == zabbix.HostInMaintenance:
+			return ReasonMaintenance
+		case skipScan:
+			return ReasonMaintenanceWindow
+		}
+		return ""
+	}
 }
 
-// fetchHostData fetches OS and package data for a single host
-func (hm *HostMatrix) fetchHostData(ctx context.Context, host *zabbix.Host) (*HostData, error) {
+// resolveCandidate records the outcome of fetching a candidate's data.
+func (hm *HostMatrix) resolveCandidate(c *HostCandidate, data *HostData, reason string, err error) {
+	switch {
+	case err != nil:
+		hm.log.Warn("Failed to fetch host data", slog.Any("error", err), slog.String("host", c.Host.Name))
+		c.Reason = ReasonFetchFailed
+	case reason != "":
+		hm.log.Debug("Excluded host", slog.String("host", c.Host.Name), slog.String("reason", reason))
+		c.Reason = reason
+	default:
+		c.Data = data
+	}
+}
+
+// fetchHostData fetches OS and package data for a single host. A non-empty
+// reason means the host is excluded from scanning.
+func (hm *HostMatrix) fetchHostData(ctx context.Context, host *zabbix.Host) (*HostData, string, error) {
 	hm.log.Debug("Fetching host data", slog.String("host", host.Name))
 
 	// Get OS name item
-	osItems, err := hm.client.GetHostItemsCtx(ctx, host.HostID, "system.sw.os")
+	osItems, err := hm.hostItems(ctx, host.HostID, "system.sw.os", hm.cfg.Scan.OSItemTag)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get OS items: %w", err)
+		return nil, "", fmt.Errorf("failed to get OS items: %w", err)
 	}
 
 	var osName, osVersion string
@@ -108,14 +343,13 @@ func (hm *HostMatrix) fetchHostData(ctx context.Context, host *zabbix.Host) (*Ho
 	}
 
 	if osName == "" {
-		hm.log.Debug("No OS information available", slog.String("host", host.Name))
-		return nil, nil
+		return nil, "no OS information", nil
 	}
 
 	// Get packages item
-	pkgItems, err := hm.client.GetHostItemsCtx(ctx, host.HostID, "system.sw.packages")
+	pkgItems, err := hm.hostItems(ctx, host.HostID, "system.sw.packages", hm.cfg.Scan.PackagesItemTag)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get package items: %w", err)
+		return nil, "", fmt.Errorf("failed to get package items: %w", err)
 	}
 
 	var packages []string
@@ -127,33 +361,102 @@ func (hm *HostMatrix) fetchHostData(ctx context.Context, host *zabbix.Host) (*Ho
 	}
 
 	if len(packages) == 0 {
-		hm.log.Debug("No package information available", slog.String("host", host.Name))
-		return nil, nil
+		return nil, "no package information", nil
 	}
 
-	// Normalize OS name for Vulners API
-	osName = NormalizeOSName(osName)
-	osVersion = ExtractOSVersion(osVersion)
-
-	// Host data validation (matching Python behavior)
-	if reason := validateHostData(osVersion, packages); reason != "" {
-		hm.log.Debug("Excluded host", slog.String("host", host.Name), slog.String("reason", reason))
-		return nil, nil
+	data, reason := hm.newHostData(host, osName, osVersion, packages)
+	if reason != "" {
+		return nil, reason, nil
 	}
 
 	hm.log.Debug("Fetched host data",
 		slog.String("host", host.Name),
-		slog.String("os", osName),
-		slog.String("version", osVersion),
+		slog.String("os", data.OSName),
+		slog.String("version", data.OSVersion),
 		slog.Int("packages", len(packages)),
 	)
 
+	return data, "", nil
+}
+
+// hostItems returns the host's items with the given key, or with the given
+// "name:value" item tag when one is configured.
+func (hm *HostMatrix) hostItems(ctx context.Context, hostID, key, tag string) ([]zabbix.Item, error) {
+	if tag == "" {
+		return hm.client.GetHostItemsCtx(ctx, hostID, key)
+	}
+	return hm.client.GetHostItemsCtx(ctx, hostID, "", zabbix.ParseItemTag(tag))
+}
+
+// hostDataFromInventory builds host data from the os_full (or os) and
+// software_full inventory fields. A non-empty reason means the host is
+// excluded from scanning.
+func (hm *HostMatrix) hostDataFromInventory(host *zabbix.Host) (*HostData, string) {
+	osInfo := host.Inventory.OSFull
+	if strings.TrimSpace(osInfo) == "" {
+		osInfo = host.Inventory.OS
+	}
+	osName, osVersion := parseOSInfo(osInfo)
+	if osName == "" {
+		return nil, "no OS information"
+	}
+
+	packages := parsePackageList(host.Inventory.SoftwareFull)
+	if len(packages) == 0 {
+		return nil, "no package information"
+	}
+
+	return hm.newHostData(host, osName, osVersion, packages)
+}
+
+// newHostData normalizes the OS for the Vulners API (applying
+// scan.os_name_map) and validates the host (matching Python behavior). A
+// non-empty reason means the host is excluded.
+func (hm *HostMatrix) newHostData(host *zabbix.Host, osName, osVersion string, packages []string) (*HostData, string) {
+	osName = NormalizeOSNameWith(osName, hm.cfg.Scan.OSNameMap)
+	osVersion = NormalizeOSVersion(osName, ExtractOSVersion(osVersion))
+
+	if reason := validateHostData(osVersion, packages); reason != "" {
+		return nil, reason
+	}
+
 	return &HostData{
 		Host:      host,
 		OSName:    osName,
 		OSVersion: osVersion,
 		Packages:  packages,
-	}, nil
+	}, ""
+}
+
+// ResolveHostIDs turns a mix of Zabbix host IDs and technical host names
+// into host IDs. Purely numeric entries are treated as IDs; anything else
+// is looked up by technical name.
+func (hm *HostMatrix) ResolveHostIDs(ctx context.Context, refs []string) ([]string, error) {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if isNumeric(ref) {
+			ids = append(ids, ref)
+			continue
+		}
+		host, err := hm.client.GetHostByNameCtx(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve host %q: %w", ref, err)
+		}
+		ids = append(ids, host.HostID)
+	}
+	return ids, nil
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 // validateHostData checks whether a host's data is valid for scanning.
@@ -1,6 +1,15 @@
 package scanner
 
-import "testing"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
 
 func TestParseOSInfo(t *testing.T) {
 	tests := []struct {
@@ -85,3 +94,188 @@ func TestValidateHostData(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatExclusions(t *testing.T) {
+	excluded := map[string]int{
+		"report.py in packages": 1,
+		"too few packages":      8,
+		"no OS information":     3,
+	}
+	want := "8 too few packages, 3 no OS information, 1 report.py in packages"
+	if got := FormatExclusions(excluded); got != want {
+		t.Errorf("FormatExclusions() = %q, want %q", got, want)
+	}
+	if got := ExcludedTotal(excluded); got != 12 {
+		t.Errorf("ExcludedTotal() = %d, want 12", got)
+	}
+	if got := FormatExclusions(nil); got != "" {
+		t.Errorf("FormatExclusions(nil) = %q, want empty", got)
+	}
+}
+
+func TestHostMatrix_Candidates(t *testing.T) {
+	packages := strings.Repeat("openssl 1.1.1f amd64\n", 10)
+	client := &fakeZabbix{
+		hosts: []zabbix.Host{
+			{HostID: "1", Host: "web-01", Name: "Web 01"},
+			{HostID: "2", Host: "db-01", Name: "DB 01"},
+			{HostID: "3", Host: "bare", Name: "Bare"},
+		},
+		items: map[string]map[string]string{
+			"1": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": packages},
+			"2": {"system.sw.os": "Ubuntu 22.04"},
+		},
+	}
+	hm := NewHostMatrix(config.DefaultConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+
+	candidates, err := hm.Candidates(context.Background(), ScanOptions{})
+	if err != nil {
+		t.Fatalf("Candidates: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("got %d candidates, want 3", len(candidates))
+	}
+	if candidates[0].Data == nil || candidates[0].Data.OSName != "ubuntu" {
+		t.Errorf("web-01: data = %+v, reason = %q", candidates[0].Data, candidates[0].Reason)
+	}
+	if candidates[1].Reason != "no package information" {
+		t.Errorf("db-01: reason = %q", candidates[1].Reason)
+	}
+	if candidates[2].Reason != "no OS information" {
+		t.Errorf("bare: reason = %q", candidates[2].Reason)
+	}
+
+	ids, err := hm.ResolveHostIDs(context.Background(), []string{"42", "db-01"})
+	if err != nil {
+		t.Fatalf("ResolveHostIDs: %v", err)
+	}
+	if strings.Join(ids, ",") != "42,2" {
+		t.Errorf("ResolveHostIDs = %v, want [42 2]", ids)
+	}
+}
+
+func TestHostMatrix_CandidatesByItemTag(t *testing.T) {
+	client := &fakeZabbix{
+		hosts: []zabbix.Host{{HostID: "1", Host: "web-01", Name: "Web 01"}},
+		items: map[string]map[string]string{
+			"1": {
+				"system.sw.os": "CentOS 7",
+				"os:report":    "Ubuntu 22.04",
+				"pkgs:report":  strings.Repeat("openssl 1.1.1f amd64\n", 10),
+			},
+		},
+	}
+	cfg := config.DefaultConfig()
+	cfg.Scan.OSItemTag = "os:report"
+	cfg.Scan.PackagesItemTag = "pkgs:report"
+	hm := NewHostMatrix(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+
+	candidates, err := hm.Candidates(context.Background(), ScanOptions{})
+	if err != nil {
+		t.Fatalf("Candidates: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Data == nil {
+		t.Fatalf("candidates = %+v, want one with data", candidates)
+	}
+	if got := candidates[0].Data; got.OSName != "ubuntu" || len(got.Packages) != 10 {
+		t.Errorf("data = %s %d packages, want the tagged items' ubuntu and 10 packages", got.OSName, len(got.Packages))
+	}
+}
+
+func TestHostMatrix_CandidatesFromInventory(t *testing.T) {
+	packages := strings.Repeat("openssl 1.1.1f amd64\n", 10)
+	client := &fakeZabbix{
+		hosts: []zabbix.Host{
+			{HostID: "1", Host: "web-01", Inventory: zabbix.HostInventory{OSFull: "Ubuntu 22.04.3 LTS", SoftwareFull: packages}},
+			{HostID: "2", Host: "db-01", Inventory: zabbix.HostInventory{OS: "CentOS Linux release 7.9.2009 (Core)", SoftwareFull: packages}},
+			{HostID: "3", Host: "app-01", Inventory: zabbix.HostInventory{OSFull: "Debian GNU/Linux 12"}},
+			{HostID: "4", Host: "bare"},
+		},
+	}
+	cfg := config.DefaultConfig()
+	cfg.Scan.DiscoveryMode = config.DiscoveryInventory
+	hm := NewHostMatrix(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), client)
+
+	candidates, err := hm.Candidates(context.Background(), ScanOptions{})
+	if err != nil {
+		t.Fatalf("Candidates: %v", err)
+	}
+	if len(candidates) != 4 {
+		t.Fatalf("got %d candidates, want 4", len(candidates))
+	}
+	if d := candidates[0].Data; d == nil || d.OSName != "ubuntu" || d.OSVersion != "22.04" || len(d.Packages) != 10 {
+		t.Errorf("web-01: data = %+v, reason = %q", d, candidates[0].Reason)
+	}
+	if d := candidates[1].Data; d == nil || d.OSName != "centos" || d.OSVersion != "7" {
+		t.Errorf("db-01 (os fallback): data = %+v, reason = %q", d, candidates[1].Reason)
+	}
+	if candidates[2].Reason != "no package information" {
+		t.Errorf("app-01: reason = %q", candidates[2].Reason)
+	}
+	if candidates[3].Reason != "no OS information" {
+		t.Errorf("bare: reason = %q", candidates[3].Reason)
+	}
+}
+
+func TestFetchHosts_Maintenance(t *testing.T) {
+	packages := strings.Repeat("openssl 1.1.1f amd64\n", 10)
+	newClient := func() *fakeZabbix {
+		return &fakeZabbix{
+			hosts: []zabbix.Host{
+				{HostID: "1", Host: "web-01", Name: "Web 01"},
+				{HostID: "2", Host: "db-01", Name: "DB 01", MaintenanceStatus: zabbix.HostInMaintenance, MaintenanceID: "7"},
+			},
+			items: map[string]map[string]string{
+				"1": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": packages},
+				"2": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": packages},
+			},
+			maintenances: []zabbix.Maintenance{{MaintenanceID: "7", Name: "DB patching"}},
+		}
+	}
+
+	tests := []struct {
+		mode         string
+		wantHosts    string
+		wantExcluded map[string]int
+	}{
+		{config.MaintenanceIgnore, "1,2", map[string]int{}},
+		{config.MaintenanceSkipHosts, "1", map[string]int{ReasonMaintenance: 1}},
+		{config.MaintenanceSkipScan, "", map[string]int{ReasonMaintenance: 1, ReasonMaintenanceWindow: 1}},
+	}
+	for _, tt := range tests {
+		for _, mode := range []string{config.DiscoveryTemplate, config.DiscoveryInventory} {
+			t.Run(tt.mode+"/"+mode, func(t *testing.T) {
+				client := newClient()
+				for i := range client.hosts {
+					client.hosts[i].Inventory = zabbix.HostInventory{OSFull: "Ubuntu 22.04", SoftwareFull: packages}
+				}
+				cfg := config.DefaultConfig()
+				cfg.Scan.Maintenance = tt.mode
+				cfg.Scan.DiscoveryMode = mode
+				var logs strings.Builder
+				hm := NewHostMatrix(cfg, slog.New(slog.NewTextHandler(&logs, nil)), client)
+
+				result, err := hm.FetchHosts(context.Background(), ScanOptions{})
+				if err != nil {
+					t.Fatalf("FetchHosts: %v", err)
+				}
+				var ids []string
+				for _, h := range result.Hosts {
+					ids = append(ids, h.Host.HostID)
+				}
+				if got := strings.Join(ids, ","); got != tt.wantHosts {
+					t.Errorf("hosts = %q, want %q", got, tt.wantHosts)
+				}
+				if FormatExclusions(result.Excluded) != FormatExclusions(tt.wantExcluded) {
+					t.Errorf("excluded = %v, want %v", result.Excluded, tt.wantExcluded)
+				}
+				if result.ScanSkipped != (tt.mode == config.MaintenanceSkipScan) {
+					t.Errorf("ScanSkipped = %v in mode %s", result.ScanSkipped, tt.mode)
+				}
+				if tt.mode != config.MaintenanceIgnore && !strings.Contains(logs.String(), "DB patching") {
+					t.Errorf("maintenance name not logged:\n%s", logs.String())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// incrementalPusher flushes partial scan results while a scan is running,
+// either every `every` scanned hosts or every `interval`, whichever comes
+// first. Flushes run one at a time on a single goroutine; triggers that
+// arrive during a flush are coalesced into one follow-up flush.
+type incrementalPusher struct {
+	every    int64
+	interval time.Duration
+	flush    func(context.Context)
+
+	count   atomic.Int64
+	trigger chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newIncrementalPusher(every int, interval time.Duration, flush func(context.Context)) *incrementalPusher {
+	return &incrementalPusher{
+		every:    int64(every),
+		interval: interval,
+		flush:    flush,
+		trigger:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// start runs the flush loop until stop is called or ctx is cancelled.
+func (p *incrementalPusher) start(ctx context.Context) {
+	var tick <-chan time.Time
+	if p.interval > 0 {
+		ticker := time.NewTicker(p.interval)
+		tick = ticker.C
+		go func() {
+			<-p.stopped
+			ticker.Stop()
+		}()
+	}
+
+	go func() {
+		defer close(p.stopped)
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ctx.Done():
+				return
+			case <-p.trigger:
+			case <-tick:
+			}
+			p.flush(ctx)
+		}
+	}()
+}
+
+// hostDone records a scanned host and requests a flush every p.every hosts.
+func (p *incrementalPusher) hostDone() {
+	if p.every <= 0 || p.count.Add(1)%p.every != 0 {
+		return
+	}
+	select {
+	case p.trigger <- struct{}{}:
+	default: // a flush is already pending
+	}
+}
+
+// stop ends the flush loop, waiting for an in-flight flush to finish.
+func (p *incrementalPusher) stop() {
+	close(p.done)
+	<-p.stopped
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIncrementalPusher_EveryNHosts(t *testing.T) {
+	var flushes atomic.Int32
+	flushed := make(chan struct{}, 10)
+	p := newIncrementalPusher(3, 0, func(context.Context) {
+		flushes.Add(1)
+		flushed <- struct{}{}
+	})
+	p.start(context.Background())
+
+	for i := 0; i < 7; i++ {
+		p.hostDone()
+		if (i+1)%3 == 0 {
+			select {
+			case <-flushed:
+			case <-time.After(time.Second):
+				t.Fatalf("no flush after %d hosts", i+1)
+			}
+		}
+	}
+	p.stop()
+
+	if got := flushes.Load(); got != 2 {
+		t.Errorf("flushes = %d, want 2", got)
+	}
+}
+
+func TestIncrementalPusher_Interval(t *testing.T) {
+	flushed := make(chan struct{}, 10)
+	p := newIncrementalPusher(0, 10*time.Millisecond, func(context.Context) {
+		flushed <- struct{}{}
+	})
+	p.start(context.Background())
+	defer p.stop()
+
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("no flush from interval")
+	}
+}
+
+func TestIncrementalPusher_StopWaitsForFlush(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+	p := newIncrementalPusher(1, 0, func(context.Context) {
+		close(started)
+		<-release
+		finished.Store(true)
+	})
+	p.start(context.Background())
+	p.hostDone()
+	<-started
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	p.stop()
+	if !finished.Load() {
+		t.Error("stop returned before the in-flight flush finished")
+	}
+}
+
+func TestAggregator_ConcurrentSnapshot(t *testing.T) {
+	agg := NewAggregator()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			agg.AddHost(HostEntry{
+				HostID:   "h",
+				Score:    5,
+				Packages: []PackageVuln{{Name: "openssl", Version: "1.0", Score: 5}},
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = agg.GetResults()
+			_ = agg.GetStatistics()
+		}()
+	}
+	wg.Wait()
+
+	if got := agg.GetResults().HostsScanned; got != 50 {
+		t.Errorf("HostsScanned = %d, want 50", got)
+	}
+}
@@ -2,15 +2,21 @@ package scanner
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/remediation"
 	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
 )
 
 // LLDGenerator generates Low-Level Discovery data for Zabbix
 type LLDGenerator struct {
-	naming config.NamingConfig
+	naming           config.NamingConfig
+	maxAffectedHosts int
+	pushCVSS         bool
 }
 
 // NewLLDGenerator creates a new LLD generator
@@ -18,6 +24,43 @@ func NewLLDGenerator(naming config.NamingConfig) *LLDGenerator {
 	return &LLDGenerator{naming: naming}
 }
 
+// WithMaxAffectedHosts caps the affected-host name lists in package and
+// bulletin LLD macros at n entries (0 = no cap). The host ID lists stay
+// complete: ztc fix plans from them. It returns g for chaining.
+func (g *LLDGenerator) WithMaxAffectedHosts(n int) *LLDGenerator {
+	g.maxAffectedHosts = n
+	return g
+}
+
+// WithPackageItemValue selects what GeneratePackageScoreData and
+// GenerateBulletinScoreData push: config.PackageValueCount or
+// config.PackageValueCVSS. It returns g for chaining.
+func (g *LLDGenerator) WithPackageItemValue(v string) *LLDGenerator {
+	g.pushCVSS = v == config.PackageValueCVSS
+	return g
+}
+
+// itemValue formats a package or bulletin item value: the affected host
+// count, or the CVSS score when configured.
+func (g *LLDGenerator) itemValue(score float64, affectedHosts int) string {
+	if g.pushCVSS {
+		return fmt.Sprintf("%.1f", score)
+	}
+	return fmt.Sprintf("%d", affectedHosts)
+}
+
+// capHosts truncates an affected-host name list to the configured maximum
+// and appends an "... and N more" entry. Counts in the score items are not
+// affected.
+func (g *LLDGenerator) capHosts(hosts []string) []string {
+	if g.maxAffectedHosts <= 0 || len(hosts) <= g.maxAffectedHosts {
+		return hosts
+	}
+	capped := make([]string, g.maxAffectedHosts, g.maxAffectedHosts+1)
+	copy(capped, hosts)
+	return append(capped, fmt.Sprintf("%s%d more", zabbix.TruncatedListPrefix, len(hosts)-g.maxAffectedHosts))
+}
+
 // GenerateHostsLLD generates LLD data for hosts
 func (g *LLDGenerator) GenerateHostsLLD(hosts []HostEntry) *zabbix.LLDData {
 	data := &zabbix.LLDData{
@@ -25,14 +68,28 @@ func (g *LLDGenerator) GenerateHostsLLD(hosts []HostEntry) *zabbix.LLDData {
 	}
 
 	for _, host := range hosts {
+		// {#H.GROUP} is the first group Zabbix returned; {#H.GROUPS} lists
+		// all of them.
+		groups := make([]string, len(host.Groups))
+		for i, g := range host.Groups {
+			groups[i] = g.Name
+		}
+		var group string
+		if len(groups) > 0 {
+			group = groups[0]
+		}
+
 		entry := map[string]interface{}{
-			"{#H.ID}":    host.HostID,
-			"{#H.HOST}":  host.Host,
-			"{#H.VNAME}": host.Name,
-			"{#H.SCORE}": fmt.Sprintf("%.1f", host.Score),
-			"{#H.OS}":    host.OSName,
-			"{#H.OSVER}": host.OSVersion,
-			"{#H.FIX}":   host.CumulativeFix,
+			"{#H.ID}":     sanitizeMacro(host.HostID),
+			"{#H.HOST}":   sanitizeMacro(host.Host),
+			"{#H.VNAME}":  sanitizeMacro(host.Name),
+			"{#H.SCORE}":  fmt.Sprintf("%.1f", host.Score),
+			"{#H.OS}":     sanitizeMacro(host.OSName),
+			"{#H.OSVER}":  sanitizeMacro(host.OSVersion),
+			"{#H.FIX}":    sanitizeMacro(host.CumulativeFix),
+			"{#H.FIXCMD}": sanitizeMacro(hostFixCommand(host)),
+			"{#H.GROUP}":  sanitizeMacro(group),
+			"{#H.GROUPS}": joinMacros(groups, ", "),
 		}
 		data.Data = append(data.Data, entry)
 	}
@@ -40,6 +97,19 @@ func (g *LLDGenerator) GenerateHostsLLD(hosts []HostEntry) *zabbix.LLDData {
 	return data
 }
 
+// hostFixCommand returns the command ztc fix would run on the host, built
+// from its vulnerable package names like the fixer does.
+func hostFixCommand(host HostEntry) string {
+	var names []string
+	for _, p := range host.Packages {
+		if !slices.Contains(names, p.Name) {
+			names = append(names, p.Name)
+		}
+	}
+	command, _ := remediation.FixCommand(host.OSName, names)
+	return command
+}
+
 // GeneratePackagesLLD generates LLD data for packages
 func (g *LLDGenerator) GeneratePackagesLLD(packages []PackageEntry) *zabbix.LLDData {
 	data := &zabbix.LLDData{
@@ -53,27 +123,35 @@ func (g *LLDGenerator) GeneratePackagesLLD(packages []PackageEntry) *zabbix.LLDD
 		// First bulletin ID for vulners.com link
 		pkgURL := ""
 		if len(pkg.Bulletins) > 0 {
-			pkgURL = pkg.Bulletins[0]
+			pkgURL = sanitizeMacro(pkg.Bulletins[0])
 		}
 
+		name := sanitizeMacro(pkg.Name)
+		version := sanitizeMacro(pkg.Version)
+		arch := sanitizeMacro(pkg.Arch)
+		fix := sanitizeMacro(pkg.Fix)
+
 		// Package ID matches Python's {#PKG.ID} format
-		pkgID := fmt.Sprintf("%s %s %s", pkg.Name, pkg.Version, pkg.Arch)
+		pkgID := fmt.Sprintf("%s %s %s", name, version, arch)
 
 		entry := map[string]interface{}{
-			"{#P.NAME}":     pkg.Name,
-			"{#P.VERSION}":  pkg.Version,
-			"{#P.ARCH}":     pkg.Arch,
+			"{#P.NAME}":     name,
+			"{#P.VERSION}":  version,
+			"{#P.ARCH}":     arch,
 			"{#P.SCORE}":    fmt.Sprintf("%.1f", pkg.Score),
-			"{#P.FIX}":      pkg.Fix,
+			"{#P.FIX}":      fix,
 			"{#P.AFFECTED}": affected,
-			"{#P.HOSTS}":    strings.Join(pkg.AffectedHosts, ","),
+			"{#P.HOSTS}":    joinMacros(pkg.AffectedHosts, ","),
 			// Python-compatible trigger macros
 			"{#PKG.ID}":     pkgID,
 			"{#PKG.SCORE}":  fmt.Sprintf("%.1f", pkg.Score),
 			"{#PKG.IMPACT}": impact,
 			"{#PKG.URL}":    pkgURL,
-			"{#PKG.HOSTS}":  strings.Join(pkg.AffectedHostNames, "\n"),
-			"{#PKG.FIX}":    pkg.Fix,
+			"{#PKG.HOSTS}":  joinMacros(g.capHosts(pkg.AffectedHostNames), "\n"),
+			"{#PKG.FIX}":    fix,
+		}
+		if g.naming.HashPackageKeys {
+			entry["{#P.KEY}"] = zabbix.PackageKeyHash(pkg.Name, pkg.Version, pkg.Arch)
 		}
 		data.Data = append(data.Data, entry)
 	}
@@ -91,19 +169,21 @@ func (g *LLDGenerator) GenerateBulletinsLLD(bulletins []BulletinEntry) *zabbix.L
 		affected := len(bulletin.AffectedHosts)
 		impact := int(float64(affected) * bulletin.Score)
 
+		id := sanitizeMacro(bulletin.ID)
+
 		entry := map[string]interface{}{
-			"{#B.ID}":       bulletin.ID,
-			"{#B.TYPE}":     bulletin.Type,
+			"{#B.ID}":       id,
+			"{#B.TYPE}":     sanitizeMacro(bulletin.Type),
 			"{#B.SCORE}":    fmt.Sprintf("%.1f", bulletin.Score),
-			"{#B.CVES}":     strings.Join(bulletin.CVEs, ","),
+			"{#B.CVES}":     joinMacros(bulletin.CVEs, ","),
 			"{#B.AFFECTED}": affected,
-			"{#B.HOSTS}":    strings.Join(bulletin.AffectedHosts, ","),
-			"{#B.PKGS}":     strings.Join(bulletin.AffectedPkgs, ","),
+			"{#B.HOSTS}":    joinMacros(bulletin.AffectedHosts, ","),
+			"{#B.PKGS}":     joinMacros(bulletin.AffectedPkgs, ","),
 			// Python-compatible trigger macros
-			"{#BULLETIN.ID}":     bulletin.ID,
+			"{#BULLETIN.ID}":     id,
 			"{#BULLETIN.SCORE}":  fmt.Sprintf("%.1f", bulletin.Score),
 			"{#BULLETIN.IMPACT}": impact,
-			"{#BULLETIN.HOSTS}":  strings.Join(bulletin.AffectedHostNames, "\n"),
+			"{#BULLETIN.HOSTS}":  joinMacros(g.capHosts(bulletin.AffectedHostNames), "\n"),
 		}
 		data.Data = append(data.Data, entry)
 	}
@@ -111,14 +191,135 @@ func (g *LLDGenerator) GenerateBulletinsLLD(bulletins []BulletinEntry) *zabbix.L
 	return data
 }
 
-// GenerateHostScoreData generates individual score data for each host
+// GenerateGroupsLLD generates LLD data for host groups with statistics.
+func (g *LLDGenerator) GenerateGroupsLLD(groups []GroupStatistics) *zabbix.LLDData {
+	data := &zabbix.LLDData{
+		Data: make([]map[string]interface{}, 0, len(groups)),
+	}
+
+	for _, group := range groups {
+		data.Data = append(data.Data, map[string]interface{}{
+			"{#G.ID}":   sanitizeMacro(group.GroupID),
+			"{#G.NAME}": sanitizeMacro(group.Name),
+		})
+	}
+
+	return data
+}
+
+// sanitizeMacro makes a value safe for an LLD macro: invalid UTF-8 is
+// replaced and control characters are removed (tabs and line breaks become
+// spaces), so one odd package string cannot get the whole LLD rejected.
+func sanitizeMacro(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// joinMacros sanitizes each value before joining, so sep itself may be a
+// line break.
+func joinMacros(values []string, sep string) string {
+	clean := make([]string, len(values))
+	for i, v := range values {
+		clean[i] = sanitizeMacro(v)
+	}
+	return strings.Join(clean, sep)
+}
+
+// formatTopCVEs renders TopCVEs for a text item, one "CVE count" per line.
+func formatTopCVEs(top []CVECount) string {
+	lines := make([]string, len(top))
+	for i, c := range top {
+		lines[i] = fmt.Sprintf("%s %d", c.CVE, c.Count)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// packageItemKey returns the item key discovered for pkg. Like the LLD
+// macros it is built from sanitized values; hashed keys use the raw ones.
+func packageItemKey(pkg PackageEntry, hashed bool) string {
+	if hashed {
+		return zabbix.PackageItemKey(pkg.Name, pkg.Version, pkg.Arch, true)
+	}
+	return zabbix.PackageItemKey(sanitizeMacro(pkg.Name), sanitizeMacro(pkg.Version), sanitizeMacro(pkg.Arch), false)
+}
+
+// discoveredKeys returns the item keys that the rows of lld discover
+// through the item prototype key protoKey.
+func discoveredKeys(lld *zabbix.LLDData, protoKey string) map[string]bool {
+	discovered := make(map[string]bool, len(lld.Data))
+	for _, row := range lld.Data {
+		discovered[zabbix.ExpandPrototypeKey(protoKey, row)] = true
+	}
+	return discovered
+}
+
+// undiscoveredKeys returns the keys in data that no row of lld discovers
+// through the item prototype key protoKey.
+func undiscoveredKeys(lld *zabbix.LLDData, protoKey string, data []zabbix.SenderData) []string {
+	discovered := discoveredKeys(lld, protoKey)
+	var missing []string
+	for _, d := range data {
+		if !discovered[d.Key] {
+			missing = append(missing, d.Key)
+		}
+	}
+	return missing
+}
+
+// discoveredOnly returns the values in data whose keys a row of lld
+// discovers through the item prototype key protoKey.
+func discoveredOnly(lld *zabbix.LLDData, protoKey string, data []zabbix.SenderData) []zabbix.SenderData {
+	discovered := discoveredKeys(lld, protoKey)
+	var kept []zabbix.SenderData
+	for _, d := range data {
+		if discovered[d.Key] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// keepHostRows appends to lld the rows of prev whose hostsMacro (a host ID
+// or comma-separated list of them) names one of hostIDs and whose item key,
+// per protoKey, lld does not discover yet. It returns the number of rows
+// kept.
+func keepHostRows(lld, prev *zabbix.LLDData, protoKey, hostsMacro string, hostIDs []string) int {
+	discovered := discoveredKeys(lld, protoKey)
+	kept := 0
+	for _, row := range prev.Data {
+		hosts, _ := row[hostsMacro].(string)
+		if !slices.ContainsFunc(strings.Split(hosts, ","), func(id string) bool {
+			return slices.Contains(hostIDs, strings.TrimSpace(id))
+		}) {
+			continue
+		}
+		if key := zabbix.ExpandPrototypeKey(protoKey, row); !discovered[key] {
+			discovered[key] = true
+			lld.Data = append(lld.Data, row)
+			kept++
+		}
+	}
+	return kept
+}
+
+// GenerateHostScoreData generates individual score data for each host. Keys
+// use the sanitized values of the LLD macros so they match the discovered
+// items.
 func (g *LLDGenerator) GenerateHostScoreData(hosts []HostEntry) []zabbix.SenderData {
 	var data []zabbix.SenderData
 
 	for _, host := range hosts {
 		data = append(data, zabbix.SenderData{
 			Host:  g.naming.HostsHost,
-			Key:   fmt.Sprintf("vulners.hosts[%s]", host.HostID),
+			Key:   zabbix.HostItemKey(sanitizeMacro(host.HostID)),
 			Value: fmt.Sprintf("%.1f", host.Score),
 		})
 	}
@@ -127,16 +328,16 @@ func (g *LLDGenerator) GenerateHostScoreData(hosts []HostEntry) []zabbix.SenderD
 }
 
 // GeneratePackageScoreData generates individual data for each package.
-// Value is the affected host count (matching Python behavior).
+// Value is the affected host count (matching Python behavior), or the
+// package's CVSS score with WithPackageItemValue(config.PackageValueCVSS).
 func (g *LLDGenerator) GeneratePackageScoreData(packages []PackageEntry) []zabbix.SenderData {
 	var data []zabbix.SenderData
 
 	for _, pkg := range packages {
-		key := fmt.Sprintf("vulners.packages[%s,%s,%s]", pkg.Name, pkg.Version, pkg.Arch)
 		data = append(data, zabbix.SenderData{
 			Host:  g.naming.PackagesHost,
-			Key:   key,
-			Value: fmt.Sprintf("%d", len(pkg.AffectedHosts)),
+			Key:   packageItemKey(pkg, g.naming.HashPackageKeys),
+			Value: g.itemValue(pkg.Score, len(pkg.AffectedHosts)),
 		})
 	}
 
@@ -144,21 +345,62 @@ func (g *LLDGenerator) GeneratePackageScoreData(packages []PackageEntry) []zabbi
 }
 
 // GenerateBulletinScoreData generates individual data for each bulletin.
-// Value is the affected host count (matching Python behavior).
+// Value is the affected host count (matching Python behavior), or the
+// bulletin's CVSS score with WithPackageItemValue(config.PackageValueCVSS).
 func (g *LLDGenerator) GenerateBulletinScoreData(bulletins []BulletinEntry) []zabbix.SenderData {
 	var data []zabbix.SenderData
 
 	for _, bulletin := range bulletins {
 		data = append(data, zabbix.SenderData{
 			Host:  g.naming.BulletinsHost,
-			Key:   fmt.Sprintf("vulners.bulletins[%s]", bulletin.ID),
-			Value: fmt.Sprintf("%d", len(bulletin.AffectedHosts)),
+			Key:   zabbix.BulletinItemKey(sanitizeMacro(bulletin.ID)),
+			Value: g.itemValue(bulletin.Score, len(bulletin.AffectedHosts)),
 		})
 	}
 
 	return data
 }
 
+// GenerateTimingData generates the scan phase durations, in seconds.
+func (g *LLDGenerator) GenerateTimingData(t ScanTimings) []zabbix.SenderData {
+	seconds := func(d time.Duration) string { return fmt.Sprintf("%.3f", d.Seconds()) }
+	return []zabbix.SenderData{
+		{Host: g.naming.StatisticsHost, Key: "vulners.stats[scan_duration_seconds]", Value: seconds(t.Total())},
+		{Host: g.naming.StatisticsHost, Key: "vulners.stats[fetch_duration_seconds]", Value: seconds(t.FetchHosts)},
+		{Host: g.naming.StatisticsHost, Key: "vulners.stats[audit_duration_seconds]", Value: seconds(t.Audit)},
+		{Host: g.naming.StatisticsHost, Key: "vulners.stats[lld_delay_seconds]", Value: seconds(t.LLDDelay)},
+		{Host: g.naming.StatisticsHost, Key: "vulners.stats[push_duration_seconds]", Value: seconds(t.Push)},
+	}
+}
+
+// GenerateGroupStatisticsData generates the per-group statistics items on
+// the statistics host. Keys use the group ID, which unlike the name is
+// always safe as an item key parameter.
+func (g *LLDGenerator) GenerateGroupStatisticsData(groups []GroupStatistics) []zabbix.SenderData {
+	var data []zabbix.SenderData
+
+	for _, group := range groups {
+		for _, m := range []struct{ metric, value string }{
+			{"total_hosts", fmt.Sprintf("%d", group.TotalHosts)},
+			{"vuln_hosts", fmt.Sprintf("%d", group.VulnerableHosts)},
+			{"total_vulns", fmt.Sprintf("%d", group.TotalPackages)},
+			{"total_bulletins", fmt.Sprintf("%d", group.TotalBulletins)},
+			{"total_cves", fmt.Sprintf("%d", group.TotalCVEs)},
+			{"max_score", fmt.Sprintf("%.1f", group.MaxCVSS)},
+			{"avg_score", fmt.Sprintf("%.2f", group.AvgCVSS)},
+			{"fleet_risk", fmt.Sprintf("%.2f", group.FleetRisk)},
+		} {
+			data = append(data, zabbix.SenderData{
+				Host:  g.naming.StatisticsHost,
+				Key:   zabbix.GroupStatsItemKey(group.GroupID, m.metric),
+				Value: m.value,
+			})
+		}
+	}
+
+	return data
+}
+
 // GenerateStatisticsData generates statistics data using Python-compatible keys
 // and backward-compatible Go keys.
 func (g *LLDGenerator) GenerateStatisticsData(stats Statistics) []zabbix.SenderData {
@@ -181,6 +423,8 @@ func (g *LLDGenerator) GenerateStatisticsData(stats Statistics) []zabbix.SenderD
 		{Host: g.naming.StatisticsHost, Key: "vulners.stats[total_cves]", Value: fmt.Sprintf("%d", stats.TotalCVEs)},
 		{Host: g.naming.StatisticsHost, Key: "vulners.stats[max_score]", Value: fmt.Sprintf("%.1f", stats.MaxCVSS)},
 		{Host: g.naming.StatisticsHost, Key: "vulners.stats[avg_score]", Value: fmt.Sprintf("%.2f", stats.AvgCVSS)},
+		{Host: g.naming.StatisticsHost, Key: "vulners.stats[top_cves]", Value: formatTopCVEs(stats.TopCVEs)},
+		{Host: g.naming.StatisticsHost, Key: "vulners.stats[fleet_risk]", Value: fmt.Sprintf("%.2f", stats.FleetRisk)},
 	}
 
 	// Histogram buckets (Python-compatible)
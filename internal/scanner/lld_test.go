@@ -2,10 +2,16 @@ package scanner
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/remediation"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
 )
 
 // testNaming returns default NamingConfig for tests.
@@ -64,6 +70,44 @@ func TestGenerateHostsLLD(t *testing.T) {
 			t.Errorf("{#H.FIX} should be empty, got %v", data.Data[0]["{#H.FIX}"])
 		}
 	})
+
+	t.Run("fix command matches the fixer", func(t *testing.T) {
+		hosts := []HostEntry{
+			{HostID: "1", OSName: "centos", Packages: []PackageVuln{
+				{Name: "openssl", Version: "1.0.2k"}, {Name: "openssl", Version: "1.0.2k-libs"}, {Name: "curl"},
+			}},
+			{HostID: "2", OSName: "ubuntu"},
+		}
+		data := gen.GenerateHostsLLD(hosts)
+		want, _ := remediation.FixCommand("centos", []string{"openssl", "curl"})
+		if got := data.Data[0]["{#H.FIXCMD}"]; got != want {
+			t.Errorf("{#H.FIXCMD} = %v, want %q", got, want)
+		}
+		if got := data.Data[1]["{#H.FIXCMD}"]; got != "apt-get update && apt-get upgrade -y" {
+			t.Errorf("{#H.FIXCMD} without packages = %v, want a full upgrade", got)
+		}
+	})
+
+	t.Run("host groups", func(t *testing.T) {
+		hosts := []HostEntry{
+			{HostID: "1", Groups: []zabbix.HostGroup{
+				{GroupID: "2", Name: "Linux servers"},
+				{GroupID: "7", Name: "Prod"},
+			}},
+			{HostID: "2"},
+		}
+		data := gen.GenerateHostsLLD(hosts)
+		if got := data.Data[0]["{#H.GROUP}"]; got != "Linux servers" {
+			t.Errorf("{#H.GROUP} = %v, want Linux servers", got)
+		}
+		if got := data.Data[0]["{#H.GROUPS}"]; got != "Linux servers, Prod" {
+			t.Errorf("{#H.GROUPS} = %v, want %q", got, "Linux servers, Prod")
+		}
+		if data.Data[1]["{#H.GROUP}"] != "" || data.Data[1]["{#H.GROUPS}"] != "" {
+			t.Errorf("host without groups: {#H.GROUP} = %v, {#H.GROUPS} = %v, want empty",
+				data.Data[1]["{#H.GROUP}"], data.Data[1]["{#H.GROUPS}"])
+		}
+	})
 }
 
 func TestGeneratePackagesLLD(t *testing.T) {
@@ -151,6 +195,103 @@ func TestGeneratePackagesLLD(t *testing.T) {
 	})
 }
 
+func TestGeneratePackagesLLD_SanitizesMacros(t *testing.T) {
+	gen := NewLLDGenerator(testNaming())
+	pkgs := []PackageEntry{{
+		Name:              "evil\npkg\x00name",
+		Version:           "1.0\xff",
+		Arch:              "amd64",
+		Score:             5.0,
+		Fix:               "apt-get install\tevil\r\n",
+		AffectedHosts:     []string{"10"},
+		AffectedHostNames: []string{"web\x01-01", "db-01"},
+	}}
+
+	data := gen.GeneratePackagesLLD(pkgs)
+	entry := data.Data[0]
+
+	if got := entry["{#P.NAME}"]; got != "evil pkgname" {
+		t.Errorf("{#P.NAME} = %q, want %q", got, "evil pkgname")
+	}
+	if got := entry["{#P.VERSION}"]; got != "1.0\uFFFD" {
+		t.Errorf("{#P.VERSION} = %q, want invalid UTF-8 replaced", got)
+	}
+	if got := entry["{#P.FIX}"]; got != "apt-get install evil  " {
+		t.Errorf("{#P.FIX} = %q", got)
+	}
+	// The line-break separator between host names is kept.
+	if got := entry["{#PKG.HOSTS}"]; got != "web-01\ndb-01" {
+		t.Errorf("{#PKG.HOSTS} = %q", got)
+	}
+
+	for macro, v := range entry {
+		str, ok := v.(string)
+		if !ok || macro == "{#PKG.HOSTS}" {
+			continue
+		}
+		if !utf8.ValidString(str) {
+			t.Errorf("%s is not valid UTF-8: %q", macro, str)
+		}
+		for _, r := range str {
+			if unicode.IsControl(r) {
+				t.Errorf("%s contains control character %U: %q", macro, r, str)
+			}
+		}
+	}
+}
+
+func TestLLD_MaxAffectedHosts(t *testing.T) {
+	hosts := make([]string, 150)
+	names := make([]string, 150)
+	for i := range hosts {
+		hosts[i] = fmt.Sprint(i + 1)
+		names[i] = fmt.Sprintf("host-%d", i+1)
+	}
+	pkgs := []PackageEntry{{Name: "openssl", AffectedHosts: hosts, AffectedHostNames: names}}
+	bulletins := []BulletinEntry{{ID: "USN-1", AffectedHosts: hosts, AffectedHostNames: names}}
+
+	t.Run("capped", func(t *testing.T) {
+		gen := NewLLDGenerator(testNaming()).WithMaxAffectedHosts(100)
+
+		p := gen.GeneratePackagesLLD(pkgs).Data[0]
+		// The host ID list ztc fix plans from is never capped.
+		if ids := strings.Split(p["{#P.HOSTS}"].(string), ","); len(ids) != 150 {
+			t.Errorf("{#P.HOSTS} has %d entries, want 150", len(ids))
+		}
+		hostNames := strings.Split(p["{#PKG.HOSTS}"].(string), "\n")
+		if len(hostNames) != 101 || hostNames[100] != "... and 50 more" {
+			t.Errorf("{#PKG.HOSTS} has %d entries, last %q", len(hostNames), hostNames[len(hostNames)-1])
+		}
+		// The count macro still reflects every affected host.
+		if p["{#P.AFFECTED}"] != 150 {
+			t.Errorf("{#P.AFFECTED} = %v, want 150", p["{#P.AFFECTED}"])
+		}
+
+		b := gen.GenerateBulletinsLLD(bulletins).Data[0]
+		if got := strings.Count(b["{#B.HOSTS}"].(string), ","); got != 149 {
+			t.Errorf("{#B.HOSTS} has %d commas, want 149", got)
+		}
+		if !strings.HasSuffix(b["{#BULLETIN.HOSTS}"].(string), "\n... and 50 more") {
+			t.Errorf("{#BULLETIN.HOSTS} not truncated")
+		}
+
+		// Score items keep the full count.
+		if v := gen.GeneratePackageScoreData(pkgs)[0].Value; v != "150" {
+			t.Errorf("package score value = %s, want 150", v)
+		}
+	})
+
+	t.Run("under the cap or disabled", func(t *testing.T) {
+		for _, limit := range []int{0, 150, 500} {
+			gen := NewLLDGenerator(testNaming()).WithMaxAffectedHosts(limit)
+			p := gen.GeneratePackagesLLD(pkgs).Data[0]
+			if got := len(strings.Split(p["{#P.HOSTS}"].(string), ",")); got != 150 {
+				t.Errorf("limit %d: {#P.HOSTS} has %d entries, want 150", limit, got)
+			}
+		}
+	})
+}
+
 func TestGenerateBulletinsLLD(t *testing.T) {
 	gen := NewLLDGenerator(testNaming())
 
@@ -266,6 +407,53 @@ func TestGenerateHostScoreData(t *testing.T) {
 	})
 }
 
+func TestGeneratePackageScoreData_HashedKeys(t *testing.T) {
+	naming := testNaming()
+	naming.HashPackageKeys = true
+	gen := NewLLDGenerator(naming)
+
+	pkg := PackageEntry{
+		Name:          strings.Repeat("very-long-package-name-", 100),
+		Version:       "1.0,beta",
+		Arch:          "x86_64",
+		Score:         7.5,
+		AffectedHosts: []string{"10"},
+	}
+
+	data := gen.GeneratePackageScoreData([]PackageEntry{pkg})
+	if len(data) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(data))
+	}
+	key := data[0].Key
+	if len(key) > 64 {
+		t.Errorf("hashed key too long (%d chars): %s", len(key), key)
+	}
+	if strings.Contains(key, ",") || strings.Contains(key, "very-long") {
+		t.Errorf("hashed key leaks package identity: %s", key)
+	}
+
+	// Deterministic, and distinct from a package differing only in arch.
+	if again := gen.GeneratePackageScoreData([]PackageEntry{pkg})[0].Key; again != key {
+		t.Errorf("key not deterministic: %s vs %s", key, again)
+	}
+	other := pkg
+	other.Arch = "i686"
+	if gen.GeneratePackageScoreData([]PackageEntry{other})[0].Key == key {
+		t.Error("different arch produced the same key")
+	}
+
+	// The LLD entry carries the hash for the prototype key and keeps the
+	// readable name in its own macro.
+	lld := gen.GeneratePackagesLLD([]PackageEntry{pkg})
+	entry := lld.Data[0]
+	if want := "vulners.packages[" + entry["{#P.KEY}"].(string) + "]"; want != key {
+		t.Errorf("LLD {#P.KEY} gives %s, sender key is %s", want, key)
+	}
+	if entry["{#P.NAME}"] != pkg.Name {
+		t.Errorf("{#P.NAME} = %v, want the readable name", entry["{#P.NAME}"])
+	}
+}
+
 func TestGeneratePackageScoreData_HostCount(t *testing.T) {
 	naming := testNaming()
 	gen := NewLLDGenerator(naming)
@@ -350,7 +538,9 @@ func TestGenerateStatisticsData(t *testing.T) {
 		AvgCVSS:         6.25,
 		MinCVSS:         2.1,
 		MedianCVSS:      5.5,
+		FleetRisk:       43.75,
 		Histogram:       [11]int{3, 0, 1, 0, 2, 1, 0, 1, 0, 1, 1},
+		TopCVEs:         []CVECount{{"CVE-2024-0001", 4}, {"CVE-2024-0002", 2}},
 	}
 
 	data := gen.GenerateStatisticsData(stats)
@@ -396,6 +586,8 @@ func TestGenerateStatisticsData(t *testing.T) {
 			{"vulners.stats[total_cves]", "42"},
 			{"vulners.stats[max_score]", "9.8"},
 			{"vulners.stats[avg_score]", "6.25"},
+			{"vulners.stats[top_cves]", "CVE-2024-0001 4\nCVE-2024-0002 2"},
+			{"vulners.stats[fleet_risk]", "43.75"},
 		}
 		for _, tc := range goKeys {
 			if got, ok := kvMap[tc.key]; !ok {
@@ -437,9 +629,9 @@ func TestGenerateStatisticsData(t *testing.T) {
 	})
 
 	t.Run("total item count", func(t *testing.T) {
-		// 5 Python prepare + 3 Python scan aliases + 7 Go-compat + 11 histogram = 26
-		if len(data) != 26 {
-			t.Errorf("expected 26 data items, got %d", len(data))
+		// 5 Python prepare + 3 Python scan aliases + 9 Go-compat + 11 histogram = 28
+		if len(data) != 28 {
+			t.Errorf("expected 28 data items, got %d", len(data))
 		}
 	})
 }
@@ -467,6 +659,35 @@ func TestGenerateStatisticsData_ZeroStats(t *testing.T) {
 	}
 }
 
+func TestGenerateTimingData(t *testing.T) {
+	gen := NewLLDGenerator(testNaming())
+	data := gen.GenerateTimingData(ScanTimings{
+		FetchHosts: 1500 * time.Millisecond,
+		Audit:      42 * time.Second,
+		LLDDelay:   5 * time.Second,
+		Push:       250 * time.Millisecond,
+	})
+
+	want := map[string]string{
+		"vulners.stats[scan_duration_seconds]":  "48.750",
+		"vulners.stats[fetch_duration_seconds]": "1.500",
+		"vulners.stats[audit_duration_seconds]": "42.000",
+		"vulners.stats[lld_delay_seconds]":      "5.000",
+		"vulners.stats[push_duration_seconds]":  "0.250",
+	}
+	if len(data) != len(want) {
+		t.Fatalf("got %d items, want %d", len(data), len(want))
+	}
+	for _, d := range data {
+		if d.Host != "vulners.statistics" {
+			t.Errorf("%s: host = %q", d.Key, d.Host)
+		}
+		if d.Value != want[d.Key] {
+			t.Errorf("%s = %q, want %q", d.Key, d.Value, want[d.Key])
+		}
+	}
+}
+
 func TestGenerateMultiplePackagesLLD(t *testing.T) {
 	gen := NewLLDGenerator(testNaming())
 
@@ -545,3 +766,165 @@ func TestLLDHostNamesNewlineSeparated(t *testing.T) {
 		}
 	})
 }
+
+func TestGenerateGroupStatistics(t *testing.T) {
+	naming := testNaming()
+	gen := NewLLDGenerator(naming)
+	groups := []GroupStatistics{
+		{GroupID: "10", Name: "Prod, EU", Statistics: Statistics{TotalHosts: 2, VulnerableHosts: 1, MaxCVSS: 9.8, FleetRisk: 9.8}},
+		{GroupID: "20", Name: "Staging"},
+	}
+
+	lld := gen.GenerateGroupsLLD(groups)
+	if len(lld.Data) != 2 || lld.Data[0]["{#G.ID}"] != "10" || lld.Data[0]["{#G.NAME}"] != "Prod, EU" {
+		t.Errorf("groups LLD = %v", lld.Data)
+	}
+
+	data := gen.GenerateGroupStatisticsData(groups)
+	if len(data) != 2*len(zabbix.GroupStatsMetrics) {
+		t.Fatalf("got %d items, want %d", len(data), 2*len(zabbix.GroupStatsMetrics))
+	}
+	kv := make(map[string]string)
+	for _, d := range data {
+		if d.Host != naming.StatisticsHost {
+			t.Errorf("item %q has wrong host: %q", d.Key, d.Host)
+		}
+		kv[d.Key] = d.Value
+	}
+	// Every metric has a template prototype.
+	for _, metric := range zabbix.GroupStatsMetrics {
+		if _, ok := kv[zabbix.GroupStatsItemKey("20", metric)]; !ok {
+			t.Errorf("missing %s for group 20", metric)
+		}
+	}
+	for key, want := range map[string]string{
+		"vulners.group.stats[10,total_hosts]": "2",
+		"vulners.group.stats[10,vuln_hosts]":  "1",
+		"vulners.group.stats[10,max_score]":   "9.8",
+		"vulners.group.stats[10,fleet_risk]":  "9.80",
+	} {
+		if kv[key] != want {
+			t.Errorf("%s = %q, want %q", key, kv[key], want)
+		}
+	}
+}
+
+// Package and bulletin items may be created as numeric unsigned
+// (scan.score_item_value_type), so their values must always be integers.
+func TestScoreData_PackageItemValue(t *testing.T) {
+	packages := []PackageEntry{
+		{Name: "openssl", Version: "1.1", Arch: "amd64", Score: 9.8, AffectedHosts: []string{"1", "2"}},
+	}
+	bulletins := []BulletinEntry{{ID: "USN-1", Score: 7.5, AffectedHosts: []string{"1"}}}
+
+	tests := []struct {
+		mode          string
+		wantPackage   string
+		wantBulletins string
+	}{
+		{"count", "2", "1"},
+		{"cvss", "9.8", "7.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			gen := NewLLDGenerator(testNaming()).WithPackageItemValue(tt.mode)
+			if got := gen.GeneratePackageScoreData(packages)[0].Value; got != tt.wantPackage {
+				t.Errorf("package value = %q, want %q", got, tt.wantPackage)
+			}
+			if got := gen.GenerateBulletinScoreData(bulletins)[0].Value; got != tt.wantBulletins {
+				t.Errorf("bulletin value = %q, want %q", got, tt.wantBulletins)
+			}
+		})
+	}
+}
+
+func TestScoreDataIsUnsigned(t *testing.T) {
+	gen := NewLLDGenerator(testNaming())
+	data := gen.GeneratePackageScoreData([]PackageEntry{
+		{Name: "openssl", Version: "1.1", Arch: "amd64", Score: 9.8, AffectedHosts: []string{"1", "2"}},
+	})
+	data = append(data, gen.GenerateBulletinScoreData([]BulletinEntry{
+		{ID: "USN-1", Score: 7.5, AffectedHosts: []string{"1"}},
+	})...)
+
+	for _, d := range data {
+		if _, err := strconv.ParseUint(d.Value, 10, 64); err != nil {
+			t.Errorf("%s = %q, not an unsigned integer", d.Key, d.Value)
+		}
+	}
+}
+
+// TestScoreKeysMatchPrototypes checks that every pushed score key is the
+// key Zabbix discovers from the item prototype for the matching LLD row,
+// including for values that sanitizeMacro changes.
+func TestScoreKeysMatchPrototypes(t *testing.T) {
+	hosts := []HostEntry{{HostID: "100", Score: 7.5}, {HostID: "1\t01", Score: 5}}
+	packages := []PackageEntry{
+		{Name: "openssl", Version: "1.1.1k", Arch: "x86_64", Score: 7.5, AffectedHosts: []string{"100"}},
+		{Name: "bad\x01pkg", Version: "1.0\n2", Arch: "noarch", Score: 5, AffectedHosts: []string{"100"}},
+	}
+	bulletins := []BulletinEntry{{ID: "RHSA-2021:1234", Score: 7.5}, {ID: "USN\r-1", Score: 5}}
+
+	for _, hashed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hashed=%v", hashed), func(t *testing.T) {
+			naming := testNaming()
+			naming.HashPackageKeys = hashed
+			gen := NewLLDGenerator(naming)
+
+			tests := []struct {
+				name     string
+				lld      *zabbix.LLDData
+				protoKey string
+				data     []zabbix.SenderData
+			}{
+				{"hosts", gen.GenerateHostsLLD(hosts), zabbix.HostsPrototypeKey, gen.GenerateHostScoreData(hosts)},
+				{"packages", gen.GeneratePackagesLLD(packages), zabbix.PackagePrototypeKey(hashed), gen.GeneratePackageScoreData(packages)},
+				{"bulletins", gen.GenerateBulletinsLLD(bulletins), zabbix.BulletinsPrototypeKey, gen.GenerateBulletinScoreData(bulletins)},
+			}
+			for _, tt := range tests {
+				if len(tt.lld.Data) != len(tt.data) {
+					t.Fatalf("%s: %d LLD rows, %d values", tt.name, len(tt.lld.Data), len(tt.data))
+				}
+				for i, row := range tt.lld.Data {
+					if want := zabbix.ExpandPrototypeKey(tt.protoKey, row); tt.data[i].Key != want {
+						t.Errorf("%s: pushed key %q, discovered item key %q", tt.name, tt.data[i].Key, want)
+					}
+				}
+				if missing := undiscoveredKeys(tt.lld, tt.protoKey, tt.data); len(missing) > 0 {
+					t.Errorf("%s: undiscovered keys %v", tt.name, missing)
+				}
+			}
+		})
+	}
+}
+
+func TestKeepHostRows(t *testing.T) {
+	lld := &zabbix.LLDData{Data: []map[string]interface{}{
+		{"{#B.ID}": "USN-1", "{#B.HOSTS}": "1"},
+	}}
+	prev := &zabbix.LLDData{Data: []map[string]interface{}{
+		{"{#B.ID}": "USN-1", "{#B.HOSTS}": "1,2"},
+		{"{#B.ID}": "USN-2", "{#B.HOSTS}": "1,2"},
+		{"{#B.ID}": "USN-3", "{#B.HOSTS}": "3"},
+		{"{#B.ID}": "USN-4", "{#B.HOSTS}": "12"},
+	}}
+	if n := keepHostRows(lld, prev, zabbix.BulletinsPrototypeKey, "{#B.HOSTS}", []string{"2"}); n != 1 {
+		t.Errorf("kept %d rows, want 1", n)
+	}
+	var ids []string
+	for _, row := range lld.Data {
+		ids = append(ids, row["{#B.ID}"].(string))
+	}
+	if got := strings.Join(ids, ","); got != "USN-1,USN-2" {
+		t.Errorf("LLD bulletins = %s, want USN-1,USN-2", got)
+	}
+}
+
+func TestUndiscoveredKeys(t *testing.T) {
+	lld := &zabbix.LLDData{Data: []map[string]interface{}{{"{#H.ID}": "100"}, {"{#H.HOST}": "web-01"}}}
+	data := []zabbix.SenderData{{Key: "vulners.hosts[100]"}, {Key: "vulners.hosts[200]"}}
+	got := undiscoveredKeys(lld, zabbix.HostsPrototypeKey, data)
+	if len(got) != 1 || got[0] != "vulners.hosts[200]" {
+		t.Errorf("undiscoveredKeys = %v, want [vulners.hosts[200]]", got)
+	}
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+// ResultsFromLLD rebuilds scan results from the hosts, packages and
+// bulletins LLD pushed to Zabbix; any of them may be nil. The LLD only
+// carries what the macros hold, so per-host packages and bulletins are
+// left empty, packages keep just their first bulletin and affected-host
+// name lists capped by scan.max_affected_hosts lose the omitted hosts.
+func ResultsFromLLD(hosts, packages, bulletins *zabbix.LLDData) *ScanResults {
+	results := &ScanResults{}
+
+	for _, row := range lldRows(hosts) {
+		host := HostEntry{
+			HostID:        macroString(row, "{#H.ID}"),
+			Host:          macroString(row, "{#H.HOST}"),
+			Name:          macroString(row, "{#H.VNAME}"),
+			OSName:        macroString(row, "{#H.OS}"),
+			OSVersion:     macroString(row, "{#H.OSVER}"),
+			Score:         macroFloat(row, "{#H.SCORE}"),
+			CumulativeFix: macroString(row, "{#H.FIX}"),
+		}
+		for _, name := range splitMacro(macroString(row, "{#H.GROUPS}"), ", ") {
+			host.Groups = append(host.Groups, zabbix.HostGroup{Name: name})
+		}
+		results.Hosts = append(results.Hosts, host)
+		if host.Score > 0 {
+			results.HostsWithVulns++
+		}
+		results.MaxCVSS = max(results.MaxCVSS, host.Score)
+	}
+	results.HostsScanned = len(results.Hosts)
+
+	for _, row := range lldRows(packages) {
+		pkg := PackageEntry{
+			Name:              macroString(row, "{#P.NAME}"),
+			Version:           macroString(row, "{#P.VERSION}"),
+			Arch:              macroString(row, "{#P.ARCH}"),
+			Score:             macroFloat(row, "{#P.SCORE}"),
+			Fix:               macroString(row, "{#P.FIX}"),
+			AffectedHosts:     splitMacro(macroString(row, "{#P.HOSTS}"), ","),
+			AffectedHostNames: splitMacro(macroString(row, "{#PKG.HOSTS}"), "\n"),
+		}
+		if url := macroString(row, "{#PKG.URL}"); url != "" {
+			pkg.Bulletins = []string{url}
+		}
+		results.Packages = append(results.Packages, pkg)
+	}
+	results.VulnerablePackages = len(results.Packages)
+
+	for _, row := range lldRows(bulletins) {
+		results.Bulletins = append(results.Bulletins, BulletinEntry{
+			ID:                macroString(row, "{#B.ID}"),
+			Type:              macroString(row, "{#B.TYPE}"),
+			Score:             macroFloat(row, "{#B.SCORE}"),
+			CVEs:              splitMacro(macroString(row, "{#B.CVES}"), ","),
+			AffectedPkgs:      splitMacro(macroString(row, "{#B.PKGS}"), ","),
+			AffectedHosts:     splitMacro(macroString(row, "{#B.HOSTS}"), ","),
+			AffectedHostNames: splitMacro(macroString(row, "{#BULLETIN.HOSTS}"), "\n"),
+		})
+	}
+
+	return results
+}
+
+func lldRows(data *zabbix.LLDData) []map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	return data.Data
+}
+
+func macroString(row map[string]interface{}, macro string) string {
+	s, _ := row[macro].(string)
+	return s
+}
+
+func macroFloat(row map[string]interface{}, macro string) float64 {
+	f, _ := strconv.ParseFloat(macroString(row, macro), 64)
+	return f
+}
+
+// splitMacro splits a joined LLD list, dropping empty entries and the
+// "... and N more" marker of a capped list.
+func splitMacro(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+	return slices.DeleteFunc(strings.Split(s, sep), func(v string) bool {
+		return v == "" || strings.HasPrefix(v, zabbix.TruncatedListPrefix)
+	})
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+// roundTrip encodes LLD data and decodes it again, as it comes back from
+// the Zabbix API.
+func roundTrip(t *testing.T, data *zabbix.LLDData) *zabbix.LLDData {
+	t.Helper()
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out zabbix.LLDData
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	return &out
+}
+
+func TestResultsFromLLD(t *testing.T) {
+	gen := NewLLDGenerator(testNaming()).WithMaxAffectedHosts(2)
+
+	hosts := []HostEntry{
+		{HostID: "10", Host: "web-01", Name: "Web 01", OSName: "ubuntu", OSVersion: "22.04", Score: 7.5,
+			CumulativeFix: "apt-get install --only-upgrade openssl",
+			Groups:        []zabbix.HostGroup{{GroupID: "2", Name: "Linux servers"}, {GroupID: "3", Name: "Web"}}},
+		{HostID: "20", Host: "db-01", Name: "DB 01", OSName: "centos", OSVersion: "7"},
+	}
+	packages := []PackageEntry{
+		{Name: "openssl", Version: "3.0.2", Arch: "amd64", Score: 7.5, Fix: "apt-get install --only-upgrade openssl",
+			AffectedHosts: []string{"10", "30", "40"}, AffectedHostNames: []string{"Web 01", "App 01", "App 02"},
+			Bulletins: []string{"USN-1", "USN-2"}},
+	}
+	bulletins := []BulletinEntry{
+		{ID: "USN-1", Type: "ubuntu", Score: 7.5, CVEs: []string{"CVE-2024-1", "CVE-2024-2"},
+			AffectedPkgs: []string{"openssl 3.0.2 amd64"}, AffectedHosts: []string{"10"}, AffectedHostNames: []string{"Web 01"}},
+	}
+
+	got := ResultsFromLLD(
+		roundTrip(t, gen.GenerateHostsLLD(hosts)),
+		roundTrip(t, gen.GeneratePackagesLLD(packages)),
+		roundTrip(t, gen.GenerateBulletinsLLD(bulletins)),
+	)
+
+	if got.HostsScanned != 2 || got.HostsWithVulns != 1 || got.MaxCVSS != 7.5 || got.VulnerablePackages != 1 {
+		t.Errorf("counters = %d/%d/%g/%d, want 2/1/7.5/1", got.HostsScanned, got.HostsWithVulns, got.MaxCVSS, got.VulnerablePackages)
+	}
+
+	// Group IDs are not in the LLD.
+	wantHost := hosts[0]
+	wantHost.Groups = []zabbix.HostGroup{{Name: "Linux servers"}, {Name: "Web"}}
+	if !reflect.DeepEqual(got.Hosts[0], wantHost) {
+		t.Errorf("host = %+v\nwant %+v", got.Hosts[0], wantHost)
+	}
+
+	// The capped host name list loses the omitted host, the ID list is
+	// complete, and only the first bulletin is in the LLD.
+	wantPkg := packages[0]
+	wantPkg.AffectedHostNames = []string{"Web 01", "App 01"}
+	wantPkg.Bulletins = []string{"USN-1"}
+	if !reflect.DeepEqual(got.Packages[0], wantPkg) {
+		t.Errorf("package = %+v\nwant %+v", got.Packages[0], wantPkg)
+	}
+
+	if !reflect.DeepEqual(got.Bulletins[0], bulletins[0]) {
+		t.Errorf("bulletin = %+v\nwant %+v", got.Bulletins[0], bulletins[0])
+	}
+}
+
+func TestResultsFromLLD_Missing(t *testing.T) {
+	got := ResultsFromLLD(nil, nil, nil)
+	if got.HostsScanned != 0 || got.Hosts != nil || got.Packages != nil || got.Bulletins != nil {
+		t.Errorf("results = %+v, want empty", got)
+	}
+}
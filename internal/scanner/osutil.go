@@ -21,9 +21,33 @@ func ParsePackageString(pkg string) (name, version, arch string) {
 
 // NormalizeOSName normalizes OS names to Vulners format
 func NormalizeOSName(osName string) string {
+	return NormalizeOSNameWith(osName, nil)
+}
+
+// NormalizeOSNameWith is NormalizeOSName with additional rules (as in
+// scan.os_name_map) checked before the built-in ones: an OS name containing
+// a rule's key, ignoring case, maps to its value. When several keys match,
+// the longest one wins.
+func NormalizeOSNameWith(osName string, rules map[string]string) string {
 	osName = strings.ToLower(osName)
 
-	// Map common OS names to Vulners format
+	best, mapped := "", ""
+	for match, vulnersOS := range rules {
+		m := strings.ToLower(match)
+		if !strings.Contains(osName, m) {
+			continue
+		}
+		// Break length ties by key so the result does not depend on map order.
+		if len(m) > len(best) || (len(m) == len(best) && m < best) {
+			best, mapped = m, vulnersOS
+		}
+	}
+	if best != "" {
+		return mapped
+	}
+
+	// Map common OS names to Vulners format. RHEL rebuilds follow Red Hat
+	// errata and versioning, so Vulners audits them as redhat.
 	switch {
 	case strings.Contains(osName, "ubuntu"):
 		return "ubuntu"
@@ -33,6 +57,10 @@ func NormalizeOSName(osName string) string {
 		return "centos"
 	case strings.Contains(osName, "red hat") || strings.Contains(osName, "rhel"):
 		return "redhat"
+	case strings.Contains(osName, "rocky"),
+		strings.Contains(osName, "almalinux") || strings.Contains(osName, "alma linux"),
+		strings.Contains(osName, "cloudlinux"):
+		return "redhat"
 	case strings.Contains(osName, "amazon"):
 		return "amazon"
 	case strings.Contains(osName, "oracle"):
@@ -63,3 +91,32 @@ func ExtractOSVersion(osVersion string) string {
 	}
 	return osVersion
 }
+
+// osVersionParts is how many dot-separated version components the Vulners
+// audit expects per (normalized) OS name. OS names not listed keep their
+// full version.
+var osVersionParts = map[string]int{
+	"ubuntu":      2, // 20.04
+	"alpine":      2, // 3.18
+	"debian":      1, // 11
+	"centos":      1, // 7
+	"redhat":      1, // 8
+	"oraclelinux": 1, // 8
+	"fedora":      1, // 39
+	"amazon":      1, // 2, 2023
+}
+
+// NormalizeOSVersion truncates a version from ExtractOSVersion to the
+// precision Vulners expects for osName (as returned by NormalizeOSName),
+// e.g. Ubuntu "20.04.3" to "20.04" and CentOS "7.9.2009" to "7".
+func NormalizeOSVersion(osName, version string) string {
+	n, ok := osVersionParts[osName]
+	if !ok {
+		return version
+	}
+	parts := strings.SplitN(version, ".", n+1)
+	if len(parts) <= n {
+		return version
+	}
+	return strings.Join(parts[:n], ".")
+}
@@ -48,6 +48,9 @@ func TestNormalizeOSName(t *testing.T) {
 		{"SUSE Linux Enterprise", "suse"},
 		{"Fedora 35", "fedora"},
 		{"Alpine Linux", "alpine"},
+		{"Rocky Linux 8.9 (Green Obsidian)", "redhat"},
+		{"AlmaLinux 9.3 (Shamrock Pulsar)", "redhat"},
+		{"CloudLinux 8.8", "redhat"},
 		{"unknown-os", "unknown-os"},
 	}
 	for _, tt := range tests {
@@ -60,6 +63,31 @@ func TestNormalizeOSName(t *testing.T) {
 	}
 }
 
+func TestNormalizeOSNameWith(t *testing.T) {
+	rules := map[string]string{
+		"Rocky":         "centos",
+		"Rocky Linux 9": "rocky",
+		"Astra":         "astralinux",
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Rocky Linux 8.9", "centos"},
+		{"Rocky Linux 9.3", "rocky"}, // longest match wins
+		{"Astra Linux 1.7", "astralinux"},
+		{"AlmaLinux 9.3", "redhat"}, // falls back to built-in rules
+		{"Ubuntu 22.04", "ubuntu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := NormalizeOSNameWith(tt.input, rules); got != tt.want {
+				t.Errorf("NormalizeOSNameWith(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractOSVersion(t *testing.T) {
 	tests := []struct {
 		input string
@@ -79,3 +107,30 @@ func TestExtractOSVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeOSVersion(t *testing.T) {
+	tests := []struct {
+		osName  string
+		version string
+		want    string
+	}{
+		{"ubuntu", "20.04.3", "20.04"},
+		{"ubuntu", "22.04", "22.04"},
+		{"debian", "11.8", "11"},
+		{"debian", "12", "12"},
+		{"centos", "7.9.2009", "7"},
+		{"redhat", "8.9", "8"},
+		{"oraclelinux", "9.3", "9"},
+		{"alpine", "3.18.4", "3.18"},
+		{"amazon", "2023", "2023"},
+		{"suse", "15.5", "15.5"}, // no rule: unchanged
+		{"ubuntu", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.osName+" "+tt.version, func(t *testing.T) {
+			if got := NormalizeOSVersion(tt.osName, tt.version); got != tt.want {
+				t.Errorf("NormalizeOSVersion(%q, %q) = %q, want %q", tt.osName, tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/transport"
 	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
 )
 
@@ -21,23 +22,47 @@ var Module = fx.Module("scanner",
 		ProvideScanner,
 		NewHostMatrix,
 		NewAggregator,
-		ProvideNamingConfig,
-		NewLLDGenerator,
-		ProvideVulnersClient,
+		ProvideLLDGenerator,
+		ProvideAuditor,
+		ProvideZabbixAPI,
 	),
 	zabbix.Module,
 )
 
-// ProvideNamingConfig extracts the NamingConfig from Config for NewLLDGenerator.
-func ProvideNamingConfig(cfg *config.Config) config.NamingConfig {
-	return cfg.Naming
+// ProvideLLDGenerator creates an LLDGenerator configured from Config.
+func ProvideLLDGenerator(cfg *config.Config) *LLDGenerator {
+	return NewLLDGenerator(cfg.Naming).
+		WithMaxAffectedHosts(cfg.Scan.MaxAffectedHosts).
+		WithPackageItemValue(cfg.Scan.PackageItemValue)
+}
+
+// ProvideZabbixAPI exposes the Zabbix client through the scanner's ZabbixAPI
+// interface.
+func ProvideZabbixAPI(c *zabbix.Client) ZabbixAPI {
+	return c
 }
 
 // ProvideVulnersClient creates a Vulners API client with OTel-instrumented HTTP transport.
-func ProvideVulnersClient(cfg *config.Config) (*vulners.Client, error) {
+func ProvideVulnersClient(cfg *config.Config, log *slog.Logger) (*vulners.Client, error) {
+	timeout := auditTimeout(cfg)
+
+	// Keep one idle connection per worker so concurrent audits reuse TCP
+	// and TLS sessions instead of reconnecting.
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.MaxIdleConnsPerHost = max(cfg.Scan.Workers, 2)
+
+	// Retry-After pauses count against the audit timeout, so keep them
+	// well below it.
+	rt := transport.WithRetryAfter(base, timeout/2, log)
+	rt = transport.WithUserAgent(rt, cfg.UserAgent())
+
 	instrumentedHTTP := &http.Client{
 		Timeout:   time.Duration(cfg.Scan.Timeout) * time.Second,
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
+		Transport: otelhttp.NewTransport(rt),
+	}
+	if cfg.Vulners.RequestTimeout > 0 {
+		// Audits are bounded per request in scanHost instead.
+		instrumentedHTTP.Timeout = 0
 	}
 
 	client, err := vulners.NewClient(cfg.Vulners.APIKey,
@@ -52,25 +77,48 @@ func ProvideVulnersClient(cfg *config.Config) (*vulners.Client, error) {
 	return client, nil
 }
 
+// auditTimeout returns the deadline for one host audit:
+// vulners.request_timeout, or scan.timeout when that is unset.
+func auditTimeout(cfg *config.Config) time.Duration {
+	if cfg.Vulners.RequestTimeout > 0 {
+		return time.Duration(cfg.Vulners.RequestTimeout) * time.Second
+	}
+	return time.Duration(cfg.Scan.Timeout) * time.Second
+}
+
+// ProvideAuditor returns a ReplayAuditor when vulners.ReplayDir is set and
+// a Vulners API auditor otherwise.
+func ProvideAuditor(cfg *config.Config, log *slog.Logger) (Auditor, error) {
+	if cfg.Vulners.ReplayDir != "" {
+		log.Info("Replaying canned Vulners audit results", slog.String("dir", cfg.Vulners.ReplayDir))
+		return NewReplayAuditor(cfg.Vulners.ReplayDir)
+	}
+	client, err := ProvideVulnersClient(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	return vulnersAuditor{client: client}, nil
+}
+
 // ProvideScanner assembles a Scanner from its injected dependencies.
 func ProvideScanner(
 	cfg *config.Config,
 	log *slog.Logger,
-	zabbixClient *zabbix.Client,
-	vulnersClient *vulners.Client,
+	zabbixClient ZabbixAPI,
+	auditor Auditor,
 	sender *zabbix.Sender,
 	hostMatrix *HostMatrix,
 	aggregator *Aggregator,
 	lldGenerator *LLDGenerator,
 ) *Scanner {
 	return &Scanner{
-		cfg:           cfg,
-		log:           log,
-		zabbixClient:  zabbixClient,
-		vulnersClient: vulnersClient,
-		sender:        sender,
-		hostMatrix:    hostMatrix,
-		aggregator:    aggregator,
-		lldGenerator:  lldGenerator,
+		cfg:          cfg,
+		log:          log,
+		zabbixClient: zabbixClient,
+		auditor:      auditor,
+		sender:       sender,
+		hostMatrix:   hostMatrix,
+		aggregator:   aggregator,
+		lldGenerator: lldGenerator,
 	}
 }
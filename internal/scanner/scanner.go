@@ -2,18 +2,19 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
-	"strings"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"log/slog"
 
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
-	"go.opentelemetry.io/otel/attribute"
-
 	vulners "github.com/kidoz/go-vulners"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
 	"github.com/kidoz/zabbix-threat-control-go/internal/telemetry"
@@ -22,14 +23,14 @@ import (
 
 // Scanner orchestrates vulnerability scanning
 type Scanner struct {
-	cfg           *config.Config
-	log           *slog.Logger
-	zabbixClient  *zabbix.Client
-	vulnersClient *vulners.Client
-	sender        *zabbix.Sender
-	hostMatrix    *HostMatrix
-	aggregator    *Aggregator
-	lldGenerator  *LLDGenerator
+	cfg          *config.Config
+	log          *slog.Logger
+	zabbixClient ZabbixAPI
+	auditor      Auditor
+	sender       *zabbix.Sender
+	hostMatrix   *HostMatrix
+	aggregator   *Aggregator
+	lldGenerator *LLDGenerator
 }
 
 // New creates a new scanner
@@ -39,89 +40,230 @@ func New(cfg *config.Config, log *slog.Logger) (*Scanner, error) {
 		return nil, fmt.Errorf("failed to create Zabbix client: %w", err)
 	}
 
-	// Create an instrumented HTTP client for Vulners
-	instrumentedHTTP := &http.Client{
-		Timeout:   time.Duration(cfg.Scan.Timeout) * time.Second,
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
-	}
-
-	vulnersClient, err := vulners.NewClient(cfg.Vulners.APIKey,
-		vulners.WithHTTPClient(instrumentedHTTP),
-		vulners.WithRateLimit(float64(cfg.Vulners.RateLimit), cfg.Vulners.RateLimit*2),
-		vulners.WithBaseURL(cfg.Vulners.Host),
-	)
+	auditor, err := ProvideAuditor(cfg, log)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Vulners client: %w", err)
+		return nil, err
 	}
 
 	return &Scanner{
-		cfg:           cfg,
-		log:           log,
-		zabbixClient:  zabbixClient,
-		vulnersClient: vulnersClient,
-		sender:        zabbix.NewSender(cfg, log),
-		hostMatrix:    NewHostMatrix(cfg, log, zabbixClient),
-		aggregator:    NewAggregator(),
-		lldGenerator:  NewLLDGenerator(cfg.Naming),
+		cfg:          cfg,
+		log:          log,
+		zabbixClient: zabbixClient,
+		auditor:      auditor,
+		sender:       zabbix.NewSender(cfg, log),
+		hostMatrix:   NewHostMatrix(cfg, log, zabbixClient),
+		aggregator:   NewAggregator(),
+		lldGenerator: ProvideLLDGenerator(cfg),
 	}, nil
 }
 
+// ErrScanSkipped is returned by Scan and ScanStream when scan.maintenance is
+// skip_scan and a host is in maintenance. Nothing must be pushed then:
+// empty LLD would make Zabbix delete the discovered items.
+var ErrScanSkipped = errors.New("scan skipped: hosts are in maintenance (scan.maintenance: skip_scan)")
+
 // Scan performs a vulnerability scan. Pass a cancellable context to allow
 // the caller (CLI signal handler, Agent 2 plugin) to abort in-flight work.
 func (s *Scanner) Scan(ctx context.Context, opts ScanOptions) (*ScanResults, error) {
 	ctx, span := telemetry.Tracer().Start(ctx, "Scanner.Scan")
 	defer span.End()
 
-	// Fetch hosts with OS-Report data
-	s.log.Info("Fetching hosts from Zabbix...")
-	hosts, err := s.hostMatrix.FetchHosts(ctx, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch hosts: %w", err)
+	// Reset aggregator so repeated calls don't accumulate stale data.
+	s.aggregator.Reset()
+
+	if opts.CollectOnly {
+		s.log.Info("Collecting host inventory from Zabbix...")
+		results, err := s.hostMatrix.Inventory(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch hosts: %w", err)
+		}
+		return results, nil
 	}
 
-	if len(hosts) == 0 {
-		s.log.Warn("No hosts with OS-Report data found")
-		return &ScanResults{}, nil
+	var pusher *incrementalPusher
+	if opts.IncrementalPush && !opts.NoPush && !opts.DryRun {
+		pusher = newIncrementalPusher(opts.PushEvery, opts.PushInterval, s.pushPartial)
+		pusher.start(ctx)
 	}
 
-	s.log.Info("Starting vulnerability scan", slog.Int("hosts", len(hosts)))
+	run, entries, errc := s.stream(ctx, opts)
+	for entry := range entries {
+		s.aggregator.AddHost(entry)
+		if pusher != nil {
+			pusher.hostDone()
+		}
+	}
+	if pusher != nil {
+		pusher.stop()
+	}
+	if err := <-errc; err != nil {
+		return nil, err
+	}
 
-	// Reset aggregator so repeated calls don't accumulate stale data.
-	s.aggregator.Reset()
+	results := s.aggregator.GetResults()
+	results.Excluded = run.excluded
+	results.MaintenanceHostIDs = run.maintenance
+	results.Timings = run.timings
+	if opts.GroupStats {
+		results.GroupStats = s.aggregator.GetGroupStatistics()
+	}
+	return results, nil
+}
 
-	// Scan hosts concurrently
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	workers := s.cfg.Scan.Workers
-	if workers <= 0 {
-		workers = 1
-	}
-	semaphore := make(chan struct{}, workers)
-
-	for _, hostData := range hosts {
-		wg.Add(1)
-		go func(hd HostData) {
-			defer wg.Done()
-			semaphore <- struct{}{}        // Acquire
-			defer func() { <-semaphore }() // Release
-
-			entry, err := s.scanHost(ctx, &hd)
-			if err != nil {
-				s.log.Warn("Failed to scan host", slog.Any("error", err), slog.String("host", hd.Host.Name))
-				return
+// ScanStream scans like Scan but sends each host entry as soon as its audit
+// completes, without aggregating or pushing anything. The entries channel
+// is closed when the scan ends; the error channel then yields the error
+// that stopped it, if any, and is closed. Hosts whose audit fails are
+// logged and skipped as in Scan. Stop reading early only by cancelling ctx.
+func (s *Scanner) ScanStream(ctx context.Context, opts ScanOptions) (<-chan HostEntry, <-chan error) {
+	_, entries, errc := s.stream(ctx, opts)
+	return entries, errc
+}
+
+// scanRun holds what a stream learns besides host entries. It is complete
+// once the entries channel is closed.
+type scanRun struct {
+	excluded    map[string]int
+	maintenance []string
+	timings     ScanTimings
+}
+
+// stream fetches the hosts and audits them concurrently, sending each
+// finished entry on the returned channel.
+func (s *Scanner) stream(ctx context.Context, opts ScanOptions) (*scanRun, <-chan HostEntry, <-chan error) {
+	run := &scanRun{}
+	entries := make(chan HostEntry)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(entries)
+
+		// Fetch hosts with OS-Report data
+		s.log.Info("Fetching hosts from Zabbix...")
+		fetchStart := time.Now()
+		fetched, err := s.hostMatrix.FetchHosts(ctx, opts)
+		if err != nil {
+			errc <- fmt.Errorf("failed to fetch hosts: %w", err)
+			return
+		}
+		if fetched.ScanSkipped {
+			errc <- ErrScanSkipped
+			return
+		}
+		run.timings.FetchHosts = time.Since(fetchStart)
+		run.excluded = fetched.Excluded
+		run.maintenance = fetched.Maintenance
+		s.log.Info("Fetched hosts", slog.Duration("duration", run.timings.FetchHosts))
+		hosts := fetched.Hosts
+
+		if len(hosts) == 0 {
+			s.log.Warn("No hosts with OS-Report data found")
+			if opts.HostCache != nil {
+				opts.HostCache.RetainHosts(nil)
 			}
+			return
+		}
+
+		s.log.Info("Starting vulnerability scan", slog.Int("hosts", len(hosts)))
+
+		// Scan hosts concurrently
+		auditStart := time.Now()
+		var wg sync.WaitGroup
+		workers := s.cfg.Scan.Workers
+		if workers <= 0 {
+			workers = 1
+		}
+		semaphore := make(chan struct{}, workers)
+		var cacheHits atomic.Int64
+		emit := hostEventEmitter(hostEventHandler(opts), len(hosts))
+
+		for _, hostData := range hosts {
+			wg.Add(1)
+			go func(hd HostData) {
+				defer wg.Done()
+				semaphore <- struct{}{}        // Acquire
+				defer func() { <-semaphore }() // Release
+
+				var fingerprint string
+				if opts.HostCache != nil {
+					fingerprint = HostFingerprint(&hd, s.cfg)
+				}
+				entry, cached := cachedHost(opts.HostCache, &hd, fingerprint)
+				if cached {
+					cacheHits.Add(1)
+				} else {
+					emit(HostEvent{Kind: HostStarted, HostID: hd.Host.HostID, Name: hd.Host.Name})
+					var err error
+					entry, err = s.scanHost(ctx, &hd)
+					if err != nil {
+						s.log.Warn("Failed to scan host", slog.Any("error", err), slog.String("host", hd.Host.Name))
+						emit(HostEvent{Kind: HostFailed, HostID: hd.Host.HostID, Name: hd.Host.Name, Err: err})
+						return
+					}
+					if opts.HostCache != nil {
+						opts.HostCache.PutHost(hd.Host.HostID, fingerprint, *entry)
+					}
+				}
+
+				emit(HostEvent{Kind: HostCompleted, HostID: hd.Host.HostID, Name: hd.Host.Name, Entry: entry})
+				select {
+				case entries <- *entry:
+				case <-ctx.Done():
+				}
+			}(hostData)
+		}
 
-			if entry != nil {
-				mu.Lock()
-				s.aggregator.AddHost(*entry)
-				mu.Unlock()
+		wg.Wait()
+		run.timings.Audit = time.Since(auditStart)
+		s.log.Info("Audited hosts", slog.Int("hosts", len(hosts)), slog.Duration("duration", run.timings.Audit))
+
+		if opts.HostCache != nil {
+			ids := make([]string, len(hosts))
+			for i := range hosts {
+				ids[i] = hosts[i].Host.HostID
 			}
-		}(hostData)
-	}
+			opts.HostCache.RetainHosts(ids)
+			s.log.Info("Reused cached host results", slog.Int64("hosts", cacheHits.Load()))
+		}
+	}()
 
-	wg.Wait()
+	return run, entries, errc
+}
 
-	return s.aggregator.GetResults(), nil
+// hostEventHandler combines the OnHostEvent and OnHostScanned callbacks of
+// opts. It returns nil when neither is set.
+func hostEventHandler(opts ScanOptions) func(HostEvent) {
+	if opts.OnHostScanned == nil {
+		return opts.OnHostEvent
+	}
+	return func(e HostEvent) {
+		if opts.OnHostEvent != nil {
+			opts.OnHostEvent(e)
+		}
+		if e.Kind == HostCompleted {
+			opts.OnHostScanned(*e.Entry)
+		}
+	}
+}
+
+// hostEventEmitter wraps fn so calls are serialized and carry the running
+// Done count. It returns a no-op when fn is nil.
+func hostEventEmitter(fn func(HostEvent), total int) func(HostEvent) {
+	if fn == nil {
+		return func(HostEvent) {}
+	}
+	var mu sync.Mutex
+	done := 0
+	return func(e HostEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Kind != HostStarted {
+			done++
+		}
+		e.Done, e.Total = done, total
+		fn(e)
+	}
 }
 
 // scanHost scans a single host for vulnerabilities
@@ -143,7 +285,7 @@ func (s *Scanner) scanHost(ctx context.Context, hostData *HostData) (*HostEntry,
 	)
 
 	// Call Vulners API
-	auditResult, err := s.vulnersClient.Audit().LinuxAudit(ctx, hostData.OSName, hostData.OSVersion, hostData.Packages)
+	auditResult, err := s.audit(ctx, hostData)
 	if err != nil {
 		return nil, fmt.Errorf("vulners audit failed: %w", err)
 	}
@@ -165,9 +307,10 @@ func (s *Scanner) scanHost(ctx context.Context, hostData *HostData) (*HostEntry,
 		OSName:        hostData.OSName,
 		OSVersion:     hostData.OSVersion,
 		Score:         auditResult.CVSSScore,
-		CumulativeFix: strings.ReplaceAll(auditResult.CumulativeFix, ",", ""),
+		CumulativeFix: auditResult.CumulativeFix,
 		Packages:      vulnPackages,
 		Bulletins:     bulletins,
+		Groups:        hostData.Host.Groups,
 	}
 
 	span.SetAttributes(attribute.Float64("cvss.score", entry.Score))
@@ -182,7 +325,45 @@ func (s *Scanner) scanHost(ctx context.Context, hostData *HostData) (*HostEntry,
 	return entry, nil
 }
 
-// PushResults pushes scan results to Zabbix
+// audit runs the Vulners audit for one host in a client span of its own, so
+// traces separate time spent waiting on Vulners from the Zabbix side. The
+// span records the outcome, result size and, for API errors, the HTTP status.
+func (s *Scanner) audit(ctx context.Context, hostData *HostData) (*vulners.AuditResult, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "Vulners.LinuxAudit", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, auditTimeout(s.cfg))
+	defer cancel()
+	result, err := s.auditor.LinuxAudit(ctx, hostData.OSName, hostData.OSVersion, hostData.Packages)
+
+	if !span.IsRecording() {
+		return result, err
+	}
+	span.SetAttributes(
+		attribute.String("vulners.os", hostData.OSName),
+		attribute.String("vulners.os_version", hostData.OSVersion),
+		attribute.Int("vulners.package_count", len(hostData.Packages)),
+	)
+	if err != nil {
+		var apiErr *vulners.APIError
+		if errors.As(err, &apiErr) {
+			span.SetAttributes(attribute.Int("http.response.status_code", apiErr.StatusCode))
+		}
+		span.SetAttributes(attribute.String("vulners.outcome", "error"))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.String("vulners.outcome", "ok"),
+		attribute.Int("vulners.vulnerability_count", len(result.Vulnerabilities)),
+		attribute.Float64("vulners.cvss_score", result.CVSSScore),
+	)
+	return result, nil
+}
+
+// PushResults pushes scan results to Zabbix, then records the push and
+// LLD delay durations in results.Timings and sends the phase timings.
 func (s *Scanner) PushResults(ctx context.Context, results *ScanResults) error {
 	_, span := telemetry.Tracer().Start(ctx, "Scanner.PushResults")
 	defer span.End()
@@ -193,61 +374,207 @@ func (s *Scanner) PushResults(ctx context.Context, results *ScanResults) error {
 		attribute.Int("bulletins", len(results.Bulletins)),
 	)
 
-	s.log.Info("Pushing LLD data to Zabbix...")
+	start := time.Now()
+	lldDelay, err := s.pushResults(ctx, results)
+	if err != nil {
+		return err
+	}
+	results.Timings.LLDDelay = lldDelay
+	results.Timings.Push = time.Since(start) - lldDelay
+
+	t := results.Timings
+	span.SetAttributes(
+		attribute.Float64("push.seconds", t.Push.Seconds()),
+		attribute.Float64("lld_delay.seconds", t.LLDDelay.Seconds()),
+	)
+	s.log.Info("Scan phase timings",
+		slog.Duration("fetch_hosts", t.FetchHosts),
+		slog.Duration("audit", t.Audit),
+		slog.Duration("lld_delay", t.LLDDelay),
+		slog.Duration("push", t.Push),
+		slog.Duration("total", t.Total()),
+	)
+
+	if err := s.sender.SendBatch(s.lldGenerator.GenerateTimingData(t)); err != nil {
+		return fmt.Errorf("failed to send scan timings: %w", err)
+	}
+	return nil
+}
+
+// filterPushMinCVSS returns results without the packages and bulletins
+// scoring below scan.push_min_cvss. results itself is not modified.
+func (s *Scanner) filterPushMinCVSS(results *ScanResults) *ScanResults {
+	minScore := s.cfg.Scan.PushMinCVSS
+	if minScore <= 0 {
+		return results
+	}
+	filtered := *results
+	filtered.Packages = scoredAtLeast(results.Packages, minScore, func(p PackageEntry) float64 { return p.Score })
+	filtered.Bulletins = scoredAtLeast(results.Bulletins, minScore, func(b BulletinEntry) float64 { return b.Score })
+	s.log.Debug("Applied push_min_cvss",
+		slog.Float64("push_min_cvss", minScore),
+		slog.Int("packages_dropped", len(results.Packages)-len(filtered.Packages)),
+		slog.Int("bulletins_dropped", len(results.Bulletins)-len(filtered.Bulletins)),
+	)
+	return &filtered
+}
+
+// scoredAtLeast returns the items whose score is at least minScore, in order.
+func scoredAtLeast[T any](items []T, minScore float64, score func(T) float64) []T {
+	var kept []T
+	for _, item := range items {
+		if score(item) >= minScore {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// capLLDEntries returns results with hosts, packages and bulletins each
+// limited to scan.max_lld_entries, keeping the highest-scoring entries, and
+// warns about every list it cuts. results itself is not modified.
+func (s *Scanner) capLLDEntries(results *ScanResults) *ScanResults {
+	limit := s.cfg.Scan.MaxLLDEntries
+	if limit <= 0 || (len(results.Hosts) <= limit && len(results.Packages) <= limit && len(results.Bulletins) <= limit) {
+		return results
+	}
+
+	warn := func(lld string, total int) {
+		s.log.Warn("LLD entry cap hit, pushing only the highest-scoring entries; check the scan for bad input or raise scan.max_lld_entries",
+			slog.String("lld", lld),
+			slog.Int("entries", total),
+			slog.Int("max_lld_entries", limit),
+			slog.Int("dropped", total-limit),
+		)
+	}
+
+	capped := *results
+	if n := len(results.Hosts); n > limit {
+		warn("hosts", n)
+		capped.Hosts = results.TopHosts(limit)
+	}
+	if n := len(results.Packages); n > limit {
+		warn("packages", n)
+		capped.Packages = results.TopPackages(limit)
+	}
+	if n := len(results.Bulletins); n > limit {
+		warn("bulletins", n)
+		capped.Bulletins = results.TopBulletins(limit)
+	}
+	return &capped
+}
+
+// warnUndiscovered logs score values whose keys the pushed LLD does not
+// discover; Zabbix would drop them without an error.
+func (s *Scanner) warnUndiscovered(lld *zabbix.LLDData, protoKey string, data []zabbix.SenderData) {
+	if missing := undiscoveredKeys(lld, protoKey, data); len(missing) > 0 {
+		s.log.Warn("Pushed item keys do not match any discovered item",
+			slog.String("prototype", protoKey), slog.Int("count", len(missing)), slog.String("first", missing[0]))
+	}
+}
+
+// pushResults sends LLD, values and statistics and returns the time spent
+// waiting for LLD processing.
+func (s *Scanner) pushResults(ctx context.Context, results *ScanResults) (time.Duration, error) {
+	results = s.capLLDEntries(s.filterPushMinCVSS(results))
+
+	s.log.Info("Pushing LLD data to Zabbix...", slog.Any("enabled_lld", s.cfg.Scan.EnabledLLD))
+
+	var hostsLLD, packagesLLD, bulletinsLLD *zabbix.LLDData
 
 	// Generate and send hosts LLD
-	hostsLLD := s.lldGenerator.GenerateHostsLLD(results.Hosts)
-	if err := s.sender.SendLLD(s.cfg.Naming.HostsHost, "vulners.hosts_lld", hostsLLD); err != nil {
-		return fmt.Errorf("failed to send hosts LLD: %w", err)
+	if s.cfg.Scan.LLDEnabled(config.LLDHosts) {
+		hostsLLD = s.lldGenerator.GenerateHostsLLD(results.Hosts)
+		s.keepMaintenanceRows(ctx, hostsLLD, s.cfg.Naming.HostsHost, "vulners.hosts_lld",
+			zabbix.HostsPrototypeKey, "{#H.ID}", results.MaintenanceHostIDs)
+		if err := s.sender.SendLLD(s.cfg.Naming.HostsHost, "vulners.hosts_lld", hostsLLD); err != nil {
+			return 0, fmt.Errorf("failed to send hosts LLD: %w", err)
+		}
 	}
 
 	// Generate and send packages LLD
-	packagesLLD := s.lldGenerator.GeneratePackagesLLD(results.Packages)
-	if err := s.sender.SendLLD(s.cfg.Naming.PackagesHost, "vulners.packages_lld", packagesLLD); err != nil {
-		return fmt.Errorf("failed to send packages LLD: %w", err)
+	if s.cfg.Scan.LLDEnabled(config.LLDPackages) {
+		packagesLLD = s.lldGenerator.GeneratePackagesLLD(results.Packages)
+		s.keepMaintenanceRows(ctx, packagesLLD, s.cfg.Naming.PackagesHost, "vulners.packages_lld",
+			zabbix.PackagePrototypeKey(s.cfg.Naming.HashPackageKeys), "{#P.HOSTS}", results.MaintenanceHostIDs)
+		if err := s.sender.SendLLD(s.cfg.Naming.PackagesHost, "vulners.packages_lld", packagesLLD); err != nil {
+			return 0, fmt.Errorf("failed to send packages LLD: %w", err)
+		}
 	}
 
 	// Generate and send bulletins LLD
-	bulletinsLLD := s.lldGenerator.GenerateBulletinsLLD(results.Bulletins)
-	if err := s.sender.SendLLD(s.cfg.Naming.BulletinsHost, "vulners.bulletins_lld", bulletinsLLD); err != nil {
-		return fmt.Errorf("failed to send bulletins LLD: %w", err)
+	if s.cfg.Scan.LLDEnabled(config.LLDBulletins) {
+		bulletinsLLD = s.lldGenerator.GenerateBulletinsLLD(results.Bulletins)
+		s.keepMaintenanceRows(ctx, bulletinsLLD, s.cfg.Naming.BulletinsHost, "vulners.bulletins_lld",
+			zabbix.BulletinsPrototypeKey, "{#B.HOSTS}", results.MaintenanceHostIDs)
+		if err := s.sender.SendLLD(s.cfg.Naming.BulletinsHost, "vulners.bulletins_lld", bulletinsLLD); err != nil {
+			return 0, fmt.Errorf("failed to send bulletins LLD: %w", err)
+		}
+	}
+
+	if len(results.GroupStats) > 0 {
+		groupsLLD := s.lldGenerator.GenerateGroupsLLD(results.GroupStats)
+		if err := s.sender.SendLLD(s.cfg.Naming.StatisticsHost, "vulners.groups_lld", groupsLLD); err != nil {
+			return 0, fmt.Errorf("failed to send groups LLD: %w", err)
+		}
 	}
 
 	// Wait for Zabbix to process LLD and create discovered items
 	if s.cfg.Scan.LLDDelay > 0 {
 		s.log.Info("Waiting for Zabbix to process LLD rules...", slog.Int("seconds", s.cfg.Scan.LLDDelay))
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(time.Duration(s.cfg.Scan.LLDDelay) * time.Second):
-		}
 	}
+	delayStart := time.Now()
+	if err := waitLLDDelay(ctx, s.cfg.Scan.LLDDelay); err != nil {
+		return 0, err
+	}
+	lldDelay := time.Since(delayStart)
 
 	s.log.Info("Pushing score data to Zabbix...")
 
 	// Generate and send host scores
-	hostScores := s.lldGenerator.GenerateHostScoreData(results.Hosts)
-	if err := s.sender.SendBatch(hostScores); err != nil {
-		return fmt.Errorf("failed to send host scores: %w", err)
+	if s.cfg.Scan.LLDEnabled(config.LLDHosts) {
+		hostScores := s.lldGenerator.GenerateHostScoreData(results.Hosts)
+		s.warnUndiscovered(hostsLLD, zabbix.HostsPrototypeKey, hostScores)
+		if err := s.sender.SendBatch(hostScores); err != nil {
+			return 0, fmt.Errorf("failed to send host scores: %w", err)
+		}
 	}
 
 	// Generate and send package scores
-	packageScores := s.lldGenerator.GeneratePackageScoreData(results.Packages)
-	if err := s.sender.SendBatch(packageScores); err != nil {
-		return fmt.Errorf("failed to send package scores: %w", err)
+	if s.cfg.Scan.LLDEnabled(config.LLDPackages) {
+		packageScores := s.lldGenerator.GeneratePackageScoreData(results.Packages)
+		s.warnUndiscovered(packagesLLD, zabbix.PackagePrototypeKey(s.cfg.Naming.HashPackageKeys), packageScores)
+		if err := s.sender.SendBatch(packageScores); err != nil {
+			return 0, fmt.Errorf("failed to send package scores: %w", err)
+		}
 	}
 
 	// Generate and send bulletin scores
-	bulletinScores := s.lldGenerator.GenerateBulletinScoreData(results.Bulletins)
-	if err := s.sender.SendBatch(bulletinScores); err != nil {
-		return fmt.Errorf("failed to send bulletin scores: %w", err)
+	if s.cfg.Scan.LLDEnabled(config.LLDBulletins) {
+		bulletinScores := s.lldGenerator.GenerateBulletinScoreData(results.Bulletins)
+		s.warnUndiscovered(bulletinsLLD, zabbix.BulletinsPrototypeKey, bulletinScores)
+		if err := s.sender.SendBatch(bulletinScores); err != nil {
+			return 0, fmt.Errorf("failed to send bulletin scores: %w", err)
+		}
 	}
 
 	// Generate and send statistics
 	stats := s.aggregator.GetStatistics()
 	statsData := s.lldGenerator.GenerateStatisticsData(stats)
 	if err := s.sender.SendBatch(statsData); err != nil {
-		return fmt.Errorf("failed to send statistics: %w", err)
+		return 0, fmt.Errorf("failed to send statistics: %w", err)
+	}
+
+	if len(results.GroupStats) > 0 {
+		if err := s.sender.SendBatch(s.lldGenerator.GenerateGroupStatisticsData(results.GroupStats)); err != nil {
+			return 0, fmt.Errorf("failed to send group statistics: %w", err)
+		}
+	}
+
+	if s.cfg.Scan.PushRawJSON {
+		if err := s.sender.SendJSON(s.cfg.Naming.StatisticsHost, "vulners.scan.raw", results); err != nil {
+			return 0, fmt.Errorf("failed to send raw scan JSON: %w", err)
+		}
 	}
 
 	s.log.Info("Results pushed to Zabbix",
@@ -256,7 +583,120 @@ func (s *Scanner) PushResults(ctx context.Context, results *ScanResults) error {
 		slog.Int("bulletins", len(results.Bulletins)),
 	)
 
-	return nil
+	return lldDelay, nil
+}
+
+// keepMaintenanceRows adds to lld the rows of the LLD last pushed to
+// host/key that list one of the maintenance hosts in hostsMacro and that lld
+// no longer discovers, so hosts skipped for maintenance keep their items.
+// Failing to read the previous LLD is only logged.
+func (s *Scanner) keepMaintenanceRows(ctx context.Context, lld *zabbix.LLDData, host, key, protoKey, hostsMacro string, maintenance []string) {
+	if len(maintenance) == 0 {
+		return
+	}
+	prev, err := s.previousLLD(ctx, host, key)
+	if err != nil {
+		s.log.Warn("Failed to read the previous LLD; hosts in maintenance lose their items", slog.String("lld", key), slog.Any("error", err))
+		return
+	}
+	if n := keepHostRows(lld, prev, protoKey, hostsMacro, maintenance); n > 0 {
+		s.log.Info("Kept LLD entries of hosts in maintenance", slog.String("lld", key), slog.Int("entries", n))
+	}
+}
+
+// previousLLD returns the LLD last pushed to host/key, empty when there is
+// none.
+func (s *Scanner) previousLLD(ctx context.Context, host, key string) (*zabbix.LLDData, error) {
+	value, err := s.zabbixClient.GetItemValueCtx(ctx, host, key)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return &zabbix.LLDData{}, nil
+	}
+	return zabbix.DecodeLLD(value)
+}
+
+// waitLLDDelay sleeps for the given number of seconds or until ctx is done.
+// A zero or negative delay returns immediately.
+func waitLLDDelay(ctx context.Context, seconds int) error {
+	if seconds <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(seconds) * time.Second):
+		return nil
+	}
+}
+
+// WaitJitter sleeps for a random duration from 0 to maxDelay, or until ctx
+// is done. Scheduled scans call it so instances started together spread
+// their load on Vulners and Zabbix. A zero or negative maxDelay returns
+// immediately.
+func WaitJitter(ctx context.Context, maxDelay time.Duration) error {
+	if maxDelay <= 0 {
+		return nil
+	}
+	t := time.NewTimer(rand.N(maxDelay + 1))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// pushPartial pushes the scores aggregated so far. It sends no LLD: one
+// holding only the hosts scanned so far would make Zabbix drop the items of
+// all the others. Only values for items the last pushed LLD discovered are
+// sent; Zabbix would reject the rest until the final push creates their
+// items. Failures are logged and left for the next flush or the final push
+// to retry.
+func (s *Scanner) pushPartial(ctx context.Context) {
+	partial := s.aggregator.GetResults()
+	if len(partial.Hosts) == 0 {
+		return
+	}
+	partial = s.filterPushMinCVSS(partial)
+
+	var data []zabbix.SenderData
+	add := func(kind, host, key, protoKey string, values func() []zabbix.SenderData) {
+		if !s.cfg.Scan.LLDEnabled(kind) {
+			return
+		}
+		prev, err := s.previousLLD(ctx, host, key)
+		if err != nil {
+			s.log.Warn("Failed to read the previous LLD; its values wait for the final push", slog.String("lld", key), slog.Any("error", err))
+			return
+		}
+		data = append(data, discoveredOnly(prev, protoKey, values())...)
+	}
+	add(config.LLDHosts, s.cfg.Naming.HostsHost, "vulners.hosts_lld", zabbix.HostsPrototypeKey, func() []zabbix.SenderData {
+		return s.lldGenerator.GenerateHostScoreData(partial.Hosts)
+	})
+	add(config.LLDPackages, s.cfg.Naming.PackagesHost, "vulners.packages_lld", zabbix.PackagePrototypeKey(s.cfg.Naming.HashPackageKeys), func() []zabbix.SenderData {
+		return s.lldGenerator.GeneratePackageScoreData(partial.Packages)
+	})
+	add(config.LLDBulletins, s.cfg.Naming.BulletinsHost, "vulners.bulletins_lld", zabbix.BulletinsPrototypeKey, func() []zabbix.SenderData {
+		return s.lldGenerator.GenerateBulletinScoreData(partial.Bulletins)
+	})
+	if len(data) == 0 {
+		s.log.Debug("No partial results for discovered items yet", slog.Int("hosts", len(partial.Hosts)))
+		return
+	}
+
+	s.log.Info("Pushing partial results to Zabbix", slog.Int("hosts", len(partial.Hosts)), slog.Int("values", len(data)))
+	if err := s.sender.SendBatch(data); err != nil {
+		s.log.Warn("Partial push failed", slog.Any("error", err))
+	}
+}
+
+// ResolveHostIDs turns a mix of host IDs and technical names into host IDs.
+func (s *Scanner) ResolveHostIDs(ctx context.Context, refs []string) ([]string, error) {
+	return s.hostMatrix.ResolveHostIDs(ctx, refs)
 }
 
 // GetAggregator returns the scanner's aggregator for external access
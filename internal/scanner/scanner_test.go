@@ -0,0 +1,440 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	vulners "github.com/kidoz/go-vulners"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+func TestWaitLLDDelay(t *testing.T) {
+	t.Run("zero skips the wait", func(t *testing.T) {
+		// An already-cancelled context proves no select happens at all.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for _, d := range []int{0, -5} {
+			start := time.Now()
+			if err := waitLLDDelay(ctx, d); err != nil {
+				t.Errorf("waitLLDDelay(%d) = %v, want nil", d, err)
+			}
+			if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+				t.Errorf("waitLLDDelay(%d) took %v", d, elapsed)
+			}
+		}
+	})
+
+	t.Run("cancellation interrupts the wait", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := waitLLDDelay(ctx, 300)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("waitLLDDelay = %v, want context.DeadlineExceeded", err)
+		}
+	})
+}
+
+func TestWaitJitter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WaitJitter(ctx, 0); err != nil {
+		t.Errorf("WaitJitter(0) = %v, want nil without waiting", err)
+	}
+	if err := WaitJitter(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("WaitJitter(1h) = %v, want context.Canceled", err)
+	}
+
+	start := time.Now()
+	if err := WaitJitter(context.Background(), 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WaitJitter(20ms) took %v", elapsed)
+	}
+}
+
+func TestCapLLDEntries(t *testing.T) {
+	var logs bytes.Buffer
+	cfg := config.DefaultConfig()
+	cfg.Scan.MaxLLDEntries = 2
+	s := &Scanner{cfg: cfg, log: slog.New(slog.NewTextHandler(&logs, nil))}
+
+	results := &ScanResults{
+		Hosts: []HostEntry{{HostID: "1", Score: 5}, {HostID: "2", Score: 9}},
+		Packages: []PackageEntry{
+			{Name: "low", Score: 2},
+			{Name: "top", Score: 9.8},
+			{Name: "mid", Score: 5},
+			{Name: "second", Score: 7.5},
+		},
+		Bulletins: []BulletinEntry{{ID: "A", Score: 1}, {ID: "B", Score: 6}, {ID: "C", Score: 8}},
+	}
+
+	capped := s.capLLDEntries(results)
+
+	names := func(pkgs []PackageEntry) string {
+		var n []string
+		for _, p := range pkgs {
+			n = append(n, p.Name)
+		}
+		return strings.Join(n, ",")
+	}
+	if got := names(capped.Packages); got != "top,second" {
+		t.Errorf("packages = %s, want top,second", got)
+	}
+	if len(capped.Bulletins) != 2 || capped.Bulletins[0].ID != "C" || capped.Bulletins[1].ID != "B" {
+		t.Errorf("bulletins = %+v, want C, B", capped.Bulletins)
+	}
+	if len(capped.Hosts) != 2 || capped.Hosts[0].HostID != "1" {
+		t.Errorf("hosts = %+v, want both hosts untouched", capped.Hosts)
+	}
+	if len(results.Packages) != 4 || results.Packages[0].Name != "low" {
+		t.Error("capLLDEntries modified the original results")
+	}
+
+	out := logs.String()
+	if !strings.Contains(out, "lld=packages") || !strings.Contains(out, "dropped=2") || !strings.Contains(out, "lld=bulletins") {
+		t.Errorf("missing cap warnings in logs:\n%s", out)
+	}
+	if strings.Contains(out, "lld=hosts") {
+		t.Errorf("hosts LLD was not cut but a warning was logged:\n%s", out)
+	}
+
+	cfg.Scan.MaxLLDEntries = 0
+	if s.capLLDEntries(results) != results {
+		t.Error("max_lld_entries 0 should not cap")
+	}
+}
+
+func TestPushMinCVSS(t *testing.T) {
+	dir := t.TempDir()
+	replay := `{
+  "cvss": 8.1,
+  "vulnerabilities": [
+    {"package": "zlib 1.2 amd64", "bulletinID": "USN-LOW", "cvss": {"score": 2.0}},
+    {"package": "curl 7.81 amd64", "bulletinID": "USN-MED", "cvss": {"score": 5.0}},
+    {"package": "openssl 1.1.1f amd64", "bulletinID": "USN-HIGH", "cvss": {"score": 8.1}}
+  ]
+}`
+	if err := os.WriteFile(filepath.Join(dir, "ubuntu.json"), []byte(replay), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		minCVSS     float64
+		pushMinCVSS float64
+		wantScanned string
+		wantPushed  string
+	}{
+		{"push everything scanned", 1, 0, "USN-HIGH,USN-MED,USN-LOW", "USN-HIGH,USN-MED,USN-LOW"},
+		{"push threshold above scan threshold", 3, 6, "USN-HIGH,USN-MED", "USN-HIGH"},
+		{"scan threshold above push threshold", 6, 3, "USN-HIGH", "USN-HIGH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Vulners.ReplayDir = dir
+			cfg.Scan.MinCVSS = tt.minCVSS
+			cfg.Scan.PushMinCVSS = tt.pushMinCVSS
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			auditor, err := NewReplayAuditor(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &fakeZabbix{
+				hosts: []zabbix.Host{{HostID: "1", Host: "web-01", Name: "Web 01"}},
+				items: map[string]map[string]string{
+					"1": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": strings.Repeat("bash 5.1 amd64\n", 6)},
+				},
+			}
+			s := &Scanner{
+				cfg:          cfg,
+				log:          log,
+				zabbixClient: client,
+				auditor:      auditor,
+				hostMatrix:   NewHostMatrix(cfg, log, client),
+				aggregator:   NewAggregator(),
+				lldGenerator: ProvideLLDGenerator(cfg),
+			}
+
+			results, err := s.Scan(context.Background(), ScanOptions{NoPush: true})
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			ids := func(bulletins []BulletinEntry) string {
+				var n []string
+				for _, b := range bulletins {
+					n = append(n, b.ID)
+				}
+				return strings.Join(n, ",")
+			}
+			if got := ids(results.Bulletins); got != tt.wantScanned {
+				t.Errorf("scanned bulletins = %s, want %s", got, tt.wantScanned)
+			}
+
+			pushed := s.filterPushMinCVSS(results)
+			if got := ids(pushed.Bulletins); got != tt.wantPushed {
+				t.Errorf("pushed bulletins = %s, want %s", got, tt.wantPushed)
+			}
+			if len(pushed.Packages) != strings.Count(tt.wantPushed, ",")+1 {
+				t.Errorf("pushed %d packages, want one per pushed bulletin", len(pushed.Packages))
+			}
+			if len(pushed.Hosts) != 1 {
+				t.Errorf("pushed %d hosts, want the host regardless of push_min_cvss", len(pushed.Hosts))
+			}
+			if ids(results.Bulletins) != tt.wantScanned {
+				t.Error("filterPushMinCVSS modified the scan results")
+			}
+		})
+	}
+}
+
+// versionAuditor fails audits of one OS version and scores the others 5.
+type versionAuditor struct{ failVersion string }
+
+func (a versionAuditor) LinuxAudit(_ context.Context, _, version string, _ []string) (*vulners.AuditResult, error) {
+	if version == a.failVersion {
+		return nil, errors.New("audit failed")
+	}
+	return &vulners.AuditResult{CVSSScore: 5}, nil
+}
+
+// newEventScanner returns a scanner over three hosts, the last of which
+// fails its audit.
+func newEventScanner() *Scanner {
+	cfg := config.DefaultConfig()
+	cfg.Scan.Workers = 4
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	packages := strings.Repeat("bash 5.1 amd64\n", 6)
+	client := &fakeZabbix{
+		hosts: []zabbix.Host{
+			{HostID: "1", Host: "web-01", Name: "Web 01"},
+			{HostID: "2", Host: "web-02", Name: "Web 02"},
+			{HostID: "3", Host: "old-01", Name: "Old 01"},
+		},
+		items: map[string]map[string]string{
+			"1": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": packages},
+			"2": {"system.sw.os": "Ubuntu 22.04", "system.sw.packages": packages},
+			"3": {"system.sw.os": "Ubuntu 14.04", "system.sw.packages": packages},
+		},
+	}
+	return &Scanner{
+		cfg:          cfg,
+		log:          log,
+		zabbixClient: client,
+		auditor:      versionAuditor{failVersion: "14.04"},
+		hostMatrix:   NewHostMatrix(cfg, log, client),
+		aggregator:   NewAggregator(),
+		lldGenerator: ProvideLLDGenerator(cfg),
+	}
+}
+
+func TestScan_HostEvents(t *testing.T) {
+	s := newEventScanner()
+
+	// The callback is not synchronized itself; serialized calls keep the
+	// race detector quiet.
+	var events []HostEvent
+	_, err := s.Scan(context.Background(), ScanOptions{NoPush: true, OnHostEvent: func(e HostEvent) {
+		events = append(events, e)
+	}})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	kinds := map[string]map[string]int{}
+	for i, e := range events {
+		if kinds[e.HostID] == nil {
+			kinds[e.HostID] = map[string]int{}
+		}
+		kinds[e.HostID][e.Kind]++
+		if e.Total != 3 {
+			t.Errorf("event %d total = %d, want 3", i, e.Total)
+		}
+		switch e.Kind {
+		case HostCompleted:
+			if e.Entry == nil || e.Entry.Score != 5 {
+				t.Errorf("completed event %+v, want the host entry", e)
+			}
+		case HostFailed:
+			if e.Err == nil || e.HostID != "3" {
+				t.Errorf("failed event %+v, want host 3 with an error", e)
+			}
+		}
+	}
+	if len(events) != 6 || events[len(events)-1].Done != 3 {
+		t.Errorf("got %d events ending at done=%d, want 6 ending at 3", len(events), events[len(events)-1].Done)
+	}
+	want := map[string]map[string]int{
+		"1": {HostStarted: 1, HostCompleted: 1},
+		"2": {HostStarted: 1, HostCompleted: 1},
+		"3": {HostStarted: 1, HostFailed: 1},
+	}
+	for id, k := range want {
+		for kind, n := range k {
+			if kinds[id][kind] != n {
+				t.Errorf("host %s %s events = %d, want %d", id, kind, kinds[id][kind], n)
+			}
+		}
+	}
+}
+
+func TestScan_OnHostScanned(t *testing.T) {
+	s := newEventScanner()
+
+	scanned := map[string]float64{}
+	var events int
+	results, err := s.Scan(context.Background(), ScanOptions{
+		NoPush:        true,
+		OnHostScanned: func(h HostEntry) { scanned[h.HostID] = h.Score },
+		OnHostEvent:   func(HostEvent) { events++ },
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	want := map[string]float64{"1": 5, "2": 5}
+	if len(scanned) != len(want) || scanned["1"] != want["1"] || scanned["2"] != want["2"] {
+		t.Errorf("scanned hosts = %v, want %v", scanned, want)
+	}
+	if len(scanned) != len(results.Hosts) {
+		t.Errorf("callback fired for %d hosts, results have %d", len(scanned), len(results.Hosts))
+	}
+	if events != 6 {
+		t.Errorf("OnHostEvent fired %d times alongside OnHostScanned, want 6", events)
+	}
+}
+
+func TestScanStream(t *testing.T) {
+	s := newEventScanner()
+
+	entries, errc := s.ScanStream(context.Background(), ScanOptions{NoPush: true})
+	got := map[string]float64{}
+	for entry := range entries {
+		got[entry.HostID] = entry.Score
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("ScanStream: %v", err)
+	}
+	if len(got) != 2 || got["1"] != 5 || got["2"] != 5 {
+		t.Errorf("streamed hosts = %v, want hosts 1 and 2 scored 5", got)
+	}
+	if len(s.aggregator.GetResults().Hosts) != 0 {
+		t.Error("ScanStream fed the aggregator")
+	}
+
+	t.Run("fetch error", func(t *testing.T) {
+		s := newEventScanner()
+		s.hostMatrix.client.(*fakeZabbix).hostsErr = errors.New("zabbix down")
+		entries, errc := s.ScanStream(context.Background(), ScanOptions{})
+		for entry := range entries {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		if err := <-errc; err == nil || !strings.Contains(err.Error(), "zabbix down") {
+			t.Errorf("error = %v, want the fetch error", err)
+		}
+	})
+}
+
+// panicAuditor fails the test run if an audit is attempted.
+type panicAuditor struct{}
+
+func (panicAuditor) LinuxAudit(context.Context, string, string, []string) (*vulners.AuditResult, error) {
+	panic("unexpected audit")
+}
+
+func TestScan_MaintenanceSkipScan(t *testing.T) {
+	s := newEventScanner()
+	s.auditor = panicAuditor{}
+	s.cfg.Scan.Maintenance = config.MaintenanceSkipScan
+	s.zabbixClient.(*fakeZabbix).hosts[1].MaintenanceStatus = zabbix.HostInMaintenance
+
+	results, err := s.Scan(context.Background(), ScanOptions{})
+	if !errors.Is(err, ErrScanSkipped) {
+		t.Fatalf("Scan = %v, %v; want ErrScanSkipped", results, err)
+	}
+}
+
+func TestPushPartial_KeepsDiscoveredItems(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake zabbix_sender is a shell script")
+	}
+	s := newEventScanner()
+	dir := t.TempDir()
+	out := filepath.Join(dir, "sent")
+	s.cfg.Zabbix.SenderPath = filepath.Join(dir, "zabbix_sender")
+	if err := os.WriteFile(s.cfg.Zabbix.SenderPath, []byte("#!/bin/sh\ncat >> "+out+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s.sender = zabbix.NewSender(s.cfg, s.log)
+
+	// The last full push discovered hosts 1 and 9; host 2 is new.
+	prev, err := json.Marshal(s.lldGenerator.GenerateHostsLLD([]HostEntry{{HostID: "1"}, {HostID: "9"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.zabbixClient.(*fakeZabbix).values = map[string]map[string]string{
+		"vulners.hosts": {"vulners.hosts_lld": string(prev)},
+	}
+	s.aggregator.AddHost(HostEntry{HostID: "1", Score: 7.5})
+	s.aggregator.AddHost(HostEntry{HostID: "2", Score: 5})
+
+	s.pushPartial(context.Background())
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("nothing sent: %v", err)
+	}
+	// No LLD, so Zabbix keeps the items of host 9; no value for host 2,
+	// whose item does not exist until the final push.
+	if want := "vulners.hosts vulners.hosts[1] 7.5\n"; string(got) != want {
+		t.Errorf("partial push sent %q, want %q", got, want)
+	}
+}
+
+func TestScan_CollectOnly(t *testing.T) {
+	s := newEventScanner()
+	s.auditor = panicAuditor{}
+
+	results, err := s.Scan(context.Background(), ScanOptions{CollectOnly: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(results.Hosts) != 0 || results.HostsScanned != 0 {
+		t.Errorf("collect-only scan has findings: %+v", results)
+	}
+	if len(results.Inventory) != 3 {
+		t.Fatalf("inventory has %d hosts, want 3", len(results.Inventory))
+	}
+	got := results.Inventory[2]
+	if got.HostID != "3" || got.Host != "old-01" || got.OSName != "ubuntu" || got.OSVersion != "14.04" {
+		t.Errorf("inventory[2] = %+v, want host 3 as ubuntu 14.04", got)
+	}
+	if len(got.Packages) == 0 || got.Packages[0] != "bash 5.1 amd64" {
+		t.Errorf("inventory[2] packages = %v, want the parsed package list", got.Packages)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteInventoryJSON(&buf, results, time.Unix(0, 0)); err != nil {
+		t.Fatalf("WriteInventoryJSON: %v", err)
+	}
+	for _, want := range []string{`"generated_at": "1970-01-01T00:00:00Z"`, `"os_version": "14.04"`, `"packages": [`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("inventory JSON lacks %s:\n%s", want, buf.String())
+		}
+	}
+}
@@ -1,81 +1,198 @@
 package scanner
 
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
 // ScanOptions configures a vulnerability scan
 type ScanOptions struct {
 	Limit   int      // Maximum number of hosts to scan (0 = unlimited)
 	NoPush  bool     // Don't push results to Zabbix
 	DryRun  bool     // Don't make any changes
 	HostIDs []string // Specific host IDs to scan (empty = all)
+
+	// IncrementalPush pushes partial results to Zabbix while scanning, every
+	// PushEvery hosts and/or every PushInterval (zero disables that trigger).
+	// Partial pushes send only the values of items the last pushed LLD
+	// discovered; LLD and statistics wait for the final push.
+	IncrementalPush bool
+	PushEvery       int
+	PushInterval    time.Duration
+
+	// HostCache, when set, reuses results for hosts whose fingerprint is
+	// unchanged and evicts hosts that are no longer scanned.
+	HostCache HostCache
+
+	// GroupStats also computes Statistics per Zabbix host group, which
+	// PushResults sends as group-tagged items.
+	GroupStats bool
+
+	// OnHostEvent, if set, is called as each host's audit starts and ends.
+	// Calls come from the scan workers but are serialized.
+	OnHostEvent func(HostEvent)
+
+	// OnHostScanned, if set, is called with each host that finishes
+	// scanning, under the same serialization as OnHostEvent.
+	OnHostScanned func(HostEntry)
+
+	// CollectOnly only fetches the hosts: Scan returns their Inventory and
+	// exclusions without auditing them, and there is nothing to push.
+	CollectOnly bool
+}
+
+// Host event kinds for HostEvent.Kind.
+const (
+	HostStarted   = "started"
+	HostCompleted = "completed" // including results reused from the host cache
+	HostFailed    = "failed"
+)
+
+// HostEvent reports the progress of one host during a scan.
+type HostEvent struct {
+	Kind   string
+	HostID string
+	Name   string
+	Entry  *HostEntry // set for HostCompleted
+	Err    error      // set for HostFailed
+
+	// Done counts the hosts completed or failed so far, out of Total.
+	Done, Total int
 }
 
 // ScanResults contains the results of a vulnerability scan
 type ScanResults struct {
-	HostsScanned       int
-	HostsWithVulns     int
-	VulnerablePackages int
-	MaxCVSS            float64
-	Hosts              []HostEntry
-	Packages           []PackageEntry
-	Bulletins          []BulletinEntry
+	HostsScanned       int             `json:"hosts_scanned"`
+	HostsWithVulns     int             `json:"hosts_with_vulns"`
+	VulnerablePackages int             `json:"vulnerable_packages"`
+	MaxCVSS            float64         `json:"max_cvss"`
+	Hosts              []HostEntry     `json:"hosts"`
+	Packages           []PackageEntry  `json:"packages"`
+	Bulletins          []BulletinEntry `json:"bulletins"`
+	Excluded           map[string]int  `json:"excluded,omitempty"` // excluded host count per reason
+	Timings            ScanTimings     `json:"-"`
+	// GroupStats is set when ScanOptions.GroupStats is, ordered by group name.
+	GroupStats []GroupStatistics `json:"-"`
+	// MaintenanceHostIDs are the hosts excluded as in maintenance; the push
+	// keeps their entries of the previously pushed LLD.
+	MaintenanceHostIDs []string `json:"-"`
+	// Inventory is set instead of the findings by ScanOptions.CollectOnly.
+	Inventory []HostInventory `json:"inventory,omitempty"`
+}
+
+// TopHosts returns the n highest-scoring hosts, highest first, or all of
+// them when there are fewer. Equal scores keep their order.
+func (r *ScanResults) TopHosts(n int) []HostEntry {
+	return topN(r.Hosts, n, func(h HostEntry) float64 { return h.Score })
+}
+
+// TopPackages returns the n highest-scoring packages like TopHosts.
+func (r *ScanResults) TopPackages(n int) []PackageEntry {
+	return topN(r.Packages, n, func(p PackageEntry) float64 { return p.Score })
+}
+
+// TopBulletins returns the n highest-scoring bulletins like TopHosts.
+func (r *ScanResults) TopBulletins(n int) []BulletinEntry {
+	return topN(r.Bulletins, n, func(b BulletinEntry) float64 { return b.Score })
+}
+
+// topN returns a sorted copy of the n highest-scoring items; nil for
+// n <= 0.
+func topN[T any](items []T, n int, score func(T) float64) []T {
+	if n <= 0 || len(items) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b T) int { return cmp.Compare(score(b), score(a)) })
+	return sorted[:min(n, len(sorted))]
+}
+
+// ScanTimings records how long each phase of a scan took. Push and LLDDelay
+// are filled in by PushResults.
+type ScanTimings struct {
+	FetchHosts time.Duration // fetching hosts and inventory from Zabbix
+	Audit      time.Duration // auditing hosts against Vulners
+	LLDDelay   time.Duration // waiting for Zabbix to process LLD
+	Push       time.Duration // sending LLD and values, excluding LLDDelay
+}
+
+// Total returns the wall time of all phases.
+func (t ScanTimings) Total() time.Duration {
+	return t.FetchHosts + t.Audit + t.LLDDelay + t.Push
 }
 
 // HostEntry represents vulnerability data for a single host
 type HostEntry struct {
-	HostID        string
-	Host          string // technical name
-	Name          string // visible name
-	OSName        string
-	OSVersion     string
-	Score         float64
-	CumulativeFix string
-	Packages      []PackageVuln
-	Bulletins     []BulletinSummary
+	HostID        string             `json:"host_id"`
+	Host          string             `json:"host"` // technical name
+	Name          string             `json:"name"` // visible name
+	OSName        string             `json:"os_name"`
+	OSVersion     string             `json:"os_version"`
+	Score         float64            `json:"score"`
+	CumulativeFix string             `json:"cumulative_fix"`
+	Packages      []PackageVuln      `json:"packages"`
+	Bulletins     []BulletinSummary  `json:"bulletins"`
+	Groups        []zabbix.HostGroup `json:"groups,omitempty"`
+}
+
+// HostInventory is what a scan audits for one host: its OS and package
+// list after normalization.
+type HostInventory struct {
+	HostID    string   `json:"host_id"`
+	Host      string   `json:"host"` // technical name
+	Name      string   `json:"name"` // visible name
+	OSName    string   `json:"os_name"`
+	OSVersion string   `json:"os_version"`
+	Packages  []string `json:"packages"`
 }
 
 // PackageVuln represents vulnerability information for a single package
 type PackageVuln struct {
-	Name      string
-	Version   string
-	Arch      string
-	Score     float64
-	Fix       string
-	Bulletins []string
-	CVEs      []string
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Arch      string   `json:"arch"`
+	Score     float64  `json:"score"`
+	Fix       string   `json:"fix"`
+	Bulletins []string `json:"bulletins"`
+	CVEs      []string `json:"cves"`
 }
 
 // BulletinSummary represents aggregated bulletin information
 type BulletinSummary struct {
-	ID            string
-	Type          string
-	Score         float64
-	CVEs          []string
-	Fix           string
-	AffectedPkg   []string
-	AffectedHosts []string
+	ID            string   `json:"id"`
+	Type          string   `json:"type"`
+	Score         float64  `json:"score"`
+	CVEs          []string `json:"cves"`
+	Fix           string   `json:"fix"`
+	AffectedPkg   []string `json:"affected_pkg"`
+	AffectedHosts []string `json:"affected_hosts"`
 }
 
 // PackageEntry represents a vulnerable package aggregated across hosts
 type PackageEntry struct {
-	Name              string
-	Version           string
-	Arch              string
-	Score             float64
-	Fix               string
-	AffectedHosts     []string // host IDs
-	AffectedHostNames []string // visible host names
-	Bulletins         []string
+	Name              string   `json:"name"`
+	Version           string   `json:"version"`
+	Arch              string   `json:"arch"`
+	Score             float64  `json:"score"`
+	Fix               string   `json:"fix"`
+	AffectedHosts     []string `json:"affected_hosts"`      // host IDs
+	AffectedHostNames []string `json:"affected_host_names"` // visible host names
+	Bulletins         []string `json:"bulletins"`
 }
 
 // BulletinEntry represents a security bulletin aggregated across hosts
 type BulletinEntry struct {
-	ID                string
-	Type              string
-	Score             float64
-	CVEs              []string
-	Fix               string
-	AffectedPkgs      []string
-	AffectedHosts     []string // host IDs
-	AffectedHostNames []string // visible host names
+	ID                string   `json:"id"`
+	Type              string   `json:"type"`
+	Score             float64  `json:"score"`
+	CVEs              []string `json:"cves"`
+	Fix               string   `json:"fix"`
+	AffectedPkgs      []string `json:"affected_pkgs"`
+	AffectedHosts     []string `json:"affected_hosts"`      // host IDs
+	AffectedHostNames []string `json:"affected_host_names"` // visible host names
 }
 
 // Statistics contains aggregated statistics
@@ -89,5 +206,23 @@ type Statistics struct {
 	AvgCVSS         float64
 	MinCVSS         float64
 	MedianCVSS      float64
-	Histogram       [11]int // index 0-10: count of hosts per integer CVSS score bucket
+	// FleetRisk is the sum of all host scores. Unlike AvgCVSS it grows with
+	// both the number of vulnerable hosts and their severity, so patching
+	// any host lowers it.
+	FleetRisk float64
+	Histogram [11]int    // index 0-10: count of hosts per integer CVSS score bucket
+	TopCVEs   []CVECount // CVEs referenced by the most bulletins, highest first
+}
+
+// GroupStatistics is Statistics restricted to the hosts of one host group.
+type GroupStatistics struct {
+	GroupID string
+	Name    string
+	Statistics
+}
+
+// CVECount is the number of bulletins that reference a CVE.
+type CVECount struct {
+	CVE   string `json:"cve"`
+	Count int    `json:"count"`
 }
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"log/slog"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+// eventBuffer is how many events a slow WebSocket client may lag behind
+// before further events are dropped for it.
+const eventBuffer = 256
+
+// Event is a scan progress message sent to WebSocket clients.
+type Event struct {
+	Kind   string  `json:"kind"` // scanner.HostStarted, HostCompleted or HostFailed
+	HostID string  `json:"host_id"`
+	Name   string  `json:"name"`
+	Score  float64 `json:"score,omitempty"` // completed hosts only
+	Error  string  `json:"error,omitempty"` // failed hosts only
+	Done   int     `json:"done"`
+	Total  int     `json:"total"`
+}
+
+// EventHub fans scan events out to the connected WebSocket clients.
+type EventHub struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+// NewEventHub creates a hub without clients.
+func NewEventHub() *EventHub {
+	return &EventHub{subs: make(map[chan Event]struct{})}
+}
+
+// Publish sends a scanner host event to every client. It never blocks, so
+// it can be used as ScanOptions.OnHostEvent.
+func (h *EventHub) Publish(e scanner.HostEvent) {
+	ev := Event{Kind: e.Kind, HostID: e.HostID, Name: e.Name, Done: e.Done, Total: e.Total}
+	if e.Entry != nil {
+		ev.Score = e.Entry.Score
+	}
+	if e.Err != nil {
+		ev.Error = e.Err.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default: // client too slow; drop rather than stall the scan
+		}
+	}
+}
+
+// Close disconnects all clients and refuses new ones.
+func (h *EventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// subscribe registers a client. The channel is closed by unsubscribe or
+// Close; ok is false once the hub is closed.
+func (h *EventHub) subscribe() (ch chan Event, unsubscribe func(), ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, false
+	}
+	ch = make(chan Event, eventBuffer)
+	h.subs[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}, true
+}
+
+// StreamEvents makes the server stream hub's events to WebSocket clients
+// at /scan/events.
+func (s *Server) StreamEvents(hub *EventHub) *Server {
+	s.events = hub
+	return s
+}
+
+// eventsHandler upgrades to a WebSocket and sends each event as a JSON
+// text message until the client disconnects or the hub closes.
+func (s *Server) eventsHandler() http.Handler {
+	return websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			events, unsubscribe, ok := s.events.subscribe()
+			if !ok {
+				return
+			}
+			defer unsubscribe()
+
+			// Clients only listen; a read returns when they go away.
+			gone := make(chan struct{})
+			go func() {
+				defer close(gone)
+				var discard []byte
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+			}()
+
+			for {
+				select {
+				case ev, ok := <-events:
+					if !ok {
+						return
+					}
+					if err := websocket.JSON.Send(ws, ev); err != nil {
+						s.log.Debug("Failed to send scan event", slog.Any("error", err))
+						return
+					}
+				case <-gone:
+					return
+				}
+			}
+		},
+	}
+}
+
+// checkOrigin accepts clients without an Origin header (non-browser) and
+// browser pages served from the same host, so other sites cannot read the
+// stream through a visitor's browser.
+func checkOrigin(_ *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != r.Host {
+		return fmt.Errorf("origin %q not allowed", origin)
+	}
+	return nil
+}
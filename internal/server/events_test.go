@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+func newEventServer(t *testing.T, hub *EventHub) *httptest.Server {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	source := func(context.Context) (*scanner.ScanResults, error) { return testResults(), nil }
+	ts := httptest.NewServer(New(source, 0, log).StreamEvents(hub).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func dialEvents(t *testing.T, ts *httptest.Server, origin string) (*websocket.Conn, error) {
+	t.Helper()
+	return websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/scan/events", "", origin)
+}
+
+// waitSubscribers waits until the hub has n clients.
+func waitSubscribers(t *testing.T, hub *EventHub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		hub.mu.Lock()
+		got := len(hub.subs)
+		hub.mu.Unlock()
+		if got == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("hub has %d subscribers, want %d", got, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEvents_Stream(t *testing.T) {
+	hub := NewEventHub()
+	ts := newEventServer(t, hub)
+
+	ws, err := dialEvents(t, ts, ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ws.Close() }()
+	waitSubscribers(t, hub, 1)
+
+	entry := scanner.HostEntry{Score: 9.8}
+	hub.Publish(scanner.HostEvent{Kind: scanner.HostStarted, HostID: "10", Name: "web-01", Total: 2})
+	hub.Publish(scanner.HostEvent{Kind: scanner.HostCompleted, HostID: "10", Name: "web-01", Entry: &entry, Done: 1, Total: 2})
+	hub.Publish(scanner.HostEvent{Kind: scanner.HostFailed, HostID: "20", Name: "db-01", Err: errors.New("timeout"), Done: 2, Total: 2})
+
+	want := []Event{
+		{Kind: "started", HostID: "10", Name: "web-01", Total: 2},
+		{Kind: "completed", HostID: "10", Name: "web-01", Score: 9.8, Done: 1, Total: 2},
+		{Kind: "failed", HostID: "20", Name: "db-01", Error: "timeout", Done: 2, Total: 2},
+	}
+	for i, w := range want {
+		var got Event
+		if err := websocket.JSON.Receive(ws, &got); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if got != w {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// Closing the hub ends the stream.
+	hub.Close()
+	var ev Event
+	if err := websocket.JSON.Receive(ws, &ev); err == nil {
+		t.Errorf("received %+v after the hub closed", ev)
+	}
+}
+
+func TestEvents_Origin(t *testing.T) {
+	ts := newEventServer(t, NewEventHub())
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	tests := []struct {
+		origin string
+		wantOK bool
+	}{
+		{"http://" + host, true},
+		{"https://evil.example", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			ws, err := dialEvents(t, ts, tt.origin)
+			if err == nil {
+				_ = ws.Close()
+			}
+			if (err == nil) != tt.wantOK {
+				t.Errorf("dial error = %v, want ok %v", err, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEvents_DisabledWithoutHub(t *testing.T) {
+	ts := newTestServer(t, func(context.Context) (*scanner.ScanResults, error) { return testResults(), nil })
+	resp, err := http.Get(ts.URL + "/scan/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,466 @@
+//go:build graphql
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+// maxGraphQLBody bounds the size of a POSTed GraphQL request.
+const maxGraphQLBody = 1 << 20
+
+func init() {
+	extraRoutes = append(extraRoutes, func(s *Server, mux *http.ServeMux) {
+		mux.HandleFunc("GET /graphql", s.handleGraphQL)
+		mux.HandleFunc("POST /graphql", s.handleGraphQL)
+		mux.HandleFunc("GET /graphql/schema", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte(GraphQLSchema))
+		})
+	})
+}
+
+// GraphQLSchema is the schema served at /graphql; /graphql/schema returns
+// it. List fields take minCvss, cve, limit and offset arguments, with the
+// REST pagination limits.
+const GraphQLSchema = `type Query {
+  hosts(minCvss: Float, cve: String, id: String, limit: Int, offset: Int): [Host!]!
+  host(id: String!): Host
+  packages(minCvss: Float, cve: String, name: String, limit: Int, offset: Int): [Package!]!
+  bulletins(minCvss: Float, cve: String, id: String, limit: Int, offset: Int): [Bulletin!]!
+  bulletin(id: String!): Bulletin
+  stats: Stats!
+}
+
+type Host {
+  id: String!  host: String!  name: String!  os: String!  osVersion: String!
+  score: Float!  cumulativeFix: String!  groups: [String!]!  cves: [String!]!
+  packages(minCvss: Float, cve: String): [HostPackage!]!
+  bulletins(minCvss: Float, cve: String): [HostBulletin!]!
+}
+
+type HostPackage {
+  name: String!  version: String!  arch: String!  score: Float!  fix: String!
+  cves: [String!]!  bulletins: [Bulletin!]!
+}
+
+type HostBulletin {
+  id: String!  type: String!  score: Float!  fix: String!  cves: [String!]!
+  packages: [String!]!
+}
+
+type Package {
+  name: String!  version: String!  arch: String!  score: Float!  fix: String!
+  cves: [String!]!  bulletins: [Bulletin!]!  hosts: [Host!]!  hostNames: [String!]!
+}
+
+type Bulletin {
+  id: String!  type: String!  score: Float!  fix: String!  cves: [String!]!
+  packages: [String!]!  hosts: [Host!]!  hostNames: [String!]!
+}
+
+type Stats {
+  hosts: Int!  vulnerableHosts: Int!  packages: Int!  bulletins: Int!
+  maxCvss: Float!  avgCvss: Float!  loadedAt: String!
+}
+`
+
+// gqlSchema binds GraphQLSchema to its resolvers. The results a query runs
+// against travel in the request context, see gqlData.
+var gqlSchema = graphql.MustParseSchema(GraphQLSchema, &gqlQuery{})
+
+type gqlRequestBody struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req gqlRequestBody
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeGraphQLError(w, http.StatusBadRequest, fmt.Errorf("invalid variables: %w", err))
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBody)).Decode(&req); err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if errs := gqlSchema.ValidateWithVariables(req.Query, req.Variables); len(errs) > 0 {
+		writeJSON(w, http.StatusBadRequest, &graphql.Response{Errors: errs})
+		return
+	}
+
+	results, loadedAt, err := s.Results(r.Context())
+	if err != nil {
+		s.unavailable(w, err)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), gqlDataKey{}, newGQLData(results, loadedAt))
+	resp := gqlSchema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	status := http.StatusOK
+	if resp.Data == nil {
+		// Not executed, e.g. a mutation or an unknown operation name.
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, resp)
+}
+
+func writeGraphQLError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &graphql.Response{Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("%s", err)}})
+}
+
+type gqlDataKey struct{}
+
+// gqlData is the results snapshot one query runs against, with hosts and
+// bulletins indexed by ID.
+type gqlData struct {
+	results   *scanner.ScanResults
+	loadedAt  time.Time
+	hosts     map[string]*scanner.HostEntry
+	bulletins map[string]*scanner.BulletinEntry
+}
+
+func newGQLData(results *scanner.ScanResults, loadedAt time.Time) *gqlData {
+	d := &gqlData{
+		results:   results,
+		loadedAt:  loadedAt,
+		hosts:     make(map[string]*scanner.HostEntry, len(results.Hosts)),
+		bulletins: make(map[string]*scanner.BulletinEntry, len(results.Bulletins)),
+	}
+	for i := range results.Hosts {
+		d.hosts[results.Hosts[i].HostID] = &results.Hosts[i]
+	}
+	for i := range results.Bulletins {
+		d.bulletins[results.Bulletins[i].ID] = &results.Bulletins[i]
+	}
+	return d
+}
+
+func gqlDataFrom(ctx context.Context) *gqlData {
+	return ctx.Value(gqlDataKey{}).(*gqlData)
+}
+
+// gqlListArgs are the arguments of the top-level list fields besides the
+// entry's own key.
+type gqlListArgs struct {
+	MinCvss *float64
+	Cve     *string
+	Limit   *int32
+	Offset  *int32
+}
+
+// listQuery applies the bounds of the REST endpoints.
+func (a gqlListArgs) listQuery() (listQuery, error) {
+	q := listQuery{limit: DefaultLimit}
+	if a.MinCvss != nil {
+		q.minCVSS = *a.MinCvss
+	}
+	if q.minCVSS < 0 || q.minCVSS > 10 {
+		return q, fmt.Errorf("minCvss must be between 0 and 10, got %g", q.minCVSS)
+	}
+	if a.Limit != nil {
+		q.limit = int(*a.Limit)
+	}
+	if q.limit < 1 || q.limit > MaxLimit {
+		return q, fmt.Errorf("limit must be between 1 and %d, got %d", MaxLimit, q.limit)
+	}
+	if a.Offset != nil {
+		q.offset = int(*a.Offset)
+	}
+	if q.offset < 0 {
+		return q, fmt.Errorf("offset must be non-negative, got %d", q.offset)
+	}
+	return q, nil
+}
+
+// gqlNestedArgs are the arguments of the lists nested in a host.
+type gqlNestedArgs struct {
+	MinCvss *float64
+	Cve     *string
+}
+
+// queryList filters items by key and the cve argument, then applies the
+// list query and wraps the page with wrap.
+func queryList[T, R any](items []T, args gqlListArgs, want *string, key func(*T) string, cves func(*T) []string, score func(T) float64, wrap func(*T) R) ([]R, error) {
+	q, err := args.listQuery()
+	if err != nil {
+		return nil, err
+	}
+	var matched []T
+	for i := range items {
+		if (want == nil || key(&items[i]) == *want) && (args.Cve == nil || slices.Contains(cves(&items[i]), *args.Cve)) {
+			matched = append(matched, items[i])
+		}
+	}
+	page := paginate(matched, q, score)
+	out := make([]R, len(page.Items))
+	for i := range page.Items {
+		out[i] = wrap(&page.Items[i])
+	}
+	return out, nil
+}
+
+// filterNested applies the minCvss and cve arguments of a nested list.
+func filterNested[T, R any](items []T, args gqlNestedArgs, cves func(*T) []string, score func(*T) float64, wrap func(*T) R) []R {
+	out := []R{}
+	for i := range items {
+		if args.MinCvss != nil && score(&items[i]) < *args.MinCvss {
+			continue
+		}
+		if args.Cve != nil && !slices.Contains(cves(&items[i]), *args.Cve) {
+			continue
+		}
+		out = append(out, wrap(&items[i]))
+	}
+	return out
+}
+
+// sortedSet returns the keys of set in order.
+func sortedSet(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// hostCVEs lists the CVEs of a host's packages and bulletins.
+func hostCVEs(h *scanner.HostEntry) []string {
+	set := make(map[string]bool)
+	for _, p := range h.Packages {
+		for _, c := range p.CVEs {
+			set[c] = true
+		}
+	}
+	for _, b := range h.Bulletins {
+		for _, c := range b.CVEs {
+			set[c] = true
+		}
+	}
+	return sortedSet(set)
+}
+
+// packageCVEs lists the CVEs of the bulletins naming a package, since the
+// aggregated package entry does not carry them.
+func (d *gqlData) packageCVEs(p *scanner.PackageEntry) []string {
+	set := make(map[string]bool)
+	for _, id := range p.Bulletins {
+		if b, ok := d.bulletins[id]; ok {
+			for _, c := range b.CVEs {
+				set[c] = true
+			}
+		}
+	}
+	return sortedSet(set)
+}
+
+// bulletinResolvers resolves bulletin IDs, skipping unknown ones.
+func (d *gqlData) bulletinResolvers(ids []string) []*gqlBulletin {
+	out := []*gqlBulletin{}
+	for _, id := range ids {
+		if b, ok := d.bulletins[id]; ok {
+			out = append(out, &gqlBulletin{b})
+		}
+	}
+	return out
+}
+
+// hostResolvers resolves host IDs, skipping unknown ones.
+func (d *gqlData) hostResolvers(ids []string) []*gqlHost {
+	out := []*gqlHost{}
+	for _, id := range ids {
+		if h, ok := d.hosts[id]; ok {
+			out = append(out, &gqlHost{h})
+		}
+	}
+	return out
+}
+
+// nonNil makes nil string lists encode as [] instead of null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// gqlQuery resolves the Query type.
+type gqlQuery struct{}
+
+func (*gqlQuery) Hosts(ctx context.Context, args struct {
+	ID *string
+	gqlListArgs
+}) ([]*gqlHost, error) {
+	return queryList(gqlDataFrom(ctx).results.Hosts, args.gqlListArgs, args.ID,
+		func(h *scanner.HostEntry) string { return h.HostID },
+		hostCVEs,
+		func(h scanner.HostEntry) float64 { return h.Score },
+		func(h *scanner.HostEntry) *gqlHost { return &gqlHost{h} })
+}
+
+func (*gqlQuery) Host(ctx context.Context, args struct{ ID string }) *gqlHost {
+	if h, ok := gqlDataFrom(ctx).hosts[args.ID]; ok {
+		return &gqlHost{h}
+	}
+	return nil
+}
+
+func (*gqlQuery) Packages(ctx context.Context, args struct {
+	Name *string
+	gqlListArgs
+}) ([]*gqlPackage, error) {
+	d := gqlDataFrom(ctx)
+	return queryList(d.results.Packages, args.gqlListArgs, args.Name,
+		func(p *scanner.PackageEntry) string { return p.Name },
+		d.packageCVEs,
+		func(p scanner.PackageEntry) float64 { return p.Score },
+		func(p *scanner.PackageEntry) *gqlPackage { return &gqlPackage{p} })
+}
+
+func (*gqlQuery) Bulletins(ctx context.Context, args struct {
+	ID *string
+	gqlListArgs
+}) ([]*gqlBulletin, error) {
+	return queryList(gqlDataFrom(ctx).results.Bulletins, args.gqlListArgs, args.ID,
+		func(b *scanner.BulletinEntry) string { return b.ID },
+		func(b *scanner.BulletinEntry) []string { return b.CVEs },
+		func(b scanner.BulletinEntry) float64 { return b.Score },
+		func(b *scanner.BulletinEntry) *gqlBulletin { return &gqlBulletin{b} })
+}
+
+func (*gqlQuery) Bulletin(ctx context.Context, args struct{ ID string }) *gqlBulletin {
+	if b, ok := gqlDataFrom(ctx).bulletins[args.ID]; ok {
+		return &gqlBulletin{b}
+	}
+	return nil
+}
+
+func (*gqlQuery) Stats(ctx context.Context) *gqlStats {
+	d := gqlDataFrom(ctx)
+	return &gqlStats{statsFor(d.results, d.loadedAt)}
+}
+
+// gqlHost resolves the Host type.
+type gqlHost struct{ h *scanner.HostEntry }
+
+func (r *gqlHost) ID() string            { return r.h.HostID }
+func (r *gqlHost) Host() string          { return r.h.Host }
+func (r *gqlHost) Name() string          { return r.h.Name }
+func (r *gqlHost) OS() string            { return r.h.OSName }
+func (r *gqlHost) OSVersion() string     { return r.h.OSVersion }
+func (r *gqlHost) Score() float64        { return r.h.Score }
+func (r *gqlHost) CumulativeFix() string { return r.h.CumulativeFix }
+func (r *gqlHost) CVEs() []string        { return hostCVEs(r.h) }
+
+func (r *gqlHost) Groups() []string {
+	groups := make([]string, len(r.h.Groups))
+	for i, g := range r.h.Groups {
+		groups[i] = g.Name
+	}
+	return groups
+}
+
+func (r *gqlHost) Packages(args gqlNestedArgs) []*gqlHostPackage {
+	return filterNested(r.h.Packages, args,
+		func(p *scanner.PackageVuln) []string { return p.CVEs },
+		func(p *scanner.PackageVuln) float64 { return p.Score },
+		func(p *scanner.PackageVuln) *gqlHostPackage { return &gqlHostPackage{p} })
+}
+
+func (r *gqlHost) Bulletins(args gqlNestedArgs) []*gqlHostBulletin {
+	return filterNested(r.h.Bulletins, args,
+		func(b *scanner.BulletinSummary) []string { return b.CVEs },
+		func(b *scanner.BulletinSummary) float64 { return b.Score },
+		func(b *scanner.BulletinSummary) *gqlHostBulletin { return &gqlHostBulletin{b} })
+}
+
+// gqlHostPackage resolves the HostPackage type.
+type gqlHostPackage struct{ p *scanner.PackageVuln }
+
+func (r *gqlHostPackage) Name() string    { return r.p.Name }
+func (r *gqlHostPackage) Version() string { return r.p.Version }
+func (r *gqlHostPackage) Arch() string    { return r.p.Arch }
+func (r *gqlHostPackage) Score() float64  { return r.p.Score }
+func (r *gqlHostPackage) Fix() string     { return r.p.Fix }
+func (r *gqlHostPackage) CVEs() []string  { return nonNil(r.p.CVEs) }
+
+func (r *gqlHostPackage) Bulletins(ctx context.Context) []*gqlBulletin {
+	return gqlDataFrom(ctx).bulletinResolvers(r.p.Bulletins)
+}
+
+// gqlHostBulletin resolves the HostBulletin type.
+type gqlHostBulletin struct{ b *scanner.BulletinSummary }
+
+func (r *gqlHostBulletin) ID() string         { return r.b.ID }
+func (r *gqlHostBulletin) Type() string       { return r.b.Type }
+func (r *gqlHostBulletin) Score() float64     { return r.b.Score }
+func (r *gqlHostBulletin) Fix() string        { return r.b.Fix }
+func (r *gqlHostBulletin) CVEs() []string     { return nonNil(r.b.CVEs) }
+func (r *gqlHostBulletin) Packages() []string { return nonNil(r.b.AffectedPkg) }
+
+// gqlPackage resolves the Package type.
+type gqlPackage struct{ p *scanner.PackageEntry }
+
+func (r *gqlPackage) Name() string        { return r.p.Name }
+func (r *gqlPackage) Version() string     { return r.p.Version }
+func (r *gqlPackage) Arch() string        { return r.p.Arch }
+func (r *gqlPackage) Score() float64      { return r.p.Score }
+func (r *gqlPackage) Fix() string         { return r.p.Fix }
+func (r *gqlPackage) HostNames() []string { return nonNil(r.p.AffectedHostNames) }
+
+func (r *gqlPackage) CVEs(ctx context.Context) []string {
+	return gqlDataFrom(ctx).packageCVEs(r.p)
+}
+
+func (r *gqlPackage) Bulletins(ctx context.Context) []*gqlBulletin {
+	return gqlDataFrom(ctx).bulletinResolvers(r.p.Bulletins)
+}
+
+func (r *gqlPackage) Hosts(ctx context.Context) []*gqlHost {
+	return gqlDataFrom(ctx).hostResolvers(r.p.AffectedHosts)
+}
+
+// gqlBulletin resolves the Bulletin type.
+type gqlBulletin struct{ b *scanner.BulletinEntry }
+
+func (r *gqlBulletin) ID() string          { return r.b.ID }
+func (r *gqlBulletin) Type() string        { return r.b.Type }
+func (r *gqlBulletin) Score() float64      { return r.b.Score }
+func (r *gqlBulletin) Fix() string         { return r.b.Fix }
+func (r *gqlBulletin) CVEs() []string      { return nonNil(r.b.CVEs) }
+func (r *gqlBulletin) Packages() []string  { return nonNil(r.b.AffectedPkgs) }
+func (r *gqlBulletin) HostNames() []string { return nonNil(r.b.AffectedHostNames) }
+
+func (r *gqlBulletin) Hosts(ctx context.Context) []*gqlHost {
+	return gqlDataFrom(ctx).hostResolvers(r.b.AffectedHosts)
+}
+
+// gqlStats resolves the Stats type.
+type gqlStats struct{ s Stats }
+
+func (r *gqlStats) Hosts() int32           { return int32(r.s.Hosts) }
+func (r *gqlStats) VulnerableHosts() int32 { return int32(r.s.VulnerableHosts) }
+func (r *gqlStats) Packages() int32        { return int32(r.s.Packages) }
+func (r *gqlStats) Bulletins() int32       { return int32(r.s.Bulletins) }
+func (r *gqlStats) MaxCvss() float64       { return r.s.MaxCVSS }
+func (r *gqlStats) AvgCvss() float64       { return r.s.AvgCVSS }
+func (r *gqlStats) LoadedAt() string       { return r.s.LoadedAt.Format(time.RFC3339) }
@@ -0,0 +1,139 @@
+//go:build graphql
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+func graphQLResults() *scanner.ScanResults {
+	return &scanner.ScanResults{
+		HostsWithVulns: 2,
+		MaxCVSS:        9.8,
+		Hosts: []scanner.HostEntry{
+			{HostID: "10", Name: "web-01", Score: 9.8, CumulativeFix: "apt-get install --only-upgrade openssl",
+				Packages:  []scanner.PackageVuln{{Name: "openssl", Score: 9.8, CVEs: []string{"CVE-1"}, Bulletins: []string{"USN-1"}}},
+				Bulletins: []scanner.BulletinSummary{{ID: "USN-1", Score: 9.8, CVEs: []string{"CVE-1"}}}},
+			{HostID: "20", Name: "web-02", Score: 5.0, CumulativeFix: "yum update nginx",
+				Packages: []scanner.PackageVuln{{Name: "nginx", Score: 5.0, CVEs: []string{"CVE-2"}, Bulletins: []string{"RHSA-2"}}}},
+		},
+		Packages: []scanner.PackageEntry{
+			{Name: "openssl", Score: 9.8, AffectedHosts: []string{"10"}, Bulletins: []string{"USN-1"}},
+			{Name: "nginx", Score: 5.0, AffectedHosts: []string{"20"}, Bulletins: []string{"RHSA-2"}},
+		},
+		Bulletins: []scanner.BulletinEntry{
+			{ID: "USN-1", Score: 9.8, CVEs: []string{"CVE-1"}, AffectedHosts: []string{"10"}},
+			{ID: "RHSA-2", Score: 5.0, CVEs: []string{"CVE-2"}, AffectedHosts: []string{"20", "99"}},
+		},
+	}
+}
+
+func postGraphQL(t *testing.T, query string, vars map[string]any) (int, string) {
+	t.Helper()
+	ts := newTestServer(t, func(context.Context) (*scanner.ScanResults, error) { return graphQLResults(), nil })
+	body, err := json.Marshal(gqlRequestBody{Query: query, Variables: vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(ts.URL+"/graphql", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, strings.TrimSpace(buf.String())
+}
+
+func TestGraphQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]any
+		want  string
+	}{
+		{
+			name:  "hosts affected by a CVE with their fix",
+			query: `query Affected($cve: String) { hosts(cve: $cve) { name cumulativeFix } }`,
+			vars:  map[string]any{"cve": "CVE-2"},
+			want:  `{"data":{"hosts":[{"name":"web-02","cumulativeFix":"yum update nginx"}]}}`,
+		},
+		{
+			name:  "nested packages, bulletins and aliases",
+			query: `{ host(id: "10") { id pkgs: packages(minCvss: 9) { name bulletins { id cves } } } }`,
+			want:  `{"data":{"host":{"id":"10","pkgs":[{"name":"openssl","bulletins":[{"id":"USN-1","cves":["CVE-1"]}]}]}}}`,
+		},
+		{
+			name:  "package CVEs come from its bulletins",
+			query: `{ packages(cve: "CVE-1") { name cves hosts { name } } }`,
+			want:  `{"data":{"packages":[{"name":"openssl","cves":["CVE-1"],"hosts":[{"name":"web-01"}]}]}}`,
+		},
+		{
+			name:  "unknown hosts are skipped and pagination applies",
+			query: `{ bulletins(limit: 1, offset: 1) { id hosts { id } } }`,
+			want:  `{"data":{"bulletins":[{"id":"RHSA-2","hosts":[{"id":"20"}]}]}}`,
+		},
+		{
+			name:  "missing object is null",
+			query: `{ bulletin(id: "nope") { id } stats { hosts maxCvss __typename } }`,
+			want:  `{"data":{"bulletin":null,"stats":{"hosts":2,"maxCvss":9.8,"__typename":"Stats"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, got := postGraphQL(t, tt.query, tt.vars)
+			if status != http.StatusOK || got != tt.want {
+				t.Errorf("status %d, got:\n%s\nwant:\n%s", status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphQL_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantErr    string
+	}{
+		{"syntax error", `{ hosts { name }`, http.StatusBadRequest, "syntax error"},
+		{"mutation", `mutation { fix }`, http.StatusBadRequest, "no mutations are offered"},
+		{"unknown field", `{ hosts { password } }`, http.StatusBadRequest, `Cannot query field \"password\" on type \"Host\"`},
+		{"unknown argument", `{ hosts(sort: "score") { id } }`, http.StatusBadRequest, `Unknown argument \"sort\"`},
+		{"object without selection", `{ hosts }`, http.StatusBadRequest, "must have a selection"},
+		{"scalar with selection", `{ stats { hosts { id } } }`, http.StatusBadRequest, "has no subfields"},
+		{"limit out of range", `{ hosts(limit: 5000) { id } }`, http.StatusOK, "limit must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, got := postGraphQL(t, tt.query, nil)
+			if status != tt.wantStatus || !strings.Contains(got, tt.wantErr) || !strings.Contains(got, `"errors"`) {
+				t.Errorf("status %d, body %s; want %d with %q", status, got, tt.wantStatus, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGraphQL_Get(t *testing.T) {
+	ts := newTestServer(t, func(context.Context) (*scanner.ScanResults, error) { return graphQLResults(), nil })
+
+	q := url.Values{"query": {`query($id: String!){ host(id: $id) { name } }`}, "variables": {`{"id":"20"}`}}
+	var resp struct {
+		Data struct {
+			Host struct{ Name string }
+		}
+	}
+	if status := get(t, ts, "/graphql?"+q.Encode(), &resp); status != http.StatusOK || resp.Data.Host.Name != "web-02" {
+		t.Errorf("status %d, response %+v", status, resp)
+	}
+}
@@ -0,0 +1,230 @@
+// Package server exposes the latest scan results over a read-only
+// REST/JSON API and, while scans run in-process, their progress over a
+// WebSocket.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+// Pagination limits for list endpoints.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Source loads the scan results the API serves.
+type Source func(ctx context.Context) (*scanner.ScanResults, error)
+
+// extraRoutes registers the endpoints of optional features compiled in with
+// build tags.
+var extraRoutes []func(s *Server, mux *http.ServeMux)
+
+// Server serves scan results from a Source, reloading them at most once per
+// refresh interval.
+type Server struct {
+	source  Source
+	refresh time.Duration
+	log     *slog.Logger
+	events  *EventHub // set by StreamEvents
+
+	mu       sync.Mutex
+	results  *scanner.ScanResults
+	loadedAt time.Time
+}
+
+// New creates a server. A zero refresh reloads the results on every
+// request.
+func New(source Source, refresh time.Duration, log *slog.Logger) *Server {
+	return &Server{source: source, refresh: refresh, log: log}
+}
+
+// Handler returns the HTTP handler with all API routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /hosts", s.handleHosts)
+	mux.HandleFunc("GET /packages", s.handlePackages)
+	mux.HandleFunc("GET /bulletins", s.handleBulletins)
+	mux.HandleFunc("GET /stats", s.handleStats)
+	if s.events != nil {
+		mux.Handle("GET /scan/events", s.eventsHandler())
+	}
+	for _, register := range extraRoutes {
+		register(s, mux)
+	}
+	return mux
+}
+
+// Results returns the cached results, loading them when the cache is
+// older than the refresh interval. A failed reload keeps serving the
+// previous results.
+func (s *Server) Results(ctx context.Context) (*scanner.ScanResults, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.results != nil && time.Since(s.loadedAt) < s.refresh {
+		return s.results, s.loadedAt, nil
+	}
+	results, err := s.source(ctx)
+	if err != nil {
+		if s.results != nil {
+			s.log.Warn("Failed to reload scan results, serving cached ones", slog.Any("error", err))
+			return s.results, s.loadedAt, nil
+		}
+		return nil, time.Time{}, err
+	}
+	s.results, s.loadedAt = results, time.Now()
+	return s.results, s.loadedAt, nil
+}
+
+// Page is one page of a list endpoint.
+type Page[T any] struct {
+	Total  int `json:"total"` // matching items across all pages
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Items  []T `json:"items"`
+}
+
+// Stats summarizes the served results.
+type Stats struct {
+	Hosts           int       `json:"hosts"`
+	VulnerableHosts int       `json:"vulnerable_hosts"`
+	Packages        int       `json:"packages"`
+	Bulletins       int       `json:"bulletins"`
+	MaxCVSS         float64   `json:"max_cvss"`
+	AvgCVSS         float64   `json:"avg_cvss"`
+	LoadedAt        time.Time `json:"loaded_at"`
+}
+
+// listQuery holds the pagination and filter parameters of a list request.
+type listQuery struct {
+	offset, limit int
+	minCVSS       float64
+}
+
+func parseListQuery(r *http.Request) (listQuery, error) {
+	q := listQuery{limit: DefaultLimit}
+	values := r.URL.Query()
+	if v := values.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("offset must be a non-negative integer, got %q", v)
+		}
+		q.offset = n
+	}
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxLimit {
+			return q, fmt.Errorf("limit must be an integer between 1 and %d, got %q", MaxLimit, v)
+		}
+		q.limit = n
+	}
+	if v := values.Get("min_cvss"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 10 {
+			return q, fmt.Errorf("min_cvss must be a number between 0 and 10, got %q", v)
+		}
+		q.minCVSS = f
+	}
+	return q, nil
+}
+
+// paginate filters items by score and returns the requested page.
+func paginate[T any](items []T, q listQuery, score func(T) float64) Page[T] {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		if score(item) >= q.minCVSS {
+			matched = append(matched, item)
+		}
+	}
+	start := min(q.offset, len(matched))
+	end := min(start+q.limit, len(matched))
+	return Page[T]{Total: len(matched), Offset: q.offset, Limit: q.limit, Items: matched[start:end]}
+}
+
+// serveList writes a page of the items list picks from the results.
+func serveList[T any](s *Server, w http.ResponseWriter, r *http.Request, list func(*scanner.ScanResults) []T, score func(T) float64) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	results, _, err := s.Results(r.Context())
+	if err != nil {
+		s.unavailable(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, paginate(list(results), q, score))
+}
+
+func (s *Server) handleHosts(w http.ResponseWriter, r *http.Request) {
+	serveList(s, w, r,
+		func(res *scanner.ScanResults) []scanner.HostEntry { return res.Hosts },
+		func(h scanner.HostEntry) float64 { return h.Score })
+}
+
+func (s *Server) handlePackages(w http.ResponseWriter, r *http.Request) {
+	serveList(s, w, r,
+		func(res *scanner.ScanResults) []scanner.PackageEntry { return res.Packages },
+		func(p scanner.PackageEntry) float64 { return p.Score })
+}
+
+func (s *Server) handleBulletins(w http.ResponseWriter, r *http.Request) {
+	serveList(s, w, r,
+		func(res *scanner.ScanResults) []scanner.BulletinEntry { return res.Bulletins },
+		func(b scanner.BulletinEntry) float64 { return b.Score })
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	results, loadedAt, err := s.Results(r.Context())
+	if err != nil {
+		s.unavailable(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, statsFor(results, loadedAt))
+}
+
+// statsFor summarizes results loaded at loadedAt.
+func statsFor(results *scanner.ScanResults, loadedAt time.Time) Stats {
+	stats := Stats{
+		Hosts:           len(results.Hosts),
+		VulnerableHosts: results.HostsWithVulns,
+		Packages:        len(results.Packages),
+		Bulletins:       len(results.Bulletins),
+		MaxCVSS:         results.MaxCVSS,
+		LoadedAt:        loadedAt.UTC(),
+	}
+	if len(results.Hosts) > 0 {
+		var total float64
+		for _, h := range results.Hosts {
+			total += h.Score
+		}
+		stats.AvgCVSS = total / float64(len(results.Hosts))
+	}
+	return stats
+}
+
+func (s *Server) unavailable(w http.ResponseWriter, err error) {
+	s.log.Error("Failed to load scan results", slog.Any("error", err))
+	writeError(w, http.StatusServiceUnavailable, fmt.Errorf("scan results unavailable: %w", err))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+)
+
+func testResults() *scanner.ScanResults {
+	return &scanner.ScanResults{
+		HostsWithVulns: 2,
+		MaxCVSS:        9.8,
+		Hosts: []scanner.HostEntry{
+			{HostID: "10", Name: "web-01", Score: 9.8},
+			{HostID: "20", Name: "web-02", Score: 5.0},
+			{HostID: "30", Name: "db-01", Score: 0},
+		},
+		Packages: []scanner.PackageEntry{
+			{Name: "openssl", Score: 9.8},
+			{Name: "nginx", Score: 5.0},
+		},
+		Bulletins: []scanner.BulletinEntry{{ID: "USN-1", Score: 9.8}},
+	}
+}
+
+func newTestServer(t *testing.T, source Source) *httptest.Server {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ts := httptest.NewServer(New(source, 0, log).Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, ts *httptest.Server, path string, out any) int {
+	t.Helper()
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	return resp.StatusCode
+}
+
+func TestServer_Lists(t *testing.T) {
+	ts := newTestServer(t, func(context.Context) (*scanner.ScanResults, error) { return testResults(), nil })
+
+	tests := []struct {
+		path      string
+		wantTotal int
+		wantIDs   []string
+	}{
+		{"/hosts", 3, []string{"10", "20", "30"}},
+		{"/hosts?min_cvss=5", 2, []string{"10", "20"}},
+		{"/hosts?limit=1&offset=1", 3, []string{"20"}},
+		{"/hosts?offset=5", 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			var page Page[scanner.HostEntry]
+			if status := get(t, ts, tt.path, &page); status != http.StatusOK {
+				t.Fatalf("status = %d", status)
+			}
+			var ids []string
+			for _, h := range page.Items {
+				ids = append(ids, h.HostID)
+			}
+			if page.Total != tt.wantTotal || len(ids) != len(tt.wantIDs) {
+				t.Fatalf("total = %d, ids = %v, want %d, %v", page.Total, ids, tt.wantTotal, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+
+	var pkgs Page[scanner.PackageEntry]
+	get(t, ts, "/packages?min_cvss=9", &pkgs)
+	if pkgs.Total != 1 || pkgs.Items[0].Name != "openssl" {
+		t.Errorf("packages = %+v, want only openssl", pkgs)
+	}
+
+	var bulletins Page[scanner.BulletinEntry]
+	get(t, ts, "/bulletins", &bulletins)
+	if bulletins.Total != 1 || bulletins.Limit != DefaultLimit {
+		t.Errorf("bulletins = %+v", bulletins)
+	}
+}
+
+func TestServer_BadQuery(t *testing.T) {
+	ts := newTestServer(t, func(context.Context) (*scanner.ScanResults, error) { return testResults(), nil })
+
+	for _, path := range []string{"/hosts?limit=0", "/hosts?limit=5000", "/hosts?offset=-1", "/packages?min_cvss=x", "/bulletins?min_cvss=11"} {
+		var body map[string]string
+		if status := get(t, ts, path, &body); status != http.StatusBadRequest || body["error"] == "" {
+			t.Errorf("%s: status %d, body %v, want 400 with an error", path, status, body)
+		}
+	}
+}
+
+func TestServer_Stats(t *testing.T) {
+	ts := newTestServer(t, func(context.Context) (*scanner.ScanResults, error) { return testResults(), nil })
+
+	var stats Stats
+	get(t, ts, "/stats", &stats)
+	if stats.Hosts != 3 || stats.VulnerableHosts != 2 || stats.Packages != 2 || stats.Bulletins != 1 ||
+		stats.MaxCVSS != 9.8 || stats.AvgCVSS < 4.93 || stats.AvgCVSS > 4.94 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.LoadedAt.IsZero() {
+		t.Error("loaded_at not set")
+	}
+}
+
+func TestServer_SourceErrors(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	ts := newTestServer(t, func(context.Context) (*scanner.ScanResults, error) {
+		if fail.Load() {
+			return nil, errors.New("zabbix down")
+		}
+		return testResults(), nil
+	})
+
+	var body map[string]string
+	if status := get(t, ts, "/stats", &body); status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 before any results loaded", status)
+	}
+
+	fail.Store(false)
+	var stats Stats
+	get(t, ts, "/stats", &stats)
+
+	// A failed reload keeps serving the last results.
+	fail.Store(true)
+	if status := get(t, ts, "/stats", &stats); status != http.StatusOK || stats.Hosts != 3 {
+		t.Errorf("status = %d, stats = %+v, want the cached results", status, stats)
+	}
+}
+
+type fakeItems map[string]string // item key → value
+
+func (f fakeItems) GetItemValueCtx(_ context.Context, _, key string) (string, error) {
+	return f[key], nil
+}
+
+func TestZabbixSource(t *testing.T) {
+	naming := config.DefaultConfig().Naming
+	gen := scanner.NewLLDGenerator(naming)
+	lld := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	res := testResults()
+	source := ZabbixSource(fakeItems{
+		"vulners.hosts_lld":    lld(gen.GenerateHostsLLD(res.Hosts)),
+		"vulners.packages_lld": lld(gen.GeneratePackagesLLD(res.Packages)),
+		// No bulletins LLD was pushed.
+	}, naming)
+
+	got, err := source(context.Background())
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+	if len(got.Hosts) != 3 || len(got.Packages) != 2 || len(got.Bulletins) != 0 || got.HostsWithVulns != 2 {
+		t.Errorf("results = %+v", got)
+	}
+
+	bad := ZabbixSource(fakeItems{"vulners.hosts_lld": "{"}, naming)
+	if _, err := bad(context.Background()); err == nil {
+		t.Error("expected error for malformed LLD")
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/scanner"
+	"github.com/kidoz/zabbix-threat-control-go/internal/zabbix"
+)
+
+// ItemReader is the part of the Zabbix API client ZabbixSource uses.
+type ItemReader interface {
+	GetItemValueCtx(ctx context.Context, hostTechName, itemKey string) (string, error)
+}
+
+// ZabbixSource reads the results of the last pushed scan back from the LLD
+// items of the virtual hosts. See scanner.ResultsFromLLD for what the LLD
+// does not carry.
+func ZabbixSource(client ItemReader, naming config.NamingConfig) Source {
+	return func(ctx context.Context) (*scanner.ScanResults, error) {
+		hosts, err := readLLD(ctx, client, naming.HostsHost, "vulners.hosts_lld")
+		if err != nil {
+			return nil, err
+		}
+		packages, err := readLLD(ctx, client, naming.PackagesHost, "vulners.packages_lld")
+		if err != nil {
+			return nil, err
+		}
+		bulletins, err := readLLD(ctx, client, naming.BulletinsHost, "vulners.bulletins_lld")
+		if err != nil {
+			return nil, err
+		}
+		return scanner.ResultsFromLLD(hosts, packages, bulletins), nil
+	}
+}
+
+// readLLD returns the LLD last pushed to an item, or nil if there is none.
+func readLLD(ctx context.Context, client ItemReader, host, key string) (*zabbix.LLDData, error) {
+	value, err := client.GetItemValueCtx(ctx, host, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	if value == "" {
+		return nil, nil
+	}
+	data, err := zabbix.DecodeLLD(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// FileSource reads a scan export written by 'ztc scan --export'. The file
+// is re-read on every load, so a newer export is picked up.
+func FileSource(path string) Source {
+	return func(context.Context) (*scanner.ScanResults, error) {
+		exp, err := scanner.ReadJSONFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return exp.ScanResults, nil
+	}
+}
@@ -3,8 +3,13 @@ package telemetry
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -14,6 +19,7 @@ import (
 	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/version"
 )
 
 const tracerName = "zabbix-threat-control-go"
@@ -27,22 +33,15 @@ func Init(ctx context.Context, cfg *config.TelemetryConfig, verbose bool) (shutd
 		return func(context.Context) error { return nil }, nil
 	}
 
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName(tracerName),
-		),
-	)
+	res, err := newResource(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTel resource: %w", err)
 	}
 
 	var exporter sdktrace.SpanExporter
 
-	if cfg.OTLPEndpoint != "" {
-		exporter, err = otlptracehttp.New(ctx,
-			otlptracehttp.WithEndpoint(cfg.OTLPEndpoint),
-			otlptracehttp.WithInsecure(),
-		)
+	if otlpConfigured(cfg) {
+		exporter, err = newOTLPExporter(ctx, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
 		}
@@ -60,12 +59,112 @@ func Init(ctx context.Context, cfg *config.TelemetryConfig, verbose bool) (shutd
 	tp := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler(cfg)),
 	)
 	otel.SetTracerProvider(tp)
 
 	return tp.Shutdown, nil
 }
 
+// newResource describes this instance: service name and build version, host
+// name, the configured deployment environment and OTEL_RESOURCE_ATTRIBUTES.
+func newResource(ctx context.Context, cfg *config.TelemetryConfig) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(tracerName),
+		semconv.ServiceVersion(version.Version),
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(cfg.Environment))
+	}
+	return resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithHost(),
+		resource.WithAttributes(attrs...),
+	)
+}
+
+// sampler samples cfg.SampleRatio of new traces and follows the parent's
+// decision for the rest.
+func sampler(cfg *config.TelemetryConfig) sdktrace.Sampler {
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+}
+
+// otlpConfigured reports whether a collector is set in cfg or through the
+// standard OTEL_EXPORTER_OTLP_* endpoint variables.
+func otlpConfigured(cfg *config.TelemetryConfig) bool {
+	return cfg.OTLPEndpoint != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != ""
+}
+
+// otlpProtocol returns the exporter protocol: cfg.OTLPProtocol, else the
+// standard OTEL_EXPORTER_OTLP_*PROTOCOL variables, else HTTP.
+func otlpProtocol(cfg *config.TelemetryConfig) (string, error) {
+	if cfg.OTLPProtocol != "" {
+		return cfg.OTLPProtocol, nil
+	}
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"} {
+		switch v := os.Getenv(env); {
+		case v == "":
+			continue
+		case v == "grpc":
+			return config.OTLPProtocolGRPC, nil
+		case v == "http/protobuf":
+			return config.OTLPProtocolHTTP, nil
+		default:
+			return "", fmt.Errorf("%s=%q is not supported (use grpc or http/protobuf)", env, v)
+		}
+	}
+	return config.OTLPProtocolHTTP, nil
+}
+
+// newOTLPExporter creates the OTLP exporter for cfg. Settings missing from
+// cfg, such as headers or a custom CA, come from the OTEL_EXPORTER_OTLP_*
+// variables, which the exporters read themselves.
+func newOTLPExporter(ctx context.Context, cfg *config.TelemetryConfig) (sdktrace.SpanExporter, error) {
+	protocol, err := otlpProtocol(cfg)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := cfg.OTLPEndpoint
+	isURL := strings.Contains(endpoint, "://")
+
+	if protocol == config.OTLPProtocolGRPC {
+		var opts []otlptracegrpc.Option
+		switch {
+		case isURL:
+			opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+		case endpoint != "":
+			opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+			if cfg.OTLPInsecure {
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			}
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	}
+
+	var opts []otlptracehttp.Option
+	switch {
+	case isURL:
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid telemetry.otlp_endpoint: %w", err)
+		}
+		// Like OTEL_EXPORTER_OTLP_ENDPOINT, a bare collector URL gets the
+		// traces path appended.
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/v1/traces"
+		}
+		opts = append(opts, otlptracehttp.WithEndpointURL(u.String()))
+	case endpoint != "":
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+	}
+	return otlptracehttp.New(ctx, opts...)
+}
+
 // Tracer returns the application tracer.
 func Tracer() trace.Tracer {
 	return otel.Tracer(tracerName)
@@ -2,12 +2,18 @@ package telemetry
 
 import (
 	"context"
+	"os"
 	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/version"
 )
 
 func TestInit_Disabled(t *testing.T) {
@@ -71,3 +77,137 @@ func TestTracer_ReturnsTracer(t *testing.T) {
 		t.Fatal("Tracer() returned nil")
 	}
 }
+
+func TestOTLPProtocol(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		env        map[string]string
+		want       string
+		wantErr    bool
+	}{
+		{name: "default", want: config.OTLPProtocolHTTP},
+		{name: "config", configured: "grpc", env: map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf"}, want: config.OTLPProtocolGRPC},
+		{name: "env", env: map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"}, want: config.OTLPProtocolGRPC},
+		{name: "traces env wins", env: map[string]string{
+			"OTEL_EXPORTER_OTLP_PROTOCOL":        "grpc",
+			"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL": "http/protobuf",
+		}, want: config.OTLPProtocolHTTP},
+		{name: "unsupported env", env: map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "http/json"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			got, err := otlpProtocol(&config.TelemetryConfig{OTLPProtocol: tt.configured})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("protocol = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInit_OTLP(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TelemetryConfig
+		env  string // OTEL_EXPORTER_OTLP_ENDPOINT
+	}{
+		{"http host:port", config.TelemetryConfig{OTLPEndpoint: "localhost:4318", OTLPInsecure: true}, ""},
+		{"http URL", config.TelemetryConfig{OTLPEndpoint: "https://otel.example.com:4318"}, ""},
+		{"grpc host:port", config.TelemetryConfig{OTLPEndpoint: "localhost:4317", OTLPProtocol: "grpc"}, ""},
+		{"endpoint from env", config.TelemetryConfig{OTLPProtocol: "grpc"}, "http://localhost:4317"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", tt.env)
+			cfg := tt.cfg
+			cfg.Enabled = true
+
+			// Exporters connect lazily, so no collector is needed.
+			shutdown, err := Init(context.Background(), &cfg, false)
+			if err != nil {
+				t.Fatalf("Init: %v", err)
+			}
+			if _, ok := otel.GetTracerProvider().(noop.TracerProvider); ok {
+				t.Error("expected an OTLP TracerProvider, got noop")
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			_ = shutdown(ctx)
+		})
+	}
+}
+
+func TestSampler(t *testing.T) {
+	tests := []struct {
+		ratio float64
+		want  sdktrace.SamplingDecision
+	}{
+		{1, sdktrace.RecordAndSample},
+		{0, sdktrace.Drop},
+	}
+	for _, tt := range tests {
+		s := sampler(&config.TelemetryConfig{SampleRatio: tt.ratio})
+		res := s.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: context.Background(),
+			TraceID:       trace.TraceID{0x01},
+			Name:          "Scanner.Scan",
+		})
+		if res.Decision != tt.want {
+			t.Errorf("ratio %g: decision = %v, want %v", tt.ratio, res.Decision, tt.want)
+		}
+	}
+
+	// A sampled parent keeps its children even at ratio 0.
+	parent := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	res := sampler(&config.TelemetryConfig{}).ShouldSample(sdktrace.SamplingParameters{
+		ParentContext: parent,
+		TraceID:       trace.TraceID{0x01},
+		Name:          "Scanner.scanHost",
+	})
+	if res.Decision != sdktrace.RecordAndSample {
+		t.Errorf("child of a sampled span: decision = %v, want RecordAndSample", res.Decision)
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	res, err := newResource(context.Background(), &config.TelemetryConfig{Environment: "staging"})
+	if err != nil {
+		t.Fatalf("newResource: %v", err)
+	}
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range res.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	hostname, _ := os.Hostname()
+	want := map[attribute.Key]string{
+		"service.name":           "zabbix-threat-control-go",
+		"service.version":        version.Version,
+		"host.name":              hostname,
+		"deployment.environment": "staging",
+	}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Errorf("%s = %q, want %q", k, attrs[k], v)
+		}
+	}
+
+	res, err = newResource(context.Background(), &config.TelemetryConfig{})
+	if err != nil {
+		t.Fatalf("newResource: %v", err)
+	}
+	if _, ok := res.Set().Value("deployment.environment"); ok {
+		t.Error("deployment.environment set without telemetry.environment")
+	}
+}
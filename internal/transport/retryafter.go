@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// retryAfter pauses all requests sharing it after the server answers 429 or
+// 503 with a Retry-After header, so concurrent workers back off together
+// instead of each running into the quota.
+type retryAfter struct {
+	next    http.RoundTripper
+	log     *slog.Logger
+	maxWait time.Duration
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+// WithRetryAfter wraps next with a shared pause honoring Retry-After. Waits
+// are capped at maxWait. The response is passed through unchanged, so any
+// retry policy of the caller still applies.
+func WithRetryAfter(next http.RoundTripper, maxWait time.Duration, log *slog.Logger) http.RoundTripper {
+	return &retryAfter{next: next, log: log, maxWait: maxWait}
+}
+
+func (t *retryAfter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.pause(); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if d > t.maxWait {
+				d = t.maxWait
+			}
+			t.extend(d)
+			t.log.Warn("Rate limited by server, pausing requests",
+				slog.String("host", req.URL.Host),
+				slog.Int("status", resp.StatusCode),
+				slog.Duration("retry_after", d),
+			)
+		}
+	}
+
+	return resp, nil
+}
+
+// pause returns how long to wait before the next request may be sent.
+func (t *retryAfter) pause() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Until(t.until)
+}
+
+func (t *retryAfter) extend(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// parseRetryAfter accepts both forms allowed by RFC 9110: delay-seconds and
+// an HTTP-date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := t.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
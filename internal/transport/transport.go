@@ -0,0 +1,27 @@
+// Package transport provides http.RoundTripper wrappers shared by the
+// Zabbix and Vulners clients.
+package transport
+
+import "net/http"
+
+// userAgent sets the User-Agent header on every request.
+type userAgent struct {
+	next http.RoundTripper
+	ua   string
+}
+
+// WithUserAgent wraps next so that every request carries the given
+// User-Agent. An empty ua returns next unchanged.
+func WithUserAgent(next http.RoundTripper, ua string) http.RoundTripper {
+	if ua == "" {
+		return next
+	}
+	return &userAgent{next: next, ua: ua}
+}
+
+func (t *userAgent) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.ua)
+	return t.next.RoundTrip(req)
+}
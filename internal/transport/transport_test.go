@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: WithUserAgent(http.DefaultTransport, "ztc-test/1.0")}
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if got != "ztc-test/1.0" {
+		t.Errorf("User-Agent = %q, want ztc-test/1.0", got)
+	}
+	if req.Header.Get("User-Agent") != "" {
+		t.Error("original request was modified")
+	}
+}
+
+func TestWithUserAgent_Empty(t *testing.T) {
+	if rt := WithUserAgent(http.DefaultTransport, ""); rt != http.DefaultTransport {
+		t.Error("expected empty User-Agent to return the wrapped transport unchanged")
+	}
+}
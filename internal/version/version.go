@@ -0,0 +1,13 @@
+// Package version holds build metadata injected via -ldflags.
+package version
+
+var (
+	Version   = "dev"
+	BuildTime = "unknown"
+	GitCommit = "unknown"
+)
+
+// UserAgent returns the default User-Agent for outgoing HTTP requests.
+func UserAgent() string {
+	return "zabbix-threat-control-go/" + Version
+}
@@ -18,6 +18,7 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+	"github.com/kidoz/zabbix-threat-control-go/internal/transport"
 )
 
 // Client is a Zabbix API client
@@ -27,12 +28,18 @@ type Client struct {
 	httpClient *http.Client
 	authToken  string
 	apiVersion string
-	requestID  int64
+	// authViaToken is set when authToken is the configured API token rather
+	// than a user.login session, which must not be logged out.
+	authViaToken bool
+	requestID    int64
+
+	// slots bounds in-flight API calls; nil means unlimited.
+	slots chan struct{}
 }
 
 // NewClient creates a new Zabbix API client
 func NewClient(cfg *config.Config, log *slog.Logger) (*Client, error) {
-	transport := &http.Transport{
+	tr := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: !cfg.Zabbix.VerifySSL, //nolint:gosec // G402: user-configurable option, defaults to VerifySSL=true
 		},
@@ -42,10 +49,13 @@ func NewClient(cfg *config.Config, log *slog.Logger) (*Client, error) {
 		cfg: cfg,
 		log: log,
 		httpClient: &http.Client{
-			Timeout:   time.Duration(cfg.Scan.Timeout) * time.Second,
-			Transport: otelhttp.NewTransport(transport),
+			Timeout:   time.Duration(cfg.Zabbix.APITimeout) * time.Second,
+			Transport: otelhttp.NewTransport(transport.WithUserAgent(tr, cfg.UserAgent())),
 		},
 	}
+	if n := cfg.Zabbix.MaxConcurrentRequests; n > 0 {
+		c.slots = make(chan struct{}, n)
+	}
 
 	// Fetch API version before auth (apiinfo.version does not require auth)
 	ver, err := c.GetAPIVersion()
@@ -63,11 +73,18 @@ func NewClient(cfg *config.Config, log *slog.Logger) (*Client, error) {
 	return c, nil
 }
 
-// authenticate logs in to the Zabbix API
+// authenticate logs in to the Zabbix API, or uses the configured API token
 func (c *Client) authenticate() error {
+	if c.cfg.Zabbix.APIToken != "" {
+		c.authToken = c.cfg.Zabbix.APIToken
+		c.authViaToken = true
+		c.log.Debug("Using Zabbix API token")
+		return nil
+	}
+
 	params := map[string]string{
-		"user":     c.cfg.Zabbix.APIUser,
-		"password": c.cfg.Zabbix.APIPassword,
+		c.loginField(): c.cfg.Zabbix.APIUser,
+		"password":     c.cfg.Zabbix.APIPassword,
 	}
 
 	result, err := c.call("user.login", params)
@@ -85,11 +102,28 @@ func (c *Client) authenticate() error {
 	return nil
 }
 
+// loginField returns the user.login parameter carrying the user name:
+// "username" since Zabbix 6.0, "user" before.
+func (c *Client) loginField() string {
+	if c.getAPIVersionFloat() >= 6.0 {
+		return "username"
+	}
+	return "user"
+}
+
 // call makes a JSON-RPC call to the Zabbix API
 func (c *Client) call(method string, params interface{}) (interface{}, error) {
 	return c.callWithContext(context.Background(), method, params)
 }
 
+// authlessMethods are the API methods Zabbix rejects when the request
+// carries the auth parameter.
+var authlessMethods = map[string]bool{
+	"apiinfo.version":          true,
+	"user.login":               true,
+	"user.checkAuthentication": true,
+}
+
 // callWithContext makes a JSON-RPC call with context
 func (c *Client) callWithContext(ctx context.Context, method string, params interface{}) (interface{}, error) {
 	reqID := atomic.AddInt64(&c.requestID, 1)
@@ -101,8 +135,8 @@ func (c *Client) callWithContext(ctx context.Context, method string, params inte
 		"id":      reqID,
 	}
 
-	// Add auth token if we have one (except for login)
-	if c.authToken != "" && method != "user.login" {
+	// Add auth token if we have one (except for authless methods)
+	if c.authToken != "" && !authlessMethods[method] {
 		reqBody["auth"] = c.authToken
 	}
 
@@ -117,7 +151,16 @@ func (c *Client) callWithContext(ctx context.Context, method string, params inte
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json-rpc")
+	req.Header.Set("Content-Type", c.cfg.Zabbix.ContentType)
+
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+			defer func() { <-c.slots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -125,6 +168,10 @@ func (c *Client) callWithContext(ctx context.Context, method string, params inte
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if resp.StatusCode == http.StatusUnsupportedMediaType {
+		return nil, fmt.Errorf("server rejected Content-Type %q (HTTP 415); try setting zabbix.content_type to application/json", c.cfg.Zabbix.ContentType)
+	}
+
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
@@ -169,6 +216,16 @@ func (c *Client) getAPIVersionFloat() float64 {
 // GetItemValueCtx retrieves the last value of a specific item by host technical
 // name and item key. Returns an empty string if the item doesn't exist.
 func (c *Client) GetItemValueCtx(ctx context.Context, hostTechName, itemKey string) (string, error) {
+	item, err := c.GetItemCtx(ctx, hostTechName, itemKey)
+	if err != nil || item == nil {
+		return "", err
+	}
+	return item.Value, nil
+}
+
+// GetItemCtx retrieves a specific item, including its last value and clock,
+// by host technical name and item key. Returns nil if the item doesn't exist.
+func (c *Client) GetItemCtx(ctx context.Context, hostTechName, itemKey string) (*Item, error) {
 	// Resolve host to hostid
 	hostParams := map[string]interface{}{
 		"output": []string{"hostid"},
@@ -178,33 +235,37 @@ func (c *Client) GetItemValueCtx(ctx context.Context, hostTechName, itemKey stri
 	}
 	hostResult, err := c.callWithContext(ctx, "host.get", hostParams)
 	if err != nil {
-		return "", fmt.Errorf("failed to get host %q: %w", hostTechName, err)
+		return nil, fmt.Errorf("failed to get host %q: %w", hostTechName, err)
 	}
 	hosts, err := parseHosts(hostResult)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(hosts) == 0 {
-		return "", fmt.Errorf("host not found: %s", hostTechName)
+		return nil, fmt.Errorf("host not found: %s", hostTechName)
 	}
 
 	items, err := c.GetHostItemsCtx(ctx, hosts[0].HostID, itemKey)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	for _, item := range items {
-		if item.Key == itemKey {
-			return item.Value, nil
+	for i := range items {
+		if items[i].Key == itemKey {
+			return &items[i], nil
 		}
 	}
-	return "", nil
+	return nil, nil
 }
 
-// Close logs out from the Zabbix API
+// Close logs out from the Zabbix API. API tokens are left valid.
 func (c *Client) Close() error {
 	if c.authToken == "" {
 		return nil
 	}
+	if c.authViaToken {
+		c.authToken = ""
+		return nil
+	}
 
 	_, err := c.call("user.logout", []string{})
 	c.authToken = ""
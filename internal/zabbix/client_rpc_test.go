@@ -3,9 +3,15 @@ package zabbix
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"io"
 	"log/slog"
@@ -22,12 +28,20 @@ func newTestServer(t *testing.T, handler func(method string, params json.RawMess
 		var req struct {
 			Method string          `json:"method"`
 			Params json.RawMessage `json:"params"`
+			Auth   *string         `json:"auth"`
 			ID     int             `json:"id"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			t.Fatalf("decode request: %v", err)
 		}
-		result, apiErr := handler(req.Method, req.Params)
+		var result interface{}
+		var apiErr *APIError
+		if req.Auth != nil && authlessMethods[req.Method] {
+			// Zabbix rejects these methods when they carry auth.
+			apiErr = &APIError{Code: -32602, Message: "Invalid params.", Data: `The "` + req.Method + `" method must be called without the "auth" parameter.`}
+		} else {
+			result, apiErr = handler(req.Method, req.Params)
+		}
 		resp := APIResponse{
 			JSONRPC: "2.0",
 			Result:  result,
@@ -92,6 +106,80 @@ func TestNewClient_AuthenticatesAndFetchesVersion(t *testing.T) {
 	}
 }
 
+func TestNewClient_LoginFieldByVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		field   string
+	}{
+		{"5.0.30", "user"},
+		{"5.4.12", "user"},
+		{"6.0.0", "username"},
+		{"7.0.3", "username"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			var login map[string]string
+			ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+				switch method {
+				case "apiinfo.version":
+					return tt.version, nil
+				case "user.login":
+					if err := json.Unmarshal(params, &login); err != nil {
+						t.Errorf("unmarshal login params: %v", err)
+					}
+					return "fake-auth-token", nil
+				default:
+					return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+				}
+			})
+			defer ts.Close()
+
+			cfg := config.DefaultConfig()
+			cfg.Zabbix.FrontURL = ts.URL
+			cfg.Zabbix.APIUser = "Admin"
+			cfg.Zabbix.APIPassword = "zabbix"
+
+			if _, err := NewClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			if login[tt.field] != "Admin" {
+				t.Errorf("login params = %v, want %q = Admin", login, tt.field)
+			}
+			if len(login) != 2 || login["password"] != "zabbix" {
+				t.Errorf("login params = %v, want only %q and password", login, tt.field)
+			}
+		})
+	}
+}
+
+func TestNewClient_UsesZabbixAPITimeout(t *testing.T) {
+	ts := newTestServer(t, func(method string, _ json.RawMessage) (interface{}, *APIError) {
+		switch method {
+		case "apiinfo.version":
+			return "7.0.0", nil
+		case "user.login":
+			return "fake-auth-token", nil
+		default:
+			return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+		}
+	})
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.FrontURL = ts.URL
+	cfg.Zabbix.APITimeout = 7
+	cfg.Scan.Timeout = 90
+
+	c, err := NewClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if c.httpClient.Timeout != 7*time.Second {
+		t.Errorf("http client timeout = %v, want 7s (zabbix.api_timeout, not scan.timeout)", c.httpClient.Timeout)
+	}
+}
+
 func TestNewClient_AuthFailure(t *testing.T) {
 	ts := newTestServer(t, func(method string, _ json.RawMessage) (interface{}, *APIError) {
 		switch method {
@@ -158,6 +246,51 @@ func TestGetHostByIDCtx_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetHostsByIDsCtx(t *testing.T) {
+	var calls int
+	var gotIDs []string
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		calls++
+		var p struct {
+			HostIDs []string `json:"hostids"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &APIError{Code: -32602, Message: "Invalid params.", Data: err.Error()}
+		}
+		gotIDs = p.HostIDs
+		return []map[string]interface{}{
+			{"hostid": "10084", "host": "web01", "name": "Web 01", "status": "0",
+				"interfaces": []map[string]string{{"ip": "10.0.0.1", "type": "1", "main": "1"}}},
+			{"hostid": "10085", "host": "web02", "name": "Web 02", "status": "1"},
+		}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+
+	hosts, err := c.GetHostsByIDsCtx(context.Background(), []string{"10084", "10085", "99999"})
+	if err != nil {
+		t.Fatalf("GetHostsByIDsCtx: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("host.get calls = %d, want 1", calls)
+	}
+	if strings.Join(gotIDs, ",") != "10084,10085,99999" {
+		t.Errorf("hostids = %v, want all three IDs as an array", gotIDs)
+	}
+	if len(hosts) != 2 || hosts[0].HostID != "10084" || hosts[1].HostID != "10085" {
+		t.Fatalf("hosts = %+v, want 10084 and 10085", hosts)
+	}
+	if len(hosts[0].Interfaces) != 1 || hosts[0].Interfaces[0].IP != "10.0.0.1" {
+		t.Errorf("interfaces = %+v, want 10.0.0.1", hosts[0].Interfaces)
+	}
+
+	calls = 0
+	if hosts, err := c.GetHostsByIDsCtx(context.Background(), nil); err != nil || hosts != nil || calls != 0 {
+		t.Errorf("empty IDs: hosts=%v err=%v calls=%d, want no call", hosts, err, calls)
+	}
+}
+
 func TestGetHostByNameCtx(t *testing.T) {
 	ts := newTestServer(t, func(method string, _ json.RawMessage) (interface{}, *APIError) {
 		if method == "host.get" {
@@ -213,6 +346,69 @@ func TestGetHostItemsCtx(t *testing.T) {
 	}
 }
 
+func TestParseItems_NumericLastValue(t *testing.T) {
+	result := []interface{}{
+		map[string]interface{}{"itemid": "1", "key_": "vulners.Maximum", "lastvalue": 9.8},
+		map[string]interface{}{"itemid": "2", "key_": "vulners.TotalHosts", "lastvalue": 42},
+		map[string]interface{}{"itemid": "3", "key_": "system.sw.os", "lastvalue": "Ubuntu 22.04"},
+		map[string]interface{}{"itemid": "4", "key_": "system.sw.packages", "lastvalue": nil},
+		map[string]interface{}{"itemid": "5", "key_": "agent.ping"},
+	}
+	items, err := parseItems(result)
+	if err != nil {
+		t.Fatalf("parseItems: %v", err)
+	}
+	want := []string{"9.8", "42", "Ubuntu 22.04", "", ""}
+	for i, w := range want {
+		if items[i].Value != w {
+			t.Errorf("item %s lastvalue = %q, want %q", items[i].Key, items[i].Value, w)
+		}
+	}
+	if items[0].ItemID != "1" || items[0].Key != "vulners.Maximum" {
+		t.Errorf("other fields lost: %+v", items[0])
+	}
+
+	if _, err := parseItems([]interface{}{map[string]interface{}{"lastvalue": true}}); err == nil {
+		t.Error("expected an error for a boolean lastvalue")
+	}
+}
+
+func TestGetHostItemsCtx_Tags(t *testing.T) {
+	var got map[string]interface{}
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		if method != "item.get" {
+			return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+		}
+		_ = json.Unmarshal(params, &got)
+		return []map[string]interface{}{
+			{"itemid": "28002", "hostid": "10084", "key_": "custom.os.packages", "lastvalue": "nginx 1.18.0"},
+		}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	items, err := c.GetHostItemsCtx(context.Background(), "10084", "",
+		ItemTag{Tag: "Application", Value: "Vulners", Exact: true},
+		ItemTag{Tag: "component", Value: "pack"})
+	if err != nil {
+		t.Fatalf("GetHostItemsCtx: %v", err)
+	}
+	if len(items) != 1 || items[0].Key != "custom.os.packages" {
+		t.Fatalf("items = %+v", items)
+	}
+
+	if _, ok := got["search"]; ok {
+		t.Errorf("search sent without a key pattern: %v", got["search"])
+	}
+	if got["evaltype"] != float64(0) {
+		t.Errorf("evaltype = %v, want 0", got["evaltype"])
+	}
+	wantTags := `[map[operator:1 tag:Application value:Vulners] map[operator:0 tag:component value:pack]]`
+	if fmt.Sprint(got["tags"]) != wantTags {
+		t.Errorf("tags = %v, want %s", got["tags"], wantTags)
+	}
+}
+
 func TestGetItemValueCtx(t *testing.T) {
 	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
 		switch method {
@@ -268,6 +464,132 @@ func TestGetHostsWithTemplateCtx(t *testing.T) {
 	}
 }
 
+func TestGetHostsMissingTemplateCtx(t *testing.T) {
+	ts := newTestServer(t, func(method string, raw json.RawMessage) (interface{}, *APIError) {
+		switch method {
+		case "template.get":
+			return []map[string]interface{}{{"templateid": "10001", "host": "tmpl.vulners.os-report"}}, nil
+		case "host.get":
+			var params map[string]interface{}
+			_ = json.Unmarshal(raw, &params)
+			if params["templateids"] != nil {
+				return []map[string]interface{}{{"hostid": "1", "host": "web01", "name": "Web 01"}}, nil
+			}
+			return []map[string]interface{}{
+				{"hostid": "1", "host": "web01", "name": "Web 01"},
+				{"hostid": "2", "host": "db01", "name": "DB 01"},
+				{"hostid": "3", "host": "vulners.hosts", "name": "Vulners - Hosts"},
+			}, nil
+		}
+		return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+	})
+	defer ts.Close()
+
+	hosts, err := newTestClient(t, ts).GetHostsMissingTemplateCtx(context.Background(), "tmpl.vulners.os-report")
+	if err != nil {
+		t.Fatalf("GetHostsMissingTemplateCtx: %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].Host != "db01" {
+		t.Errorf("hosts = %+v, want only db01", hosts)
+	}
+}
+
+func TestLinkOSReportTemplateCtx(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprintf("dryRun=%v", dryRun), func(t *testing.T) {
+			var massadd map[string]interface{}
+			var methods []string
+			ts := newTestServer(t, func(method string, raw json.RawMessage) (interface{}, *APIError) {
+				methods = append(methods, method)
+				switch method {
+				case "hostgroup.get":
+					return []map[string]string{{"groupid": "7", "name": "Linux servers"}}, nil
+				case "template.get":
+					return []map[string]string{{"templateid": "10001", "host": "tmpl.vulners.os-report"}}, nil
+				case "item.get":
+					return []map[string]string{{"itemid": "1", "key_": "system.sw.os"}, {"itemid": "2", "key_": "system.sw.packages"}}, nil
+				case "host.get":
+					return []map[string]interface{}{
+						{"hostid": "1", "host": "web01", "parentTemplates": []map[string]string{{"templateid": "10001", "host": "tmpl.vulners.os-report"}}},
+						{"hostid": "2", "host": "db01", "parentTemplates": []map[string]string{{"templateid": "10050", "host": "Linux by Zabbix agent"}}},
+						{"hostid": "3", "host": "db02"},
+					}, nil
+				case "host.massadd":
+					_ = json.Unmarshal(raw, &massadd)
+					return map[string]interface{}{"hostids": []string{"2", "3"}}, nil
+				}
+				return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+			})
+			defer ts.Close()
+
+			hosts, err := newTestClient(t, ts).LinkOSReportTemplateCtx(context.Background(), "Linux servers", dryRun)
+			if err != nil {
+				t.Fatalf("LinkOSReportTemplateCtx: %v", err)
+			}
+			if len(hosts) != 2 || hosts[0].Host != "db01" || hosts[1].Host != "db02" {
+				t.Errorf("hosts = %+v, want db01 and db02", hosts)
+			}
+			if dryRun {
+				if slices.Contains(methods, "host.massadd") || slices.Contains(methods, "item.get") {
+					t.Errorf("dry run changed or prepared objects: %v", methods)
+				}
+				return
+			}
+			if got := fmt.Sprint(massadd["hosts"], massadd["templates"]); got != "[map[hostid:2] map[hostid:3]] [map[templateid:10001]]" {
+				t.Errorf("host.massadd params = %s", got)
+			}
+		})
+	}
+}
+
+func TestLinkOSReportTemplateCtx_UnknownGroup(t *testing.T) {
+	ts := newTestServer(t, func(method string, _ json.RawMessage) (interface{}, *APIError) {
+		return []interface{}{}, nil
+	})
+	defer ts.Close()
+
+	_, err := newTestClient(t, ts).LinkOSReportTemplateCtx(context.Background(), "Nope", false)
+	if err == nil || !strings.Contains(err.Error(), "host group not found") {
+		t.Errorf("err = %v, want host group not found", err)
+	}
+}
+
+func TestGetHostsWithInventoryCtx(t *testing.T) {
+	var params map[string]interface{}
+	ts := newTestServer(t, func(method string, raw json.RawMessage) (interface{}, *APIError) {
+		if method != "host.get" {
+			return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+		}
+		_ = json.Unmarshal(raw, &params)
+		// Zabbix returns an empty array for hosts with inventory disabled.
+		return []interface{}{
+			map[string]interface{}{"hostid": "1", "host": "web01", "name": "Web 01",
+				"inventory": map[string]string{"os_full": "Ubuntu 22.04.3 LTS", "software_full": "bash 5.1 amd64"}},
+			map[string]interface{}{"hostid": "2", "host": "db01", "name": "DB 01", "inventory": []interface{}{}},
+		}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+
+	hosts, err := c.GetHostsWithInventoryCtx(context.Background())
+	if err != nil {
+		t.Fatalf("GetHostsWithInventoryCtx: %v", err)
+	}
+	if params["withInventory"] != true || params["selectInventory"] == nil {
+		t.Errorf("host.get params = %v, want withInventory and selectInventory", params)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("len(hosts) = %d, want 2", len(hosts))
+	}
+	if hosts[0].Inventory.OSFull != "Ubuntu 22.04.3 LTS" || hosts[0].Inventory.SoftwareFull != "bash 5.1 amd64" {
+		t.Errorf("hosts[0].Inventory = %+v", hosts[0].Inventory)
+	}
+	if hosts[1].Inventory != (HostInventory{}) {
+		t.Errorf("hosts[1].Inventory = %+v, want empty", hosts[1].Inventory)
+	}
+}
+
 func TestGetHostsWithTemplateCtx_TemplateNotFound(t *testing.T) {
 	ts := newTestServer(t, func(method string, _ json.RawMessage) (interface{}, *APIError) {
 		return []interface{}{}, nil
@@ -306,6 +628,36 @@ func TestClose_LogsOut(t *testing.T) {
 	}
 }
 
+func TestClose_APITokenSkipsLogout(t *testing.T) {
+	var gotMethods []string
+	ts := newTestServer(t, func(method string, _ json.RawMessage) (interface{}, *APIError) {
+		gotMethods = append(gotMethods, method)
+		if method == "apiinfo.version" {
+			return "7.0.0", nil
+		}
+		return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+	})
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.FrontURL = ts.URL
+	cfg.Zabbix.APIToken = "api-token"
+
+	c, err := NewClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if c.authToken != "api-token" {
+		t.Errorf("authToken = %q, want api-token", c.authToken)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if len(gotMethods) != 1 || gotMethods[0] != "apiinfo.version" {
+		t.Errorf("methods = %v, want [apiinfo.version]", gotMethods)
+	}
+}
+
 func TestClose_NoAuthToken(t *testing.T) {
 	c := &Client{}
 	if err := c.Close(); err != nil {
@@ -344,3 +696,100 @@ func TestAPIError_Error(t *testing.T) {
 		t.Errorf("Error() = %q, want %q", got, want)
 	}
 }
+
+func TestClient_MaxConcurrentRequests(t *testing.T) {
+	const limit = 2
+	var inFlight, peak atomic.Int32
+	ts := newTestServer(t, func(string, json.RawMessage) (interface{}, *APIError) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return []interface{}{}, nil
+	})
+	c := newTestClient(t, ts)
+	c.slots = make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetHostItemsCtx(context.Background(), "1", "system.sw.os"); err != nil {
+				t.Errorf("GetHostItemsCtx: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > limit {
+		t.Errorf("peak in-flight requests = %d, want <= %d", got, limit)
+	}
+	if got := peak.Load(); got < limit {
+		t.Errorf("peak in-flight requests = %d, calls were not concurrent", got)
+	}
+}
+
+func TestClient_ContentType(t *testing.T) {
+	// The server stands in for a proxy that only accepts application/json.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","result":"7.0.0","id":1}`)
+	}))
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	_, err := c.GetAPIVersion()
+	if err == nil || !strings.Contains(err.Error(), "zabbix.content_type") {
+		t.Fatalf("default content type: err = %v, want a zabbix.content_type hint", err)
+	}
+
+	c.cfg.Zabbix.ContentType = "application/json"
+	ver, err := c.GetAPIVersion()
+	if err != nil {
+		t.Fatalf("GetAPIVersion: %v", err)
+	}
+	if ver != "7.0.0" {
+		t.Errorf("version = %q, want 7.0.0", ver)
+	}
+}
+
+func TestGetMaintenancesCtx(t *testing.T) {
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		if method != "maintenance.get" {
+			t.Errorf("method = %s, want maintenance.get", method)
+		}
+		var p struct {
+			MaintenanceIDs []string `json:"maintenanceids"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil || len(p.MaintenanceIDs) != 1 || p.MaintenanceIDs[0] != "3" {
+			t.Errorf("params = %s", params)
+		}
+		return []map[string]interface{}{
+			{"maintenanceid": "3", "name": "Patch Tuesday"},
+		}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+
+	got, err := c.GetMaintenancesCtx(context.Background(), []string{"3"})
+	if err != nil {
+		t.Fatalf("GetMaintenancesCtx: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "Patch Tuesday" {
+		t.Errorf("maintenances = %+v", got)
+	}
+
+	if got, err := c.GetMaintenancesCtx(context.Background(), nil); err != nil || got != nil {
+		t.Errorf("no IDs: %v, %v; want no call", got, err)
+	}
+}
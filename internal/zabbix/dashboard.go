@@ -2,9 +2,13 @@ package zabbix
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 
 	"log/slog"
+	"slices"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
 )
 
 // EnsureVirtualHosts creates virtual hosts for aggregated vulnerability data
@@ -38,59 +42,99 @@ func (c *Client) EnsureVirtualHostsCtx(ctx context.Context, force bool) error {
 		{c.cfg.Naming.StatisticsHost, c.cfg.Naming.StatisticsVisibleName},
 	}
 
+	names := make([]string, len(virtualHosts))
+	for i, vh := range virtualHosts {
+		names[i] = vh.host
+	}
+	existing, err := c.getHostIDsByName(ctx, names)
+	if err != nil {
+		return fmt.Errorf("failed to look up virtual hosts: %w", err)
+	}
+
+	var toUpdate []string
 	for _, vh := range virtualHosts {
-		if err := c.ensureVirtualHost(ctx, vh.host, vh.name, groupID, templateID, force); err != nil {
+		if id, ok := existing[vh.host]; ok {
+			if force {
+				toUpdate = append(toUpdate, id)
+			} else {
+				c.log.Debug("Virtual host already exists", slog.String("host", vh.host))
+			}
+			continue
+		}
+		if err := c.createVirtualHost(ctx, vh.host, vh.name, groupID, templateID); err != nil {
 			return fmt.Errorf("failed to create virtual host %s: %w", vh.host, err)
 		}
 	}
 
+	if len(toUpdate) > 0 {
+		if err := c.updateVirtualHosts(ctx, toUpdate, templateID); err != nil {
+			return err
+		}
+	}
+
 	c.log.Info("Virtual hosts ready")
 	return nil
 }
 
-// ensureVirtualHost creates a virtual host if it doesn't exist.
-// When force is true, an existing host is updated with current template linkage and macros.
-func (c *Client) ensureVirtualHost(ctx context.Context, host, name, groupID, templateID string, force bool) error {
-	// Check if host exists
+// virtualHostMacros returns the user macros set on every virtual host.
+func (c *Client) virtualHostMacros() []map[string]string {
+	return []map[string]string{
+		{"macro": "{$SCORE.MIN}", "value": fmt.Sprintf("%g", c.cfg.Scan.MinCVSS)},
+		{"macro": "{$SCORE.CRIT}", "value": fmt.Sprintf("%g", c.cfg.Scan.CritCVSS)},
+	}
+}
+
+// getHostIDsByName looks up several hosts by technical name in one call and
+// returns a name → host ID map of those that exist.
+func (c *Client) getHostIDsByName(ctx context.Context, names []string) (map[string]string, error) {
 	params := map[string]interface{}{
 		"output": []string{"hostid", "host"},
 		"filter": map[string]interface{}{
-			"host": host,
+			"host": names,
 		},
 	}
 
 	result, err := c.callWithContext(ctx, "host.get", params)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	hosts, err := parseHosts(result)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if len(hosts) > 0 {
-		if force {
-			c.log.Info("Force-updating virtual host", slog.String("host", host))
-			updateParams := map[string]interface{}{
-				"hostid": hosts[0].HostID,
-				"templates": []map[string]string{
-					{"templateid": templateID},
-				},
-				"macros": []map[string]string{
-					{"macro": "{$SCORE.MIN}", "value": fmt.Sprintf("%g", c.cfg.Scan.MinCVSS)},
-				},
-			}
-			_, err = c.callWithContext(ctx, "host.update", updateParams)
-			if err != nil {
-				return fmt.Errorf("failed to update host: %w", err)
-			}
-			return nil
-		}
-		c.log.Debug("Virtual host already exists", slog.String("host", host))
-		return nil
+	ids := make(map[string]string, len(hosts))
+	for _, h := range hosts {
+		ids[h.Host] = h.HostID
+	}
+	return ids, nil
+}
+
+// updateVirtualHosts re-links the template and resets macros on existing
+// virtual hosts with a single host.massupdate call.
+func (c *Client) updateVirtualHosts(ctx context.Context, hostIDs []string, templateID string) error {
+	hosts := make([]map[string]string, len(hostIDs))
+	for i, id := range hostIDs {
+		hosts[i] = map[string]string{"hostid": id}
 	}
 
+	c.log.Info("Force-updating virtual hosts", slog.Int("count", len(hostIDs)))
+	params := map[string]interface{}{
+		"hosts": hosts,
+		"templates": []map[string]string{
+			{"templateid": templateID},
+		},
+		"macros": c.virtualHostMacros(),
+	}
+	if _, err := c.callWithContext(ctx, "host.massupdate", params); err != nil {
+		return fmt.Errorf("failed to update virtual hosts: %w", err)
+	}
+	return nil
+}
+
+// createVirtualHost creates a virtual host linked to the Vulners template.
+func (c *Client) createVirtualHost(ctx context.Context, host, name, groupID, templateID string) error {
 	// Create host with agent interface (required by Zabbix but not used)
 	createParams := map[string]interface{}{
 		"host": host,
@@ -111,13 +155,10 @@ func (c *Client) ensureVirtualHost(ctx context.Context, host, name, groupID, tem
 				"port":  "10050",
 			},
 		},
-		"macros": []map[string]string{
-			{"macro": "{$SCORE.MIN}", "value": fmt.Sprintf("%g", c.cfg.Scan.MinCVSS)},
-		},
+		"macros": c.virtualHostMacros(),
 	}
 
-	_, err = c.callWithContext(ctx, "host.create", createParams)
-	if err != nil {
+	if _, err := c.callWithContext(ctx, "host.create", createParams); err != nil {
 		return fmt.Errorf("failed to create host: %w", err)
 	}
 
@@ -214,6 +255,11 @@ func (c *Client) ensureVulnersTemplate(ctx context.Context, groupID string, forc
 			if err := c.createVulnersTemplateItems(ctx, templateID); err != nil {
 				return "", err
 			}
+			return templateID, nil
+		}
+		// Fill in objects an interrupted earlier run did not create.
+		if err := c.createVulnersTemplateItems(ctx, templateID); err != nil {
+			return "", err
 		}
 		return templateID, nil
 	}
@@ -336,9 +382,56 @@ func (c *Client) deleteTemplateItems(ctx context.Context, templateID string) err
 	return nil
 }
 
-// createVulnersTemplateItems creates LLD rules and items for the Vulners template
-func (c *Client) createVulnersTemplateItems(ctx context.Context, templateID string) error {
-	// Create LLD rule for hosts
+// Zabbix item value types.
+const (
+	valueTypeFloat    = 0
+	valueTypeChar     = 1
+	valueTypeUnsigned = 3
+	valueTypeText     = 4
+)
+
+// scoreItemValueType returns the value type for package and bulletin
+// item prototypes: float for CVSS scores, otherwise per
+// scan.score_item_value_type.
+func (c *Client) scoreItemValueType() int {
+	if c.cfg.Scan.PackageItemValue == config.PackageValueCVSS || c.cfg.Scan.ScoreItemValueType == config.ValueTypeFloat {
+		return valueTypeFloat
+	}
+	return valueTypeUnsigned
+}
+
+// fleetTrends is the trends retention of the fleet-level risk items, longer
+// than the Zabbix default of one year.
+const fleetTrends = "1825d"
+
+// lldDecompressScript is the JavaScript preprocessing step that decodes the
+// LLD values sent with scan.compress_lld.
+//
+//go:embed lld_decompress.js
+var lldDecompressScript string
+
+// preprocessingJavaScript is the Zabbix preprocessing step type "JavaScript".
+const preprocessingJavaScript = 21
+
+// lldRuleTypes maps the discovery rules that scan.enabled_lld can turn off
+// to their LLD type.
+var lldRuleTypes = map[string]string{
+	"vulners.hosts_lld":     config.LLDHosts,
+	"vulners.packages_lld":  config.LLDPackages,
+	"vulners.bulletins_lld": config.LLDBulletins,
+}
+
+// lldRuleEnabled reports whether scan.enabled_lld keeps the rule with key.
+func (c *Client) lldRuleEnabled(ruleKey string) bool {
+	kind, ok := lldRuleTypes[ruleKey]
+	return !ok || c.cfg.Scan.LLDEnabled(kind)
+}
+
+// vulnersLLDRules returns the discovery rules of the Vulners template.
+// Rules left out by scan.enabled_lld get no item or trigger prototypes
+// either. With scan.compress_lld every rule decodes its value in a
+// JavaScript preprocessing step.
+func (c *Client) vulnersLLDRules(templateID string) []map[string]interface{} {
 	lldRules := []map[string]interface{}{
 		{
 			"hostid":   templateID,
@@ -346,7 +439,7 @@ func (c *Client) createVulnersTemplateItems(ctx context.Context, templateID stri
 			"key_":     "vulners.hosts_lld",
 			"type":     2, // Zabbix trapper
 			"delay":    "0",
-			"lifetime": "0",
+			"lifetime": c.cfg.Scan.LLDLifetime,
 		},
 		{
 			"hostid":   templateID,
@@ -354,7 +447,7 @@ func (c *Client) createVulnersTemplateItems(ctx context.Context, templateID stri
 			"key_":     "vulners.packages_lld",
 			"type":     2, // Zabbix trapper
 			"delay":    "0",
-			"lifetime": "0",
+			"lifetime": c.cfg.Scan.LLDLifetime,
 		},
 		{
 			"hostid":   templateID,
@@ -362,78 +455,69 @@ func (c *Client) createVulnersTemplateItems(ctx context.Context, templateID stri
 			"key_":     "vulners.bulletins_lld",
 			"type":     2, // Zabbix trapper
 			"delay":    "0",
-			"lifetime": "0",
+			"lifetime": c.cfg.Scan.LLDLifetime,
+		},
+		{
+			"hostid":   templateID,
+			"name":     "Vulners - Host Groups Discovery",
+			"key_":     "vulners.groups_lld",
+			"type":     2, // Zabbix trapper
+			"delay":    "0",
+			"lifetime": c.cfg.Scan.LLDLifetime,
 		},
 	}
-
-	// Map LLD rule key → rule ID for creating item prototypes
-	lldRuleIDs := make(map[string]string)
-	for _, rule := range lldRules {
-		result, err := c.callWithContext(ctx, "discoveryrule.create", rule)
-		if err != nil {
-			// Rule may already exist — fetch its ID
-			c.log.Debug("LLD rule create failed, fetching existing", slog.Any("rule", rule["name"]))
-			getParams := map[string]interface{}{
-				"output":  []string{"itemid"},
-				"hostids": templateID,
-				"filter": map[string]interface{}{
-					"key_": rule["key_"],
-				},
-			}
-			existing, getErr := c.callWithContext(ctx, "discoveryrule.get", getParams)
-			if getErr == nil {
-				if items, ok := existing.([]interface{}); ok && len(items) > 0 {
-					if item, ok := items[0].(map[string]interface{}); ok {
-						if id, ok := item["itemid"].(string); ok {
-							lldRuleIDs[rule["key_"].(string)] = id
-						}
-					}
-				}
-			}
-			continue
-		}
-		if resultMap, ok := result.(map[string]interface{}); ok {
-			if ids, ok := resultMap["itemids"].([]interface{}); ok && len(ids) > 0 {
-				if id, ok := ids[0].(string); ok {
-					lldRuleIDs[rule["key_"].(string)] = id
-				}
-			}
+	if c.cfg.Scan.CompressLLD {
+		for _, rule := range lldRules {
+			rule["preprocessing"] = []map[string]interface{}{{
+				"type":                 preprocessingJavaScript,
+				"params":               lldDecompressScript,
+				"error_handler":        0,
+				"error_handler_params": "",
+			}}
 		}
 	}
+	return slices.DeleteFunc(lldRules, func(rule map[string]interface{}) bool {
+		return !c.lldRuleEnabled(rule["key_"].(string))
+	})
+}
 
-	// Create item prototypes for each LLD rule so that discovered entities
-	// produce actual trapper items that accept score data.
-	type itemProto struct {
-		ruleKey string
-		name    string
-		key     string
-	}
-	prototypes := []itemProto{
-		{"vulners.hosts_lld", "Host {#H.VNAME} CVSS Score", "vulners.hosts[{#H.ID}]"},
-		{"vulners.packages_lld", "Package {#P.NAME} {#P.VERSION} ({#P.ARCH}) CVSS Score", "vulners.packages[{#P.NAME},{#P.VERSION},{#P.ARCH}]"},
-		{"vulners.bulletins_lld", "Bulletin {#B.ID} CVSS Score", "vulners.bulletins[{#B.ID}]"},
-	}
-	for _, proto := range prototypes {
-		ruleID, ok := lldRuleIDs[proto.ruleKey]
-		if !ok {
-			continue
-		}
-		protoParams := map[string]interface{}{
-			"hostid":     templateID,
-			"ruleid":     ruleID,
-			"name":       proto.name,
-			"key_":       proto.key,
-			"type":       2, // Zabbix trapper
-			"value_type": 0, // numeric float
-			"delay":      "0",
-		}
-		_, err := c.callWithContext(ctx, "itemprototype.create", protoParams)
-		if err != nil {
-			c.log.Warn("Failed to create item prototype (may already exist)", slog.String("prototype", proto.key))
-		}
+// itemPrototypeDef describes an item prototype of a Vulners template LLD rule.
+type itemPrototypeDef struct {
+	ruleKey   string
+	name      string
+	key       string
+	valueType int
+}
+
+// vulnersItemPrototypes returns the item prototypes of the enabled LLD rules
+// so that discovered entities produce actual trapper items that accept
+// score data.
+// Host items hold the host's CVSS score; package and bulletin items hold
+// the number of affected hosts (Python-compatible) or, with
+// scan.package_item_value=cvss, their CVSS score. The triggers compare
+// either with > 0.
+func (c *Client) vulnersItemPrototypes() []itemPrototypeDef {
+	suffix := "affected hosts"
+	if c.cfg.Scan.PackageItemValue == config.PackageValueCVSS {
+		suffix = "CVSS Score"
+	}
+	countType := c.scoreItemValueType()
+	prototypes := []itemPrototypeDef{
+		{"vulners.hosts_lld", "Host {#H.VNAME} CVSS Score", HostsPrototypeKey, valueTypeFloat},
+		{"vulners.packages_lld", "Package {#P.NAME} {#P.VERSION} ({#P.ARCH}) " + suffix, PackagePrototypeKey(c.cfg.Naming.HashPackageKeys), countType},
+		{"vulners.bulletins_lld", "Bulletin {#B.ID} " + suffix, BulletinsPrototypeKey, countType},
+	}
+	for _, metric := range GroupStatsMetrics {
+		prototypes = append(prototypes, itemPrototypeDef{
+			"vulners.groups_lld", "Group {#G.NAME} " + metric, GroupStatsItemKey("{#G.ID}", metric), valueTypeFloat,
+		})
 	}
+	return slices.DeleteFunc(prototypes, func(p itemPrototypeDef) bool { return !c.lldRuleEnabled(p.ruleKey) })
+}
 
-	// Create trapper items for statistics — Python-compatible keys.
+// vulnersStatItems returns the statistics trapper items of the Vulners
+// template, with Python-compatible keys.
+func (c *Client) vulnersStatItems(templateID string) []map[string]interface{} {
 	// value_type 3 = numeric unsigned (for integer values: counts).
 	// value_type 0 = numeric float (for CVSS scores: preserves decimals).
 	// Note: Python used value_type=3 for ALL stats items (including scores),
@@ -458,56 +542,284 @@ func (c *Client) createVulnersTemplateItems(ctx context.Context, templateID stri
 		})
 	}
 
-	// Go backward-compatible stat items
+	// Go backward-compatible stat items. The fleet-level risk series keep
+	// trends for fleetTrends so long-term risk graphs outlive history.
 	goStatItems := []map[string]interface{}{
 		{"hostid": templateID, "name": "Vulners - Total Hosts", "key_": "vulners.stats[total_hosts]", "type": 2, "value_type": 3},
-		{"hostid": templateID, "name": "Vulners - Vulnerable Hosts", "key_": "vulners.stats[vuln_hosts]", "type": 2, "value_type": 3},
+		{"hostid": templateID, "name": "Vulners - Vulnerable Hosts", "key_": "vulners.stats[vuln_hosts]", "type": 2, "value_type": 3, "trends": fleetTrends},
 		{"hostid": templateID, "name": "Vulners - Total Vulnerabilities", "key_": "vulners.stats[total_vulns]", "type": 2, "value_type": 3},
-		{"hostid": templateID, "name": "Vulners - Max CVSS Score", "key_": "vulners.stats[max_score]", "type": 2, "value_type": 0},
+		{"hostid": templateID, "name": "Vulners - Max CVSS Score", "key_": "vulners.stats[max_score]", "type": 2, "value_type": 0, "trends": fleetTrends},
 		{"hostid": templateID, "name": "Vulners - Total Bulletins", "key_": "vulners.stats[total_bulletins]", "type": 2, "value_type": 3},
-		{"hostid": templateID, "name": "Vulners - Total CVEs", "key_": "vulners.stats[total_cves]", "type": 2, "value_type": 3},
-		{"hostid": templateID, "name": "Vulners - Average CVSS Score", "key_": "vulners.stats[avg_score]", "type": 2, "value_type": 0},
+		{"hostid": templateID, "name": "Vulners - Total CVEs", "key_": "vulners.stats[total_cves]", "type": 2, "value_type": 3, "trends": fleetTrends},
+		{"hostid": templateID, "name": "Vulners - Average CVSS Score", "key_": "vulners.stats[avg_score]", "type": 2, "value_type": 0, "trends": fleetTrends},
+		{"hostid": templateID, "name": "Vulners - Fleet risk", "key_": "vulners.stats[fleet_risk]", "type": 2, "value_type": 0, "trends": fleetTrends,
+			"description": "Sum of all host CVSS scores. Drops whenever any host is patched."},
+		{"hostid": templateID, "name": "Vulners - Top CVEs by bulletin count", "key_": "vulners.stats[top_cves]", "type": 2, "value_type": 4},
+		{"hostid": templateID, "name": "Vulners - Scan duration", "key_": "vulners.stats[scan_duration_seconds]", "type": 2, "value_type": 0, "units": "s"},
+		{"hostid": templateID, "name": "Vulners - Host fetch duration", "key_": "vulners.stats[fetch_duration_seconds]", "type": 2, "value_type": 0, "units": "s"},
+		{"hostid": templateID, "name": "Vulners - Audit duration", "key_": "vulners.stats[audit_duration_seconds]", "type": 2, "value_type": 0, "units": "s"},
+		{"hostid": templateID, "name": "Vulners - LLD delay", "key_": "vulners.stats[lld_delay_seconds]", "type": 2, "value_type": 0, "units": "s"},
+		{"hostid": templateID, "name": "Vulners - Push duration", "key_": "vulners.stats[push_duration_seconds]", "type": 2, "value_type": 0, "units": "s"},
+		{"hostid": templateID, "name": "Vulners - Self-test", "key_": SelfTestKey, "type": 2, "value_type": valueTypeChar,
+			"description": "Written only by ztc prepare --self-test."},
 	}
 	statItems = append(statItems, goStatItems...)
 
-	for _, item := range statItems {
-		_, err := c.callWithContext(ctx, "item.create", item)
-		if err != nil {
-			c.log.Warn("Failed to create item (may already exist)", slog.Any("item", item["name"]))
+	if c.cfg.Scan.PushRawJSON {
+		statItems = append(statItems, map[string]interface{}{
+			"hostid": templateID, "name": "Vulners - Raw Scan JSON", "key_": "vulners.scan.raw", "type": 2, "value_type": 4,
+		})
+	}
+	return statItems
+}
+
+// createVulnersTemplateItems creates LLD rules and items for the Vulners template
+func (c *Client) createVulnersTemplateItems(ctx context.Context, templateID string) error {
+	existing := c.existingTemplateObjects(ctx, templateID)
+
+	// Map LLD rule key → rule ID for creating item prototypes
+	lldRuleIDs := make(map[string]string)
+	var lldRules []map[string]interface{}
+	for _, rule := range c.vulnersLLDRules(templateID) {
+		key := rule["key_"].(string)
+		if id, ok := existing.rules[key]; ok {
+			lldRuleIDs[key] = id
+			continue
+		}
+		lldRules = append(lldRules, rule)
+	}
+	ruleIDs := c.createMany(ctx, "discoveryrule.create", "itemids", lldRules)
+	for i, rule := range lldRules {
+		key := rule["key_"].(string)
+		if ruleIDs[i] != "" {
+			lldRuleIDs[key] = ruleIDs[i]
+			continue
+		}
+		// Rule may already exist — fetch its ID
+		c.log.Debug("LLD rule create failed, fetching existing", slog.Any("rule", rule["name"]))
+		getParams := map[string]interface{}{
+			"output":  []string{"itemid"},
+			"hostids": templateID,
+			"filter": map[string]interface{}{
+				"key_": key,
+			},
+		}
+		existing, getErr := c.callWithContext(ctx, "discoveryrule.get", getParams)
+		if getErr == nil {
+			if items, ok := existing.([]interface{}); ok && len(items) > 0 {
+				if item, ok := items[0].(map[string]interface{}); ok {
+					if id, ok := item["itemid"].(string); ok {
+						lldRuleIDs[key] = id
+					}
+				}
+			}
+		}
+	}
+
+	// Create item prototypes for each LLD rule
+	var protoParams []map[string]interface{}
+	for _, proto := range c.vulnersItemPrototypes() {
+		ruleID, ok := lldRuleIDs[proto.ruleKey]
+		if !ok || existing.prototypes[proto.key] != "" {
+			continue
+		}
+		protoParams = append(protoParams, map[string]interface{}{
+			"hostid":     templateID,
+			"ruleid":     ruleID,
+			"name":       proto.name,
+			"key_":       proto.key,
+			"type":       2, // Zabbix trapper
+			"value_type": proto.valueType,
+			"delay":      "0",
+		})
+	}
+	for i, id := range c.createMany(ctx, "itemprototype.create", "itemids", protoParams) {
+		if id == "" {
+			c.log.Warn("Failed to create item prototype (may already exist)", slog.Any("prototype", protoParams[i]["key_"]))
+		}
+	}
+
+	statItems := slices.DeleteFunc(c.vulnersStatItems(templateID), func(item map[string]interface{}) bool {
+		return existing.items[item["key_"].(string)] != ""
+	})
+	for i, id := range c.createMany(ctx, "item.create", "itemids", statItems) {
+		if id == "" {
+			c.log.Warn("Failed to create item (may already exist)", slog.Any("item", statItems[i]["name"]))
 		}
 	}
 
 	// Create trigger prototypes for alerting
-	if err := c.createTriggerPrototypes(ctx, lldRuleIDs); err != nil {
+	if err := c.createTriggerPrototypes(ctx, lldRuleIDs, existing.triggers); err != nil {
 		c.log.Warn("Failed to create some trigger prototypes", slog.Any("error", err))
 	}
 
 	return nil
 }
 
-// createTriggerPrototypes creates version-aware trigger prototypes for all LLD rules.
-func (c *Client) createTriggerPrototypes(ctx context.Context, lldRuleIDs map[string]string) error {
-	version := c.getAPIVersionFloat()
+// templateObjects holds the IDs of a template's existing objects: discovery
+// rules, item prototypes and items by key, trigger prototypes by
+// description.
+type templateObjects struct {
+	rules, prototypes, items, triggers map[string]string
+}
+
+// existingTemplateObjects returns the objects already on the template, so
+// re-running prepare only creates what is missing. A failed lookup leaves
+// that kind empty; creating an existing object then fails with a warning.
+func (c *Client) existingTemplateObjects(ctx context.Context, templateID string) templateObjects {
+	lookup := func(method, idField, nameField string, filter map[string]interface{}) map[string]string {
+		params := map[string]interface{}{
+			"output":      []string{idField, nameField},
+			"templateids": templateID,
+		}
+		if filter != nil {
+			params["filter"] = filter
+		}
+		ids, err := c.getObjectIDs(ctx, method, idField, nameField, params)
+		if err != nil {
+			c.log.Debug("Failed to look up existing template objects", slog.String("method", method), slog.Any("error", err))
+		}
+		return ids
+	}
+	return templateObjects{
+		rules:      lookup("discoveryrule.get", "itemid", "key_", nil),
+		prototypes: lookup("itemprototype.get", "itemid", "key_", nil),
+		items:      lookup("item.get", "itemid", "key_", map[string]interface{}{"flags": 0}),
+		triggers:   lookup("triggerprototype.get", "triggerid", "description", nil),
+	}
+}
+
+// getObjectIDs calls a *.get method and maps each object's nameField to its
+// idField.
+func (c *Client) getObjectIDs(ctx context.Context, method, idField, nameField string, params map[string]interface{}) (map[string]string, error) {
+	ids := make(map[string]string)
+	result, err := c.callWithContext(ctx, method, params)
+	if err != nil {
+		return ids, err
+	}
+	objects, ok := result.([]interface{})
+	if !ok {
+		return ids, fmt.Errorf("unexpected response type: %T", result)
+	}
+	for _, o := range objects {
+		m, ok := o.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m[nameField].(string)
+		id, _ := m[idField].(string)
+		if name != "" && id != "" {
+			ids[name] = id
+		}
+	}
+	return ids, nil
+}
+
+// createMany creates objects with a single array call to method. Zabbix
+// rejects the whole batch if any object fails (e.g. already exists), in
+// which case each object is retried on its own. The returned slice holds the
+// created ID per object, or "" where creation failed.
+func (c *Client) createMany(ctx context.Context, method, idsKey string, objects []map[string]interface{}) []string {
+	ids := make([]string, len(objects))
+	if len(objects) == 0 {
+		return ids
+	}
+
+	result, err := c.callWithContext(ctx, method, objects)
+	if err == nil {
+		if created := parseCreatedIDs(result, idsKey); len(created) == len(objects) {
+			return created
+		}
+	}
+	c.log.Debug("Batch create failed, falling back to individual calls",
+		slog.String("method", method), slog.Int("count", len(objects)), slog.Any("error", err))
+
+	for i, obj := range objects {
+		result, err := c.callWithContext(ctx, method, obj)
+		if err != nil {
+			continue
+		}
+		if created := parseCreatedIDs(result, idsKey); len(created) > 0 {
+			ids[i] = created[0]
+		}
+	}
+	return ids
+}
+
+// parseCreatedIDs extracts the ID list (e.g. "itemids") from a *.create response.
+func parseCreatedIDs(result interface{}, idsKey string) []string {
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	raw, ok := resultMap[idsKey].([]interface{})
+	if !ok {
+		return nil
+	}
+	ids := make([]string, 0, len(raw))
+	for _, v := range raw {
+		id, ok := v.(string)
+		if !ok {
+			return nil
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
 
-	type triggerDef struct {
-		ruleKey     string
-		expression  string
-		description string
-		url         string
-		comments    string
+// createTriggerPrototypes creates version-aware trigger prototypes for all
+// LLD rules, skipping those whose description is in existing.
+func (c *Client) createTriggerPrototypes(ctx context.Context, lldRuleIDs, existing map[string]string) error {
+	var params []map[string]interface{}
+	for _, trig := range c.vulnersTriggerPrototypes() {
+		if _, ok := lldRuleIDs[trig.ruleKey]; !ok || existing[trig.description] != "" {
+			continue
+		}
+		params = append(params, map[string]interface{}{
+			"expression":   trig.expression,
+			"description":  trig.description,
+			"url":          trig.url,
+			"manual_close": 1,
+			"priority":     "0",
+			"comments":     trig.comments,
+			"status":       "0",
+		})
 	}
+	for i, id := range c.createMany(ctx, "triggerprototype.create", "triggerids", params) {
+		if id == "" {
+			c.log.Warn("Failed to create trigger prototype (may already exist)", slog.Any("trigger", params[i]["description"]))
+		}
+	}
+
+	return nil
+}
+
+// triggerPrototypeDef describes a trigger prototype of a Vulners template
+// LLD rule.
+type triggerPrototypeDef struct {
+	ruleKey     string
+	expression  string
+	description string
+	url         string
+	comments    string
+}
+
+// vulnersTriggerPrototypes returns the version-aware trigger prototypes of
+// the enabled LLD rules.
+func (c *Client) vulnersTriggerPrototypes() []triggerPrototypeDef {
+	version := c.getAPIVersionFloat()
 
-	var triggers []triggerDef
+	var triggers []triggerPrototypeDef
 
 	if version < 5.4 {
 		// Legacy syntax: {host:key.last()}
-		triggers = []triggerDef{
+		triggers = []triggerPrototypeDef{
 			{
 				ruleKey:     "vulners.hosts_lld",
-				expression:  fmt.Sprintf("{%s:vulners.hosts[{#H.ID}].last()} > 0 and {#H.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.HostsHost),
+				expression:  fmt.Sprintf("{%s:%s.last()} > 0 and {#H.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.HostsHost, HostsPrototypeKey),
 				description: "Score {#H.SCORE}. Host = {#H.VNAME}",
 				url:         "",
-				comments:    "Cumulative fix:\r\n\r\n{#H.FIX}",
+				comments:    "Cumulative fix:\r\n\r\n{#H.FIX}\r\n----\r\nztc fix runs:\r\n\r\n{#H.FIXCMD}",
 			},
 			{
 				ruleKey:     "vulners.bulletins_lld",
@@ -518,7 +830,7 @@ func (c *Client) createTriggerPrototypes(ctx context.Context, lldRuleIDs map[str
 			},
 			{
 				ruleKey:     "vulners.packages_lld",
-				expression:  fmt.Sprintf("{%s:vulners.packages[{#P.NAME},{#P.VERSION},{#P.ARCH}].last()} > 0 and {#PKG.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.PackagesHost),
+				expression:  fmt.Sprintf("{%s:%s.last()} > 0 and {#PKG.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.PackagesHost, PackagePrototypeKey(c.cfg.Naming.HashPackageKeys)),
 				description: "Impact {#PKG.IMPACT}. Score {#PKG.SCORE}. Affected {ITEM.VALUE}. Package = {#PKG.ID}",
 				url:         "https://vulners.com/info/{#PKG.URL}",
 				comments:    "Vulnerabilities are found on:\r\n\r\n{#PKG.HOSTS}\r\n----\r\n{#PKG.FIX}",
@@ -526,13 +838,13 @@ func (c *Client) createTriggerPrototypes(ctx context.Context, lldRuleIDs map[str
 		}
 	} else {
 		// New syntax: last(/host/key)
-		triggers = []triggerDef{
+		triggers = []triggerPrototypeDef{
 			{
 				ruleKey:     "vulners.hosts_lld",
-				expression:  fmt.Sprintf("last(/%s/vulners.hosts[{#H.ID}]) > 0 and {#H.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.HostsHost),
+				expression:  fmt.Sprintf("last(/%s/%s) > 0 and {#H.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.HostsHost, HostsPrototypeKey),
 				description: "Score {#H.SCORE}. Host = {#H.VNAME}",
 				url:         "",
-				comments:    "Cumulative fix:\r\n\r\n{#H.FIX}",
+				comments:    "Cumulative fix:\r\n\r\n{#H.FIX}\r\n----\r\nztc fix runs:\r\n\r\n{#H.FIXCMD}",
 			},
 			{
 				ruleKey:     "vulners.bulletins_lld",
@@ -543,7 +855,7 @@ func (c *Client) createTriggerPrototypes(ctx context.Context, lldRuleIDs map[str
 			},
 			{
 				ruleKey:     "vulners.packages_lld",
-				expression:  fmt.Sprintf("last(/%s/vulners.packages[{#P.NAME},{#P.VERSION},{#P.ARCH}]) > 0 and {#PKG.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.PackagesHost),
+				expression:  fmt.Sprintf("last(/%s/%s) > 0 and {#PKG.SCORE} >= {$SCORE.MIN}", c.cfg.Naming.PackagesHost, PackagePrototypeKey(c.cfg.Naming.HashPackageKeys)),
 				description: "Impact {#PKG.IMPACT}. Score {#PKG.SCORE}. Affected {ITEM.VALUE}. Package = {#PKG.ID}",
 				url:         "https://vulners.com/info/{#PKG.URL}",
 				comments:    "Vulnerabilities are found on:\r\n\r\n{#PKG.HOSTS}\r\n----\r\n{#PKG.FIX}",
@@ -551,26 +863,7 @@ func (c *Client) createTriggerPrototypes(ctx context.Context, lldRuleIDs map[str
 		}
 	}
 
-	for _, trig := range triggers {
-		if _, ok := lldRuleIDs[trig.ruleKey]; !ok {
-			continue
-		}
-		params := map[string]interface{}{
-			"expression":   trig.expression,
-			"description":  trig.description,
-			"url":          trig.url,
-			"manual_close": 1,
-			"priority":     "0",
-			"comments":     trig.comments,
-			"status":       "0",
-		}
-		_, err := c.callWithContext(ctx, "triggerprototype.create", params)
-		if err != nil {
-			c.log.Warn("Failed to create trigger prototype (may already exist)", slog.String("trigger", trig.description))
-		}
-	}
-
-	return nil
+	return slices.DeleteFunc(triggers, func(t triggerPrototypeDef) bool { return !c.lldRuleEnabled(t.ruleKey) })
 }
 
 // EnsureDashboard creates the Vulners dashboard
@@ -631,7 +924,35 @@ func (c *Client) EnsureDashboardCtx(ctx context.Context, force bool) error {
 	packagesHostID := c.resolveHostID(ctx, c.cfg.Naming.PackagesHost)
 	bulletinsHostID := c.resolveHostID(ctx, c.cfg.Naming.BulletinsHost)
 
-	// Build widgets
+	widgets := c.dashboardWidgets(hostsHostID, packagesHostID, bulletinsHostID, medianGraphID, scoreGraphID)
+
+	// Create dashboard
+	createParams := map[string]interface{}{
+		"name":           dashboardName,
+		"display_period": 30,
+		"auto_start":     1,
+	}
+
+	if c.getAPIVersionFloat() > 5.0 {
+		createParams["pages"] = []map[string]interface{}{
+			{"widgets": widgets},
+		}
+	} else {
+		createParams["widgets"] = widgets
+	}
+
+	_, err = c.callWithContext(ctx, "dashboard.create", createParams)
+	if err != nil {
+		return fmt.Errorf("failed to create dashboard: %w", err)
+	}
+
+	c.log.Info("Created dashboard")
+	return nil
+}
+
+// dashboardWidgets returns the widgets of the Vulners dashboard. Graph
+// widgets are left out when their graph ID is empty.
+func (c *Client) dashboardWidgets(hostsHostID, packagesHostID, bulletinsHostID, medianGraphID, scoreGraphID string) []map[string]interface{} {
 	widgets := []map[string]interface{}{
 		{
 			"type": "problems", "name": "Vulners - Hosts",
@@ -691,29 +1012,7 @@ func (c *Client) EnsureDashboardCtx(ctx context.Context, force bool) error {
 			},
 		})
 	}
-
-	// Create dashboard
-	createParams := map[string]interface{}{
-		"name":           dashboardName,
-		"display_period": 30,
-		"auto_start":     1,
-	}
-
-	if c.getAPIVersionFloat() > 5.0 {
-		createParams["pages"] = []map[string]interface{}{
-			{"widgets": widgets},
-		}
-	} else {
-		createParams["widgets"] = widgets
-	}
-
-	_, err = c.callWithContext(ctx, "dashboard.create", createParams)
-	if err != nil {
-		return fmt.Errorf("failed to create dashboard: %w", err)
-	}
-
-	c.log.Info("Created dashboard")
-	return nil
+	return widgets
 }
 
 // resolveHostID looks up the Zabbix host ID for a virtual host by technical name.
@@ -760,25 +1059,21 @@ func (c *Client) createStatisticsGraphs(ctx context.Context) (string, string, er
 		return items[0].ItemID
 	}
 
-	// Check if graphs already exist
-	for _, name := range []string{"Median CVSS Score", "CVSS Score ratio by servers"} {
-		params := map[string]interface{}{
-			"output":  []string{"graphid"},
-			"hostids": statisticsHostID,
-			"filter":  map[string]interface{}{"name": name},
-		}
-		result, err := c.callWithContext(ctx, "graph.get", params)
-		if err == nil {
-			if graphs, ok := result.([]interface{}); ok && len(graphs) > 0 {
-				c.log.Debug("Graph already exists", slog.String("graph", name))
-			}
-		}
+	// Reuse graphs an earlier run created
+	existing, err := c.getObjectIDs(ctx, "graph.get", "graphid", "name", map[string]interface{}{
+		"output":  []string{"graphid", "name"},
+		"hostids": statisticsHostID,
+		"filter":  map[string]interface{}{"name": []string{"Median CVSS Score", "CVSS Score ratio by servers"}},
+	})
+	if err != nil {
+		c.log.Debug("Failed to look up existing graphs", slog.Any("error", err))
 	}
 
 	// Graph 1: Median CVSS Score (line graph)
-	medianItemID := findItem("vulners.scoreMedian")
-	var medianGraphID string
-	if medianItemID != "" {
+	medianGraphID := existing["Median CVSS Score"]
+	if medianGraphID != "" {
+		c.log.Debug("Graph already exists", slog.String("graph", "Median CVSS Score"))
+	} else if medianItemID := findItem("vulners.scoreMedian"); medianItemID != "" {
 		params := map[string]interface{}{
 			"name":             "Median CVSS Score",
 			"width":            1000,
@@ -804,6 +1099,11 @@ func (c *Client) createStatisticsGraphs(ctx context.Context) (string, string, er
 	}
 
 	// Graph 2: CVSS Score ratio by servers (pie chart)
+	scoreGraphID := existing["CVSS Score ratio by servers"]
+	if scoreGraphID != "" {
+		c.log.Debug("Graph already exists", slog.String("graph", "CVSS Score ratio by servers"))
+		return medianGraphID, scoreGraphID, nil
+	}
 	colors := []string{"DD0000", "EE0000", "FF3333", "EEEE00", "FFFF66", "00EEEE", "00DDDD", "3333FF", "6666FF", "00DD00", "33FF33"}
 	var gitems []map[string]interface{}
 	for i := 0; i <= 10; i++ {
@@ -819,7 +1119,6 @@ func (c *Client) createStatisticsGraphs(ctx context.Context) (string, string, er
 		})
 	}
 
-	var scoreGraphID string
 	if len(gitems) == 11 {
 		params := map[string]interface{}{
 			"name":             "CVSS Score ratio by servers",
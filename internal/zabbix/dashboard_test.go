@@ -0,0 +1,393 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordCreates runs createVulnersTemplateItems against a fake server and
+// returns the decoded params of every call, keyed by method.
+func recordCreates(t *testing.T, mutate func(c *Client)) map[string][]map[string]interface{} {
+	t.Helper()
+	var mu sync.Mutex
+	calls := make(map[string][]map[string]interface{})
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		var batch []map[string]interface{}
+		if err := json.Unmarshal(params, &batch); err != nil {
+			var p map[string]interface{}
+			_ = json.Unmarshal(params, &p)
+			batch = []map[string]interface{}{p}
+		}
+		mu.Lock()
+		calls[method] = append(calls[method], batch...)
+		mu.Unlock()
+
+		ids := make([]string, len(batch))
+		for i := range ids {
+			ids[i] = fmt.Sprint(i + 1)
+		}
+		return map[string]interface{}{"itemids": ids, "triggerids": ids}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	if mutate != nil {
+		mutate(c)
+	}
+	if err := c.createVulnersTemplateItems(context.Background(), "100"); err != nil {
+		t.Fatalf("createVulnersTemplateItems: %v", err)
+	}
+	return calls
+}
+
+func findByKey(params []map[string]interface{}, key string) map[string]interface{} {
+	for _, p := range params {
+		if p["key_"] == key {
+			return p
+		}
+	}
+	return nil
+}
+
+func TestCreateVulnersTemplateItems_RawJSONItem(t *testing.T) {
+	calls := recordCreates(t, nil)
+	if findByKey(calls["item.create"], "vulners.scan.raw") != nil {
+		t.Error("vulners.scan.raw created although push_raw_json is disabled")
+	}
+
+	calls = recordCreates(t, func(c *Client) { c.cfg.Scan.PushRawJSON = true })
+	item := findByKey(calls["item.create"], "vulners.scan.raw")
+	if item == nil {
+		t.Fatal("vulners.scan.raw not created with push_raw_json enabled")
+	}
+	if item["value_type"] != float64(4) {
+		t.Errorf("value_type = %v, want 4 (text)", item["value_type"])
+	}
+}
+
+func TestCreateVulnersTemplateItems_HashedPackageKeys(t *testing.T) {
+	calls := recordCreates(t, func(c *Client) { c.cfg.Naming.HashPackageKeys = true })
+
+	proto := findByKey(calls["itemprototype.create"], "vulners.packages[{#P.KEY}]")
+	if proto == nil {
+		t.Fatal("package item prototype does not use {#P.KEY}")
+	}
+	found := false
+	for _, tp := range calls["triggerprototype.create"] {
+		expr, _ := tp["expression"].(string)
+		if !strings.Contains(expr, "vulners.packages[") {
+			continue
+		}
+		found = true
+		if !strings.Contains(expr, "vulners.packages[{#P.KEY}]") {
+			t.Errorf("trigger expression does not use the hashed key: %s", expr)
+		}
+	}
+	if !found {
+		t.Error("no package trigger prototype created")
+	}
+}
+
+func TestCreateVulnersTemplateItems_CountItemsUnsignedByDefault(t *testing.T) {
+	protos := recordCreates(t, nil)["itemprototype.create"]
+	for _, key := range []string{"vulners.packages[{#P.NAME},{#P.VERSION},{#P.ARCH}]", "vulners.bulletins[{#B.ID}]"} {
+		proto := findByKey(protos, key)
+		if proto == nil {
+			t.Fatalf("prototype %s not created", key)
+		}
+		if proto["value_type"] != float64(3) {
+			t.Errorf("%s value_type = %v, want 3 (numeric unsigned host count)", key, proto["value_type"])
+		}
+		if name, _ := proto["name"].(string); !strings.HasSuffix(name, "affected hosts") {
+			t.Errorf("%s name = %q, want it to say it holds affected hosts", key, name)
+		}
+	}
+}
+
+func TestCreateVulnersTemplateItems_ScoreValueType(t *testing.T) {
+	tests := []struct {
+		valueType string
+		want      float64
+	}{
+		{"float", 0},
+		{"unsigned", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.valueType, func(t *testing.T) {
+			calls := recordCreates(t, func(c *Client) { c.cfg.Scan.ScoreItemValueType = tt.valueType })
+			protos := calls["itemprototype.create"]
+
+			for _, key := range []string{"vulners.packages[{#P.NAME},{#P.VERSION},{#P.ARCH}]", "vulners.bulletins[{#B.ID}]"} {
+				proto := findByKey(protos, key)
+				if proto == nil {
+					t.Fatalf("prototype %s not created", key)
+				}
+				if proto["value_type"] != tt.want {
+					t.Errorf("%s value_type = %v, want %v", key, proto["value_type"], tt.want)
+				}
+			}
+			// Host items hold CVSS scores and stay float either way.
+			if host := findByKey(protos, "vulners.hosts[{#H.ID}]"); host == nil || host["value_type"] != float64(0) {
+				t.Errorf("host prototype = %v, want value_type 0", host)
+			}
+		})
+	}
+}
+
+func TestCreateVulnersTemplateItems_PackageItemValueCVSS(t *testing.T) {
+	protos := recordCreates(t, func(c *Client) { c.cfg.Scan.PackageItemValue = "cvss" })["itemprototype.create"]
+	for _, key := range []string{"vulners.packages[{#P.NAME},{#P.VERSION},{#P.ARCH}]", "vulners.bulletins[{#B.ID}]"} {
+		proto := findByKey(protos, key)
+		if proto == nil {
+			t.Fatalf("prototype %s not created", key)
+		}
+		// Scores are fractional, so the unsigned default must not apply.
+		if proto["value_type"] != float64(0) {
+			t.Errorf("%s value_type = %v, want 0 (float)", key, proto["value_type"])
+		}
+		if name, _ := proto["name"].(string); !strings.HasSuffix(name, "CVSS Score") {
+			t.Errorf("%s name = %q, want a CVSS Score item", key, name)
+		}
+	}
+}
+
+func TestCreateVulnersTemplateItems_EnabledLLD(t *testing.T) {
+	calls := recordCreates(t, func(c *Client) { c.cfg.Scan.EnabledLLD = []string{"hosts"} })
+
+	rules := calls["discoveryrule.create"]
+	for _, key := range []string{"vulners.hosts_lld", "vulners.groups_lld"} {
+		if findByKey(rules, key) == nil {
+			t.Errorf("discovery rule %s not created", key)
+		}
+	}
+	for _, key := range []string{"vulners.packages_lld", "vulners.bulletins_lld"} {
+		if findByKey(rules, key) != nil {
+			t.Errorf("disabled discovery rule %s was created", key)
+		}
+	}
+
+	protos := calls["itemprototype.create"]
+	if findByKey(protos, "vulners.hosts[{#H.ID}]") == nil {
+		t.Error("host item prototype not created")
+	}
+	if findByKey(protos, "vulners.bulletins[{#B.ID}]") != nil {
+		t.Error("bulletin item prototype created for a disabled rule")
+	}
+	if triggers := calls["triggerprototype.create"]; len(triggers) != 1 {
+		t.Errorf("created %d trigger prototypes, want only the host one", len(triggers))
+	}
+}
+
+func TestCreateVulnersTemplateItems_LLDLifetime(t *testing.T) {
+	rules := recordCreates(t, func(c *Client) { c.cfg.Scan.LLDLifetime = "7d" })["discoveryrule.create"]
+	if len(rules) == 0 {
+		t.Fatal("no discovery rules created")
+	}
+	for _, rule := range rules {
+		if rule["lifetime"] != "7d" {
+			t.Errorf("%v lifetime = %v, want 7d", rule["key_"], rule["lifetime"])
+		}
+	}
+}
+
+func TestCreateVulnersTemplateItems_CompressLLD(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		rules := recordCreates(t, func(c *Client) { c.cfg.Scan.CompressLLD = compress })["discoveryrule.create"]
+		if len(rules) == 0 {
+			t.Fatal("no discovery rules created")
+		}
+		for _, rule := range rules {
+			steps, _ := rule["preprocessing"].([]interface{})
+			if !compress {
+				if steps != nil {
+					t.Errorf("%v has preprocessing without compress_lld", rule["key_"])
+				}
+				continue
+			}
+			if len(steps) != 1 {
+				t.Fatalf("%v has %d preprocessing steps, want 1", rule["key_"], len(steps))
+			}
+			step := steps[0].(map[string]interface{})
+			if step["type"] != float64(preprocessingJavaScript) || step["params"] != lldDecompressScript {
+				t.Errorf("%v preprocessing = type %v, want the decompress script", rule["key_"], step["type"])
+			}
+		}
+	}
+}
+
+func TestCreateMany_Batches(t *testing.T) {
+	var methods []string
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		methods = append(methods, method)
+		return map[string]interface{}{"itemids": []string{"11", "12", "13"}}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	ids := c.createMany(context.Background(), "item.create", "itemids", []map[string]interface{}{
+		{"key_": "a"}, {"key_": "b"}, {"key_": "c"},
+	})
+
+	if len(methods) != 1 {
+		t.Errorf("expected a single batched call, got %d", len(methods))
+	}
+	if fmt.Sprint(ids) != "[11 12 13]" {
+		t.Errorf("ids = %v, want [11 12 13]", ids)
+	}
+}
+
+func TestCreateMany_FallsBackPerObject(t *testing.T) {
+	var calls int
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		calls++
+		var p map[string]interface{}
+		if err := json.Unmarshal(params, &p); err != nil {
+			// Batch call: reject as Zabbix does when one object already exists.
+			return nil, &APIError{Code: -32602, Message: "Invalid params.", Data: "Item already exists."}
+		}
+		if p["key_"] == "exists" {
+			return nil, &APIError{Code: -32602, Message: "Invalid params.", Data: "Item already exists."}
+		}
+		return map[string]interface{}{"itemids": []string{"2" + p["key_"].(string)}}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	ids := c.createMany(context.Background(), "item.create", "itemids", []map[string]interface{}{
+		{"key_": "1"}, {"key_": "exists"}, {"key_": "3"},
+	})
+
+	if calls != 4 {
+		t.Errorf("calls = %d, want 1 batch + 3 individual", calls)
+	}
+	if fmt.Sprint(ids) != "[21  23]" {
+		t.Errorf("ids = %q, want [21 \"\" 23]", ids)
+	}
+}
+
+func TestEnsureVirtualHostsCtx_ForceUsesMassUpdate(t *testing.T) {
+	var methods []string
+	var massParams map[string]interface{}
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		methods = append(methods, method)
+		switch method {
+		case "hostgroup.get":
+			return []map[string]string{{"groupid": "5", "name": "Vulners"}}, nil
+		case "template.get":
+			return []map[string]string{{"templateid": "100", "host": "Vulners"}}, nil
+		case "host.get":
+			return []map[string]string{
+				{"hostid": "1", "host": "vulners.hosts"},
+				{"hostid": "2", "host": "vulners.packages"},
+				{"hostid": "3", "host": "vulners.bulletins"},
+			}, nil
+		case "host.massupdate":
+			_ = json.Unmarshal(params, &massParams)
+			return map[string]interface{}{"hostids": []string{"1", "2", "3"}}, nil
+		case "host.create":
+			return map[string]interface{}{"hostids": []string{"4"}}, nil
+		default:
+			return []interface{}{}, nil
+		}
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	c.apiVersion = "5.0.0" // skip template-group and force-recreate branches
+	if err := c.EnsureVirtualHostsCtx(context.Background(), false); err != nil {
+		t.Fatalf("EnsureVirtualHostsCtx: %v", err)
+	}
+
+	count := func(m string) int {
+		n := 0
+		for _, got := range methods {
+			if got == m {
+				n++
+			}
+		}
+		return n
+	}
+	if count("host.get") != 1 {
+		t.Errorf("host.get calls = %d, want 1", count("host.get"))
+	}
+	if count("host.create") != 1 {
+		t.Errorf("host.create calls = %d, want 1 (statistics host missing)", count("host.create"))
+	}
+	if count("host.massupdate") != 0 {
+		t.Error("host.massupdate called without force")
+	}
+
+	methods = nil
+	if err := c.EnsureVirtualHostsCtx(context.Background(), true); err != nil {
+		t.Fatalf("EnsureVirtualHostsCtx(force): %v", err)
+	}
+	if count("host.massupdate") != 1 || count("host.update") != 0 {
+		t.Errorf("methods = %v, want a single host.massupdate", methods)
+	}
+	if hosts, _ := massParams["hosts"].([]interface{}); len(hosts) != 3 {
+		t.Errorf("massupdate hosts = %v, want 3", massParams["hosts"])
+	}
+	macros := fmt.Sprint(massParams["macros"])
+	for _, want := range []string{"{$SCORE.MIN}", "{$SCORE.CRIT} value:9"} {
+		if !strings.Contains(macros, want) {
+			t.Errorf("massupdate macros = %s, missing %s", macros, want)
+		}
+	}
+}
+
+func TestCreateVulnersTemplateItems_RerunCreatesOnlyMissing(t *testing.T) {
+	// A first run got as far as creating everything but one item prototype
+	// and one statistics item.
+	created := recordCreates(t, nil)
+	existing := func(method, idField, nameField, skip string) []map[string]string {
+		var out []map[string]string
+		for _, o := range created[method] {
+			name := fmt.Sprint(o[nameField])
+			if name != skip {
+				out = append(out, map[string]string{idField: "id-" + name, nameField: name})
+			}
+		}
+		return out
+	}
+
+	var mu sync.Mutex
+	creates := make(map[string][]map[string]interface{})
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		switch method {
+		case "discoveryrule.get":
+			return existing("discoveryrule.create", "itemid", "key_", ""), nil
+		case "itemprototype.get":
+			return existing("itemprototype.create", "itemid", "key_", "vulners.bulletins[{#B.ID}]"), nil
+		case "item.get":
+			return existing("item.create", "itemid", "key_", "vulners.Maximum"), nil
+		case "triggerprototype.get":
+			return existing("triggerprototype.create", "triggerid", "description", ""), nil
+		}
+		var batch []map[string]interface{}
+		_ = json.Unmarshal(params, &batch)
+		mu.Lock()
+		creates[method] = append(creates[method], batch...)
+		mu.Unlock()
+		return map[string]interface{}{"itemids": []string{"1"}, "triggerids": []string{"1"}}, nil
+	})
+	defer ts.Close()
+
+	if err := newTestClient(t, ts).createVulnersTemplateItems(context.Background(), "100"); err != nil {
+		t.Fatalf("createVulnersTemplateItems: %v", err)
+	}
+
+	if len(creates) != 2 || len(creates["itemprototype.create"]) != 1 || len(creates["item.create"]) != 1 {
+		t.Fatalf("creates = %v, want one item prototype and one item", creates)
+	}
+	proto := creates["itemprototype.create"][0]
+	if proto["key_"] != "vulners.bulletins[{#B.ID}]" || proto["ruleid"] != "id-vulners.bulletins_lld" {
+		t.Errorf("item prototype = %v, want vulners.bulletins[{#B.ID}] on the existing rule", proto)
+	}
+	if key := creates["item.create"][0]["key_"]; key != "vulners.Maximum" {
+		t.Errorf("item = %v, want vulners.Maximum", key)
+	}
+}
@@ -0,0 +1,121 @@
+package zabbix
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+)
+
+// Template export formats.
+const (
+	ExportXML  = "xml"
+	ExportYAML = "yaml"
+)
+
+// exportVersion is the Zabbix import format version of exported templates.
+// Zabbix 6.0 and later import it, converting groups to template groups.
+const exportVersion = "6.0"
+
+// Item value types as named in the Zabbix import format.
+var exportValueTypes = map[int]string{
+	valueTypeFloat:    "FLOAT",
+	valueTypeChar:     "CHAR",
+	valueTypeUnsigned: "UNSIGNED",
+	valueTypeText:     "TEXT",
+}
+
+type exportDocument struct {
+	XMLName   xml.Name         `xml:"zabbix_export" yaml:"-"`
+	Version   string           `xml:"version" yaml:"version"`
+	Groups    []exportGroup    `xml:"groups>group" yaml:"groups"`
+	Templates []exportTemplate `xml:"templates>template" yaml:"templates"`
+}
+
+type exportGroup struct {
+	Name string `xml:"name" yaml:"name"`
+}
+
+type exportTemplate struct {
+	UUID     string        `xml:"uuid" yaml:"uuid"`
+	Template string        `xml:"template" yaml:"template"`
+	Name     string        `xml:"name" yaml:"name"`
+	Groups   []exportGroup `xml:"groups>group" yaml:"groups"`
+	Items    []exportItem  `xml:"items>item" yaml:"items"`
+}
+
+// exportItem leaves out fields at their import defaults, such as the
+// Zabbix agent item type.
+type exportItem struct {
+	UUID        string `xml:"uuid" yaml:"uuid"`
+	Name        string `xml:"name" yaml:"name"`
+	Key         string `xml:"key" yaml:"key"`
+	Delay       string `xml:"delay" yaml:"delay"`
+	Trends      string `xml:"trends" yaml:"trends"`
+	ValueType   string `xml:"value_type" yaml:"value_type"`
+	Description string `xml:"description" yaml:"description"`
+}
+
+// ExportOSReportTemplate renders the OS-Report template, with the names from
+// cfg, as a Zabbix import file in the given format (ExportXML or ExportYAML).
+// Importing it creates the same template prepare -t creates through the API.
+func ExportOSReportTemplate(cfg *config.Config, format string) ([]byte, error) {
+	group := exportGroup{Name: cfg.Scan.TemplateGroupName}
+	tmpl := exportTemplate{
+		UUID:     exportUUID(cfg.Scan.OSReportTemplate),
+		Template: cfg.Scan.OSReportTemplate,
+		Name:     cfg.Scan.OSReportVisibleName,
+		Groups:   []exportGroup{group},
+	}
+	for _, item := range osReportItems {
+		tmpl.Items = append(tmpl.Items, exportItem{
+			UUID:        exportUUID(cfg.Scan.OSReportTemplate, item.key),
+			Name:        item.name,
+			Key:         item.key,
+			Delay:       osReportDelay,
+			Trends:      "0", // text values have no trends
+			ValueType:   exportValueTypes[item.valueType],
+			Description: item.description,
+		})
+	}
+	doc := exportDocument{
+		Version:   exportVersion,
+		Groups:    []exportGroup{group},
+		Templates: []exportTemplate{tmpl},
+	}
+
+	switch format {
+	case ExportXML:
+		out, err := xml.MarshalIndent(doc, "", "    ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode template: %w", err)
+		}
+		return append([]byte(xml.Header), append(out, '\n')...), nil
+	case ExportYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(map[string]exportDocument{"zabbix_export": doc}); err != nil {
+			return nil, fmt.Errorf("failed to encode template: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unknown template export format %q (valid: %s, %s)", format, ExportXML, ExportYAML)
+	}
+}
+
+// exportUUID derives the version 4 UUID Zabbix requires on imported objects
+// from their names, so repeated exports stay identical and re-imports update
+// the same objects.
+func exportUUID(names ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(names, "\x00")))
+	sum[6] = sum[6]&0x0f | 0x40
+	sum[8] = sum[8]&0x3f | 0x80
+	return hex.EncodeToString(sum[:16])
+}
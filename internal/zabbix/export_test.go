@@ -0,0 +1,77 @@
+package zabbix
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+)
+
+// uuidPattern matches the UUIDv4 without dashes that Zabbix imports expect.
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{12}4[0-9a-f]{3}[89ab][0-9a-f]{15}$`)
+
+func TestExportOSReportTemplate(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Scan.OSReportTemplate = "tmpl.custom"
+
+	check := func(t *testing.T, doc exportDocument) {
+		t.Helper()
+		if doc.Version != exportVersion || len(doc.Templates) != 1 {
+			t.Fatalf("doc = %+v, want one template in format %s", doc, exportVersion)
+		}
+		tmpl := doc.Templates[0]
+		if tmpl.Template != "tmpl.custom" || tmpl.Groups[0].Name != cfg.Scan.TemplateGroupName {
+			t.Errorf("template = %+v, want the configured names", tmpl)
+		}
+		if !uuidPattern.MatchString(tmpl.UUID) {
+			t.Errorf("template uuid %q is not a Zabbix UUID", tmpl.UUID)
+		}
+		got := make(map[string]string)
+		for _, item := range tmpl.Items {
+			if !uuidPattern.MatchString(item.UUID) || item.UUID == tmpl.UUID {
+				t.Errorf("item %s uuid %q is not a distinct Zabbix UUID", item.Key, item.UUID)
+			}
+			got[item.Key] = item.ValueType
+		}
+		if got["system.sw.os"] != "CHAR" || got["system.sw.packages"] != "TEXT" || len(got) != 2 {
+			t.Errorf("items = %v, want system.sw.os CHAR and system.sw.packages TEXT", got)
+		}
+	}
+
+	t.Run("xml", func(t *testing.T) {
+		out, err := ExportOSReportTemplate(cfg, ExportXML)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(out), "<?xml") {
+			t.Errorf("output does not start with an XML header:\n%s", out)
+		}
+		var doc exportDocument
+		if err := xml.Unmarshal(out, &doc); err != nil {
+			t.Fatalf("unmarshal: %v\n%s", err, out)
+		}
+		check(t, doc)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		out, err := ExportOSReportTemplate(cfg, ExportYAML)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var wrapped struct {
+			Export exportDocument `yaml:"zabbix_export"`
+		}
+		if err := yaml.Unmarshal(out, &wrapped); err != nil {
+			t.Fatalf("unmarshal: %v\n%s", err, out)
+		}
+		check(t, wrapped.Export)
+	})
+
+	if _, err := ExportOSReportTemplate(cfg, "json"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
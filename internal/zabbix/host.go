@@ -39,7 +39,7 @@ func (c *Client) GetHostsWithTemplateCtx(ctx context.Context, templateName strin
 
 	// Get hosts linked to this template (only monitored hosts, matching Python behavior)
 	hostParams := map[string]interface{}{
-		"output":                []string{"hostid", "host", "name", "status"},
+		"output":                []string{"hostid", "host", "name", "status", "maintenance_status", "maintenanceid"},
 		"templateids":           templateID,
 		"monitored_hosts":       true,
 		"selectInterfaces":      []string{"interfaceid", "ip", "dns", "port", "type", "main", "useip"},
@@ -87,6 +87,28 @@ func (c *Client) GetHostByIDCtx(ctx context.Context, hostID string) (*Host, erro
 	return &hosts[0], nil
 }
 
+// GetHostsByIDsCtx returns the hosts with the given IDs in a single
+// host.get call. IDs that do not exist are silently absent from the result.
+func (c *Client) GetHostsByIDsCtx(ctx context.Context, hostIDs []string) ([]Host, error) {
+	if len(hostIDs) == 0 {
+		return nil, nil
+	}
+	params := map[string]interface{}{
+		"output":                []string{"hostid", "host", "name", "status"},
+		"hostids":               hostIDs,
+		"selectInterfaces":      []string{"interfaceid", "ip", "dns", "port", "type", "main", "useip"},
+		"selectGroups":          []string{"groupid", "name"},
+		"selectParentTemplates": []string{"templateid", "host", "name"},
+	}
+
+	result, err := c.callWithContext(ctx, "host.get", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hosts: %w", err)
+	}
+
+	return parseHosts(result)
+}
+
 // GetHostByName returns a host by its technical name
 func (c *Client) GetHostByName(name string) (*Host, error) {
 	return c.GetHostByNameCtx(context.Background(), name)
@@ -120,19 +142,36 @@ func (c *Client) GetHostByNameCtx(ctx context.Context, name string) (*Host, erro
 }
 
 // GetHostItems returns items for a host by key pattern
-func (c *Client) GetHostItems(hostID string, keyPattern string) ([]Item, error) {
-	return c.GetHostItemsCtx(context.Background(), hostID, keyPattern)
+func (c *Client) GetHostItems(hostID string, keyPattern string, tags ...ItemTag) ([]Item, error) {
+	return c.GetHostItemsCtx(context.Background(), hostID, keyPattern, tags...)
 }
 
-// GetHostItemsCtx returns items for a host by key pattern using context
-func (c *Client) GetHostItemsCtx(ctx context.Context, hostID string, keyPattern string) ([]Item, error) {
+// GetHostItemsCtx returns items for a host by key pattern using context.
+// With tags, items must match every tag name given, and any one of the
+// tags sharing a name (Zabbix And/Or evaluation). This finds items whose
+// keys were customized; an empty keyPattern then selects by tag only.
+func (c *Client) GetHostItemsCtx(ctx context.Context, hostID string, keyPattern string, tags ...ItemTag) ([]Item, error) {
 	params := map[string]interface{}{
-		"output":  []string{"itemid", "hostid", "name", "key_", "lastvalue", "value_type", "state"},
+		"output":  []string{"itemid", "hostid", "name", "key_", "lastvalue", "lastclock", "value_type", "state"},
 		"hostids": hostID,
-		"search": map[string]interface{}{
+	}
+	if keyPattern != "" {
+		params["search"] = map[string]interface{}{
 			"key_": keyPattern,
-		},
-		"searchWildcardsEnabled": true,
+		}
+		params["searchWildcardsEnabled"] = true
+	}
+	if len(tags) > 0 {
+		filters := make([]map[string]string, len(tags))
+		for i, tag := range tags {
+			operator := "0" // contains
+			if tag.Exact {
+				operator = "1" // equals
+			}
+			filters[i] = map[string]string{"tag": tag.Tag, "value": tag.Value, "operator": operator}
+		}
+		params["evaltype"] = 0 // And/Or: tags sharing a name are ORed
+		params["tags"] = filters
 	}
 
 	result, err := c.callWithContext(ctx, "item.get", params)
@@ -193,6 +232,92 @@ func (c *Client) CreateHostCtx(ctx context.Context, host *Host, groupIDs []strin
 	return hostID, nil
 }
 
+// GetHostsWithInventoryCtx returns monitored hosts whose inventory is
+// enabled, with the OS and software inventory fields selected.
+func (c *Client) GetHostsWithInventoryCtx(ctx context.Context) ([]Host, error) {
+	hostParams := map[string]interface{}{
+		"output":           []string{"hostid", "host", "name", "status", "maintenance_status", "maintenanceid"},
+		"monitored_hosts":  true,
+		"withInventory":    true,
+		"selectInterfaces": []string{"interfaceid", "ip", "dns", "port", "type", "main", "useip"},
+		"selectGroups":     []string{"groupid", "name"},
+		"selectInventory":  []string{"os", "os_full", "software_full"},
+	}
+
+	result, err := c.callWithContext(ctx, "host.get", hostParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hosts: %w", err)
+	}
+
+	return parseHosts(result)
+}
+
+// GetHostsMissingTemplateCtx returns the monitored hosts that do not have
+// the template linked, leaving out the ZTC virtual hosts.
+func (c *Client) GetHostsMissingTemplateCtx(ctx context.Context, templateName string) ([]Host, error) {
+	linked, err := c.GetHostsWithTemplateCtx(ctx, templateName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.callWithContext(ctx, "host.get", map[string]interface{}{
+		"output":          []string{"hostid", "host", "name", "status"},
+		"monitored_hosts": true,
+		"selectGroups":    []string{"groupid", "name"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hosts: %w", err)
+	}
+	all, err := parseHosts(result)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := map[string]bool{
+		c.cfg.Naming.HostsHost:      true,
+		c.cfg.Naming.PackagesHost:   true,
+		c.cfg.Naming.BulletinsHost:  true,
+		c.cfg.Naming.StatisticsHost: true,
+	}
+	for _, h := range linked {
+		skip[h.Host] = true
+	}
+
+	var missing []Host
+	for _, h := range all {
+		if !skip[h.Host] {
+			missing = append(missing, h)
+		}
+	}
+	return missing, nil
+}
+
+// GetMaintenancesCtx returns the maintenances with the given IDs.
+func (c *Client) GetMaintenancesCtx(ctx context.Context, maintenanceIDs []string) ([]Maintenance, error) {
+	if len(maintenanceIDs) == 0 {
+		return nil, nil
+	}
+	params := map[string]interface{}{
+		"output":         []string{"maintenanceid", "name"},
+		"maintenanceids": maintenanceIDs,
+	}
+
+	result, err := c.callWithContext(ctx, "maintenance.get", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenances: %w", err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	var maintenances []Maintenance
+	if err := json.Unmarshal(data, &maintenances); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal maintenances: %w", err)
+	}
+	return maintenances, nil
+}
+
 // parseHosts parses the API response into a slice of Host
 func parseHosts(result interface{}) ([]Host, error) {
 	data, err := json.Marshal(result)
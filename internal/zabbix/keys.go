@@ -0,0 +1,80 @@
+package zabbix
+
+import (
+	"crypto/sha1" //nolint:gosec // G505: used for a stable short identifier, not for security
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// Item prototype keys of the hosts and bulletins discovery rules; the
+// packages key depends on naming.hash_package_keys (PackagePrototypeKey).
+const (
+	HostsPrototypeKey     = "vulners.hosts[{#H.ID}]"
+	BulletinsPrototypeKey = "vulners.bulletins[{#B.ID}]"
+)
+
+// HostItemKey returns the trapper item key for a host's score.
+func HostItemKey(hostID string) string {
+	return fmt.Sprintf("vulners.hosts[%s]", hostID)
+}
+
+// BulletinItemKey returns the trapper item key for a bulletin.
+func BulletinItemKey(id string) string {
+	return fmt.Sprintf("vulners.bulletins[%s]", id)
+}
+
+// packageKeyHashLen is the number of hex characters of the SHA-1 digest
+// kept in hashed package keys.
+const packageKeyHashLen = 16
+
+// PackageKeyHash returns a short, deterministic identifier for a package,
+// used in place of name/version/arch when naming.hash_package_keys is set.
+func PackageKeyHash(name, version, arch string) string {
+	sum := sha1.Sum([]byte(name + "\x00" + version + "\x00" + arch)) //nolint:gosec // G401: see import
+	return hex.EncodeToString(sum[:])[:packageKeyHashLen]
+}
+
+// PackageItemKey returns the trapper item key for a package.
+func PackageItemKey(name, version, arch string, hashed bool) string {
+	if hashed {
+		return fmt.Sprintf("vulners.packages[%s]", PackageKeyHash(name, version, arch))
+	}
+	return fmt.Sprintf("vulners.packages[%s,%s,%s]", name, version, arch)
+}
+
+// PackagePrototypeKey returns the item prototype key matching PackageItemKey.
+func PackagePrototypeKey(hashed bool) string {
+	if hashed {
+		return "vulners.packages[{#P.KEY}]"
+	}
+	return "vulners.packages[{#P.NAME},{#P.VERSION},{#P.ARCH}]"
+}
+
+// GroupStatsMetrics lists the per-host-group statistics sent by
+// "ztc scan --group-stats".
+var GroupStatsMetrics = []string{
+	"total_hosts", "vuln_hosts", "total_vulns", "total_bulletins",
+	"total_cves", "max_score", "avg_score", "fleet_risk",
+}
+
+// GroupStatsItemKey returns the statistics host item key for one metric of
+// a host group.
+func GroupStatsItemKey(groupID, metric string) string {
+	return fmt.Sprintf("vulners.group.stats[%s,%s]", groupID, metric)
+}
+
+// lldMacro matches an LLD macro such as {#H.ID}.
+var lldMacro = regexp.MustCompile(`\{#[A-Z0-9_.]+\}`)
+
+// ExpandPrototypeKey returns the item key Zabbix discovers from the
+// prototype key for one LLD row. Macros missing from the row are left in
+// place, as Zabbix does.
+func ExpandPrototypeKey(key string, row map[string]interface{}) string {
+	return lldMacro.ReplaceAllStringFunc(key, func(macro string) string {
+		if v, ok := row[macro]; ok {
+			return fmt.Sprint(v)
+		}
+		return macro
+	})
+}
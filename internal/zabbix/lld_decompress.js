@@ -0,0 +1,250 @@
+// JavaScript preprocessing step of the Vulners discovery rules with
+// scan.compress_lld. It turns the base64-encoded gzipped JSON sent by ztc
+// back into JSON; plain JSON passes through unchanged. This is the body of
+// the preprocessing function: Zabbix passes the item value as "value".
+// Plain ES5 plus Uint8Array, as supported by Zabbix's Duktape engine.
+
+if (/^\s*\{/.test(value)) {
+    return value;
+}
+
+var B64 = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/';
+
+function base64Decode(s) {
+    var lookup = {}, i;
+    for (i = 0; i < B64.length; i++) {
+        lookup[B64.charAt(i)] = i;
+    }
+    s = s.replace(/[^A-Za-z0-9+\/]/g, '');
+    var out = new Uint8Array(Math.floor(s.length * 3 / 4)), n = 0, acc = 0, bits = 0;
+    for (i = 0; i < s.length; i++) {
+        acc = (acc << 6) | lookup[s.charAt(i)];
+        bits += 6;
+        if (bits >= 8) {
+            bits -= 8;
+            out[n++] = (acc >> bits) & 0xff;
+        }
+    }
+    return out.subarray(0, n);
+}
+
+// Inflate (RFC 1951), after zlib's puff.c.
+var LEN_BASE = [3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 15, 17, 19, 23, 27, 31, 35, 43, 51, 59, 67, 83, 99, 115, 131, 163, 195, 227, 258];
+var LEN_EXTRA = [0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 0];
+var DIST_BASE = [1, 2, 3, 4, 5, 7, 9, 13, 17, 25, 33, 49, 65, 97, 129, 193, 257, 385, 513, 769, 1025, 1537, 2049, 3073,
+    4097, 6145, 8193, 12289, 16385, 24577];
+var DIST_EXTRA = [0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13];
+var CL_ORDER = [16, 17, 18, 0, 8, 7, 9, 6, 10, 5, 11, 4, 12, 3, 13, 2, 14, 1, 15];
+
+function inflate(src, pos) {
+    var out = new Uint8Array(Math.max(src.length * 4, 1024)), outLen = 0;
+    var bitBuf = 0, bitCnt = 0;
+
+    function bits(need) {
+        while (bitCnt < need) {
+            if (pos >= src.length) {
+                throw 'compressed LLD value is truncated';
+            }
+            bitBuf |= src[pos++] << bitCnt;
+            bitCnt += 8;
+        }
+        var v = bitBuf & ((1 << need) - 1);
+        bitBuf >>>= need;
+        bitCnt -= need;
+        return v;
+    }
+
+    function put(b) {
+        if (outLen === out.length) {
+            var grown = new Uint8Array(out.length * 2);
+            grown.set(out);
+            out = grown;
+        }
+        out[outLen++] = b;
+    }
+
+    function huffman(lengths) {
+        var count = [], offs = [], symbol = [], len, sym;
+        for (len = 0; len <= 15; len++) {
+            count[len] = 0;
+        }
+        for (sym = 0; sym < lengths.length; sym++) {
+            count[lengths[sym]]++;
+        }
+        offs[1] = 0;
+        for (len = 1; len < 15; len++) {
+            offs[len + 1] = offs[len] + count[len];
+        }
+        for (sym = 0; sym < lengths.length; sym++) {
+            if (lengths[sym] !== 0) {
+                symbol[offs[lengths[sym]]++] = sym;
+            }
+        }
+        return {count: count, symbol: symbol};
+    }
+
+    function decode(h) {
+        var code = 0, first = 0, index = 0, len, count;
+        for (len = 1; len <= 15; len++) {
+            code |= bits(1);
+            count = h.count[len];
+            if (code - count < first) {
+                return h.symbol[index + (code - first)];
+            }
+            index += count;
+            first = (first + count) << 1;
+            code <<= 1;
+        }
+        throw 'invalid Huffman code in compressed LLD value';
+    }
+
+    function codes(lit, dist) {
+        var sym, len, d, i;
+        for (;;) {
+            sym = decode(lit);
+            if (sym < 256) {
+                put(sym);
+            } else if (sym === 256) {
+                return;
+            } else {
+                sym -= 257;
+                len = LEN_BASE[sym] + bits(LEN_EXTRA[sym]);
+                sym = decode(dist);
+                d = DIST_BASE[sym] + bits(DIST_EXTRA[sym]);
+                if (d > outLen) {
+                    throw 'invalid distance in compressed LLD value';
+                }
+                for (i = 0; i < len; i++) {
+                    put(out[outLen - d]);
+                }
+            }
+        }
+    }
+
+    var fixedLit, fixedDist;
+    function fixed() {
+        if (!fixedLit) {
+            var lengths = [], i;
+            for (i = 0; i < 288; i++) {
+                lengths[i] = i < 144 ? 8 : i < 256 ? 9 : i < 280 ? 7 : 8;
+            }
+            fixedLit = huffman(lengths);
+            lengths = [];
+            for (i = 0; i < 30; i++) {
+                lengths[i] = 5;
+            }
+            fixedDist = huffman(lengths);
+        }
+        codes(fixedLit, fixedDist);
+    }
+
+    function dynamic() {
+        var nlen = bits(5) + 257, ndist = bits(5) + 1, ncode = bits(4) + 4;
+        var lengths = [], i, sym, rep, prev;
+        for (i = 0; i < 19; i++) {
+            lengths[CL_ORDER[i]] = i < ncode ? bits(3) : 0;
+        }
+        var lencode = huffman(lengths);
+        lengths = [];
+        while (lengths.length < nlen + ndist) {
+            sym = decode(lencode);
+            if (sym < 16) {
+                lengths.push(sym);
+                continue;
+            }
+            prev = 0;
+            if (sym === 16) {
+                if (lengths.length === 0) {
+                    throw 'invalid code lengths in compressed LLD value';
+                }
+                prev = lengths[lengths.length - 1];
+                rep = 3 + bits(2);
+            } else if (sym === 17) {
+                rep = 3 + bits(3);
+            } else {
+                rep = 11 + bits(7);
+            }
+            while (rep--) {
+                lengths.push(prev);
+            }
+        }
+        codes(huffman(lengths.slice(0, nlen)), huffman(lengths.slice(nlen, nlen + ndist)));
+    }
+
+    var last, type, len;
+    do {
+        last = bits(1);
+        type = bits(2);
+        if (type === 0) {
+            bitBuf = 0;
+            bitCnt = 0;
+            if (pos + 4 > src.length) {
+                throw 'compressed LLD value is truncated';
+            }
+            len = src[pos] | (src[pos + 1] << 8);
+            pos += 4;
+            if (pos + len > src.length) {
+                throw 'compressed LLD value is truncated';
+            }
+            while (len--) {
+                put(src[pos++]);
+            }
+        } else if (type === 1) {
+            fixed();
+        } else if (type === 2) {
+            dynamic();
+        } else {
+            throw 'invalid block type in compressed LLD value';
+        }
+    } while (!last);
+    return out.subarray(0, outLen);
+}
+
+// gunzip skips the gzip header (RFC 1952) and inflates the member.
+function gunzip(src) {
+    if (src.length < 18 || src[0] !== 0x1f || src[1] !== 0x8b || src[2] !== 8) {
+        throw 'LLD value is neither JSON nor compressed JSON';
+    }
+    var flags = src[3], pos = 10;
+    if (flags & 4) {
+        pos += 2 + (src[pos] | (src[pos + 1] << 8));
+    }
+    if (flags & 8) {
+        while (src[pos++] !== 0) {}
+    }
+    if (flags & 16) {
+        while (src[pos++] !== 0) {}
+    }
+    if (flags & 2) {
+        pos += 2;
+    }
+    return inflate(src, pos);
+}
+
+function utf8Decode(bytes) {
+    var parts = [], chunk = [], i = 0, b, c;
+    while (i < bytes.length) {
+        b = bytes[i++];
+        if (b < 0x80) {
+            c = b;
+        } else if (b < 0xe0) {
+            c = ((b & 0x1f) << 6) | (bytes[i++] & 0x3f);
+        } else if (b < 0xf0) {
+            c = ((b & 0x0f) << 12) | ((bytes[i++] & 0x3f) << 6) | (bytes[i++] & 0x3f);
+        } else {
+            c = ((b & 0x07) << 18) | ((bytes[i++] & 0x3f) << 12) | ((bytes[i++] & 0x3f) << 6) | (bytes[i++] & 0x3f);
+            c -= 0x10000;
+            chunk.push(0xd800 + (c >> 10));
+            c = 0xdc00 + (c & 0x3ff);
+        }
+        chunk.push(c);
+        if (chunk.length >= 8192) {
+            parts.push(String.fromCharCode.apply(null, chunk));
+            chunk = [];
+        }
+    }
+    parts.push(String.fromCharCode.apply(null, chunk));
+    return parts.join('');
+}
+
+return utf8Decode(gunzip(base64Decode(value)));
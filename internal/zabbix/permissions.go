@@ -0,0 +1,221 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Zabbix user types as returned by user.checkAuthentication.
+const (
+	userTypeUser       = "1"
+	userTypeAdmin      = "2"
+	userTypeSuperAdmin = "3"
+)
+
+// PermissionCheck lists what a set of operations needs from the API user.
+type PermissionCheck struct {
+	Methods    []string // API methods that will be called, e.g. "template.create"
+	HostGroups []string // host groups that must be writable, or creatable when missing
+}
+
+// PermissionProblem describes one thing the API user is not allowed to do.
+type PermissionProblem struct {
+	Subject string // API method or "host group NAME"
+	Reason  string
+}
+
+func (p PermissionProblem) String() string {
+	return p.Subject + ": " + p.Reason
+}
+
+// CheckPermissionsCtx verifies, before anything is created, that the
+// authenticated user can perform the operations in check. It inspects the
+// user type, the role's API method rules (Zabbix >= 5.2) and host group
+// write access, and returns one problem per missing permission.
+func (c *Client) CheckPermissionsCtx(ctx context.Context, check PermissionCheck) ([]PermissionProblem, error) {
+	user, err := c.currentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+	if user.Type == userTypeSuperAdmin {
+		return nil, nil
+	}
+
+	var problems []PermissionProblem
+
+	rules, err := c.roleAPIRules(ctx, user.RoleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user role: %w", err)
+	}
+
+	for _, method := range check.Methods {
+		if user.Type == userTypeUser && !strings.HasPrefix(method, "dashboard.") {
+			problems = append(problems, PermissionProblem{method, "user type \"User\" cannot modify configuration (Admin or Super admin required)"})
+			continue
+		}
+		if rules != nil && !rules.allows(method) {
+			problems = append(problems, PermissionProblem{method, "denied by the API method rules of the user role"})
+		}
+	}
+
+	if user.Type == userTypeAdmin && len(check.HostGroups) > 0 {
+		groupProblems, err := c.checkHostGroupAccess(ctx, check.HostGroups)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, groupProblems...)
+	}
+
+	return problems, nil
+}
+
+type authUser struct {
+	Type   string `json:"type"`
+	RoleID string `json:"roleid"`
+}
+
+// currentUser returns the type and role of the session's user. The call
+// carries the session ID, or the API token, in its params instead of auth.
+func (c *Client) currentUser(ctx context.Context) (*authUser, error) {
+	param := "sessionid"
+	if c.authViaToken {
+		param = "token"
+	}
+	result, err := c.callWithContext(ctx, "user.checkAuthentication", map[string]string{
+		param: c.authToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseAuthUser(result)
+}
+
+// apiRules is the API access part of a user role.
+type apiRules struct {
+	Access  string   `json:"api.access"`
+	Mode    string   `json:"api.mode"` // "0" = deny list, "1" = allow list
+	Methods []string `json:"api"`
+}
+
+// allows reports whether method passes the role's API rules. Patterns may
+// use "*" wildcards, e.g. "template.*" or "*.create".
+func (r *apiRules) allows(method string) bool {
+	if r.Access == "0" {
+		return false
+	}
+	matched := false
+	for _, pattern := range r.Methods {
+		if ok, _ := path.Match(pattern, method); ok {
+			matched = true
+			break
+		}
+	}
+	if r.Mode == "1" {
+		return matched
+	}
+	return !matched
+}
+
+// roleAPIRules fetches the API rules of the given role. It returns nil when
+// the Zabbix version has no roles.
+func (c *Client) roleAPIRules(ctx context.Context, roleID string) (*apiRules, error) {
+	if roleID == "" || c.getAPIVersionFloat() < 5.2 {
+		return nil, nil
+	}
+	result, err := c.callWithContext(ctx, "role.get", map[string]interface{}{
+		"output":      []string{"roleid"},
+		"roleids":     roleID,
+		"selectRules": "extend",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseRoleAPIRules(result)
+}
+
+// checkHostGroupAccess reports host groups an Admin user cannot write to.
+// Missing groups are also a problem because only Super admins create them.
+func (c *Client) checkHostGroupAccess(ctx context.Context, names []string) ([]PermissionProblem, error) {
+	get := func(editable bool) (map[string]bool, error) {
+		result, err := c.callWithContext(ctx, "hostgroup.get", map[string]interface{}{
+			"output":   []string{"groupid", "name"},
+			"filter":   map[string]interface{}{"name": names},
+			"editable": editable,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get host groups: %w", err)
+		}
+		groups, err := parseHostGroups(result)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string]bool, len(groups))
+		for _, g := range groups {
+			found[g.Name] = true
+		}
+		return found, nil
+	}
+
+	writable, err := get(true)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := get(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []PermissionProblem
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] || writable[name] {
+			continue
+		}
+		seen[name] = true
+		reason := "no write permission on this group"
+		if !visible[name] {
+			reason = "group does not exist and only a Super admin can create it"
+		}
+		problems = append(problems, PermissionProblem{"host group " + name, reason})
+	}
+	return problems, nil
+}
+
+// parseAuthUser parses a user.checkAuthentication response
+func parseAuthUser(result interface{}) (*authUser, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	var user authUser
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// parseRoleAPIRules parses a role.get response and returns the API rules of
+// the first role, or nil when there is none
+func parseRoleAPIRules(result interface{}) (*apiRules, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	var roles []struct {
+		Rules apiRules `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &roles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal roles: %w", err)
+	}
+
+	if len(roles) == 0 {
+		return nil, nil
+	}
+	return &roles[0].Rules, nil
+}
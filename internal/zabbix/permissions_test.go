@@ -0,0 +1,137 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func permissionServer(t *testing.T, userType string, rules map[string]interface{}, writable, visible []string) *Client {
+	t.Helper()
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		switch method {
+		case "user.checkAuthentication":
+			var p struct {
+				SessionID string `json:"sessionid"`
+			}
+			_ = json.Unmarshal(params, &p)
+			if p.SessionID != "test-token" {
+				return nil, &APIError{Code: -32602, Message: "Invalid params.", Data: "Session terminated, re-login, please."}
+			}
+			return map[string]string{"userid": "5", "type": userType, "roleid": "7"}, nil
+		case "role.get":
+			return []map[string]interface{}{{"roleid": "7", "rules": rules}}, nil
+		case "hostgroup.get":
+			var p struct {
+				Editable bool `json:"editable"`
+			}
+			_ = json.Unmarshal(params, &p)
+			names := visible
+			if p.Editable {
+				names = writable
+			}
+			groups := make([]map[string]string, len(names))
+			for i, n := range names {
+				groups[i] = map[string]string{"groupid": "1", "name": n}
+			}
+			return groups, nil
+		default:
+			return nil, &APIError{Code: -32601, Message: "unexpected", Data: method}
+		}
+	})
+	t.Cleanup(ts.Close)
+	return newTestClient(t, ts)
+}
+
+func problemSubjects(problems []PermissionProblem) string {
+	subjects := make([]string, len(problems))
+	for i, p := range problems {
+		subjects[i] = p.Subject
+	}
+	return strings.Join(subjects, ",")
+}
+
+func TestCheckPermissionsCtx(t *testing.T) {
+	check := PermissionCheck{
+		Methods:    []string{"template.create", "host.create", "dashboard.create"},
+		HostGroups: []string{"Templates", "Vulners"},
+	}
+	allowAll := map[string]interface{}{"api.access": "1", "api.mode": "0", "api": []string{}}
+
+	tests := []struct {
+		name     string
+		userType string
+		rules    map[string]interface{}
+		writable []string
+		visible  []string
+		want     string
+	}{
+		{"super admin", userTypeSuperAdmin, allowAll, nil, nil, ""},
+		{"user type", userTypeUser, allowAll, nil, nil, "template.create,host.create"},
+		{"admin with access", userTypeAdmin, allowAll, []string{"Templates", "Vulners"}, []string{"Templates", "Vulners"}, ""},
+		{"admin read-only and missing groups", userTypeAdmin, allowAll,
+			nil, []string{"Templates"}, "host group Templates,host group Vulners"},
+		{"deny list", userTypeAdmin,
+			map[string]interface{}{"api.access": "1", "api.mode": "0", "api": []string{"template.*"}},
+			[]string{"Templates", "Vulners"}, []string{"Templates", "Vulners"}, "template.create"},
+		{"allow list", userTypeAdmin,
+			map[string]interface{}{"api.access": "1", "api.mode": "1", "api": []string{"*.create"}},
+			[]string{"Templates", "Vulners"}, []string{"Templates", "Vulners"}, ""},
+		{"api disabled", userTypeAdmin,
+			map[string]interface{}{"api.access": "0"},
+			[]string{"Templates", "Vulners"}, []string{"Templates", "Vulners"}, "template.create,host.create,dashboard.create"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := permissionServer(t, tt.userType, tt.rules, tt.writable, tt.visible)
+			problems, err := c.CheckPermissionsCtx(context.Background(), check)
+			if err != nil {
+				t.Fatalf("CheckPermissionsCtx: %v", err)
+			}
+			if got := problemSubjects(problems); got != tt.want {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentUser_APIToken(t *testing.T) {
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		var p map[string]string
+		_ = json.Unmarshal(params, &p)
+		if method != "user.checkAuthentication" || p["token"] != "test-token" || p["sessionid"] != "" {
+			return nil, &APIError{Code: -32602, Message: "Invalid params.", Data: method}
+		}
+		return map[string]string{"userid": "5", "type": userTypeSuperAdmin}, nil
+	})
+	defer ts.Close()
+	c := newTestClient(t, ts)
+	c.authViaToken = true
+
+	user, err := c.currentUser(context.Background())
+	if err != nil {
+		t.Fatalf("currentUser: %v", err)
+	}
+	if user.Type != userTypeSuperAdmin {
+		t.Errorf("type = %q, want %q", user.Type, userTypeSuperAdmin)
+	}
+}
+
+func TestCheckPermissionsCtx_GroupReasons(t *testing.T) {
+	c := permissionServer(t, userTypeAdmin, map[string]interface{}{"api.access": "1"}, nil, []string{"Templates"})
+	problems, err := c.CheckPermissionsCtx(context.Background(), PermissionCheck{HostGroups: []string{"Templates", "Vulners"}})
+	if err != nil {
+		t.Fatalf("CheckPermissionsCtx: %v", err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("got %d problems, want 2", len(problems))
+	}
+	if !strings.Contains(problems[0].Reason, "no write permission") {
+		t.Errorf("Templates reason = %q", problems[0].Reason)
+	}
+	if !strings.Contains(problems[1].Reason, "does not exist") {
+		t.Errorf("Vulners reason = %q", problems[1].Reason)
+	}
+}
@@ -0,0 +1,255 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+)
+
+// updateReplay rewrites the replay fixtures from the current client. Review
+// the fixture diff like code: it shows every API call that changed.
+var updateReplay = flag.Bool("update", false, "rewrite the testdata/replay fixtures")
+
+// replayVersions are the Zabbix versions with a prepare fixture; they cover
+// each version branch of the client.
+var replayVersions = []string{"5.0.0", "5.4.0", "6.0.0", "6.4.0", "7.0.0"}
+
+// exchange is one JSON-RPC request and its response.
+type exchange struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *APIError       `json:"error,omitempty"`
+}
+
+// replayFixture is a recorded API session against one Zabbix version.
+type replayFixture struct {
+	Version   string     `json:"version"`
+	Exchanges []exchange `json:"exchanges"`
+}
+
+func replayPath(version string) string {
+	return filepath.Join("testdata", "replay", "prepare-"+version+".json")
+}
+
+// runPrepare logs in and creates every object "ztc prepare" creates, like
+// the command does on an empty Zabbix.
+func runPrepare(url string) error {
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.FrontURL = url
+	cfg.Zabbix.APIUser = "Admin"
+	cfg.Zabbix.APIPassword = "zabbix"
+
+	c, err := NewClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if err := c.EnsureOSReportTemplateCtx(ctx, false); err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+	if err := c.EnsureVirtualHostsCtx(ctx, false); err != nil {
+		return fmt.Errorf("virtual hosts: %w", err)
+	}
+	if err := c.EnsureDashboardCtx(ctx, false); err != nil {
+		return fmt.Errorf("dashboard: %w", err)
+	}
+	return c.Close()
+}
+
+// emptyZabbix answers like a Zabbix of the given version that has none of
+// the prepare objects yet: lookups find nothing and creates return new IDs.
+// It is used to record fixtures.
+func emptyZabbix(version string) func(method string, params json.RawMessage) (interface{}, *APIError) {
+	idKeys := map[string]string{
+		"hostgroup":        "groupids",
+		"templategroup":    "groupids",
+		"template":         "templateids",
+		"host":             "hostids",
+		"item":             "itemids",
+		"discoveryrule":    "itemids",
+		"itemprototype":    "itemids",
+		"triggerprototype": "triggerids",
+		"graph":            "graphids",
+		"dashboard":        "dashboardids",
+	}
+	nextID := 100
+	return func(method string, params json.RawMessage) (interface{}, *APIError) {
+		object, action, _ := strings.Cut(method, ".")
+		switch {
+		case method == "apiinfo.version":
+			return version, nil
+		case method == "user.login":
+			return "session-token", nil
+		case method == "user.logout":
+			return true, nil
+		case action == "get":
+			return []interface{}{}, nil
+		case action == "create" && idKeys[object] != "":
+			n := 1
+			var batch []json.RawMessage
+			if json.Unmarshal(params, &batch) == nil {
+				n = len(batch)
+			}
+			ids := make([]string, n)
+			for i := range ids {
+				ids[i] = fmt.Sprint(nextID)
+				nextID++
+			}
+			return map[string]interface{}{idKeys[object]: ids}, nil
+		}
+		return nil, &APIError{Code: -32602, Message: "Invalid params.", Data: "unexpected method " + method}
+	}
+}
+
+// recordPrepare runs prepare against emptyZabbix and writes the exchanges to
+// the version's fixture.
+func recordPrepare(t *testing.T, version string) {
+	t.Helper()
+	var mu sync.Mutex
+	fixture := replayFixture{Version: version}
+	respond := emptyZabbix(version)
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		result, apiErr := respond(method, params)
+		raw, err := json.Marshal(result)
+		if err != nil {
+			t.Errorf("marshal %s result: %v", method, err)
+		}
+		mu.Lock()
+		fixture.Exchanges = append(fixture.Exchanges, exchange{Method: method, Params: params, Result: raw, Error: apiErr})
+		mu.Unlock()
+		return result, apiErr
+	})
+	defer ts.Close()
+
+	if err := runPrepare(ts.URL); err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(replayPath(version)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(replayPath(version), append(data, '\n'), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadFixture(t *testing.T, version string) replayFixture {
+	t.Helper()
+	data, err := os.ReadFile(replayPath(version))
+	if err != nil {
+		t.Fatalf("%v (record it with go test -run TestReplay -update)", err)
+	}
+	var fixture replayFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("parse %s: %v", replayPath(version), err)
+	}
+	return fixture
+}
+
+// jsonEqual reports whether a and b hold the same JSON value.
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// TestReplayPrepare replays each version's fixture: the client must send
+// exactly the recorded requests, in order, and handle the recorded
+// responses.
+func TestReplayPrepare(t *testing.T) {
+	for _, version := range replayVersions {
+		t.Run(version, func(t *testing.T) {
+			if *updateReplay {
+				recordPrepare(t, version)
+			}
+			fixture := loadFixture(t, version)
+
+			var mu sync.Mutex
+			next := 0
+			ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+				mu.Lock()
+				defer mu.Unlock()
+				if next >= len(fixture.Exchanges) {
+					t.Errorf("unexpected request %d: %s %s", next, method, params)
+					return nil, &APIError{Code: -1, Message: "replay exhausted"}
+				}
+				want := fixture.Exchanges[next]
+				next++
+				if method != want.Method || !jsonEqual(params, want.Params) {
+					t.Errorf("request %d = %s %s\nrecorded: %s %s", next-1, method, params, want.Method, want.Params)
+					return nil, &APIError{Code: -1, Message: "replay mismatch"}
+				}
+				return want.Result, want.Error
+			})
+			defer ts.Close()
+
+			if err := runPrepare(ts.URL); err != nil {
+				t.Fatalf("prepare: %v", err)
+			}
+			if next != len(fixture.Exchanges) {
+				t.Errorf("prepare made %d requests, the fixture has %d", next, len(fixture.Exchanges))
+			}
+		})
+	}
+}
+
+// TestReplayFixtures_VersionBranches checks the recorded sessions for the
+// calls that differ between Zabbix versions, so a re-recorded fixture
+// cannot silently lose a version branch.
+func TestReplayFixtures_VersionBranches(t *testing.T) {
+	tests := []struct {
+		version           string
+		loginField        string // user.login parameter holding the user name
+		templateGroups    bool   // templategroup.create (6.2+)
+		expressionSyntax  string // trigger prototype expression syntax
+		dashboardWidgetAt string // where dashboard.create puts its widgets
+	}{
+		{"5.0.0", "user", false, "{vulners.hosts:", "widgets"},
+		{"5.4.0", "user", false, "last(/vulners.hosts/", "pages"},
+		{"6.0.0", "username", false, "last(/vulners.hosts/", "pages"},
+		{"6.4.0", "username", true, "last(/vulners.hosts/", "pages"},
+		{"7.0.0", "username", true, "last(/vulners.hosts/", "pages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			fixture := loadFixture(t, tt.version)
+			calls := make(map[string][]string)
+			for _, ex := range fixture.Exchanges {
+				calls[ex.Method] = append(calls[ex.Method], string(ex.Params))
+			}
+
+			if login := calls["user.login"]; len(login) != 1 || !strings.Contains(login[0], `"`+tt.loginField+`":`) {
+				t.Errorf("user.login = %v, want the %q field", login, tt.loginField)
+			}
+			if got := len(calls["templategroup.create"]) > 0; got != tt.templateGroups {
+				t.Errorf("templategroup.create called = %v, want %v", got, tt.templateGroups)
+			}
+			if triggers := strings.Join(calls["triggerprototype.create"], ""); !strings.Contains(triggers, tt.expressionSyntax) {
+				t.Errorf("trigger prototypes do not use %q expressions", tt.expressionSyntax)
+			}
+			if dash := calls["dashboard.create"]; len(dash) != 1 || !strings.Contains(dash[0], `"`+tt.dashboardWidgetAt+`":`) {
+				t.Errorf("dashboard.create = %v, want widgets in %q", dash, tt.dashboardWidgetAt)
+			}
+			if logout := calls["user.logout"]; len(logout) != 1 {
+				t.Errorf("user.logout called %d times, want 1", len(logout))
+			}
+		})
+	}
+}
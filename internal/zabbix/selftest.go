@@ -0,0 +1,143 @@
+package zabbix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"log/slog"
+)
+
+// valueSender is the part of Sender used by the self-test.
+type valueSender interface {
+	Send(data []SenderData) error
+}
+
+// SelfTestKey is the trapper item the self-test writes to. It exists only
+// for the self-test, so the check never overwrites scan data.
+const SelfTestKey = "vulners.self_test"
+
+// selfTestValue is the value the self-test sends to SelfTestKey.
+const selfTestValue = "ztc-self-test"
+
+// SelfTestResult is the outcome of the self-test for one trapper item.
+type SelfTestResult struct {
+	Host string
+	Key  string
+	Err  error // nil when the item passed
+}
+
+// SelfTestCtx checks the trapper items of every virtual host. Disabled items
+// fail, and a test value sent to each host's SelfTestKey item must become
+// visible through the API, so that trapper or permission problems surface
+// before the first real scan. Items holding scan data are never written;
+// prepare --verify checks their value types. It waits up to timeout for
+// Zabbix to process the values.
+func (c *Client) SelfTestCtx(ctx context.Context, sender valueSender, timeout time.Duration) ([]SelfTestResult, error) {
+	hosts := []string{c.cfg.Naming.HostsHost, c.cfg.Naming.PackagesHost, c.cfg.Naming.BulletinsHost, c.cfg.Naming.StatisticsHost}
+	ids, err := c.getHostIDsByName(ctx, hosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up virtual hosts: %w", err)
+	}
+	hostIDs := make([]string, len(hosts))
+	for i, host := range hosts {
+		id, ok := ids[host]
+		if !ok {
+			return nil, fmt.Errorf("host not found: %s (run prepare first)", host)
+		}
+		hostIDs[i] = id
+	}
+
+	var results []SelfTestResult
+	sent := make(map[string]int)         // itemid → index in results
+	lastClock := make(map[string]string) // itemid → lastclock before sending
+	for i, host := range hosts {
+		items, err := c.getTrapperItems(ctx, hostIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return nil, fmt.Errorf("no trapper items found on %s", host)
+		}
+
+		var testItem *Item
+		for j, item := range items {
+			if item.Key == SelfTestKey {
+				testItem = &items[j]
+			}
+			if item.Status != "0" {
+				results = append(results, SelfTestResult{Host: host, Key: item.Key, Err: errors.New("item is disabled")})
+			}
+		}
+		if testItem == nil {
+			results = append(results, SelfTestResult{Host: host, Key: SelfTestKey, Err: errors.New("item not found (run prepare)")})
+			continue
+		}
+		if testItem.Status != "0" {
+			continue // reported as disabled above
+		}
+
+		results = append(results, SelfTestResult{Host: host, Key: SelfTestKey})
+		if err := sender.Send([]SenderData{{Host: host, Key: SelfTestKey, Value: selfTestValue}}); err != nil {
+			results[len(results)-1].Err = fmt.Errorf("value %q rejected: %w", selfTestValue, err)
+			continue
+		}
+		sent[testItem.ItemID] = len(results) - 1
+		lastClock[testItem.ItemID] = testItem.LastClock
+	}
+
+	// Poll until every sent value is visible or the deadline passes.
+	pending := make(map[string]bool, len(sent))
+	for id := range sent {
+		pending[id] = true
+	}
+
+	deadline := time.Now().Add(timeout)
+	for len(pending) > 0 {
+		for _, hostID := range hostIDs {
+			after, err := c.getTrapperItems(ctx, hostID)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range after {
+				if pending[item.ItemID] && item.LastClock != lastClock[item.ItemID] && item.Value == selfTestValue {
+					delete(pending, item.ItemID)
+				}
+			}
+		}
+		if len(pending) == 0 || time.Now().After(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	for id := range pending {
+		results[sent[id]].Err = fmt.Errorf("value %q not visible via API after %s", selfTestValue, timeout)
+	}
+
+	c.log.Debug("Self-test finished", slog.Int("hosts", len(hosts)), slog.Int("items", len(results)))
+	return results, nil
+}
+
+// getTrapperItems returns the plain (non-discovered) trapper items of a host.
+func (c *Client) getTrapperItems(ctx context.Context, hostID string) ([]Item, error) {
+	params := map[string]interface{}{
+		"output":  []string{"itemid", "key_", "lastvalue", "lastclock", "value_type", "status"},
+		"hostids": hostID,
+		"filter": map[string]interface{}{
+			"type":  2, // Zabbix trapper
+			"flags": 0, // plain items only
+		},
+	}
+
+	result, err := c.callWithContext(ctx, "item.get", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items: %w", err)
+	}
+	return parseItems(result)
+}
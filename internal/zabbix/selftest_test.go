@@ -0,0 +1,140 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSender struct {
+	mu     sync.Mutex
+	sent   map[string]string // "host key" → value
+	reject map[string]bool   // host
+}
+
+func (f *fakeSender) Send(data []SenderData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range data {
+		if f.reject[d.Host] {
+			return errors.New("exit status 2: processed: 0; failed: 1")
+		}
+		f.sent[d.Host+" "+d.Key] = d.Value
+	}
+	return nil
+}
+
+func TestSelfTestCtx(t *testing.T) {
+	sender := &fakeSender{
+		sent:   make(map[string]string),
+		reject: map[string]bool{"vulners.packages": true},
+	}
+
+	hosts := map[string]string{"1": "vulners.hosts", "2": "vulners.packages", "3": "vulners.bulletins", "4": "vulners.statistics"}
+	items := map[string][]map[string]string{
+		"1": {
+			{"itemid": "11", "key_": SelfTestKey, "value_type": "1", "status": "0", "lastclock": "0", "lastvalue": ""},
+			{"itemid": "12", "key_": "vulners.TotalHosts", "value_type": "3", "status": "0", "lastclock": "0", "lastvalue": ""},
+		},
+		"2": {
+			{"itemid": "21", "key_": SelfTestKey, "value_type": "1", "status": "0", "lastclock": "0", "lastvalue": ""},
+		},
+		"3": {
+			{"itemid": "32", "key_": "vulners.TotalHosts", "value_type": "3", "status": "1", "lastclock": "0", "lastvalue": ""},
+		},
+		"4": {
+			{"itemid": "41", "key_": SelfTestKey, "value_type": "1", "status": "0", "lastclock": "100", "lastvalue": "ztc-self-test"},
+			{"itemid": "42", "key_": "vulners.Maximum", "value_type": "0", "status": "0", "lastclock": "100", "lastvalue": "9.8"},
+		},
+	}
+
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		switch method {
+		case "host.get":
+			var out []map[string]string
+			for id, host := range hosts {
+				out = append(out, map[string]string{"hostid": id, "host": host})
+			}
+			return out, nil
+		case "item.get":
+			var p struct {
+				HostIDs string `json:"hostids"`
+			}
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, &APIError{Code: -1, Message: err.Error()}
+			}
+			sender.mu.Lock()
+			defer sender.mu.Unlock()
+			var out []map[string]string
+			for _, it := range items[p.HostIDs] {
+				cp := map[string]string{}
+				for k, v := range it {
+					cp[k] = v
+				}
+				if v, ok := sender.sent[hosts[p.HostIDs]+" "+it["key_"]]; ok {
+					cp["lastvalue"] = v
+					cp["lastclock"] = "200"
+				}
+				out = append(out, cp)
+			}
+			return out, nil
+		default:
+			return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+		}
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	results, err := c.SelfTestCtx(context.Background(), sender, time.Second)
+	if err != nil {
+		t.Fatalf("SelfTestCtx: %v", err)
+	}
+
+	got := make(map[string]error, len(results))
+	for _, r := range results {
+		got[r.Host+" "+r.Key] = r.Err
+	}
+	want := map[string]string{
+		"vulners.hosts " + SelfTestKey:         "",
+		"vulners.packages " + SelfTestKey:      "rejected",
+		"vulners.bulletins " + SelfTestKey:     "not found",
+		"vulners.bulletins vulners.TotalHosts": "disabled",
+		"vulners.statistics " + SelfTestKey:    "",
+	}
+	if len(got) != len(want) {
+		t.Errorf("results = %v, want %d entries", got, len(want))
+	}
+	for key, msg := range want {
+		err, ok := got[key]
+		switch {
+		case !ok:
+			t.Errorf("%s: no result", key)
+		case msg == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", key, err)
+		case msg != "" && (err == nil || !strings.Contains(err.Error(), msg)):
+			t.Errorf("%s: error = %v, want %q", key, err, msg)
+		}
+	}
+
+	for key := range sender.sent {
+		if !strings.HasSuffix(key, " "+SelfTestKey) {
+			t.Errorf("self-test wrote to %s, want only %s", key, SelfTestKey)
+		}
+	}
+}
+
+func TestSelfTestCtx_HostMissing(t *testing.T) {
+	ts := newTestServer(t, func(method string, _ json.RawMessage) (interface{}, *APIError) {
+		return []interface{}{}, nil
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	if _, err := c.SelfTestCtx(context.Background(), &fakeSender{}, time.Second); err == nil {
+		t.Error("expected error for missing host")
+	}
+}
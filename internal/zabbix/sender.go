@@ -1,10 +1,15 @@
 package zabbix
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -18,6 +23,10 @@ import (
 type Sender struct {
 	cfg *config.Config
 	log *slog.Logger
+
+	// binErr is set when zabbix_sender is missing and returned by every
+	// send, so that commands which never push still work without it.
+	binErr error
 }
 
 // SenderData represents data to be sent to Zabbix
@@ -30,29 +39,49 @@ type SenderData struct {
 // NewSender creates a new Zabbix sender
 func NewSender(cfg *config.Config, log *slog.Logger) *Sender {
 	return &Sender{
-		cfg: cfg,
-		log: log,
+		cfg:    cfg,
+		log:    log,
+		binErr: CheckBinary("zabbix_sender", cfg.Zabbix.SenderPath, "zabbix.sender_path"),
 	}
 }
 
+// CheckBinary reports whether the Zabbix tool name is executable at path
+// (looked up in $PATH when path has no slash), with an error naming the
+// configKey that sets the path.
+func CheckBinary(name, path, configKey string) error {
+	if _, err := exec.LookPath(path); err != nil {
+		return fmt.Errorf("%s not found at %s; install it or set %s", name, path, configKey)
+	}
+	return nil
+}
+
 // Send sends data to Zabbix using zabbix_sender
 func (s *Sender) Send(data []SenderData) error {
 	if len(data) == 0 {
 		return nil
 	}
 
-	// Build input data
-	var lines []string
-	for _, d := range data {
-		// Format: hostname key value
-		// Escape newlines in values
-		value := strings.ReplaceAll(d.Value, "\n", "\\n")
-		lines = append(lines, fmt.Sprintf(`%s %s %s`, d.Host, d.Key, value))
-	}
+	s.log.Debug("Sending data to Zabbix", slog.Int("items", len(data)))
 
-	input := strings.Join(lines, "\n")
+	return s.run(func(w *bufio.Writer) error {
+		for _, d := range data {
+			// Format: hostname key value
+			// Escape newlines in values
+			value := strings.ReplaceAll(d.Value, "\n", "\\n")
+			if _, err := fmt.Fprintf(w, "%s %s %s\n", d.Host, d.Key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-	s.log.Debug("Sending data to Zabbix", slog.Int("items", len(data)))
+// run executes zabbix_sender and streams its stdin input from write, so
+// large payloads are never held in memory as one string.
+func (s *Sender) run(write func(w *bufio.Writer) error) error {
+	if s.binErr != nil {
+		return s.binErr
+	}
 
 	// Execute zabbix_sender with a timeout to prevent hanging
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
@@ -65,33 +94,213 @@ func (s *Sender) Send(data []SenderData) error {
 		"-i", "-", // read from stdin
 	)
 
-	cmd.Stdin = bytes.NewReader([]byte(input))
-
-	output, err := cmd.CombinedOutput()
+	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		return fmt.Errorf("zabbix_sender failed: %w: %s", err, string(output))
+		return fmt.Errorf("zabbix_sender failed: %w", err)
 	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
 
-	s.log.Debug("zabbix_sender completed", slog.String("output", string(output)))
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("zabbix_sender failed: %w", err)
+	}
+
+	var input io.Writer = stdin
+	dump, closeDump := s.openDump()
+	if dump != nil {
+		defer closeDump()
+		input = io.MultiWriter(stdin, dump)
+	}
+
+	w := bufio.NewWriterSize(input, senderBufferSize)
+	writeErr := write(w)
+	if writeErr == nil {
+		writeErr = w.Flush()
+	}
+	_ = stdin.Close()
+
+	// A sender that exits early also breaks the pipe; its own error and
+	// output explain more than the write error does.
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("zabbix_sender failed: %w: %s", err, output.String())
+	}
+	if writeErr != nil {
+		return fmt.Errorf("failed to write zabbix_sender input: %w", writeErr)
+	}
+
+	s.log.Debug("zabbix_sender completed", slog.String("output", output.String()))
 	return nil
 }
 
-// SendLLD sends Low-Level Discovery data to Zabbix
-func (s *Sender) SendLLD(host, key string, lldData *LLDData) error {
-	jsonData, err := json.Marshal(lldData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal LLD data: %w", err)
+// DumpSenderEnv names the environment variable that dumps every line fed to
+// zabbix_sender to stderr ("stderr" or "-") or appends it to a file (any
+// other value). Without it, the lines are logged at debug level.
+const DumpSenderEnv = "ZTC_DUMP_SENDER"
+
+// dumpMaxLine is how much of each dumped line is kept; the rest of large
+// LLD values is replaced by a byte count.
+const dumpMaxLine = 512
+
+// openDump returns the writer that receives a copy of the zabbix_sender
+// input, or nil when dumping is off, and a func closing it.
+func (s *Sender) openDump() (io.Writer, func()) {
+	switch dest := os.Getenv(DumpSenderEnv); dest {
+	case "":
+		if !s.log.Enabled(context.Background(), slog.LevelDebug) {
+			return nil, nil
+		}
+		d := &lineDump{emit: func(line string) {
+			s.log.Debug("zabbix_sender input", slog.String("line", line))
+		}}
+		return d, d.flush
+	case "stderr", "-":
+		d := &lineDump{emit: func(line string) { fmt.Fprintln(os.Stderr, line) }}
+		return d, d.flush
+	default:
+		f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600) //nolint:gosec // G304: path from the operator's environment
+		if err != nil {
+			s.log.Warn("Failed to open sender dump file", slog.String("path", dest), slog.Any("error", err))
+			return nil, nil
+		}
+		d := &lineDump{emit: func(line string) { fmt.Fprintln(f, line) }}
+		return d, func() {
+			d.flush()
+			_ = f.Close()
+		}
 	}
+}
 
-	return s.Send([]SenderData{
-		{
-			Host:  host,
-			Key:   key,
-			Value: string(jsonData),
-		},
+// lineDump splits the sender input into lines, truncated to dumpMaxLine
+// bytes, and passes each to emit. Writes never fail so that dumping cannot
+// break a push.
+type lineDump struct {
+	emit    func(line string)
+	line    []byte
+	dropped int
+}
+
+func (d *lineDump) Write(p []byte) (int, error) {
+	for _, b := range p {
+		switch {
+		case b == '\n':
+			d.flush()
+		case len(d.line) < dumpMaxLine:
+			d.line = append(d.line, b)
+		default:
+			d.dropped++
+		}
+	}
+	return len(p), nil
+}
+
+// flush emits the pending line, if any.
+func (d *lineDump) flush() {
+	if len(d.line) == 0 && d.dropped == 0 {
+		return
+	}
+	line := string(d.line)
+	if d.dropped > 0 {
+		line += fmt.Sprintf("... (%d more bytes)", d.dropped)
+	}
+	d.emit(line)
+	d.line, d.dropped = d.line[:0], 0
+}
+
+// senderBufferSize is the write buffer between payload encoding and the
+// zabbix_sender pipe.
+const senderBufferSize = 64 << 10
+
+// SendLLD sends Low-Level Discovery data to Zabbix. The JSON is encoded one
+// row at a time straight into zabbix_sender's stdin, so a packages LLD of
+// tens of MB is never built as a single string. It is still one value:
+// splitting it across several values would make each one replace the
+// previous discovery and mark the other rows' items as lost. With
+// scan.compress_lld the value is gzipped and base64-encoded, see DecodeLLD.
+func (s *Sender) SendLLD(host, key string, lldData *LLDData) error {
+	s.log.Debug("Sending LLD to Zabbix", slog.String("key", key), slog.Int("rows", len(lldData.Data)),
+		slog.Bool("compressed", s.cfg.Scan.CompressLLD))
+
+	return s.run(func(w *bufio.Writer) error {
+		if _, err := fmt.Fprintf(w, "%s %s ", host, key); err != nil {
+			return err
+		}
+		write := writeLLDJSON
+		if s.cfg.Scan.CompressLLD {
+			write = writeCompressedLLD
+		}
+		if err := write(w, lldData); err != nil {
+			return err
+		}
+		return w.WriteByte('\n')
 	})
 }
 
+// writeCompressedLLD writes lldData to w as base64-encoded gzipped JSON,
+// still encoding one row at a time.
+func writeCompressedLLD(w *bufio.Writer, lldData *LLDData) error {
+	b64 := base64.NewEncoder(base64.StdEncoding, w)
+	gz := gzip.NewWriter(b64)
+	jw := bufio.NewWriterSize(gz, senderBufferSize)
+	if err := writeLLDJSON(jw, lldData); err != nil {
+		return err
+	}
+	if err := jw.Flush(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return b64.Close()
+}
+
+// DecodeLLD parses an LLD value as SendLLD sends it: JSON, or with
+// scan.compress_lld, base64-encoded gzipped JSON.
+func DecodeLLD(value string) (*LLDData, error) {
+	var r io.Reader = strings.NewReader(value)
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		gz, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, r))
+		if err != nil {
+			return nil, fmt.Errorf("LLD value is neither JSON nor compressed JSON: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+	var data LLDData
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// writeLLDJSON writes lldData to w exactly as json.Marshal would, encoding
+// one row at a time.
+func writeLLDJSON(w *bufio.Writer, lldData *LLDData) error {
+	if lldData.Data == nil {
+		_, err := w.WriteString(`{"data":null}`)
+		return err
+	}
+	if _, err := w.WriteString(`{"data":[`); err != nil {
+		return err
+	}
+	for i, row := range lldData.Data {
+		if i > 0 {
+			if err := w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		b, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("failed to marshal LLD data: %w", err)
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString("]}")
+	return err
+}
+
 // SendJSON sends JSON data to a trapper item
 func (s *Sender) SendJSON(host, key string, data interface{}) error {
 	jsonData, err := json.Marshal(data)
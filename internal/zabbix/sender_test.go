@@ -0,0 +1,270 @@
+package zabbix
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/kidoz/zabbix-threat-control-go/internal/config"
+)
+
+// scriptSender returns a Sender whose zabbix_sender copies its stdin to the
+// returned file.
+func scriptSender(t *testing.T, script string) (*Sender, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake zabbix_sender is a shell script")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "stdin")
+	path := filepath.Join(dir, "zabbix_sender")
+	if script == "" {
+		script = fmt.Sprintf("cat > %q", out)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.SenderPath = path
+	return NewSender(cfg, slog.New(slog.NewTextHandler(io.Discard, nil))), out
+}
+
+func TestSender_Send(t *testing.T) {
+	s, out := scriptSender(t, "")
+	err := s.Send([]SenderData{
+		{Host: "vulners.hosts", Key: "vulners.hosts[1]", Value: "7.5"},
+		{Host: "vulners.statistics", Key: "vulners.scan.raw", Value: "a\nb"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	want := "vulners.hosts vulners.hosts[1] 7.5\nvulners.statistics vulners.scan.raw a\\nb\n"
+	if string(got) != want {
+		t.Errorf("stdin = %q, want %q", got, want)
+	}
+}
+
+func TestSender_Failure(t *testing.T) {
+	s, _ := scriptSender(t, "echo 'sent: 0; failed: 1'; exit 2")
+	err := s.SendLLD("vulners.hosts", "vulners.hosts_lld", &LLDData{Data: []map[string]interface{}{{"{#H.ID}": "1"}}})
+	if err == nil || !strings.Contains(err.Error(), "failed: 1") {
+		t.Errorf("err = %v, want zabbix_sender output", err)
+	}
+}
+
+func TestSender_SendLLD_Large(t *testing.T) {
+	s, out := scriptSender(t, "")
+
+	// Roughly 20 MB of JSON, as for a fleet with tens of thousands of packages.
+	const rows = 60000
+	lld := &LLDData{Data: make([]map[string]interface{}, rows)}
+	for i := range lld.Data {
+		lld.Data[i] = map[string]interface{}{
+			"{#P.NAME}":  fmt.Sprintf("package-%d", i),
+			"{#P.HOSTS}": strings.Repeat("10084,", 40),
+			"{#P.FIX}":   "apt-get install --only-upgrade \"pkg\" <&>",
+		}
+	}
+	want, err := json.Marshal(lld)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) < 16<<20 {
+		t.Fatalf("test payload is only %d bytes", len(want))
+	}
+
+	if err := s.SendLLD("vulners.packages", "vulners.packages_lld", lld); err != nil {
+		t.Fatalf("SendLLD: %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix := "vulners.packages vulners.packages_lld "
+	if !strings.HasPrefix(string(got), prefix) || !strings.HasSuffix(string(got), "\n") || strings.Count(string(got), "\n") != 1 {
+		t.Fatalf("stdin is not a single sender line (%d bytes)", len(got))
+	}
+	if value := strings.TrimSuffix(strings.TrimPrefix(string(got), prefix), "\n"); value != string(want) {
+		t.Errorf("streamed LLD differs from json.Marshal (%d vs %d bytes)", len(value), len(want))
+	}
+}
+
+func TestSender_SendLLD_Compressed(t *testing.T) {
+	s, out := scriptSender(t, "")
+	s.cfg.Scan.CompressLLD = true
+
+	lld := &LLDData{Data: make([]map[string]interface{}, 2000)}
+	for i := range lld.Data {
+		lld.Data[i] = map[string]interface{}{
+			"{#P.NAME}":  fmt.Sprintf("package-%d", i),
+			"{#P.HOSTS}": strings.Repeat("10084,", 40),
+			"{#P.SCORE}": 7.5,
+		}
+	}
+	plain, err := json.Marshal(lld)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SendLLD("vulners.packages", "vulners.packages_lld", lld); err != nil {
+		t.Fatalf("SendLLD: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, ok := strings.CutPrefix(strings.TrimSuffix(string(got), "\n"), "vulners.packages vulners.packages_lld ")
+	if !ok || strings.ContainsAny(value, " \n") {
+		t.Fatalf("stdin is not a single sender line: %.100q", got)
+	}
+	if len(value) >= len(plain)/5 {
+		t.Errorf("compressed value is %d bytes, JSON is %d", len(value), len(plain))
+	}
+
+	decoded, err := DecodeLLD(value)
+	if err != nil {
+		t.Fatalf("DecodeLLD: %v", err)
+	}
+	roundTrip, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(roundTrip) != string(plain) {
+		t.Errorf("decoded LLD differs from the sent one (%d vs %d bytes)", len(roundTrip), len(plain))
+	}
+}
+
+// TestLLDDecompressScript runs the discovery rules' preprocessing script
+// under Node.js, standing in for Zabbix's JavaScript engine.
+func TestLLDDecompressScript(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+	lld := &LLDData{Data: make([]map[string]interface{}, 3000)}
+	for i := range lld.Data {
+		lld.Data[i] = map[string]interface{}{
+			"{#P.NAME}":  fmt.Sprintf("package-%d", i),
+			"{#P.HOSTS}": strings.Repeat(fmt.Sprint(i%97, ","), i%50),
+			"{#P.ARCH}":  "ünïcode ✓ 𝄞",
+		}
+	}
+	plain, err := json.Marshal(lld)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed strings.Builder
+	w := bufio.NewWriter(&compressed)
+	if err := writeCompressedLLD(w, lld); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	runner := `const fs = require("fs");
+const preprocess = new Function("value", fs.readFileSync(process.argv[1], "utf8"));
+process.stdout.write(preprocess(fs.readFileSync(process.argv[2], "utf8")));`
+	for name, value := range map[string]string{"compressed": compressed.String(), "plain": string(plain)} {
+		t.Run(name, func(t *testing.T) {
+			in := filepath.Join(dir, name)
+			if err := os.WriteFile(in, []byte(value), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := exec.Command(node, "-e", runner, "--", "lld_decompress.js", in).Output()
+			if err != nil {
+				t.Fatalf("node: %v", err)
+			}
+			if string(got) != string(plain) {
+				t.Errorf("script output differs from the LLD JSON (%d vs %d bytes)", len(got), len(plain))
+			}
+		})
+	}
+}
+
+func TestDecodeLLD(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		rows    int
+		wantErr bool
+	}{
+		{"json", `{"data":[{"{#H.ID}":"1"}]}`, 1, false},
+		{"json with leading space", ` {"data":[]}`, 0, false},
+		{"not base64 gzip", "garbage", 0, true},
+		{"truncated json", `{"data":[`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeLLD(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeLLD(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if err == nil && len(got.Data) != tt.rows {
+				t.Errorf("DecodeLLD(%q) has %d rows, want %d", tt.value, len(got.Data), tt.rows)
+			}
+		})
+	}
+}
+
+func TestSender_Dump(t *testing.T) {
+	s, _ := scriptSender(t, "cat > /dev/null")
+	dump := filepath.Join(t.TempDir(), "sender.txt")
+	t.Setenv(DumpSenderEnv, dump)
+
+	if err := s.SendValue("vulners.statistics", "vulners.TotalHosts", "12"); err != nil {
+		t.Fatalf("SendValue: %v", err)
+	}
+	big := strings.Repeat("x", 2000)
+	if err := s.SendLLD("vulners.hosts", "vulners.hosts_lld", &LLDData{Data: []map[string]interface{}{{"{#H.NAME}": big}}}); err != nil {
+		t.Fatalf("SendLLD: %v", err)
+	}
+
+	got, err := os.ReadFile(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("dump has %d lines, want 2:\n%s", len(lines), got)
+	}
+	if lines[0] != "vulners.statistics vulners.TotalHosts 12" {
+		t.Errorf("line 1 = %q", lines[0])
+	}
+	full := len(`vulners.hosts vulners.hosts_lld {"data":[{"{#H.NAME}":""}]}`) + len(big)
+	want := fmt.Sprintf("... (%d more bytes)", full-dumpMaxLine)
+	if !strings.HasPrefix(lines[1], "vulners.hosts vulners.hosts_lld {") || !strings.HasSuffix(lines[1], want) {
+		t.Errorf("line 2 = %q, want it truncated with %q", lines[1], want)
+	}
+}
+
+func TestSender_MissingBinary(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Zabbix.SenderPath = filepath.Join(t.TempDir(), "zabbix_sender")
+	s := NewSender(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	err := s.SendValue("vulners.statistics", "vulners.stats[total_hosts]", "1")
+	want := "zabbix_sender not found at " + cfg.Zabbix.SenderPath + "; install it or set zabbix.sender_path"
+	if err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+
+	// A bare name is looked up in $PATH.
+	if err := CheckBinary("zabbix_sender", "ztc-no-such-binary", "zabbix.sender_path"); err == nil {
+		t.Error("CheckBinary found a binary that does not exist")
+	}
+	if err := CheckBinary("sh", "sh", "-"); err != nil {
+		t.Errorf("CheckBinary(sh) = %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package zabbix
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"log/slog"
 )
@@ -77,33 +78,43 @@ func (c *Client) EnsureOSReportTemplateCtx(ctx context.Context, force bool) erro
 	return c.createOSReportItems(ctx, templateID)
 }
 
-// createOSReportItems creates the items for the OS-Report template
-func (c *Client) createOSReportItems(ctx context.Context, templateID string) error {
-	items := []map[string]interface{}{
-		{
-			"hostid":      templateID,
-			"name":        "OS - Name",
-			"key_":        "system.sw.os",
-			"type":        0, // Zabbix agent
-			"value_type":  1, // text
-			"delay":       "1d",
-			"description": "Operating system name and version",
-		},
-		{
-			"hostid":      templateID,
-			"name":        "OS - Packages",
-			"key_":        "system.sw.packages",
-			"type":        0, // Zabbix agent
-			"value_type":  4, // text
-			"delay":       "1d",
-			"description": "List of installed packages",
-		},
+// osReportItem describes an item of the OS-Report template.
+type osReportItem struct {
+	name        string
+	key         string
+	valueType   int
+	description string
+}
+
+// osReportItems are the agent items the OS-Report template collects once a
+// day.
+var osReportItems = []osReportItem{
+	{"OS - Name", "system.sw.os", valueTypeChar, "Operating system name and version"},
+	{"OS - Packages", "system.sw.packages", valueTypeText, "List of installed packages"},
+}
+
+// osReportDelay is the update interval of the OS-Report items.
+const osReportDelay = "1d"
+
+// osReportItemParams returns the item.create params for item on templateID.
+func osReportItemParams(templateID string, item osReportItem) map[string]interface{} {
+	return map[string]interface{}{
+		"hostid":      templateID,
+		"name":        item.name,
+		"key_":        item.key,
+		"type":        0, // Zabbix agent
+		"value_type":  item.valueType,
+		"delay":       osReportDelay,
+		"description": item.description,
 	}
+}
 
-	for _, item := range items {
-		_, err := c.callWithContext(ctx, "item.create", item)
+// createOSReportItems creates the items for the OS-Report template
+func (c *Client) createOSReportItems(ctx context.Context, templateID string) error {
+	for _, item := range osReportItems {
+		_, err := c.callWithContext(ctx, "item.create", osReportItemParams(templateID, item))
 		if err != nil {
-			return fmt.Errorf("failed to create item %s: %w", item["name"], err)
+			return fmt.Errorf("failed to create item %s: %w", item.name, err)
 		}
 	}
 
@@ -129,47 +140,109 @@ func (c *Client) updateOSReportItems(ctx context.Context, templateID string) err
 		return err
 	}
 
-	// Check if required items exist
-	requiredKeys := map[string]bool{
-		"system.sw.os":       false,
-		"system.sw.packages": false,
-	}
-
+	existing := make(map[string]bool, len(items))
 	for _, item := range items {
-		if _, exists := requiredKeys[item.Key]; exists {
-			requiredKeys[item.Key] = true
-		}
+		existing[item.Key] = true
 	}
 
 	// Create missing items
-	for key, exists := range requiredKeys {
-		if !exists {
-			c.log.Info("Creating missing template item", slog.String("key", key))
-			// Create the missing item
-			itemDef := map[string]interface{}{
-				"hostid":     templateID,
-				"key_":       key,
-				"type":       0,
-				"value_type": 1,
-				"delay":      "1d",
-			}
-			switch key {
-			case "system.sw.os":
-				itemDef["name"] = "OS - Name"
-			case "system.sw.packages":
-				itemDef["name"] = "OS - Packages"
-				itemDef["value_type"] = 4
-			}
-			_, err := c.callWithContext(ctx, "item.create", itemDef)
-			if err != nil {
-				return fmt.Errorf("failed to create item %s: %w", key, err)
-			}
+	for _, item := range osReportItems {
+		if existing[item.key] {
+			continue
+		}
+		c.log.Info("Creating missing template item", slog.String("key", item.key))
+		_, err := c.callWithContext(ctx, "item.create", osReportItemParams(templateID, item))
+		if err != nil {
+			return fmt.Errorf("failed to create item %s: %w", item.key, err)
 		}
 	}
 
 	return nil
 }
 
+// LinkOSReportTemplateCtx links the OS-Report template to every host in the
+// host group that does not have it yet, with one host.massadd call, and
+// returns those hosts. The template is created first if missing. With
+// dryRun nothing is changed and the hosts that would be linked are returned.
+func (c *Client) LinkOSReportTemplateCtx(ctx context.Context, groupName string, dryRun bool) ([]Host, error) {
+	result, err := c.callWithContext(ctx, "hostgroup.get", map[string]interface{}{
+		"output": []string{"groupid", "name"},
+		"filter": map[string]interface{}{"name": groupName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host group: %w", err)
+	}
+	groups, err := parseHostGroups(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("host group not found: %s", groupName)
+	}
+
+	if !dryRun {
+		if err := c.EnsureOSReportTemplateCtx(ctx, false); err != nil {
+			return nil, err
+		}
+	}
+	templateName := c.cfg.Scan.OSReportTemplate
+	result, err = c.callWithContext(ctx, "template.get", map[string]interface{}{
+		"output": []string{"templateid", "host"},
+		"filter": map[string]interface{}{"host": templateName},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check template: %w", err)
+	}
+	templates, err := parseTemplates(result)
+	if err != nil {
+		return nil, err
+	}
+	templateID := ""
+	if len(templates) > 0 {
+		templateID = templates[0].TemplateID
+	} else if !dryRun {
+		return nil, fmt.Errorf("template not found: %s", templateName)
+	}
+
+	result, err = c.callWithContext(ctx, "host.get", map[string]interface{}{
+		"output":                []string{"hostid", "host", "name", "status"},
+		"groupids":              groups[0].GroupID,
+		"selectParentTemplates": []string{"templateid", "host", "name"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hosts: %w", err)
+	}
+	hosts, err := parseHosts(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var unlinked []Host
+	for _, h := range hosts {
+		if !slices.ContainsFunc(h.Templates, func(t Template) bool { return t.Host == templateName }) {
+			unlinked = append(unlinked, h)
+		}
+	}
+	if dryRun || len(unlinked) == 0 {
+		return unlinked, nil
+	}
+
+	hostIDs := make([]map[string]string, len(unlinked))
+	for i, h := range unlinked {
+		hostIDs[i] = map[string]string{"hostid": h.HostID}
+	}
+	_, err = c.callWithContext(ctx, "host.massadd", map[string]interface{}{
+		"hosts":     hostIDs,
+		"templates": []map[string]string{{"templateid": templateID}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link template: %w", err)
+	}
+
+	c.log.Info("Linked OS-Report template", slog.String("group", groupName), slog.Int("hosts", len(unlinked)))
+	return unlinked, nil
+}
+
 // ensureHostGroup ensures a host group exists and returns its ID
 func (c *Client) ensureHostGroup(ctx context.Context, name string) (string, error) {
 	// Check if group exists
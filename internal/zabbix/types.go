@@ -1,5 +1,11 @@
 package zabbix
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 // Host represents a Zabbix host
 type Host struct {
 	HostID     string          `json:"hostid"`
@@ -9,6 +15,47 @@ type Host struct {
 	Interfaces []HostInterface `json:"interfaces,omitempty"`
 	Groups     []HostGroup     `json:"groups,omitempty"`
 	Templates  []Template      `json:"parentTemplates,omitempty"`
+	Inventory  HostInventory   `json:"inventory"`
+
+	// MaintenanceStatus is HostInMaintenance while a maintenance covering
+	// the host is active, MaintenanceID then being that maintenance. Only
+	// the host listings used for scanning fetch them.
+	MaintenanceStatus string `json:"maintenance_status,omitempty"`
+	MaintenanceID     string `json:"maintenanceid,omitempty"`
+}
+
+// Host.Status values.
+const (
+	HostStatusMonitored = "0"
+	HostStatusDisabled  = "1"
+)
+
+// HostInMaintenance is the Host.MaintenanceStatus of a host in an active
+// maintenance.
+const HostInMaintenance = "1"
+
+// Maintenance is a Zabbix maintenance period.
+type Maintenance struct {
+	MaintenanceID string `json:"maintenanceid"`
+	Name          string `json:"name"`
+}
+
+// HostInventory holds the host inventory fields ZTC reads.
+type HostInventory struct {
+	OS           string `json:"os"`
+	OSFull       string `json:"os_full"`
+	SoftwareFull string `json:"software_full"`
+}
+
+// UnmarshalJSON accepts the empty array Zabbix returns for hosts with
+// inventory disabled.
+func (inv *HostInventory) UnmarshalJSON(data []byte) error {
+	if string(data) == "[]" {
+		*inv = HostInventory{}
+		return nil
+	}
+	type plain HostInventory
+	return json.Unmarshal(data, (*plain)(inv))
 }
 
 // HostInterface represents a Zabbix host interface
@@ -44,6 +91,50 @@ type Item struct {
 	Value     string `json:"lastvalue"`
 	ValueType string `json:"value_type"`
 	State     string `json:"state"`
+	Status    string `json:"status"`
+	LastClock string `json:"lastclock"`
+}
+
+// UnmarshalJSON accepts lastvalue as a JSON number as well as the quoted
+// string Zabbix usually sends, keeping the number's text.
+func (it *Item) UnmarshalJSON(data []byte) error {
+	type plain Item
+	aux := struct {
+		*plain
+		Value json.RawMessage `json:"lastvalue"`
+	}{plain: (*plain)(it)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	switch {
+	case len(aux.Value) == 0 || string(aux.Value) == "null":
+		it.Value = ""
+	case aux.Value[0] == '"':
+		return json.Unmarshal(aux.Value, &it.Value)
+	default:
+		var n json.Number
+		if err := json.Unmarshal(aux.Value, &n); err != nil {
+			return fmt.Errorf("lastvalue: %w", err)
+		}
+		it.Value = n.String()
+	}
+	return nil
+}
+
+// ItemTag selects items by tag in GetHostItemsCtx. Item tags need Zabbix
+// 5.4 or later.
+type ItemTag struct {
+	Tag   string
+	Value string
+	// Exact matches Value exactly instead of as a substring.
+	Exact bool
+}
+
+// ParseItemTag parses a "name:value" tag setting such as scan.os_item_tag
+// into an ItemTag matching the value exactly.
+func ParseItemTag(s string) ItemTag {
+	name, value, _ := strings.Cut(s, ":")
+	return ItemTag{Tag: name, Value: value, Exact: true}
 }
 
 // Trigger represents a Zabbix trigger
@@ -128,6 +219,10 @@ type LLDData struct {
 	Data []map[string]interface{} `json:"data"`
 }
 
+// TruncatedListPrefix starts the last entry of an LLD host name list that was
+// capped by scan.max_affected_hosts, e.g. "... and 12 more".
+const TruncatedListPrefix = "... and "
+
 // HostLLDEntry represents a host entry for LLD
 type HostLLDEntry struct {
 	HostID  string  `json:"{#H.ID}"`
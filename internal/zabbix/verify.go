@@ -0,0 +1,348 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"log/slog"
+)
+
+// Drift describes one difference between the Zabbix objects prepare would
+// create and those that exist.
+type Drift struct {
+	Object  string // e.g. "item prototype"
+	Name    string // key, description or widget name
+	Problem string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s %s: %s", d.Object, d.Name, d.Problem)
+}
+
+// verifiedObject is the subset of discovery rule, item and prototype fields
+// compared by VerifyCtx.
+type verifiedObject struct {
+	Key       string `json:"key_"`
+	ValueType string `json:"value_type"`
+	Status    string `json:"status"`
+	Lifetime  string `json:"lifetime"` // discovery rules only
+}
+
+// VerifyCtx compares the Vulners template, its virtual hosts and the
+// dashboard with the objects prepare would create for the current config and
+// returns the differences. It only reads from Zabbix.
+func (c *Client) VerifyCtx(ctx context.Context) ([]Drift, error) {
+	drifts, err := c.verifyVirtualHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	templateDrifts, err := c.verifyVulnersTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	drifts = append(drifts, templateDrifts...)
+
+	dashboardDrifts, err := c.verifyDashboard(ctx)
+	if err != nil {
+		return nil, err
+	}
+	drifts = append(drifts, dashboardDrifts...)
+
+	c.log.Debug("Verified Zabbix objects", slog.Int("drifts", len(drifts)))
+	return drifts, nil
+}
+
+// Prepare steps reported by CompletedStepsCtx.
+const (
+	StepTemplates    = "templates"     // the OS-Report template
+	StepVirtualHosts = "virtual-hosts" // the Vulners template and virtual hosts
+	StepDashboard    = "dashboard"
+)
+
+// CompletedStepsCtx reports which prepare steps have nothing left to
+// create, so an interrupted prepare can resume after them. A step with any
+// drift counts as not completed.
+func (c *Client) CompletedStepsCtx(ctx context.Context) (map[string]bool, error) {
+	osReportDrifts, err := c.verifyOSReportTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hostDrifts, err := c.verifyVirtualHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	templateDrifts, err := c.verifyVulnersTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dashboardDrifts, err := c.verifyDashboard(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]bool{
+		StepTemplates:    len(osReportDrifts) == 0,
+		StepVirtualHosts: len(hostDrifts) == 0 && len(templateDrifts) == 0,
+		StepDashboard:    len(dashboardDrifts) == 0,
+	}, nil
+}
+
+// verifyOSReportTemplate checks that the OS-Report template and its items
+// exist.
+func (c *Client) verifyOSReportTemplate(ctx context.Context) ([]Drift, error) {
+	name := c.cfg.Scan.OSReportTemplate
+	result, err := c.callWithContext(ctx, "template.get", map[string]interface{}{
+		"output": []string{"templateid", "host"},
+		"filter": map[string]interface{}{"host": name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	templates, err := parseTemplates(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return []Drift{{"template", name, "missing"}}, nil
+	}
+
+	items, err := c.getObjectIDs(ctx, "item.get", "itemid", "key_", map[string]interface{}{
+		"output":      []string{"itemid", "key_"},
+		"templateids": templates[0].TemplateID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("item.get failed: %w", err)
+	}
+	var drifts []Drift
+	for _, item := range osReportItems {
+		if items[item.key] == "" {
+			drifts = append(drifts, Drift{"item", item.key, "missing"})
+		}
+	}
+	return drifts, nil
+}
+
+// verifyVirtualHosts checks that the four virtual hosts exist.
+func (c *Client) verifyVirtualHosts(ctx context.Context) ([]Drift, error) {
+	names := []string{c.cfg.Naming.HostsHost, c.cfg.Naming.PackagesHost, c.cfg.Naming.BulletinsHost, c.cfg.Naming.StatisticsHost}
+	hostIDs, err := c.getHostIDsByName(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	var drifts []Drift
+	for _, name := range names {
+		if _, ok := hostIDs[name]; !ok {
+			drifts = append(drifts, Drift{"virtual host", name, "missing"})
+		}
+	}
+	return drifts, nil
+}
+
+// verifyVulnersTemplate compares the discovery rules, item prototypes,
+// statistics items and trigger prototypes of the Vulners template.
+func (c *Client) verifyVulnersTemplate(ctx context.Context) ([]Drift, error) {
+	templateName := c.cfg.Naming.GroupName
+	result, err := c.callWithContext(ctx, "template.get", map[string]interface{}{
+		"output": []string{"templateid", "host"},
+		"filter": map[string]interface{}{"host": templateName},
+	})
+	if err != nil {
+		return nil, err
+	}
+	templates, err := parseTemplates(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return []Drift{{"template", templateName, "missing"}}, nil
+	}
+	templateID := templates[0].TemplateID
+
+	var drifts []Drift
+
+	rules, err := c.getVerifiedObjects(ctx, "discoveryrule.get", templateID)
+	if err != nil {
+		return nil, err
+	}
+	wantRules := make(map[string]bool)
+	for _, rule := range c.vulnersLLDRules(templateID) {
+		key := rule["key_"].(string)
+		wantRules[key] = true
+		drifts = append(drifts, compareObject("discovery rule", key, -1, rules)...)
+		if got, ok := rules[key]; ok && got.Lifetime != c.cfg.Scan.LLDLifetime {
+			drifts = append(drifts, Drift{"discovery rule", key, fmt.Sprintf("lifetime is %s, want %s (scan.lld_lifetime)", got.Lifetime, c.cfg.Scan.LLDLifetime)})
+		}
+	}
+	for key := range rules {
+		if !wantRules[key] && strings.HasPrefix(key, "vulners.") {
+			drifts = append(drifts, Drift{"discovery rule", key, "not expected (disabled by scan.enabled_lld or obsolete)"})
+		}
+	}
+
+	protos, err := c.getVerifiedObjects(ctx, "itemprototype.get", templateID)
+	if err != nil {
+		return nil, err
+	}
+	for _, proto := range c.vulnersItemPrototypes() {
+		drifts = append(drifts, compareObject("item prototype", proto.key, proto.valueType, protos)...)
+	}
+
+	items, err := c.getVerifiedObjects(ctx, "item.get", templateID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range c.vulnersStatItems(templateID) {
+		drifts = append(drifts, compareObject("item", item["key_"].(string), item["value_type"].(int), items)...)
+	}
+
+	triggerDrifts, err := c.verifyTriggerPrototypes(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return append(drifts, triggerDrifts...), nil
+}
+
+// getVerifiedObjects returns the template's objects of one kind keyed by
+// item key. Keys that differ from the expected ones show up as missing.
+func (c *Client) getVerifiedObjects(ctx context.Context, method, templateID string) (map[string]verifiedObject, error) {
+	params := map[string]interface{}{
+		"output":  []string{"key_", "value_type", "status"},
+		"hostids": templateID,
+	}
+	if method == "discoveryrule.get" {
+		params["output"] = []string{"key_", "status", "lifetime"}
+	}
+	if method == "item.get" {
+		params["filter"] = map[string]interface{}{"flags": 0} // plain items only
+	}
+	result, err := c.callWithContext(ctx, method, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	var objects []verifiedObject
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s result: %w", method, err)
+	}
+	byKey := make(map[string]verifiedObject, len(objects))
+	for _, o := range objects {
+		byKey[o.Key] = o
+	}
+	return byKey, nil
+}
+
+// compareObject checks that key exists in actual, is enabled and, unless
+// valueType is negative, has the expected value type.
+func compareObject(kind, key string, valueType int, actual map[string]verifiedObject) []Drift {
+	got, ok := actual[key]
+	if !ok {
+		return []Drift{{kind, key, "missing"}}
+	}
+	var drifts []Drift
+	if valueType >= 0 && got.ValueType != fmt.Sprint(valueType) {
+		drifts = append(drifts, Drift{kind, key, fmt.Sprintf("value_type is %s, want %d", got.ValueType, valueType)})
+	}
+	if got.Status != "0" {
+		drifts = append(drifts, Drift{kind, key, "disabled"})
+	}
+	return drifts
+}
+
+// verifyTriggerPrototypes checks the template's trigger prototypes by
+// description, since their expressions embed the template name.
+func (c *Client) verifyTriggerPrototypes(ctx context.Context, templateID string) ([]Drift, error) {
+	result, err := c.callWithContext(ctx, "triggerprototype.get", map[string]interface{}{
+		"output":      []string{"triggerid", "description", "status"},
+		"templateids": templateID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("triggerprototype.get failed: %w", err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	var triggers []Trigger
+	if err := json.Unmarshal(data, &triggers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trigger prototypes: %w", err)
+	}
+	byDescription := make(map[string]Trigger, len(triggers))
+	for _, t := range triggers {
+		byDescription[t.Description] = t
+	}
+
+	var drifts []Drift
+	for _, want := range c.vulnersTriggerPrototypes() {
+		got, ok := byDescription[want.description]
+		switch {
+		case !ok:
+			drifts = append(drifts, Drift{"trigger prototype", want.description, "missing"})
+		case got.Status != "0":
+			drifts = append(drifts, Drift{"trigger prototype", want.description, "disabled"})
+		}
+	}
+	return drifts, nil
+}
+
+// verifyDashboard checks that the dashboard exists and has every widget
+// prepare creates, matched by name.
+func (c *Client) verifyDashboard(ctx context.Context) ([]Drift, error) {
+	name := c.cfg.Naming.DashboardName
+	params := map[string]interface{}{
+		"output": []string{"dashboardid", "name"},
+		"filter": map[string]interface{}{"name": name},
+	}
+	if c.getAPIVersionFloat() > 5.0 {
+		params["selectPages"] = "extend"
+	} else {
+		params["selectWidgets"] = "extend"
+	}
+	result, err := c.callWithContext(ctx, "dashboard.get", params)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard.get failed: %w", err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	// Widget coordinates come back as strings, so only names are decoded.
+	type namedWidget struct {
+		Name string `json:"name"`
+	}
+	var dashboards []struct {
+		Pages []struct {
+			Widgets []namedWidget `json:"widgets"`
+		} `json:"pages"`
+		Widgets []namedWidget `json:"widgets"`
+	}
+	if err := json.Unmarshal(data, &dashboards); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dashboards: %w", err)
+	}
+	if len(dashboards) == 0 {
+		return []Drift{{"dashboard", name, "missing"}}, nil
+	}
+
+	have := make(map[string]bool)
+	for _, w := range dashboards[0].Widgets {
+		have[w.Name] = true
+	}
+	for _, page := range dashboards[0].Pages {
+		for _, w := range page.Widgets {
+			have[w.Name] = true
+		}
+	}
+
+	var drifts []Drift
+	// Placeholder IDs make every optional graph widget part of the check.
+	for _, w := range c.dashboardWidgets("", "", "", "-", "-") {
+		if widget := w["name"].(string); !have[widget] {
+			drifts = append(drifts, Drift{"dashboard widget", widget, "missing"})
+		}
+	}
+	return drifts, nil
+}
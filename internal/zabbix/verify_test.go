@@ -0,0 +1,170 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCtx(t *testing.T) {
+	// The actual objects start as what prepare creates, then drift.
+	created := recordCreates(t, nil)
+	actual := func(method string, mutate func(o map[string]interface{}) bool) []map[string]interface{} {
+		var out []map[string]interface{}
+		for _, o := range created[method] {
+			got := map[string]interface{}{
+				"key_":        o["key_"],
+				"description": o["description"],
+				"value_type":  fmt.Sprint(o["value_type"]),
+				"status":      "0",
+				"lifetime":    o["lifetime"],
+			}
+			if mutate(got) {
+				out = append(out, got)
+			}
+		}
+		return out
+	}
+
+	rules := append(actual("discoveryrule.create", func(o map[string]interface{}) bool {
+		if o["key_"] == "vulners.packages_lld" {
+			o["lifetime"] = "30d"
+		}
+		return true
+	}),
+		map[string]interface{}{"key_": "vulners.obsolete_lld", "status": "0"})
+	protos := actual("itemprototype.create", func(o map[string]interface{}) bool {
+		return o["key_"] != "vulners.bulletins[{#B.ID}]"
+	})
+	items := actual("item.create", func(o map[string]interface{}) bool {
+		if o["key_"] == "vulners.Maximum" {
+			o["value_type"] = "3"
+		}
+		return true
+	})
+	triggers := actual("triggerprototype.create", func(o map[string]interface{}) bool {
+		if strings.Contains(fmt.Sprint(o["description"]), "{#H.VNAME}") {
+			o["status"] = "1"
+		}
+		return true
+	})
+
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		switch method {
+		case "host.get":
+			return []map[string]string{
+				{"hostid": "1", "host": "vulners.hosts"},
+				{"hostid": "2", "host": "vulners.packages"},
+				{"hostid": "3", "host": "vulners.bulletins"},
+			}, nil
+		case "template.get":
+			return []map[string]string{{"templateid": "100", "host": "Vulners"}}, nil
+		case "discoveryrule.get":
+			return rules, nil
+		case "itemprototype.get":
+			return protos, nil
+		case "item.get":
+			return items, nil
+		case "triggerprototype.get":
+			return triggers, nil
+		case "dashboard.get":
+			return []interface{}{map[string]interface{}{
+				"dashboardid": "7",
+				"pages": []interface{}{map[string]interface{}{"widgets": []interface{}{
+					map[string]interface{}{"name": "Vulners - Hosts", "x": "0"},
+					map[string]interface{}{"name": "Vulners - Packages", "x": "8"},
+					map[string]interface{}{"name": "Vulners - Bulletins", "x": "8"},
+					map[string]interface{}{"name": "Median CVSS Score", "x": "0"},
+				}}},
+			}}, nil
+		default:
+			return nil, &APIError{Code: -1, Message: "unexpected", Data: method}
+		}
+	})
+	defer ts.Close()
+
+	drifts, err := newTestClient(t, ts).VerifyCtx(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCtx: %v", err)
+	}
+
+	var got []string
+	for _, d := range drifts {
+		got = append(got, d.String())
+	}
+	sort.Strings(got)
+	want := []string{
+		"dashboard widget CVSS Score ratio by servers: missing",
+		"discovery rule vulners.obsolete_lld: not expected (disabled by scan.enabled_lld or obsolete)",
+		"discovery rule vulners.packages_lld: lifetime is 30d, want 0 (scan.lld_lifetime)",
+		"item prototype vulners.bulletins[{#B.ID}]: missing",
+		"item vulners.Maximum: value_type is 3, want 0",
+		"virtual host vulners.statistics: missing",
+	}
+	hostTrigger := ""
+	for _, d := range drifts {
+		if d.Object == "trigger prototype" {
+			hostTrigger = d.String()
+		}
+	}
+	if !strings.HasSuffix(hostTrigger, ": disabled") || !strings.Contains(hostTrigger, "{#H.VNAME}") {
+		t.Errorf("trigger drift = %q, want the host trigger prototype reported disabled", hostTrigger)
+	}
+	got = slices.DeleteFunc(got, func(s string) bool { return s == hostTrigger })
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("drifts:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestVerifyCtx_MissingTemplateAndDashboard(t *testing.T) {
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		return []interface{}{}, nil
+	})
+	defer ts.Close()
+
+	drifts, err := newTestClient(t, ts).VerifyCtx(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCtx: %v", err)
+	}
+	if len(drifts) != 6 {
+		t.Errorf("got %d drifts, want 4 hosts, the template and the dashboard: %v", len(drifts), drifts)
+	}
+}
+
+func TestCompletedStepsCtx(t *testing.T) {
+	ts := newTestServer(t, func(method string, params json.RawMessage) (interface{}, *APIError) {
+		var p struct {
+			Filter struct {
+				Host string `json:"host"`
+			} `json:"filter"`
+		}
+		_ = json.Unmarshal(params, &p)
+		switch {
+		case method == "template.get" && p.Filter.Host == "tmpl.vulners.os-report":
+			return []map[string]string{{"templateid": "50", "host": p.Filter.Host}}, nil
+		case method == "item.get":
+			return []map[string]string{
+				{"itemid": "1", "key_": "system.sw.os"},
+				{"itemid": "2", "key_": "system.sw.packages"},
+			}, nil
+		default:
+			return []interface{}{}, nil
+		}
+	})
+	defer ts.Close()
+
+	c := newTestClient(t, ts)
+	c.cfg.Scan.OSReportTemplate = "tmpl.vulners.os-report"
+	done, err := c.CompletedStepsCtx(context.Background())
+	if err != nil {
+		t.Fatalf("CompletedStepsCtx: %v", err)
+	}
+	want := map[string]bool{StepTemplates: true, StepVirtualHosts: false, StepDashboard: false}
+	if fmt.Sprint(done) != fmt.Sprint(want) {
+		t.Errorf("completed = %v, want %v", done, want)
+	}
+}